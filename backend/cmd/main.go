@@ -1,83 +1,157 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	stdhttp "net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"vehicle-showroom/internal/config"
 	"vehicle-showroom/internal/database"
 	"vehicle-showroom/internal/delivery/http"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/events"
+	"vehicle-showroom/internal/logger"
+	"vehicle-showroom/internal/metrics"
+	"vehicle-showroom/internal/notifier"
 	"vehicle-showroom/internal/repository"
+	"vehicle-showroom/internal/scheduler"
+	"vehicle-showroom/internal/storage"
 	"vehicle-showroom/internal/usecase"
 )
 
 func main() {
 	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
-	}
+	envErr := godotenv.Load()
 
 	// Initialize config
 	cfg := config.New()
 
+	// Initialize logging
+	logger.New(cfg.Log)
+	if envErr != nil {
+		slog.Info("No .env file found")
+	}
+
 	// Initialize database
 	db, err := database.NewPostgreSQL(cfg.Database)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		fatal("Failed to connect to database", err)
 	}
 	defer db.Close()
 
 	// Run migrations
 	if err := database.RunMigrations(db); err != nil {
-		log.Fatal("Failed to run migrations:", err)
+		fatal("Failed to run migrations", err)
 	}
 
-	// Seed demo data
-	if err := database.SeedDemoData(db); err != nil {
-		log.Fatal("Failed to seed demo data:", err)
+	metrics.RegisterDBStats(db)
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		fatal("Failed to load migrations", err)
+	}
+
+	// Initialize notifier
+	notify, err := notifier.New(cfg.Notifier)
+	if err != nil {
+		fatal("Failed to initialize notifier", err)
+	}
+
+	// Initialize file storage
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		fatal("Failed to initialize file storage", err)
 	}
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	sessionRepo := repository.NewSessionRepository(db)
 	customerRepo := repository.NewCustomerRepository(db)
+	customerDocumentRepo := repository.NewCustomerDocumentRepository(db)
 	vehicleRepo := repository.NewVehicleRepository(db)
 	transactionRepo := repository.NewTransactionRepository(db)
 	reportRepo := repository.NewReportRepository(db)
 	sparePartRepo := repository.NewSparePartRepository(db)
 	repairRepo := repository.NewRepairRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	healthRepo := repository.NewHealthRepository(db, migrator)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	leadRepo := repository.NewLeadRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
 
 	// Initialize use cases
-	authUsecase := usecase.NewAuthUsecase(userRepo, sessionRepo, cfg.JWT)
+	roleUsecase := usecase.NewRoleUsecase(roleRepo)
+
+	// Initialize the event stream broker
+	broker, err := events.New(cfg.Events, db, cfg.Database, roleUsecase.HasPermission)
+	if err != nil {
+		fatal("Failed to initialize event broker", err)
+	}
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo, broker)
+
+	authUsecase := usecase.NewAuthUsecase(userRepo, sessionRepo, roleRepo, loginAttemptRepo, twoFactorRepo, cfg.JWT, cfg.Login, cfg.Password, cfg.TwoFactor)
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(userRepo, sessionRepo, twoFactorRepo, cfg.TwoFactor, cfg.Login)
+	passwordUsecase := usecase.NewPasswordUsecase(userRepo, sessionRepo, passwordResetRepo, notify, cfg.Password)
 	customerUsecase := usecase.NewCustomerUsecase(customerRepo)
-	vehicleUsecase := usecase.NewVehicleUsecase(vehicleRepo, customerRepo)
-	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, vehicleRepo, customerRepo)
+	customerDocumentUsecase := usecase.NewCustomerDocumentUsecase(customerDocumentRepo, customerRepo, store)
+	customerPrivacyUsecase := usecase.NewCustomerPrivacyUsecase(customerRepo, customerDocumentRepo, leadRepo, store)
+	vehicleUsecase := usecase.NewVehicleUsecase(vehicleRepo, customerRepo, notificationUsecase)
+	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, vehicleRepo, customerRepo, customerDocumentRepo, notificationUsecase, cfg.KYC)
 	reportUsecase := usecase.NewReportUsecase(reportRepo)
 	sparePartUsecase := usecase.NewSparePartUsecase(sparePartRepo)
-	repairUsecase := usecase.NewRepairUsecase(repairRepo, vehicleRepo, sparePartRepo)
+	repairUsecase := usecase.NewRepairUsecase(repairRepo, vehicleRepo, sparePartRepo, notificationUsecase)
+	healthUsecase := usecase.NewHealthUsecase(healthRepo, cfg.Timeout)
+	searchUsecase := usecase.NewSearchUsecase(searchRepo, roleUsecase)
+	leadUsecase := usecase.NewLeadUsecase(leadRepo, vehicleRepo, customerRepo)
+	taskUsecase := usecase.NewTaskUsecase(taskRepo, userRepo, roleUsecase, notificationUsecase, notify)
 
 	// Initialize HTTP handlers
 	authHandler := http.NewAuthHandler(authUsecase)
+	passwordHandler := http.NewPasswordHandler(passwordUsecase)
+	twoFactorHandler := http.NewTwoFactorHandler(twoFactorUsecase)
 	customerHandler := http.NewCustomerHandler(customerUsecase)
+	customerDocumentHandler := http.NewCustomerDocumentHandler(customerDocumentUsecase)
+	customerPrivacyHandler := http.NewCustomerPrivacyHandler(customerPrivacyUsecase)
 	vehicleHandler := http.NewVehicleHandler(vehicleUsecase)
 	transactionHandler := http.NewTransactionHandler(transactionUsecase)
 	reportHandler := http.NewReportHandler(reportUsecase)
 	sparePartHandler := http.NewSparePartHandler(sparePartUsecase)
 	repairHandler := http.NewRepairHandler(repairUsecase)
+	roleHandler := http.NewRoleHandler(roleUsecase)
+	healthHandler := http.NewHealthHandler(healthUsecase)
+	searchHandler := http.NewSearchHandler(searchUsecase)
+	leadHandler := http.NewLeadHandler(leadUsecase)
+	taskHandler := http.NewTaskHandler(taskUsecase)
+	notificationHandler := http.NewNotificationHandler(notificationUsecase, time.Duration(cfg.JWT.AccessExpireMinutes)*time.Minute)
 
 	// Initialize Middleware
 	authMiddleware := http.AuthMiddleware(authUsecase)
+	can := func(permissions ...string) gin.HandlerFunc {
+		return http.RequirePermission(roleUsecase, permissions...)
+	}
 
 	// Setup router
-	router := gin.Default()
+	router := gin.New()
+	router.Use(http.RequestID(), http.RequestLogger(), http.Metrics(), http.Recovery(), http.ErrorHandler())
+	router.NoRoute(http.NoRoute)
 
 	// Add CORS middleware
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -87,67 +161,159 @@ func main() {
 		c.Next()
 	})
 
+	// Probes for the reverse proxy / orchestrator
+	router.GET("/healthz", healthHandler.Live)
+	router.GET("/readyz", healthHandler.Ready)
+	router.GET("/metrics", http.MetricsHandler(cfg.Metrics.Token))
+
 	// Setup routes
 	api := router.Group("/api/v1")
+	api.Use(http.Timeout(seconds(cfg.Timeout.RequestSeconds), seconds(cfg.Timeout.ExportSeconds), routeTimeouts(cfg.Timeout)))
 	{
 		auth := api.Group("/auth")
 		{
 			auth.POST("/login", authHandler.Login)
-			auth.POST("/register", authHandler.Register)
+			auth.POST("/login/2fa", authHandler.VerifyTwoFactor)
+			auth.POST("/refresh", authHandler.Refresh)
+			auth.POST("/forgot-password", passwordHandler.ForgotPassword)
+			auth.POST("/reset-password", passwordHandler.ResetPassword)
+			auth.POST("/change-password", authMiddleware, passwordHandler.ChangePassword)
 			auth.POST("/logout", authMiddleware, authHandler.Logout)
 			auth.GET("/me", authMiddleware, authHandler.GetProfile)
+			auth.GET("/sessions", authMiddleware, authHandler.ListSessions)
+			auth.DELETE("/sessions/:id", authMiddleware, authHandler.RevokeSession)
+			auth.GET("/2fa", authMiddleware, twoFactorHandler.Status)
+			auth.POST("/2fa/setup", authMiddleware, twoFactorHandler.Setup)
+			auth.POST("/2fa/enable", authMiddleware, twoFactorHandler.Enable)
+			auth.POST("/2fa/disable", authMiddleware, twoFactorHandler.Disable)
+			auth.POST("/2fa/recovery-codes", authMiddleware, twoFactorHandler.RegenerateRecoveryCodes)
 		}
 
+		// EventSource cannot send headers, so the stream also takes the
+		// token as ?access_token=
+		api.GET("/notifications/stream", http.QueryToken(), authMiddleware, http.RequireTwoFactorEnrollment(authUsecase), notificationHandler.Stream)
+
 		// Protected routes
 		protected := api.Group("")
-		protected.Use(authMiddleware)
+		protected.Use(authMiddleware, http.RequireTwoFactorEnrollment(authUsecase))
 		{
+			// Accounts are created by administrators, never by self sign-up
+			protected.POST("/auth/register", can(entity.PermUserManage), authHandler.Register)
+
+			users := protected.Group("/users")
+			{
+				users.POST("/:id/force-logout", can(entity.PermUserManage), authHandler.ForceLogout)
+				users.POST("/:id/unlock", can(entity.PermUserManage), authHandler.UnlockUser)
+				users.POST("/:id/2fa/reset", can(entity.PermUserManage), twoFactorHandler.Reset)
+			}
+
+			roles := protected.Group("/roles")
+			roles.Use(can(entity.PermRoleManage))
+			{
+				roles.GET("", roleHandler.List)
+				roles.POST("", roleHandler.Create)
+				roles.GET("/:id", roleHandler.GetByID)
+				roles.PUT("/:id", roleHandler.Update)
+				roles.DELETE("/:id", roleHandler.Delete)
+			}
+			protected.GET("/permissions", can(entity.PermRoleManage), roleHandler.ListPermissions)
+
 			customers := protected.Group("/customers")
 			{
-				customers.GET("", customerHandler.List)
-				customers.POST("", http.RoleMiddleware("admin", "cashier"), customerHandler.Create)
-				customers.GET("/:id", customerHandler.GetByID)
-				customers.PUT("/:id", http.RoleMiddleware("admin", "cashier"), customerHandler.Update)
-				customers.DELETE("/:id", http.RoleMiddleware("admin"), customerHandler.Delete)
+				customers.GET("", can(entity.PermCustomerView), customerHandler.List)
+				customers.POST("", can(entity.PermCustomerCreate), customerHandler.Create)
+				customers.GET("/:id", can(entity.PermCustomerView), customerHandler.GetByID)
+				customers.GET("/:id/profile", can(entity.PermCustomerView), customerHandler.Profile)
+				customers.POST("/:id/notes", can(entity.PermCustomerUpdate), customerHandler.AddNote)
+				customers.GET("/:id/duplicates", can(entity.PermCustomerView), customerHandler.Duplicates)
+				customers.POST("/:id/merge", can(entity.PermCustomerMerge), customerHandler.Merge)
+				customers.GET("/:id/merges", can(entity.PermCustomerView), customerHandler.Merges)
+				customers.GET("/:id/kyc", can(entity.PermCustomerView), customerDocumentHandler.KYCStatus)
+				customers.GET("/:id/documents", can(entity.PermCustomerDocumentView), customerDocumentHandler.List)
+				customers.POST("/:id/documents", can(entity.PermCustomerUpdate), customerDocumentHandler.Upload)
+				customers.GET("/:id/documents/:documentId/file", can(entity.PermCustomerDocumentView), customerDocumentHandler.Download)
+				customers.POST("/:id/documents/:documentId/verify", can(entity.PermCustomerDocumentVerify), customerDocumentHandler.Verify)
+				customers.DELETE("/:id/documents/:documentId", can(entity.PermCustomerUpdate), customerDocumentHandler.Delete)
+				customers.GET("/:id/consents", can(entity.PermCustomerView), customerPrivacyHandler.Consents)
+				customers.PUT("/:id/consents", can(entity.PermCustomerUpdate), customerPrivacyHandler.RecordConsent)
+				customers.GET("/:id/personal-data", can(entity.PermCustomerPrivacy), customerPrivacyHandler.Export)
+				customers.POST("/:id/anonymize", can(entity.PermCustomerPrivacy), customerPrivacyHandler.Anonymize)
+				customers.PUT("/:id", can(entity.PermCustomerUpdate), customerHandler.Update)
+				customers.DELETE("/:id", can(entity.PermCustomerDelete), customerHandler.Delete)
 			}
 
 			vehicles := protected.Group("/vehicles")
 			{
-				vehicles.GET("", vehicleHandler.List)
-				vehicles.POST("", http.RoleMiddleware("admin", "cashier"), vehicleHandler.Create)
-				vehicles.GET("/:id", vehicleHandler.GetByID)
-				vehicles.PUT("/:id", http.RoleMiddleware("admin", "cashier", "mechanic"), vehicleHandler.Update)
-				vehicles.DELETE("/:id", http.RoleMiddleware("admin"), vehicleHandler.Delete)
-				vehicles.PUT("/:id/status", http.RoleMiddleware("admin", "cashier", "mechanic"), vehicleHandler.UpdateStatus)
+				vehicles.GET("", can(entity.PermVehicleView), vehicleHandler.List)
+				vehicles.POST("", can(entity.PermVehicleCreate), vehicleHandler.Create)
+				vehicles.POST("/import", can(entity.PermVehicleCreate), vehicleHandler.Import)
+				vehicles.GET("/:id", can(entity.PermVehicleView), vehicleHandler.GetByID)
+				vehicles.PUT("/:id", can(entity.PermVehicleUpdate), vehicleHandler.Update)
+				vehicles.DELETE("/:id", can(entity.PermVehicleDelete), vehicleHandler.Delete)
+				vehicles.PUT("/:id/status", can(entity.PermVehicleStatusUpdate), vehicleHandler.UpdateStatus)
+				vehicles.PUT("/:id/approve-price", can(entity.PermVehiclePriceApprove), vehicleHandler.ApprovePrice)
+				vehicles.GET("/:id/test-drives", can(entity.PermLeadView), leadHandler.VehicleTestDrives)
+			}
+
+			leads := protected.Group("/leads")
+			{
+				leads.GET("", can(entity.PermLeadView), leadHandler.List)
+				leads.POST("", can(entity.PermLeadCreate), leadHandler.Create)
+				leads.GET("/:id", can(entity.PermLeadView), leadHandler.GetByID)
+				leads.PUT("/:id", can(entity.PermLeadUpdate), leadHandler.Update)
+				leads.PUT("/:id/status", can(entity.PermLeadUpdate), leadHandler.UpdateStatus)
+				leads.POST("/:id/convert", can(entity.PermLeadUpdate, entity.PermCustomerCreate), leadHandler.Convert)
+				leads.POST("/:id/test-drives", can(entity.PermLeadUpdate), leadHandler.ScheduleTestDrive)
+				leads.PUT("/:id/test-drives/:testDriveId/status", can(entity.PermLeadUpdate), leadHandler.UpdateTestDriveStatus)
+			}
+
+			tasks := protected.Group("/tasks")
+			{
+				tasks.GET("", can(entity.PermTaskView), taskHandler.List)
+				tasks.POST("", can(entity.PermTaskCreate), taskHandler.Create)
+				tasks.GET("/:id", can(entity.PermTaskView), taskHandler.GetByID)
+				tasks.PUT("/:id", can(entity.PermTaskUpdate), taskHandler.Update)
+				// Assignees may update their own tasks; see TaskUsecase.UpdateStatus
+				tasks.PUT("/:id/status", taskHandler.UpdateStatus)
+			}
+			protected.GET("/me/tasks", taskHandler.Mine)
+
+			// Each user sees and marks only their own notifications
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("", notificationHandler.List)
+				notifications.PUT("/read-all", notificationHandler.MarkAllRead)
+				notifications.PUT("/:id/read", notificationHandler.MarkRead)
 			}
 
 			transactions := protected.Group("/transactions")
 			{
 				purchases := transactions.Group("/purchases")
-				purchases.Use(http.RoleMiddleware("admin", "cashier"))
 				{
-					purchases.GET("", transactionHandler.ListPurchases)
-					purchases.POST("", transactionHandler.CreatePurchase)
-					purchases.GET("/:id", transactionHandler.GetPurchaseByID)
+					purchases.GET("", can(entity.PermPurchaseView), transactionHandler.ListPurchases)
+					purchases.POST("", can(entity.PermPurchaseCreate), transactionHandler.CreatePurchase)
+					purchases.GET("/:id", can(entity.PermPurchaseView), transactionHandler.GetPurchaseByID)
 				}
 
 				sales := transactions.Group("/sales")
-				sales.Use(http.RoleMiddleware("admin", "cashier"))
 				{
-					sales.GET("", transactionHandler.ListSales)
-					sales.POST("", transactionHandler.CreateSales)
-					sales.GET("/:id", transactionHandler.GetSalesByID)
+					sales.GET("", can(entity.PermSalesView), transactionHandler.ListSales)
+					sales.POST("", can(entity.PermSalesCreate), transactionHandler.CreateSales)
+					sales.GET("/:id", can(entity.PermSalesView), transactionHandler.GetSalesByID)
 				}
 			}
 
+			// Hits are limited to the kinds the caller's role may view
+			protected.GET("/search", searchHandler.Search)
+
 			dashboard := protected.Group("/dashboard")
-			dashboard.Use(http.RoleMiddleware("admin"))
+			dashboard.Use(can(entity.PermDashboardView))
 			{
 				dashboard.GET("/stats", transactionHandler.GetDashboardStats)
 			}
 
 			reports := protected.Group("/reports")
-			reports.Use(http.RoleMiddleware("admin"))
+			reports.Use(can(entity.PermReportView))
 			{
 				reports.GET("/profitability", reportHandler.GetVehicleProfitability)
 				reports.GET("/sales", reportHandler.GetSalesReport)
@@ -155,37 +321,104 @@ func main() {
 			}
 
 			spareParts := protected.Group("/spare-parts")
-			spareParts.Use(http.RoleMiddleware("admin", "mechanic"))
 			{
-				spareParts.GET("", sparePartHandler.List)
-				spareParts.POST("", sparePartHandler.Create)
-				spareParts.GET("/:id", sparePartHandler.GetByID)
-				spareParts.PUT("/:id", sparePartHandler.Update)
-				spareParts.DELETE("/:id", http.RoleMiddleware("admin"), sparePartHandler.Delete)
+				spareParts.GET("", can(entity.PermSparePartView), sparePartHandler.List)
+				spareParts.POST("", can(entity.PermSparePartCreate), sparePartHandler.Create)
+				spareParts.POST("/import", can(entity.PermSparePartCreate), sparePartHandler.Import)
+				spareParts.GET("/:id", can(entity.PermSparePartView), sparePartHandler.GetByID)
+				spareParts.PUT("/:id", can(entity.PermSparePartUpdate), sparePartHandler.Update)
+				spareParts.DELETE("/:id", can(entity.PermSparePartDelete), sparePartHandler.Delete)
 			}
 
 			repairs := protected.Group("/repairs")
-			repairs.Use(http.RoleMiddleware("admin", "mechanic"))
 			{
-				repairs.GET("", repairHandler.List)
-				repairs.POST("", repairHandler.Create)
-				repairs.GET("/:id", repairHandler.GetByID)
-				repairs.PUT("/:id", repairHandler.Update)
-				repairs.PUT("/:id/status", repairHandler.UpdateStatus)
-				repairs.POST("/:id/parts", repairHandler.AddPart)
-				repairs.DELETE("/:id/parts/:partId", repairHandler.RemovePart)
+				repairs.GET("", can(entity.PermRepairView), repairHandler.List)
+				repairs.POST("", can(entity.PermRepairCreate), repairHandler.Create)
+				repairs.GET("/:id", can(entity.PermRepairView), repairHandler.GetByID)
+				repairs.PUT("/:id", can(entity.PermRepairUpdate), repairHandler.Update)
+				repairs.PUT("/:id/status", can(entity.PermRepairStatusUpdate), repairHandler.UpdateStatus)
+				repairs.POST("/:id/parts", can(entity.PermRepairPartsManage), repairHandler.AddPart)
+				repairs.DELETE("/:id/parts/:partId", can(entity.PermRepairPartsManage), repairHandler.RemovePart)
 			}
 		}
 	}
 
 	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	srv := &stdhttp.Server{
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      router,
+		ReadTimeout:  time.Duration(cfg.Server.ReadTimeoutSeconds) * time.Second,
+		WriteTimeout: time.Duration(cfg.Server.WriteTimeoutSeconds) * time.Second,
+		IdleTimeout:  time.Duration(cfg.Server.IdleTimeoutSeconds) * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server starting", "port", cfg.Server.Port)
+		serverErr <- srv.ListenAndServe()
+	}()
+
+	// Background jobs stop with ctx, before the server drains
+	jobs := scheduler.New(scheduler.Job{
+		Name:     "tasks",
+		Interval: seconds(cfg.Scheduler.TaskIntervalSeconds),
+		Run:      taskUsecase.ProcessDue,
+	})
+	if cfg.Scheduler.Enabled {
+		jobs.Start(ctx)
+	} else {
+		slog.Info("Scheduler disabled")
+	}
+
+	select {
+	case err := <-serverErr:
+		fatal("Failed to start server", err)
+	case <-ctx.Done():
 	}
+	stop()
+	jobs.Wait()
+
+	// Fail readiness first and give the load balancer time to notice, then
+	// wait for in-flight requests to finish. Event streams never finish by
+	// themselves, so they are closed first.
+	slog.Info("Shutting down, draining in-flight requests", "drain_seconds", cfg.Server.DrainSeconds)
+	healthUsecase.StartDraining()
+	time.Sleep(time.Duration(cfg.Server.DrainSeconds) * time.Second)
+	broker.Close()
 
-	log.Printf("Server starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, stdhttp.ErrServerClosed) {
+		slog.Error("Server did not shut down cleanly", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+// routeTimeouts gives reports their longer budget unless ROUTE_TIMEOUTS says
+// otherwise.
+func routeTimeouts(cfg config.TimeoutConfig) map[string]time.Duration {
+	routes := map[string]time.Duration{
+		"/api/v1/reports":            seconds(cfg.ReportSeconds),
+		"/api/v1/vehicles/import":    seconds(cfg.ImportSeconds),
+		"/api/v1/spare-parts/import": seconds(cfg.ImportSeconds),
+		// Open until the client leaves; see NotificationHandler.Stream
+		"/api/v1/notifications/stream": 0,
+	}
+	for prefix, n := range cfg.Routes {
+		routes[prefix] = seconds(n)
 	}
+	return routes
 }
@@ -0,0 +1,102 @@
+// Command migrate manages the database schema.
+//
+//	go run ./cmd/migrate up          apply all pending migrations
+//	go run ./cmd/migrate down [n]    revert the last n migrations (default 1)
+//	go run ./cmd/migrate status      list migrations and when they were applied
+//	go run ./cmd/migrate redo        revert and re-apply the last migration
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"vehicle-showroom/internal/config"
+	"vehicle-showroom/internal/database"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	cfg := config.New()
+
+	db, err := database.NewPostgreSQL(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch os.Args[1] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			fmt.Printf("applied  %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := database.SyncPermissions(db); err != nil {
+			log.Fatal("Failed to sync permissions:", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps < 1 {
+				log.Fatal("down expects a positive number of steps")
+			}
+		}
+		reverted, err := migrator.Down(steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+	case "redo":
+		m, err := migrator.Redo()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("redone   %04d_%s\n", m.Version, m.Name)
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate up | down [n] | status | redo")
+	os.Exit(2)
+}
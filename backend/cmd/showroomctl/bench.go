@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"vehicle-showroom/internal/database"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/repository"
+)
+
+// benchQueries runs the listing and report reads against the configured
+// database and prints how many statements each one sent, next to what
+// loading the vehicle, customer, cashier, mechanic and parts row by row
+// would have cost. Seed a database first (seed demo --size large).
+func benchQueries(app *app, args []string) error {
+	fs := flag.NewFlagSet("bench queries", flag.ExitOnError)
+	limit := fs.Int("limit", 100, "page size for the listings")
+	days := fs.Int("days", 365, "date range of the reports, ending today")
+	fs.Parse(args)
+
+	counter := &database.QueryCounter{}
+	db, err := database.NewCountingPostgreSQL(app.cfg.Database, counter)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	transactionRepo := repository.NewTransactionRepository(db)
+	reportRepo := repository.NewReportRepository(db)
+	repairRepo := repository.NewRepairRepository(db)
+
+	page := entity.ListParams{Page: 1, Limit: *limit, WithTotal: true}
+	end := time.Now()
+	start := end.AddDate(0, 0, -*days)
+
+	// Each bench returns the number of rows and the statement count the
+	// per-row loaders needed for them
+	benches := []struct {
+		name string
+		run  func() (rows, perRow int, err error)
+	}{
+		{"ListSales", func() (int, int, error) {
+			sales, _, err := transactionRepo.ListSales(app.ctx, page, "")
+			return len(sales), 2 + 3*len(sales), err
+		}},
+		{"ListPurchases", func() (int, int, error) {
+			purchases, _, err := transactionRepo.ListPurchases(app.ctx, page, "")
+			return len(purchases), 2 + 3*len(purchases), err
+		}},
+		{"GetSalesReport", func() (int, int, error) {
+			sales, err := reportRepo.GetSalesReport(app.ctx, start, end)
+			return len(sales), 1 + 3*len(sales), err
+		}},
+		{"GetPurchaseReport", func() (int, int, error) {
+			purchases, err := reportRepo.GetPurchaseReport(app.ctx, start, end)
+			return len(purchases), 1 + 3*len(purchases), err
+		}},
+		{"Repairs List", func() (int, int, error) {
+			repairs, _, err := repairRepo.List(app.ctx, page, "", "")
+			perRow := 2
+			for _, repair := range repairs {
+				// vehicle, parts, one spare part per part, and the mechanic
+				perRow += 2 + len(repair.RepairParts)
+				if repair.MechanicID != nil {
+					perRow++
+				}
+			}
+			return len(repairs), perRow, err
+		}},
+	}
+
+	fmt.Printf("%-20s %6s %8s %10s %10s\n", "read", "rows", "queries", "per-row", "elapsed")
+	for _, b := range benches {
+		counter.Reset()
+		began := time.Now()
+		rows, perRow, err := b.run()
+		if err != nil {
+			return fmt.Errorf("%s: %w", b.name, err)
+		}
+		fmt.Printf("%-20s %6d %8d %10d %10s\n", b.name, rows, counter.Count(), perRow, time.Since(began).Round(time.Millisecond))
+	}
+	return nil
+}
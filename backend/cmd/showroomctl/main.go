@@ -0,0 +1,108 @@
+// Command showroomctl performs administrative tasks against the showroom
+// database. The API server never seeds or bootstraps data on its own; use
+// this tool instead.
+//
+//	go run ./cmd/showroomctl seed demo [--size small|medium|large] [--vehicles n] ...
+//	go run ./cmd/showroomctl user create-admin --username u --email e --full-name n
+//	go run ./cmd/showroomctl user reset-password --username u [--password p]
+//	go run ./cmd/showroomctl sessions purge-expired [--keep-days 30]
+//	go run ./cmd/showroomctl numbering repair [--dry-run]
+//	go run ./cmd/showroomctl bench queries [--limit 100] [--days 365]
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"vehicle-showroom/internal/config"
+	"vehicle-showroom/internal/database"
+)
+
+type command struct {
+	usage string
+	run   func(app *app, args []string) error
+}
+
+var commands = map[string]map[string]command{
+	"seed": {
+		"demo": {"[--size small|medium|large] [--vehicles n] [--customers n] [--months n] [--random-seed n]", seedDemo},
+	},
+	"user": {
+		"create-admin":   {"--username u --email e --full-name n [--phone p] [--password p]", createAdmin},
+		"reset-password": {"--username u [--password p]", resetPassword},
+	},
+	"sessions": {
+		"purge-expired": {"[--keep-days 30]", purgeExpiredSessions},
+	},
+	"numbering": {
+		"repair": {"[--dry-run]", repairNumbering},
+	},
+	"bench": {
+		"queries": {"[--limit 100] [--days 365]", benchQueries},
+	},
+}
+
+// app holds what every subcommand needs. The database connection is opened
+// before dispatch so that connection errors are reported the same way
+// everywhere. ctx is cancelled on Ctrl-C, which aborts running queries.
+type app struct {
+	ctx context.Context
+	cfg *config.Config
+	db  *sqlx.DB
+}
+
+func main() {
+	log.SetFlags(0)
+
+	if len(os.Args) < 3 {
+		usage()
+	}
+
+	cmd, ok := commands[os.Args[1]][os.Args[2]]
+	if !ok {
+		usage()
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	cfg := config.New()
+
+	db, err := database.NewPostgreSQL(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database: ", err)
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := cmd.run(&app{ctx: ctx, cfg: cfg, db: db}, os.Args[3:]); err != nil {
+		stop()
+		db.Close()
+		log.Fatalf("%s %s: %v", os.Args[1], os.Args[2], err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: showroomctl <command> <subcommand> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, group := range []string{"seed", "user", "sessions", "numbering", "bench"} {
+		names := make([]string, 0, len(commands[group]))
+		for name := range commands[group] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(os.Stderr, "  %s %s %s\n", group, name, commands[group][name].usage)
+		}
+	}
+	os.Exit(2)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"vehicle-showroom/internal/database"
+	"vehicle-showroom/internal/repository"
+)
+
+// repairNumbering resets id sequences that fell behind their tables and
+// prints the next business code each generator will hand out, so an
+// operator can check them against the paper trail.
+func repairNumbering(app *app, args []string) error {
+	fs := flag.NewFlagSet("numbering repair", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "report problems without fixing them")
+	fs.Parse(args)
+
+	statuses, err := database.RepairSequences(app.db, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	behind := 0
+	for _, s := range statuses {
+		state := "ok"
+		switch {
+		case s.Repaired:
+			state = fmt.Sprintf("reset from %d to %d", s.LastValue, s.MaxID)
+		case s.Behind():
+			state = fmt.Sprintf("behind: at %d, max id %d", s.LastValue, s.MaxID)
+		}
+		if s.Behind() {
+			behind++
+		}
+		fmt.Printf("%-40s %s\n", s.Table+"."+s.Column, state)
+	}
+
+	customerRepo := repository.NewCustomerRepository(app.db)
+	vehicleRepo := repository.NewVehicleRepository(app.db)
+	sparePartRepo := repository.NewSparePartRepository(app.db)
+	repairRepo := repository.NewRepairRepository(app.db)
+	transactionRepo := repository.NewTransactionRepository(app.db)
+
+	generators := []struct {
+		name     string
+		generate func(context.Context) (string, error)
+	}{
+		{"customer code", customerRepo.GenerateCustomerCode},
+		{"vehicle code", vehicleRepo.GenerateVehicleCode},
+		{"spare part code", sparePartRepo.GeneratePartCode},
+		{"repair number", repairRepo.GenerateRepairNumber},
+		{"purchase number", transactionRepo.GeneratePurchaseTransactionNumber},
+		{"purchase invoice", transactionRepo.GeneratePurchaseInvoiceNumber},
+		{"sales number", transactionRepo.GenerateSalesTransactionNumber},
+		{"sales invoice", transactionRepo.GenerateSalesInvoiceNumber},
+	}
+
+	fmt.Println()
+	for _, g := range generators {
+		code, err := g.generate(app.ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("next %-35s %s\n", g.name, code)
+	}
+
+	if *dryRun && behind > 0 {
+		fmt.Printf("\n%d sequences are behind; run without --dry-run to fix them\n", behind)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"vehicle-showroom/internal/database"
+)
+
+func seedDemo(app *app, args []string) error {
+	fs := flag.NewFlagSet("seed demo", flag.ExitOnError)
+	size := fs.String("size", "small", "preset: small, medium or large")
+	vehicles := fs.Int("vehicles", 0, "number of vehicles (overrides the preset)")
+	customers := fs.Int("customers", 0, "number of customers (overrides the preset)")
+	mechanics := fs.Int("mechanics", 0, "number of mechanic accounts (overrides the preset)")
+	months := fs.Int("months", 0, "months of history to spread purchases over (overrides the preset)")
+	randomSeed := fs.Int64("random-seed", 0, "seed for reproducible data; 0 picks one from the clock")
+	force := fs.Bool("force", false, "seed even when GIN_MODE=release")
+	fs.Parse(args)
+
+	// Demo users have well-known passwords
+	if app.cfg.Server.Mode == "release" && !*force {
+		return errors.New("refusing to seed demo data with GIN_MODE=release; pass --force if you really mean it")
+	}
+
+	opts, ok := database.SeedSizes[*size]
+	if !ok {
+		return fmt.Errorf("unknown size %q", *size)
+	}
+	if *vehicles > 0 {
+		opts.Vehicles = *vehicles
+	}
+	if *customers > 0 {
+		opts.Customers = *customers
+	}
+	if *mechanics > 0 {
+		opts.Mechanics = *mechanics
+	}
+	if *months > 0 {
+		opts.Months = *months
+	}
+	opts.RandomSeed = *randomSeed
+
+	if err := requireCurrentSchema(app); err != nil {
+		return err
+	}
+
+	result, err := database.SeedDemo(app.db, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("users:       %d new\n", result.Users)
+	fmt.Printf("customers:   %d\n", result.Customers)
+	fmt.Printf("spare parts: %d\n", result.SpareParts)
+	fmt.Printf("vehicles:    %d (%d purchases, %d repairs, %d sales)\n",
+		result.Vehicles, result.Purchases, result.Repairs, result.Sales)
+	return nil
+}
+
+func requireCurrentSchema(app *app) error {
+	migrator, err := database.NewMigrator(app.db)
+	if err != nil {
+		return err
+	}
+
+	current, err := migrator.CurrentVersion(app.ctx)
+	if err != nil {
+		return err
+	}
+	if current < migrator.LatestVersion() {
+		return fmt.Errorf("schema is at version %d but %d is available; run `go run ./cmd/migrate up` first",
+			current, migrator.LatestVersion())
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"vehicle-showroom/internal/repository"
+)
+
+func purgeExpiredSessions(app *app, args []string) error {
+	fs := flag.NewFlagSet("sessions purge-expired", flag.ExitOnError)
+	keepDays := fs.Int("keep-days", 30, "keep ended sessions this many days for auditing")
+	fs.Parse(args)
+
+	if *keepDays < 0 {
+		return errors.New("--keep-days must not be negative")
+	}
+
+	before := time.Now().AddDate(0, 0, -*keepDays)
+	purged, err := repository.NewSessionRepository(app.db).PurgeExpired(app.ctx, before)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("purged %d sessions that ended before %s\n", purged, before.Format("2006-01-02 15:04"))
+	return nil
+}
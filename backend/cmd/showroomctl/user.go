@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/notifier"
+	"vehicle-showroom/internal/repository"
+	"vehicle-showroom/internal/usecase"
+)
+
+// Passwords can also come from SHOWROOMCTL_PASSWORD so they do not end up in
+// shell history.
+const passwordEnv = "SHOWROOMCTL_PASSWORD"
+
+func createAdmin(app *app, args []string) error {
+	fs := flag.NewFlagSet("user create-admin", flag.ExitOnError)
+	username := fs.String("username", "", "login name")
+	email := fs.String("email", "", "email address")
+	fullName := fs.String("full-name", "", "display name")
+	phone := fs.String("phone", "", "phone number")
+	password := fs.String("password", "", "password; read from "+passwordEnv+" or stdin when omitted")
+	fs.Parse(args)
+
+	if *username == "" || *email == "" || *fullName == "" {
+		return errors.New("--username, --email and --full-name are required")
+	}
+
+	if *password == "" {
+		var err error
+		if *password, err = readPassword(); err != nil {
+			return err
+		}
+	}
+
+	authUsecase := usecase.NewAuthUsecase(
+		repository.NewUserRepository(app.db),
+		repository.NewSessionRepository(app.db),
+		repository.NewRoleRepository(app.db),
+		repository.NewLoginAttemptRepository(app.db),
+		repository.NewTwoFactorRepository(app.db),
+		app.cfg.JWT, app.cfg.Login, app.cfg.Password, app.cfg.TwoFactor,
+	)
+
+	user, err := authUsecase.Register(app.ctx, &entity.RegisterRequest{
+		Username: *username,
+		Email:    *email,
+		Password: *password,
+		FullName: *fullName,
+		Phone:    *phone,
+		Role:     "admin",
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("created admin %s (id %d)\n", user.Username, user.ID)
+	return nil
+}
+
+func resetPassword(app *app, args []string) error {
+	fs := flag.NewFlagSet("user reset-password", flag.ExitOnError)
+	username := fs.String("username", "", "login name")
+	password := fs.String("password", "", "new password; read from "+passwordEnv+", or generated and printed when omitted")
+	fs.Parse(args)
+
+	if *username == "" {
+		return errors.New("--username is required")
+	}
+
+	generated := false
+	if *password == "" {
+		*password = os.Getenv(passwordEnv)
+	}
+	if *password == "" {
+		var err error
+		if *password, err = generatePassword(); err != nil {
+			return err
+		}
+		generated = true
+	}
+
+	notify, err := notifier.New(app.cfg.Notifier)
+	if err != nil {
+		return err
+	}
+
+	passwordUsecase := usecase.NewPasswordUsecase(
+		repository.NewUserRepository(app.db),
+		repository.NewSessionRepository(app.db),
+		repository.NewPasswordResetRepository(app.db),
+		notify,
+		app.cfg.Password,
+	)
+
+	if err := passwordUsecase.SetPassword(app.ctx, *username, *password); err != nil {
+		return err
+	}
+
+	fmt.Printf("password for %s has been reset; all sessions were revoked\n", *username)
+	if generated {
+		fmt.Printf("new password: %s\n", *password)
+	}
+	return nil
+}
+
+func readPassword() (string, error) {
+	if password := os.Getenv(passwordEnv); password != "" {
+		return password, nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("no password given")
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// generatePassword returns a random password with every character class, so
+// it passes any combination of password policy settings.
+func generatePassword() (string, error) {
+	classes := []string{
+		"ABCDEFGHJKLMNPQRSTUVWXYZ",
+		"abcdefghijkmnopqrstuvwxyz",
+		"23456789",
+		"!@#$%*-_",
+	}
+	all := strings.Join(classes, "")
+
+	var b strings.Builder
+	for i := 0; i < 20; i++ {
+		set := all
+		if i < len(classes) {
+			set = classes[i]
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate password: %w", err)
+		}
+		b.WriteByte(set[n.Int64()])
+	}
+
+	return b.String(), nil
+}
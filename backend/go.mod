@@ -4,6 +4,7 @@ go 1.21
 
 require (
   github.com/gin-gonic/gin v1.9.1
+  github.com/go-playground/validator/v10 v10.14.0
   github.com/golang-jwt/jwt/v5 v5.0.0
   github.com/jmoiron/sqlx v1.3.5
   github.com/joho/godotenv v1.4.0
@@ -18,7 +19,6 @@ require (
   github.com/gin-contrib/sse v0.1.0 // indirect
   github.com/go-playground/locales v0.14.1 // indirect
   github.com/go-playground/universal-translator v0.18.1 // indirect
-  github.com/go-playground/validator/v10 v10.14.0 // indirect
   github.com/goccy/go-json v0.10.2 // indirect
   github.com/json-iterator/go v1.1.12 // indirect
   github.com/klauspost/cpuid/v2 v2.2.4 // indirect
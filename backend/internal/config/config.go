@@ -3,12 +3,24 @@ package config
 import (
   "os"
   "strconv"
+  "strings"
 )
 
 type Config struct {
-  Database DatabaseConfig
-  JWT      JWTConfig
-  Server   ServerConfig
+  Database  DatabaseConfig
+  JWT       JWTConfig
+  Login     LoginConfig
+  Password  PasswordConfig
+  TwoFactor TwoFactorConfig
+  Notifier  NotifierConfig
+  Events    EventsConfig
+  Storage   StorageConfig
+  KYC       KYCConfig
+  Server    ServerConfig
+  Log       LogConfig
+  Metrics   MetricsConfig
+  Timeout   TimeoutConfig
+  Scheduler SchedulerConfig
 }
 
 type DatabaseConfig struct {
@@ -21,17 +33,154 @@ type DatabaseConfig struct {
 }
 
 type JWTConfig struct {
-  Secret      string
-  ExpireHours int
+  Secret              string
+  AccessExpireMinutes int
+  RefreshExpireHours  int
 }
 
+// LoginConfig controls brute-force protection on the login endpoint.
+type LoginConfig struct {
+  MaxFailedAttempts int // consecutive failures before the account is locked
+  LockoutMinutes    int
+  WindowMinutes     int // look-back window for per-IP and unknown-username counts
+  MaxIPFailures     int // failures from one IP inside the window before it is blocked
+  DelayAfter        int // failures before progressive delay kicks in
+  MaxDelaySeconds   int
+}
+
+type PasswordConfig struct {
+  MinLength          int
+  RequireUppercase   bool
+  RequireLowercase   bool
+  RequireDigit       bool
+  RequireSymbol      bool
+  ResetTokenMinutes  int
+  ResetURL           string // frontend page the reset token is appended to
+  ResetWindowMinutes int    // look-back window for the reset request limits
+  ResetMaxPerEmail   int    // reset requests for one email inside the window
+  ResetMaxPerIP      int    // reset requests from one IP inside the window
+}
+
+// TwoFactorConfig controls TOTP two-factor authentication. When
+// RequireForAdmin is set, admins without 2FA can only reach the enrollment
+// endpoints until they enable it.
+type TwoFactorConfig struct {
+  Issuer           string // shown as the account label in authenticator apps
+  RequireForAdmin  bool
+  ChallengeMinutes int // how long the code prompt after a correct password stays valid
+}
+
+// NotifierConfig selects how outbound messages (e.g. password resets) are
+// delivered. "log" writes to the server log, "file" appends to FilePath.
+type NotifierConfig struct {
+  Driver   string
+  FilePath string
+}
+
+// EventsConfig selects how events reach the clients connected to the event
+// stream. "local" delivers them within this process only, which is enough
+// for a single server; "postgres" relays them through LISTEN/NOTIFY so every
+// server's clients get them.
+type EventsConfig struct {
+  Driver string
+}
+
+// StorageConfig selects where uploaded files are kept. "local" keeps them
+// under LocalPath on the server's disk.
+type StorageConfig struct {
+  Driver    string
+  LocalPath string
+}
+
+// KYCConfig is the know-your-customer policy. With RequireForSales set, a
+// vehicle can only be sold to a customer whose identity documents have been
+// verified.
+type KYCConfig struct {
+  RequireForSales bool
+}
+
+// ServerConfig controls the HTTP server. On SIGTERM readiness fails for
+// DrainSeconds, so load balancers stop sending traffic, then the server stops
+// accepting connections and waits up to ShutdownTimeoutSeconds for
+// in-flight requests to finish.
 type ServerConfig struct {
-  Port string
-  Mode string
+  Port                   string
+  Mode                   string
+  ReadTimeoutSeconds     int
+  WriteTimeoutSeconds    int
+  IdleTimeoutSeconds     int
+  DrainSeconds           int
+  ShutdownTimeoutSeconds int
+}
+
+// LogConfig controls structured logging. Level is debug, info, warn or
+// error; Format is json (default) or text for local development.
+type LogConfig struct {
+  Level  string
+  Format string
+}
+
+// MetricsConfig protects /metrics. With an empty Token the endpoint is open,
+// which is only appropriate when the reverse proxy does not expose it.
+type MetricsConfig struct {
+  Token string
+}
+
+// TimeoutConfig bounds how long a request, including its database queries,
+// may run. Reports, bulk imports and file exports get their own budgets;
+// Routes overrides the budget for route prefixes (e.g. "/api/v1/dashboard"
+// -> 20). Zero means no limit. ReadinessSeconds bounds the database checks
+// of /readyz, past which the database counts as unavailable.
+type TimeoutConfig struct {
+  RequestSeconds   int
+  ReportSeconds    int
+  ImportSeconds    int
+  ExportSeconds    int
+  ReadinessSeconds int
+  Routes           map[string]int
+}
+
+// SchedulerConfig controls the in-process background jobs. Every
+// TaskIntervalSeconds the task job sends due reminders and marks past-due
+// tasks overdue. Jobs claim rows with SKIP LOCKED, so running it on several
+// servers is safe; disable it where a server should only answer requests.
+type SchedulerConfig struct {
+  Enabled             bool
+  TaskIntervalSeconds int
 }
 
 func New() *Config {
-  expireHours, _ := strconv.Atoi(getEnv("JWT_EXPIRE_HOURS", "24"))
+  accessExpireMinutes, _ := strconv.Atoi(getEnv("JWT_ACCESS_EXPIRE_MINUTES", "15"))
+  refreshExpireHours, _ := strconv.Atoi(getEnv("JWT_REFRESH_EXPIRE_HOURS", "720"))
+
+  maxFailedAttempts, _ := strconv.Atoi(getEnv("LOGIN_MAX_FAILED_ATTEMPTS", "5"))
+  lockoutMinutes, _ := strconv.Atoi(getEnv("LOGIN_LOCKOUT_MINUTES", "15"))
+  windowMinutes, _ := strconv.Atoi(getEnv("LOGIN_WINDOW_MINUTES", "15"))
+  maxIPFailures, _ := strconv.Atoi(getEnv("LOGIN_MAX_IP_FAILURES", "20"))
+  delayAfter, _ := strconv.Atoi(getEnv("LOGIN_DELAY_AFTER", "2"))
+  maxDelaySeconds, _ := strconv.Atoi(getEnv("LOGIN_MAX_DELAY_SECONDS", "30"))
+
+  minLength, _ := strconv.Atoi(getEnv("PASSWORD_MIN_LENGTH", "8"))
+  resetTokenMinutes, _ := strconv.Atoi(getEnv("PASSWORD_RESET_TOKEN_MINUTES", "30"))
+  resetWindowMinutes, _ := strconv.Atoi(getEnv("PASSWORD_RESET_WINDOW_MINUTES", "60"))
+  resetMaxPerEmail, _ := strconv.Atoi(getEnv("PASSWORD_RESET_MAX_PER_EMAIL", "3"))
+  resetMaxPerIP, _ := strconv.Atoi(getEnv("PASSWORD_RESET_MAX_PER_IP", "10"))
+
+  challengeMinutes, _ := strconv.Atoi(getEnv("TWO_FACTOR_CHALLENGE_MINUTES", "5"))
+
+  readTimeout, _ := strconv.Atoi(getEnv("SERVER_READ_TIMEOUT_SECONDS", "15"))
+  writeTimeout, _ := strconv.Atoi(getEnv("SERVER_WRITE_TIMEOUT_SECONDS", "60"))
+  idleTimeout, _ := strconv.Atoi(getEnv("SERVER_IDLE_TIMEOUT_SECONDS", "120"))
+  drainSeconds, _ := strconv.Atoi(getEnv("SERVER_DRAIN_SECONDS", "5"))
+  shutdownTimeout, _ := strconv.Atoi(getEnv("SERVER_SHUTDOWN_TIMEOUT_SECONDS", "30"))
+
+  requestTimeout, _ := strconv.Atoi(getEnv("REQUEST_TIMEOUT_SECONDS", "10"))
+  reportTimeout, _ := strconv.Atoi(getEnv("REPORT_TIMEOUT_SECONDS", "45"))
+  importTimeout, _ := strconv.Atoi(getEnv("IMPORT_TIMEOUT_SECONDS", "55"))
+  exportTimeout, _ := strconv.Atoi(getEnv("EXPORT_TIMEOUT_SECONDS", "300"))
+  readinessTimeout, _ := strconv.Atoi(getEnv("READINESS_TIMEOUT_SECONDS", "2"))
+
+  taskInterval, _ := strconv.Atoi(getEnv("TASK_SCHEDULER_INTERVAL_SECONDS", "60"))
 
   return &Config{
     Database: DatabaseConfig{
@@ -43,12 +192,76 @@ func New() *Config {
       SSLMode:  getEnv("DB_SSLMODE", "disable"),
     },
     JWT: JWTConfig{
-      Secret:      getEnv("JWT_SECRET", "your-super-secret-jwt-key"),
-      ExpireHours: expireHours,
+      Secret:              getEnv("JWT_SECRET", "your-super-secret-jwt-key"),
+      AccessExpireMinutes: accessExpireMinutes,
+      RefreshExpireHours:  refreshExpireHours,
+    },
+    Login: LoginConfig{
+      MaxFailedAttempts: maxFailedAttempts,
+      LockoutMinutes:    lockoutMinutes,
+      WindowMinutes:     windowMinutes,
+      MaxIPFailures:     maxIPFailures,
+      DelayAfter:        delayAfter,
+      MaxDelaySeconds:   maxDelaySeconds,
+    },
+    Password: PasswordConfig{
+      MinLength:          minLength,
+      RequireUppercase:   getEnvBool("PASSWORD_REQUIRE_UPPERCASE", true),
+      RequireLowercase:   getEnvBool("PASSWORD_REQUIRE_LOWERCASE", true),
+      RequireDigit:       getEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+      RequireSymbol:      getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+      ResetTokenMinutes:  resetTokenMinutes,
+      ResetURL:           getEnv("PASSWORD_RESET_URL", "http://localhost:5173/reset-password"),
+      ResetWindowMinutes: resetWindowMinutes,
+      ResetMaxPerEmail:   resetMaxPerEmail,
+      ResetMaxPerIP:      resetMaxPerIP,
+    },
+    TwoFactor: TwoFactorConfig{
+      Issuer:           getEnv("TWO_FACTOR_ISSUER", "Vehicle Showroom"),
+      RequireForAdmin:  getEnvBool("TWO_FACTOR_REQUIRED_FOR_ADMIN", false),
+      ChallengeMinutes: challengeMinutes,
+    },
+    Notifier: NotifierConfig{
+      Driver:   getEnv("NOTIFIER_DRIVER", "log"),
+      FilePath: getEnv("NOTIFIER_FILE_PATH", "notifications.log"),
+    },
+    Events: EventsConfig{
+      Driver: getEnv("EVENTS_DRIVER", "local"),
+    },
+    Storage: StorageConfig{
+      Driver:    getEnv("STORAGE_DRIVER", "local"),
+      LocalPath: getEnv("STORAGE_LOCAL_PATH", "uploads"),
+    },
+    KYC: KYCConfig{
+      RequireForSales: getEnvBool("KYC_REQUIRED_FOR_SALES", false),
     },
     Server: ServerConfig{
-      Port: getEnv("PORT", "8080"),
-      Mode: getEnv("GIN_MODE", "debug"),
+      Port:                   getEnv("PORT", "8080"),
+      Mode:                   getEnv("GIN_MODE", "debug"),
+      ReadTimeoutSeconds:     readTimeout,
+      WriteTimeoutSeconds:    writeTimeout,
+      IdleTimeoutSeconds:     idleTimeout,
+      DrainSeconds:           drainSeconds,
+      ShutdownTimeoutSeconds: shutdownTimeout,
+    },
+    Log: LogConfig{
+      Level:  getEnv("LOG_LEVEL", "info"),
+      Format: getEnv("LOG_FORMAT", "json"),
+    },
+    Metrics: MetricsConfig{
+      Token: getEnv("METRICS_TOKEN", ""),
+    },
+    Timeout: TimeoutConfig{
+      RequestSeconds:   requestTimeout,
+      ReportSeconds:    reportTimeout,
+      ImportSeconds:    importTimeout,
+      ExportSeconds:    exportTimeout,
+      ReadinessSeconds: readinessTimeout,
+      Routes:           getEnvSeconds("ROUTE_TIMEOUTS"),
+    },
+    Scheduler: SchedulerConfig{
+      Enabled:             getEnvBool("SCHEDULER_ENABLED", true),
+      TaskIntervalSeconds: taskInterval,
     },
   }
 }
@@ -59,3 +272,30 @@ func getEnv(key, defaultValue string) string {
   }
   return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+  if value := os.Getenv(key); value != "" {
+    if parsed, err := strconv.ParseBool(value); err == nil {
+      return parsed
+    }
+  }
+  return defaultValue
+}
+
+// getEnvSeconds parses a comma-separated list of key=seconds pairs, e.g.
+// "/api/v1/dashboard=20,/api/v1/vehicles=5". Malformed entries are skipped.
+func getEnvSeconds(key string) map[string]int {
+  values := map[string]int{}
+  for _, entry := range strings.Split(os.Getenv(key), ",") {
+    name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+    if !ok {
+      continue
+    }
+    seconds, err := strconv.Atoi(strings.TrimSpace(value))
+    if err != nil || seconds < 0 {
+      continue
+    }
+    values[strings.TrimSpace(name)] = seconds
+  }
+  return values
+}
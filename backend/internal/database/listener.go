@@ -0,0 +1,31 @@
+package database
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+	"vehicle-showroom/internal/config"
+)
+
+// NewListener opens a dedicated connection that LISTENs on channel. The
+// listener reconnects by itself after the connection drops; notifications
+// sent while it was down are lost, and a nil notification marks the gap.
+func NewListener(cfg config.DatabaseConfig, channel string) (*pq.Listener, error) {
+	listener := pq.NewListener(dsn(cfg), time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			slog.Error("database listener connection failed",
+				slog.String("channel", channel),
+				slog.String("error", err.Error()),
+			)
+		}
+	})
+
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", channel, err)
+	}
+
+	return listener, nil
+}
@@ -1,227 +1,349 @@
 package database
 
 import (
+  "context"
+  "embed"
+  "fmt"
+  "io/fs"
+  "regexp"
+  "sort"
+  "strconv"
+  "time"
+
   "github.com/jmoiron/sqlx"
 )
 
+// Migrations live in migrations/ as NNNN_name.up.sql and NNNN_name.down.sql.
+// Never edit a migration that has been released; add a new one instead.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Arbitrary key for pg_advisory_lock so that only one instance migrates at
+// a time. Others wait for the lock and then find nothing left to do.
+const migrationLockKey = 72541830
+
+const createSchemaMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+  version INTEGER PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+type Migration struct {
+  Version int
+  Name    string
+  Up      string
+  Down    string
+}
+
+type MigrationStatus struct {
+  Version   int
+  Name      string
+  AppliedAt *time.Time
+}
+
+type Migrator struct {
+  db         *sqlx.DB
+  migrations []Migration
+}
+
+// RunMigrations brings the schema up to date and syncs the permission
+// catalog. It is safe to call from several instances at once.
 func RunMigrations(db *sqlx.DB) error {
-  migrations := []string{
-    createUsersTable,
-    createUserSessionsTable,
-    createCustomersTable,
-    createVehiclesTable,
-    createVehicleImagesTable,
-    createSparePartsTable,
-    createPurchaseTransactionsTable,
-    createSalesTransactionsTable,
-    createRepairsTable,
-    createRepairPartsTable,
-    createStockMovementsTable,
-  }
-
-  for _, migration := range migrations {
-    if _, err := db.Exec(migration); err != nil {
+  migrator, err := NewMigrator(db)
+  if err != nil {
+    return err
+  }
+
+  if _, err := migrator.Up(); err != nil {
+    return err
+  }
+
+  return SyncPermissions(db)
+}
+
+func NewMigrator(db *sqlx.DB) (*Migrator, error) {
+  migrations, err := loadMigrations(migrationFiles)
+  if err != nil {
+    return nil, err
+  }
+
+  return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// LatestVersion is the version of the newest embedded migration.
+func (m *Migrator) LatestVersion() int {
+  if len(m.migrations) == 0 {
+    return 0
+  }
+  return m.migrations[len(m.migrations)-1].Version
+}
+
+// CurrentVersion is the highest version applied to the database, or 0.
+func (m *Migrator) CurrentVersion(ctx context.Context) (int, error) {
+  exists, err := m.tableExists(ctx)
+  if err != nil {
+    return 0, err
+  }
+  if !exists {
+    return 0, nil
+  }
+
+  var version int
+  if err := m.db.GetContext(ctx, &version, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`); err != nil {
+    return 0, fmt.Errorf("failed to get schema version: %w", err)
+  }
+
+  return version, nil
+}
+
+// Up applies every pending migration in order and returns the ones applied.
+func (m *Migrator) Up() ([]Migration, error) {
+  var applied []Migration
+
+  err := m.withLock(func(conn *sqlx.Conn) error {
+    done, err := appliedVersions(conn)
+    if err != nil {
       return err
     }
+
+    for _, migration := range m.migrations {
+      if done[migration.Version] {
+        continue
+      }
+
+      if err := apply(conn, migration); err != nil {
+        return err
+      }
+      applied = append(applied, migration)
+    }
+
+    return nil
+  })
+
+  return applied, err
+}
+
+// Down reverts the last `steps` applied migrations, newest first.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+  var reverted []Migration
+
+  err := m.withLock(func(conn *sqlx.Conn) error {
+    var err error
+    reverted, err = m.down(conn, steps)
+    return err
+  })
+
+  return reverted, err
+}
+
+// Redo reverts and re-applies the newest applied migration under one hold
+// of the lock. Pending migrations stay pending.
+func (m *Migrator) Redo() (*Migration, error) {
+  var redone *Migration
+
+  err := m.withLock(func(conn *sqlx.Conn) error {
+    reverted, err := m.down(conn, 1)
+    if err != nil {
+      return err
+    }
+    if len(reverted) == 0 {
+      return fmt.Errorf("no migration to redo")
+    }
+
+    if err := apply(conn, reverted[0]); err != nil {
+      return err
+    }
+    redone = &reverted[0]
+    return nil
+  })
+
+  return redone, err
+}
+
+// down reverts up to steps applied migrations, newest first; the caller
+// holds the lock.
+func (m *Migrator) down(conn *sqlx.Conn, steps int) ([]Migration, error) {
+  done, err := appliedVersions(conn)
+  if err != nil {
+    return nil, err
+  }
+
+  var reverted []Migration
+  for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+    migration := m.migrations[i]
+    if !done[migration.Version] {
+      continue
+    }
+
+    if err := runInTx(conn, migration.Down,
+      `DELETE FROM schema_migrations WHERE version = $1`, migration.Version); err != nil {
+      return reverted, fmt.Errorf("rollback of %04d_%s failed: %w", migration.Version, migration.Name, err)
+    }
+    reverted = append(reverted, migration)
   }
 
+  return reverted, nil
+}
+
+// apply runs one migration and records it; the caller holds the lock.
+func apply(conn *sqlx.Conn, migration Migration) error {
+  if err := runInTx(conn, migration.Up,
+    `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, migration.Version, migration.Name); err != nil {
+    return fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+  }
   return nil
 }
 
-const createUsersTable = `
-CREATE TABLE IF NOT EXISTS users (
-  id SERIAL PRIMARY KEY,
-  username VARCHAR(50) UNIQUE NOT NULL,
-  email VARCHAR(100) UNIQUE NOT NULL,
-  password_hash VARCHAR(255) NOT NULL,
-  full_name VARCHAR(100) NOT NULL,
-  phone VARCHAR(20),
-  role VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'mechanic', 'cashier')),
-  is_active BOOLEAN DEFAULT true,
-  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-);
-`
+// Status lists every known migration with the time it was applied, if any.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+  exists, err := m.tableExists(context.Background())
+  if err != nil {
+    return nil, err
+  }
 
-const createUserSessionsTable = `
-CREATE TABLE IF NOT EXISTS user_sessions (
-  id SERIAL PRIMARY KEY,
-  user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
-  session_token VARCHAR(255) UNIQUE NOT NULL,
-  login_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  logout_at TIMESTAMP,
-  ip_address VARCHAR(45),
-  is_active BOOLEAN DEFAULT true
-);
-`
+  var rows []struct {
+    Version   int       `db:"version"`
+    AppliedAt time.Time `db:"applied_at"`
+  }
+  if exists {
+    if err := m.db.Select(&rows, `SELECT version, applied_at FROM schema_migrations`); err != nil {
+      return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+    }
+  }
 
-const createCustomersTable = `
-CREATE TABLE IF NOT EXISTS customers (
-  id SERIAL PRIMARY KEY,
-  customer_code VARCHAR(20) UNIQUE NOT NULL,
-  name VARCHAR(100) NOT NULL,
-  phone VARCHAR(20),
-  email VARCHAR(100),
-  address TEXT,
-  id_card_number VARCHAR(50),
-  type VARCHAR(20) NOT NULL CHECK (type IN ('individual', 'corporate')),
-  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  created_by INTEGER REFERENCES users(id),
-  is_active BOOLEAN DEFAULT true
-);
-`
+  appliedAt := make(map[int]time.Time, len(rows))
+  for _, row := range rows {
+    appliedAt[row.Version] = row.AppliedAt
+  }
 
-const createVehiclesTable = `
-CREATE TABLE IF NOT EXISTS vehicles (
-  id SERIAL PRIMARY KEY,
-  vehicle_code VARCHAR(20) UNIQUE NOT NULL,
-  chassis_number VARCHAR(50) UNIQUE NOT NULL,
-  license_plate VARCHAR(20),
-  brand VARCHAR(50) NOT NULL,
-  model VARCHAR(50) NOT NULL,
-  variant VARCHAR(50),
-  year INTEGER NOT NULL,
-  color VARCHAR(30),
-  mileage INTEGER,
-  fuel_type VARCHAR(20) CHECK (fuel_type IN ('gasoline', 'diesel', 'electric', 'hybrid')),
-  transmission VARCHAR(20) CHECK (transmission IN ('manual', 'automatic', 'cvt')),
-  purchase_price DECIMAL(15,2),
-  total_repair_cost DECIMAL(15,2) DEFAULT 0,
-  suggested_selling_price DECIMAL(15,2),
-  approved_selling_price DECIMAL(15,2),
-  final_selling_price DECIMAL(15,2),
-  status VARCHAR(20) DEFAULT 'purchased' CHECK (status IN ('purchased', 'in_repair', 'ready_to_sell', 'reserved', 'sold')),
-  purchased_from_customer_id INTEGER REFERENCES customers(id),
-  sold_to_customer_id INTEGER REFERENCES customers(id),
-  purchased_by_cashier INTEGER REFERENCES users(id),
-  sold_by_cashier INTEGER REFERENCES users(id),
-  price_approved_by_admin INTEGER REFERENCES users(id),
-  purchased_at TIMESTAMP,
-  sold_at TIMESTAMP,
-  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  purchase_notes TEXT,
-  condition_notes TEXT
-);
-`
+  statuses := make([]MigrationStatus, len(m.migrations))
+  for i, migration := range m.migrations {
+    statuses[i] = MigrationStatus{Version: migration.Version, Name: migration.Name}
+    if at, ok := appliedAt[migration.Version]; ok {
+      statuses[i].AppliedAt = &at
+    }
+  }
 
-const createVehicleImagesTable = `
-CREATE TABLE IF NOT EXISTS vehicle_images (
-  id SERIAL PRIMARY KEY,
-  vehicle_id INTEGER REFERENCES vehicles(id) ON DELETE CASCADE,
-  image_path VARCHAR(255) NOT NULL,
-  image_type VARCHAR(20) CHECK (image_type IN ('front', 'back', 'left', 'right', 'interior', 'engine', 'dashboard', 'damage', 'other')),
-  description TEXT,
-  is_primary BOOLEAN DEFAULT false,
-  uploaded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  uploaded_by INTEGER REFERENCES users(id)
-);
-`
+  return statuses, nil
+}
 
-const createSparePartsTable = `
-CREATE TABLE IF NOT EXISTS spare_parts (
-  id SERIAL PRIMARY KEY,
-  part_code VARCHAR(20) UNIQUE NOT NULL,
-  name VARCHAR(100) NOT NULL,
-  description TEXT,
-  brand VARCHAR(50),
-  cost_price DECIMAL(15,2) NOT NULL,
-  selling_price DECIMAL(15,2) NOT NULL,
-  stock_quantity INTEGER DEFAULT 0,
-  min_stock_level INTEGER DEFAULT 0,
-  unit_measure VARCHAR(20),
-  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  is_active BOOLEAN DEFAULT true
-);
-`
+func (m *Migrator) tableExists(ctx context.Context) (bool, error) {
+  var exists bool
+  if err := m.db.GetContext(ctx, &exists, `SELECT to_regclass('schema_migrations') IS NOT NULL`); err != nil {
+    return false, fmt.Errorf("failed to check schema_migrations: %w", err)
+  }
+  return exists, nil
+}
 
-const createPurchaseTransactionsTable = `
-CREATE TABLE IF NOT EXISTS purchase_transactions (
-  id SERIAL PRIMARY KEY,
-  transaction_number VARCHAR(30) UNIQUE NOT NULL,
-  invoice_number VARCHAR(30) UNIQUE NOT NULL,
-  vehicle_id INTEGER REFERENCES vehicles(id),
-  customer_id INTEGER REFERENCES customers(id),
-  vehicle_price DECIMAL(15,2) NOT NULL,
-  tax_amount DECIMAL(15,2) DEFAULT 0,
-  total_amount DECIMAL(15,2) NOT NULL,
-  payment_method VARCHAR(20) CHECK (payment_method IN ('cash', 'transfer', 'check')),
-  payment_reference VARCHAR(100),
-  transaction_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  cashier_id INTEGER REFERENCES users(id),
-  status VARCHAR(20) DEFAULT 'completed' CHECK (status IN ('completed', 'cancelled')),
-  notes TEXT,
-  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-);
-`
+// withLock runs fn on a single connection holding the migration advisory
+// lock; session-level advisory locks belong to the connection that took them.
+func (m *Migrator) withLock(fn func(conn *sqlx.Conn) error) error {
+  ctx := context.Background()
 
-const createSalesTransactionsTable = `
-CREATE TABLE IF NOT EXISTS sales_transactions (
-  id SERIAL PRIMARY KEY,
-  transaction_number VARCHAR(30) UNIQUE NOT NULL,
-  invoice_number VARCHAR(30) UNIQUE NOT NULL,
-  vehicle_id INTEGER REFERENCES vehicles(id),
-  customer_id INTEGER REFERENCES customers(id),
-  vehicle_price DECIMAL(15,2) NOT NULL,
-  tax_amount DECIMAL(15,2) DEFAULT 0,
-  discount_amount DECIMAL(15,2) DEFAULT 0,
-  total_amount DECIMAL(15,2) NOT NULL,
-  payment_method VARCHAR(20) CHECK (payment_method IN ('cash', 'transfer', 'check', 'credit')),
-  payment_reference VARCHAR(100),
-  transaction_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  cashier_id INTEGER REFERENCES users(id),
-  status VARCHAR(20) DEFAULT 'completed' CHECK (status IN ('completed', 'cancelled')),
-  notes TEXT,
-  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-);
-`
+  conn, err := m.db.Connx(ctx)
+  if err != nil {
+    return fmt.Errorf("failed to get connection: %w", err)
+  }
+  defer conn.Close()
 
-const createRepairsTable = `
-CREATE TABLE IF NOT EXISTS repairs (
-  id SERIAL PRIMARY KEY,
-  repair_number VARCHAR(30) UNIQUE NOT NULL,
-  vehicle_id INTEGER REFERENCES vehicles(id),
-  title VARCHAR(100) NOT NULL,
-  description TEXT,
-  labor_cost DECIMAL(15,2) DEFAULT 0,
-  total_parts_cost DECIMAL(15,2) DEFAULT 0,
-  total_cost DECIMAL(15,2) DEFAULT 0,
-  status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'in_progress', 'completed', 'cancelled')),
-  mechanic_id INTEGER REFERENCES users(id),
-  started_at TIMESTAMP,
-  completed_at TIMESTAMP,
-  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  work_notes TEXT
-);
-`
+  if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+    return fmt.Errorf("failed to acquire migration lock: %w", err)
+  }
+  defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockKey)
 
-const createRepairPartsTable = `
-CREATE TABLE IF NOT EXISTS repair_parts (
-  id SERIAL PRIMARY KEY,
-  repair_id INTEGER REFERENCES repairs(id) ON DELETE CASCADE,
-  spare_part_id INTEGER REFERENCES spare_parts(id),
-  quantity_used INTEGER NOT NULL,
-  unit_cost DECIMAL(15,2) NOT NULL,
-  total_cost DECIMAL(15,2) NOT NULL,
-  used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  notes TEXT
-);
-`
+  if _, err := conn.ExecContext(ctx, createSchemaMigrationsTable); err != nil {
+    return fmt.Errorf("failed to create schema_migrations: %w", err)
+  }
 
-const createStockMovementsTable = `
-CREATE TABLE IF NOT EXISTS stock_movements (
-  id SERIAL PRIMARY KEY,
-  spare_part_id INTEGER REFERENCES spare_parts(id),
-  movement_type VARCHAR(20) CHECK (movement_type IN ('in', 'out', 'adjustment')),
-  reference_type VARCHAR(20) CHECK (reference_type IN ('repair', 'purchase', 'sales', 'adjustment')),
-  reference_id INTEGER,
-  quantity_before INTEGER NOT NULL,
-  quantity_moved INTEGER NOT NULL,
-  quantity_after INTEGER NOT NULL,
-  movement_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  processed_by INTEGER REFERENCES users(id),
-  notes TEXT
-);
-`
+  return fn(conn)
+}
+
+func appliedVersions(conn *sqlx.Conn) (map[int]bool, error) {
+  var versions []int
+  if err := conn.SelectContext(context.Background(), &versions, `SELECT version FROM schema_migrations`); err != nil {
+    return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+  }
+
+  done := make(map[int]bool, len(versions))
+  for _, v := range versions {
+    done[v] = true
+  }
+
+  return done, nil
+}
+
+// runInTx executes a migration script and the bookkeeping statement in one
+// transaction, so a failed migration leaves no partial state behind.
+func runInTx(conn *sqlx.Conn, script, bookkeeping string, args ...interface{}) error {
+  ctx := context.Background()
+
+  tx, err := conn.BeginTxx(ctx, nil)
+  if err != nil {
+    return err
+  }
+  defer tx.Rollback()
+
+  if _, err := tx.ExecContext(ctx, script); err != nil {
+    return err
+  }
+
+  if _, err := tx.ExecContext(ctx, bookkeeping, args...); err != nil {
+    return err
+  }
+
+  return tx.Commit()
+}
+
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+  entries, err := fs.ReadDir(fsys, "migrations")
+  if err != nil {
+    return nil, fmt.Errorf("failed to read migrations: %w", err)
+  }
+
+  byVersion := make(map[int]*Migration)
+  for _, entry := range entries {
+    match := migrationFileName.FindStringSubmatch(entry.Name())
+    if match == nil {
+      return nil, fmt.Errorf("unexpected file in migrations: %s", entry.Name())
+    }
+
+    version, _ := strconv.Atoi(match[1])
+    content, err := fs.ReadFile(fsys, "migrations/"+entry.Name())
+    if err != nil {
+      return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+    }
+
+    migration, ok := byVersion[version]
+    if !ok {
+      migration = &Migration{Version: version, Name: match[2]}
+      byVersion[version] = migration
+    } else if migration.Name != match[2] {
+      return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, migration.Name, match[2])
+    }
+
+    if match[3] == "up" {
+      migration.Up = string(content)
+    } else {
+      migration.Down = string(content)
+    }
+  }
+
+  migrations := make([]Migration, 0, len(byVersion))
+  for _, migration := range byVersion {
+    if migration.Up == "" || migration.Down == "" {
+      return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", migration.Version, migration.Name)
+    }
+    migrations = append(migrations, *migration)
+  }
+
+  sort.Slice(migrations, func(i, j int) bool {
+    return migrations[i].Version < migrations[j].Version
+  })
+
+  return migrations, nil
+}
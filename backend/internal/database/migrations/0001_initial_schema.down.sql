@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS login_attempts;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS session_refresh_tokens;
+DROP TABLE IF EXISTS stock_movements;
+DROP TABLE IF EXISTS repair_parts;
+DROP TABLE IF EXISTS repairs;
+DROP TABLE IF EXISTS sales_transactions;
+DROP TABLE IF EXISTS purchase_transactions;
+DROP TABLE IF EXISTS spare_parts;
+DROP TABLE IF EXISTS vehicle_images;
+DROP TABLE IF EXISTS vehicles;
+DROP TABLE IF EXISTS customers;
+DROP TABLE IF EXISTS user_sessions;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,273 @@
+-- Schema as it stood when versioned migrations were introduced. Every
+-- statement is idempotent so databases created by older builds, which ran
+-- these on every boot, can adopt the migration history without changes.
+
+CREATE TABLE IF NOT EXISTS users (
+  id SERIAL PRIMARY KEY,
+  username VARCHAR(50) UNIQUE NOT NULL,
+  email VARCHAR(100) UNIQUE NOT NULL,
+  password_hash VARCHAR(255) NOT NULL,
+  full_name VARCHAR(100) NOT NULL,
+  phone VARCHAR(20),
+  role VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'mechanic', 'cashier')),
+  is_active BOOLEAN DEFAULT true,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+  session_token VARCHAR(255) UNIQUE NOT NULL,
+  login_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  logout_at TIMESTAMP,
+  ip_address VARCHAR(45),
+  is_active BOOLEAN DEFAULT true
+);
+
+CREATE TABLE IF NOT EXISTS customers (
+  id SERIAL PRIMARY KEY,
+  customer_code VARCHAR(20) UNIQUE NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  phone VARCHAR(20),
+  email VARCHAR(100),
+  address TEXT,
+  id_card_number VARCHAR(50),
+  type VARCHAR(20) NOT NULL CHECK (type IN ('individual', 'corporate')),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  created_by INTEGER REFERENCES users(id),
+  is_active BOOLEAN DEFAULT true
+);
+
+CREATE TABLE IF NOT EXISTS vehicles (
+  id SERIAL PRIMARY KEY,
+  vehicle_code VARCHAR(20) UNIQUE NOT NULL,
+  chassis_number VARCHAR(50) UNIQUE NOT NULL,
+  license_plate VARCHAR(20),
+  brand VARCHAR(50) NOT NULL,
+  model VARCHAR(50) NOT NULL,
+  variant VARCHAR(50),
+  year INTEGER NOT NULL,
+  color VARCHAR(30),
+  mileage INTEGER,
+  fuel_type VARCHAR(20) CHECK (fuel_type IN ('gasoline', 'diesel', 'electric', 'hybrid')),
+  transmission VARCHAR(20) CHECK (transmission IN ('manual', 'automatic', 'cvt')),
+  purchase_price DECIMAL(15,2),
+  total_repair_cost DECIMAL(15,2) DEFAULT 0,
+  suggested_selling_price DECIMAL(15,2),
+  approved_selling_price DECIMAL(15,2),
+  final_selling_price DECIMAL(15,2),
+  status VARCHAR(20) DEFAULT 'purchased' CHECK (status IN ('purchased', 'in_repair', 'ready_to_sell', 'reserved', 'sold')),
+  purchased_from_customer_id INTEGER REFERENCES customers(id),
+  sold_to_customer_id INTEGER REFERENCES customers(id),
+  purchased_by_cashier INTEGER REFERENCES users(id),
+  sold_by_cashier INTEGER REFERENCES users(id),
+  price_approved_by_admin INTEGER REFERENCES users(id),
+  purchased_at TIMESTAMP,
+  sold_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  purchase_notes TEXT,
+  condition_notes TEXT
+);
+
+CREATE TABLE IF NOT EXISTS vehicle_images (
+  id SERIAL PRIMARY KEY,
+  vehicle_id INTEGER REFERENCES vehicles(id) ON DELETE CASCADE,
+  image_path VARCHAR(255) NOT NULL,
+  image_type VARCHAR(20) CHECK (image_type IN ('front', 'back', 'left', 'right', 'interior', 'engine', 'dashboard', 'damage', 'other')),
+  description TEXT,
+  is_primary BOOLEAN DEFAULT false,
+  uploaded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  uploaded_by INTEGER REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS spare_parts (
+  id SERIAL PRIMARY KEY,
+  part_code VARCHAR(20) UNIQUE NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  description TEXT,
+  brand VARCHAR(50),
+  cost_price DECIMAL(15,2) NOT NULL,
+  selling_price DECIMAL(15,2) NOT NULL,
+  stock_quantity INTEGER DEFAULT 0,
+  min_stock_level INTEGER DEFAULT 0,
+  unit_measure VARCHAR(20),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  is_active BOOLEAN DEFAULT true
+);
+
+CREATE TABLE IF NOT EXISTS purchase_transactions (
+  id SERIAL PRIMARY KEY,
+  transaction_number VARCHAR(30) UNIQUE NOT NULL,
+  invoice_number VARCHAR(30) UNIQUE NOT NULL,
+  vehicle_id INTEGER REFERENCES vehicles(id),
+  customer_id INTEGER REFERENCES customers(id),
+  vehicle_price DECIMAL(15,2) NOT NULL,
+  tax_amount DECIMAL(15,2) DEFAULT 0,
+  total_amount DECIMAL(15,2) NOT NULL,
+  payment_method VARCHAR(20) CHECK (payment_method IN ('cash', 'transfer', 'check')),
+  payment_reference VARCHAR(100),
+  transaction_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  cashier_id INTEGER REFERENCES users(id),
+  status VARCHAR(20) DEFAULT 'completed' CHECK (status IN ('completed', 'cancelled')),
+  notes TEXT,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS sales_transactions (
+  id SERIAL PRIMARY KEY,
+  transaction_number VARCHAR(30) UNIQUE NOT NULL,
+  invoice_number VARCHAR(30) UNIQUE NOT NULL,
+  vehicle_id INTEGER REFERENCES vehicles(id),
+  customer_id INTEGER REFERENCES customers(id),
+  vehicle_price DECIMAL(15,2) NOT NULL,
+  tax_amount DECIMAL(15,2) DEFAULT 0,
+  discount_amount DECIMAL(15,2) DEFAULT 0,
+  total_amount DECIMAL(15,2) NOT NULL,
+  payment_method VARCHAR(20) CHECK (payment_method IN ('cash', 'transfer', 'check', 'credit')),
+  payment_reference VARCHAR(100),
+  transaction_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  cashier_id INTEGER REFERENCES users(id),
+  status VARCHAR(20) DEFAULT 'completed' CHECK (status IN ('completed', 'cancelled')),
+  notes TEXT,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS repairs (
+  id SERIAL PRIMARY KEY,
+  repair_number VARCHAR(30) UNIQUE NOT NULL,
+  vehicle_id INTEGER REFERENCES vehicles(id),
+  title VARCHAR(100) NOT NULL,
+  description TEXT,
+  labor_cost DECIMAL(15,2) DEFAULT 0,
+  total_parts_cost DECIMAL(15,2) DEFAULT 0,
+  total_cost DECIMAL(15,2) DEFAULT 0,
+  status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'in_progress', 'completed', 'cancelled')),
+  mechanic_id INTEGER REFERENCES users(id),
+  started_at TIMESTAMP,
+  completed_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  work_notes TEXT
+);
+
+CREATE TABLE IF NOT EXISTS repair_parts (
+  id SERIAL PRIMARY KEY,
+  repair_id INTEGER REFERENCES repairs(id) ON DELETE CASCADE,
+  spare_part_id INTEGER REFERENCES spare_parts(id),
+  quantity_used INTEGER NOT NULL,
+  unit_cost DECIMAL(15,2) NOT NULL,
+  total_cost DECIMAL(15,2) NOT NULL,
+  used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  notes TEXT
+);
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+  id SERIAL PRIMARY KEY,
+  spare_part_id INTEGER REFERENCES spare_parts(id),
+  movement_type VARCHAR(20) CHECK (movement_type IN ('in', 'out', 'adjustment')),
+  reference_type VARCHAR(20) CHECK (reference_type IN ('repair', 'purchase', 'sales', 'adjustment')),
+  reference_id INTEGER,
+  quantity_before INTEGER NOT NULL,
+  quantity_moved INTEGER NOT NULL,
+  quantity_after INTEGER NOT NULL,
+  movement_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  processed_by INTEGER REFERENCES users(id),
+  notes TEXT
+);
+
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS refresh_token_hash VARCHAR(64) UNIQUE;
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS refresh_expires_at TIMESTAMP;
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS user_agent TEXT;
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS revoked_reason VARCHAR(50);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_active ON user_sessions(user_id) WHERE is_active = true;
+
+CREATE TABLE IF NOT EXISTS session_refresh_tokens (
+  token_hash VARCHAR(64) PRIMARY KEY,
+  session_id INTEGER REFERENCES user_sessions(id) ON DELETE CASCADE,
+  rotated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS roles (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(20) UNIQUE NOT NULL,
+  description TEXT,
+  is_system BOOLEAN DEFAULT false,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO roles (name, description, is_system) VALUES
+  ('admin', 'Full access to all features', true),
+  ('cashier', 'Customers, vehicles and transactions', true),
+  ('mechanic', 'Repairs and spare parts', true)
+ON CONFLICT (name) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS permissions (
+  id SERIAL PRIMARY KEY,
+  code VARCHAR(50) UNIQUE NOT NULL,
+  description TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+  role_id INTEGER REFERENCES roles(id) ON DELETE CASCADE,
+  permission_id INTEGER REFERENCES permissions(id) ON DELETE CASCADE,
+  PRIMARY KEY (role_id, permission_id)
+);
+
+-- Roles used to be a fixed CHECK list; they now reference the roles table.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'users_role_fkey') THEN
+    ALTER TABLE users ADD CONSTRAINT users_role_fkey
+      FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+  END IF;
+END $$;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_attempts INTEGER DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS login_attempts (
+  id SERIAL PRIMARY KEY,
+  username VARCHAR(50) NOT NULL,
+  user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  ip_address VARCHAR(45),
+  user_agent TEXT,
+  success BOOLEAN NOT NULL,
+  failure_reason VARCHAR(30),
+  attempted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_username ON login_attempts(username, attempted_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip ON login_attempts(ip_address, attempted_at);
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+  token_hash VARCHAR(64) UNIQUE NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  used_at TIMESTAMP,
+  requested_ip VARCHAR(45),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- totp_last_step remembers the last accepted time step so a code cannot be
+-- replayed within its validity window.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT;
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+  code_hash VARCHAR(64) NOT NULL,
+  used_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user ON user_recovery_codes(user_id) WHERE used_at IS NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_vehicles_selling_price;
+DROP INDEX IF EXISTS idx_vehicles_year;
+DROP INDEX IF EXISTS idx_vehicles_brand_model;
+DROP INDEX IF EXISTS idx_vehicles_status;
@@ -0,0 +1,5 @@
+-- Indexes for the vehicle list filters and facet counts
+CREATE INDEX IF NOT EXISTS idx_vehicles_status ON vehicles(status);
+CREATE INDEX IF NOT EXISTS idx_vehicles_brand_model ON vehicles(LOWER(brand), LOWER(model));
+CREATE INDEX IF NOT EXISTS idx_vehicles_year ON vehicles(year);
+CREATE INDEX IF NOT EXISTS idx_vehicles_selling_price ON vehicles((COALESCE(approved_selling_price, suggested_selling_price, 0)));
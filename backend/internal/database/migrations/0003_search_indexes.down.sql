@@ -0,0 +1,23 @@
+DROP INDEX IF EXISTS idx_repairs_number_trgm;
+DROP INDEX IF EXISTS idx_repairs_title_trgm;
+DROP INDEX IF EXISTS idx_repairs_search_fts;
+
+DROP INDEX IF EXISTS idx_purchases_invoice_trgm;
+DROP INDEX IF EXISTS idx_purchases_number_trgm;
+DROP INDEX IF EXISTS idx_sales_invoice_trgm;
+DROP INDEX IF EXISTS idx_sales_number_trgm;
+
+DROP INDEX IF EXISTS idx_vehicles_plate_trgm;
+DROP INDEX IF EXISTS idx_vehicles_chassis_trgm;
+DROP INDEX IF EXISTS idx_vehicles_code_trgm;
+DROP INDEX IF EXISTS idx_vehicles_name_trgm;
+DROP INDEX IF EXISTS idx_vehicles_search_fts;
+
+DROP INDEX IF EXISTS idx_customers_id_card_trgm;
+DROP INDEX IF EXISTS idx_customers_email_trgm;
+DROP INDEX IF EXISTS idx_customers_phone_trgm;
+DROP INDEX IF EXISTS idx_customers_code_trgm;
+DROP INDEX IF EXISTS idx_customers_name_trgm;
+DROP INDEX IF EXISTS idx_customers_search_fts;
+
+-- pg_trgm is left installed; other objects may depend on it
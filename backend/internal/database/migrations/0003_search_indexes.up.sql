@@ -0,0 +1,26 @@
+-- Global search: full-text documents plus trigram indexes, which serve both
+-- similarity (%) and ILIKE '%x%'. The indexed expressions must match those
+-- in repository/search_repository.go exactly or the planner ignores them.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_customers_search_fts ON customers USING GIN (to_tsvector('simple', name || ' ' || COALESCE(email, '')));
+CREATE INDEX IF NOT EXISTS idx_customers_name_trgm ON customers USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_customers_code_trgm ON customers USING GIN (customer_code gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_customers_phone_trgm ON customers USING GIN (phone gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_customers_email_trgm ON customers USING GIN (email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_customers_id_card_trgm ON customers USING GIN (id_card_number gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_vehicles_search_fts ON vehicles USING GIN (to_tsvector('simple', brand || ' ' || model || ' ' || COALESCE(variant, '') || ' ' || COALESCE(color, '')));
+CREATE INDEX IF NOT EXISTS idx_vehicles_name_trgm ON vehicles USING GIN ((brand || ' ' || model) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_vehicles_code_trgm ON vehicles USING GIN (vehicle_code gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_vehicles_chassis_trgm ON vehicles USING GIN (chassis_number gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_vehicles_plate_trgm ON vehicles USING GIN ((REPLACE(license_plate, ' ', '')) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_sales_number_trgm ON sales_transactions USING GIN (transaction_number gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_sales_invoice_trgm ON sales_transactions USING GIN (invoice_number gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_purchases_number_trgm ON purchase_transactions USING GIN (transaction_number gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_purchases_invoice_trgm ON purchase_transactions USING GIN (invoice_number gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_repairs_search_fts ON repairs USING GIN (to_tsvector('simple', title || ' ' || COALESCE(description, '')));
+CREATE INDEX IF NOT EXISTS idx_repairs_title_trgm ON repairs USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_repairs_number_trgm ON repairs USING GIN (repair_number gin_trgm_ops);
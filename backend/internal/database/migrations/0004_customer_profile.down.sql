@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS customer_notes;
+
+DROP INDEX IF EXISTS idx_purchases_customer;
+DROP INDEX IF EXISTS idx_sales_customer;
+DROP INDEX IF EXISTS idx_vehicles_reserved_for;
+DROP INDEX IF EXISTS idx_vehicles_purchased_from;
+
+ALTER TABLE vehicles DROP COLUMN IF EXISTS reserved_at;
+ALTER TABLE vehicles DROP COLUMN IF EXISTS reserved_for_customer_id;
@@ -0,0 +1,19 @@
+-- Customer profile: who a reserved vehicle is held for, notes staff keep
+-- on a customer, and indexes for looking up a customer's history.
+ALTER TABLE vehicles ADD COLUMN IF NOT EXISTS reserved_for_customer_id INTEGER REFERENCES customers(id);
+ALTER TABLE vehicles ADD COLUMN IF NOT EXISTS reserved_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_vehicles_purchased_from ON vehicles(purchased_from_customer_id);
+CREATE INDEX IF NOT EXISTS idx_vehicles_reserved_for ON vehicles(reserved_for_customer_id);
+CREATE INDEX IF NOT EXISTS idx_sales_customer ON sales_transactions(customer_id);
+CREATE INDEX IF NOT EXISTS idx_purchases_customer ON purchase_transactions(customer_id);
+
+CREATE TABLE IF NOT EXISTS customer_notes (
+  id SERIAL PRIMARY KEY,
+  customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+  note TEXT NOT NULL,
+  created_by INTEGER REFERENCES users(id),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_notes_customer ON customer_notes(customer_id, created_at);
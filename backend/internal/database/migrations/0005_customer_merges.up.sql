@@ -0,0 +1,16 @@
+-- Audit trail of customer merges. merged_customer keeps the merged row as
+-- it was, since the merge overwrites nothing but deactivates it.
+CREATE TABLE IF NOT EXISTS customer_merges (
+  id SERIAL PRIMARY KEY,
+  surviving_customer_id INTEGER NOT NULL REFERENCES customers(id),
+  merged_customer_id INTEGER NOT NULL REFERENCES customers(id),
+  merged_customer JSONB NOT NULL,
+  vehicles_moved INTEGER NOT NULL DEFAULT 0,
+  purchases_moved INTEGER NOT NULL DEFAULT 0,
+  sales_moved INTEGER NOT NULL DEFAULT 0,
+  notes_moved INTEGER NOT NULL DEFAULT 0,
+  merged_by INTEGER REFERENCES users(id),
+  merged_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_merges_surviving ON customer_merges(surviving_customer_id, merged_at);
@@ -0,0 +1,4 @@
+ALTER TABLE customer_merges DROP COLUMN IF EXISTS documents_moved;
+
+-- Stored files are left in place; remove them from file storage separately
+DROP TABLE IF EXISTS customer_documents;
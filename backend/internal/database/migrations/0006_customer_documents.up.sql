@@ -0,0 +1,21 @@
+-- KYC documents. The file itself is in file storage under storage_key.
+CREATE TABLE IF NOT EXISTS customer_documents (
+  id SERIAL PRIMARY KEY,
+  customer_id INTEGER NOT NULL REFERENCES customers(id),
+  type VARCHAR(20) NOT NULL CHECK (type IN ('id_card', 'tax_number', 'company_deed', 'other')),
+  document_number VARCHAR(50),
+  file_name VARCHAR(255) NOT NULL,
+  content_type VARCHAR(100) NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  storage_key VARCHAR(255) UNIQUE NOT NULL,
+  expires_at DATE,
+  verified_by INTEGER REFERENCES users(id),
+  verified_at TIMESTAMP,
+  uploaded_by INTEGER REFERENCES users(id),
+  uploaded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_documents_customer ON customer_documents(customer_id, type);
+
+-- Merging customers moves their documents too
+ALTER TABLE customer_merges ADD COLUMN IF NOT EXISTS documents_moved INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+ALTER TABLE customers DROP COLUMN IF EXISTS anonymized_by;
+ALTER TABLE customers DROP COLUMN IF EXISTS anonymized_at;
+
+DROP TABLE IF EXISTS customer_consents;
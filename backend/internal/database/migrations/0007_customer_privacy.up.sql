@@ -0,0 +1,18 @@
+-- Marketing consent, one row per change so the history is the evidence of
+-- what the customer agreed to and when.
+CREATE TABLE IF NOT EXISTS customer_consents (
+  id SERIAL PRIMARY KEY,
+  customer_id INTEGER NOT NULL REFERENCES customers(id),
+  channel VARCHAR(20) NOT NULL CHECK (channel IN ('email', 'sms', 'whatsapp', 'phone')),
+  granted BOOLEAN NOT NULL,
+  source VARCHAR(50),
+  recorded_by INTEGER REFERENCES users(id),
+  recorded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_consents_customer ON customer_consents(customer_id, channel, recorded_at);
+
+-- Anonymized customers keep their row, code and transactions; the personal
+-- data is gone.
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS anonymized_by INTEGER REFERENCES users(id);
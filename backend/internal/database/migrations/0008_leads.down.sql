@@ -0,0 +1,5 @@
+ALTER TABLE customer_merges DROP COLUMN IF EXISTS leads_moved;
+
+DROP TABLE IF EXISTS test_drives;
+DROP TABLE IF EXISTS lead_vehicles;
+DROP TABLE IF EXISTS leads;
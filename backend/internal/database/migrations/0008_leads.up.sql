@@ -0,0 +1,54 @@
+-- Prospects before they become customers. A lead is converted into a
+-- customer once won; customer_id then points at it.
+CREATE TABLE IF NOT EXISTS leads (
+  id SERIAL PRIMARY KEY,
+  lead_number VARCHAR(20) UNIQUE NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  phone VARCHAR(20),
+  email VARCHAR(100),
+  source VARCHAR(20) NOT NULL CHECK (source IN ('walk_in', 'phone', 'website', 'referral', 'other')),
+  status VARCHAR(20) NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'contacted', 'test_drive', 'negotiation', 'won', 'lost')),
+  assigned_to INTEGER REFERENCES users(id),
+  notes TEXT,
+  lost_reason TEXT,
+  customer_id INTEGER REFERENCES customers(id),
+  converted_at TIMESTAMP,
+  created_by INTEGER REFERENCES users(id),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_leads_status ON leads(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_leads_assigned_to ON leads(assigned_to);
+CREATE INDEX IF NOT EXISTS idx_leads_customer ON leads(customer_id);
+
+-- The vehicles a lead asked about
+CREATE TABLE IF NOT EXISTS lead_vehicles (
+  lead_id INTEGER NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+  vehicle_id INTEGER NOT NULL REFERENCES vehicles(id),
+  added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (lead_id, vehicle_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lead_vehicles_vehicle ON lead_vehicles(vehicle_id);
+
+-- Scheduled test drives never overlap on the same vehicle; the application
+-- checks that under a lock on the vehicle row.
+CREATE TABLE IF NOT EXISTS test_drives (
+  id SERIAL PRIMARY KEY,
+  lead_id INTEGER NOT NULL REFERENCES leads(id),
+  vehicle_id INTEGER NOT NULL REFERENCES vehicles(id),
+  starts_at TIMESTAMP NOT NULL,
+  ends_at TIMESTAMP NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'completed', 'cancelled', 'no_show')),
+  notes TEXT,
+  created_by INTEGER REFERENCES users(id),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_test_drives_vehicle ON test_drives(vehicle_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_test_drives_lead ON test_drives(lead_id);
+
+-- Merging customers moves their converted leads too
+ALTER TABLE customer_merges ADD COLUMN IF NOT EXISTS leads_moved INTEGER NOT NULL DEFAULT 0;
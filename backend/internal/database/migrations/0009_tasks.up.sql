@@ -0,0 +1,27 @@
+-- Follow-up tasks for staff. The scheduler sends the reminder at remind_at
+-- and marks tasks past due_at overdue; reminded_at and overdue_at record
+-- that it did, so each happens once.
+CREATE TABLE IF NOT EXISTS tasks (
+  id SERIAL PRIMARY KEY,
+  title VARCHAR(200) NOT NULL,
+  description TEXT,
+  assigned_to INTEGER NOT NULL REFERENCES users(id),
+  due_at TIMESTAMP NOT NULL,
+  remind_at TIMESTAMP,
+  status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'in_progress', 'overdue', 'done', 'cancelled')),
+  entity_type VARCHAR(20) CHECK (entity_type IN ('customer', 'vehicle', 'sale', 'repair', 'lead')),
+  entity_id INTEGER,
+  reminded_at TIMESTAMP,
+  overdue_at TIMESTAMP,
+  completed_at TIMESTAMP,
+  created_by INTEGER REFERENCES users(id),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  CHECK ((entity_type IS NULL) = (entity_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_assigned_to ON tasks(assigned_to, status, due_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_entity ON tasks(entity_type, entity_id);
+-- The scheduler only looks at tasks still in progress
+CREATE INDEX IF NOT EXISTS idx_tasks_pending_due ON tasks(due_at) WHERE status IN ('open', 'in_progress');
+CREATE INDEX IF NOT EXISTS idx_tasks_pending_remind ON tasks(remind_at) WHERE status IN ('open', 'in_progress') AND reminded_at IS NULL;
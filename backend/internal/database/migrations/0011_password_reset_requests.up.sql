@@ -0,0 +1,11 @@
+-- Every forgot-password request, whether or not the email belongs to an
+-- account, so the per-email and per-IP limits behave the same for both.
+CREATE TABLE IF NOT EXISTS password_reset_requests (
+  id SERIAL PRIMARY KEY,
+  email VARCHAR(100) NOT NULL,
+  ip_address VARCHAR(45) NOT NULL,
+  requested_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_requests_email ON password_reset_requests(email, requested_at);
+CREATE INDEX IF NOT EXISTS idx_password_reset_requests_ip ON password_reset_requests(ip_address, requested_at);
@@ -0,0 +1,2 @@
+-- Merging customers moves their consent history too
+ALTER TABLE customer_merges ADD COLUMN IF NOT EXISTS consents_moved INTEGER NOT NULL DEFAULT 0;
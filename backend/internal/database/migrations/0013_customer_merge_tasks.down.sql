@@ -0,0 +1 @@
+ALTER TABLE customer_merges DROP COLUMN IF EXISTS tasks_moved;
@@ -0,0 +1,2 @@
+-- Merging customers moves the tasks about them too
+ALTER TABLE customer_merges ADD COLUMN IF NOT EXISTS tasks_moved INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"vehicle-showroom/internal/config"
+)
+
+// Needs a disposable PostgreSQL database named by TEST_DB_NAME, like the
+// repository tests; the other DB_* settings apply.
+func TestRedoLeavesPendingMigrations(t *testing.T) {
+	name := os.Getenv("TEST_DB_NAME")
+	if name == "" {
+		t.Skip("TEST_DB_NAME is not set")
+	}
+	cfg := config.New().Database
+	cfg.Name = name
+
+	db, err := NewPostgreSQL(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { migrator.Up() })
+
+	latest := migrator.LatestVersion()
+	if _, err := migrator.Down(2); err != nil {
+		t.Fatal(err)
+	}
+
+	redone, err := migrator.Redo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redone.Version != latest-2 {
+		t.Errorf("redid %04d, want %04d", redone.Version, latest-2)
+	}
+
+	current, err := migrator.CurrentVersion(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current != latest-2 {
+		t.Errorf("schema at %d after redo, want %d with the rest still pending", current, latest-2)
+	}
+}
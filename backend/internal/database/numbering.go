@@ -0,0 +1,80 @@
+package database
+
+import (
+  "fmt"
+
+  "github.com/jmoiron/sqlx"
+)
+
+// SequenceStatus describes one SERIAL sequence and the value it should be at.
+type SequenceStatus struct {
+  Table     string
+  Column    string
+  Sequence  string
+  LastValue int64
+  MaxID     int64
+  Repaired  bool
+}
+
+// Behind reports whether the next nextval() would collide with an existing row.
+func (s SequenceStatus) Behind() bool {
+  return s.LastValue < s.MaxID
+}
+
+// RepairSequences moves every SERIAL sequence in the public schema past the
+// highest id in its table. Rows inserted with explicit ids (restores, manual
+// fixes) leave sequences behind, and the next insert then fails on the
+// primary key. With dryRun set nothing is changed.
+func RepairSequences(db *sqlx.DB, dryRun bool) ([]SequenceStatus, error) {
+  var columns []struct {
+    Table  string `db:"table_name"`
+    Column string `db:"column_name"`
+  }
+  query := `
+    SELECT table_name, column_name
+    FROM information_schema.columns
+    WHERE table_schema = 'public' AND column_default LIKE 'nextval(%'
+    ORDER BY table_name, column_name
+  `
+  if err := db.Select(&columns, query); err != nil {
+    return nil, fmt.Errorf("failed to list sequences: %w", err)
+  }
+
+  statuses := make([]SequenceStatus, 0, len(columns))
+  for _, col := range columns {
+    status := SequenceStatus{Table: col.Table, Column: col.Column}
+
+    if err := db.Get(&status.Sequence, `SELECT pg_get_serial_sequence($1, $2)`, col.Table, col.Column); err != nil {
+      return nil, fmt.Errorf("failed to get sequence for %s.%s: %w", col.Table, col.Column, err)
+    }
+
+    var current struct {
+      LastValue int64 `db:"last_value"`
+      IsCalled  bool  `db:"is_called"`
+    }
+    if err := db.Get(&current, fmt.Sprintf(`SELECT last_value, is_called FROM %s`, status.Sequence)); err != nil {
+      return nil, fmt.Errorf("failed to read sequence %s: %w", status.Sequence, err)
+    }
+    // A sequence that was never called hands out last_value itself next
+    status.LastValue = current.LastValue
+    if !current.IsCalled {
+      status.LastValue--
+    }
+
+    maxQuery := fmt.Sprintf(`SELECT COALESCE(MAX(%s), 0) FROM %s`, col.Column, col.Table)
+    if err := db.Get(&status.MaxID, maxQuery); err != nil {
+      return nil, fmt.Errorf("failed to get max %s.%s: %w", col.Table, col.Column, err)
+    }
+
+    if status.Behind() && !dryRun {
+      if _, err := db.Exec(`SELECT setval($1, $2, true)`, status.Sequence, status.MaxID); err != nil {
+        return nil, fmt.Errorf("failed to reset sequence %s: %w", status.Sequence, err)
+      }
+      status.Repaired = true
+    }
+
+    statuses = append(statuses, status)
+  }
+
+  return statuses, nil
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"vehicle-showroom/internal/entity"
+)
+
+// SyncPermissions makes sure every permission in entity.PermissionCatalog
+// exists. A permission's default roles are granted only when the permission
+// is first inserted, so grants revoked through the roles API stay revoked.
+func SyncPermissions(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin permission sync: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, def := range entity.PermissionCatalog {
+		var permissionID int
+		err := tx.Get(&permissionID, `
+			INSERT INTO permissions (code, description)
+			VALUES ($1, $2)
+			ON CONFLICT (code) DO NOTHING
+			RETURNING id
+		`, def.Code, def.Description)
+		if err == sql.ErrNoRows {
+			// Already known: keep the description current, leave grants alone
+			if _, err := tx.Exec(`UPDATE permissions SET description = $1 WHERE code = $2`, def.Description, def.Code); err != nil {
+				return fmt.Errorf("failed to update permission %s: %w", def.Code, err)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to insert permission %s: %w", def.Code, err)
+		}
+
+		for _, roleName := range def.DefaultRoles {
+			_, err := tx.Exec(`
+				INSERT INTO role_permissions (role_id, permission_id)
+				SELECT id, $1 FROM roles WHERE name = $2
+				ON CONFLICT DO NOTHING
+			`, permissionID, roleName)
+			if err != nil {
+				return fmt.Errorf("failed to grant permission %s to %s: %w", def.Code, roleName, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
@@ -9,12 +9,7 @@ import (
 )
 
 func NewPostgreSQL(cfg config.DatabaseConfig) (*sqlx.DB, error) {
-  dsn := fmt.Sprintf(
-    "host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-    cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode,
-  )
-
-  db, err := sqlx.Connect("postgres", dsn)
+  db, err := sqlx.Connect("postgres", dsn(cfg))
   if err != nil {
     return nil, fmt.Errorf("failed to connect to database: %w", err)
   }
@@ -25,3 +20,10 @@ func NewPostgreSQL(cfg config.DatabaseConfig) (*sqlx.DB, error) {
 
   return db, nil
 }
+
+func dsn(cfg config.DatabaseConfig) string {
+  return fmt.Sprintf(
+    "host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+    cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode,
+  )
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"vehicle-showroom/internal/config"
+)
+
+// QueryCounter counts the statements sent to PostgreSQL over a connection
+// pool opened with NewCountingPostgreSQL. It is meant for benchmarks that
+// check a read does not issue a query per row.
+type QueryCounter struct {
+	n atomic.Int64
+}
+
+// Count returns the number of statements since the last Reset.
+func (q *QueryCounter) Count() int64 {
+	return q.n.Load()
+}
+
+func (q *QueryCounter) Reset() {
+	q.n.Store(0)
+}
+
+// NewCountingPostgreSQL is NewPostgreSQL with every query and exec counted
+// by counter.
+func NewCountingPostgreSQL(cfg config.DatabaseConfig, counter *QueryCounter) (*sqlx.DB, error) {
+	connector, err := pq.NewConnector(dsn(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	db := sqlx.NewDb(sql.OpenDB(&countingConnector{Connector: connector, counter: counter}), "postgres")
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return db, nil
+}
+
+type countingConnector struct {
+	driver.Connector
+	counter *QueryCounter
+}
+
+func (c *countingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Conn: conn, counter: c.counter}, nil
+}
+
+// countingConn forwards to the lib/pq connection. Statements prepared
+// through Prepare are not counted; sqlx only prepares for Preparex, which
+// the repositories do not use.
+type countingConn struct {
+	driver.Conn
+	counter *QueryCounter
+}
+
+func (c *countingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	c.counter.n.Add(1)
+	return queryer.QueryContext(ctx, query, args)
+}
+
+func (c *countingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	c.counter.n.Add(1)
+	return execer.ExecContext(ctx, query, args)
+}
+
+func (c *countingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *countingConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *countingConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
@@ -1,34 +1,217 @@
 package database
 
 import (
-  "log"
+  "fmt"
+  "math"
+  "math/rand"
+  "strings"
+  "time"
 
   "github.com/jmoiron/sqlx"
   "golang.org/x/crypto/bcrypt"
 )
 
-func SeedDemoData(db *sqlx.DB) error {
-  // Check if demo users already exist
-  var count int
-  err := db.Get(&count, "SELECT COUNT(*) FROM users")
+// SeedOptions controls how much demo data SeedDemo generates. Vehicles are
+// bought at random points over the last Months months and then repaired,
+// priced and sold like they would be in the showroom.
+type SeedOptions struct {
+  Customers  int
+  Vehicles   int
+  Mechanics  int
+  Months     int
+  RandomSeed int64
+}
+
+// SeedSizes are the presets accepted by `showroomctl seed demo --size`.
+var SeedSizes = map[string]SeedOptions{
+  "small":  {Customers: 10, Vehicles: 20, Mechanics: 1, Months: 3},
+  "medium": {Customers: 100, Vehicles: 300, Mechanics: 3, Months: 12},
+  "large":  {Customers: 1000, Vehicles: 5000, Mechanics: 8, Months: 36},
+}
+
+type SeedResult struct {
+  Users      int
+  Customers  int
+  SpareParts int
+  Vehicles   int
+  Purchases  int
+  Repairs    int
+  Sales      int
+}
+
+// Demo accounts share well-known passwords and must never be seeded into a
+// production database.
+var demoUsers = []struct {
+  username, email, password, fullName, phone, role string
+}{
+  {"admin", "admin@showroom.com", "admin123", "Admin User", "081234567890", "admin"},
+  {"cashier", "cashier@showroom.com", "cashier123", "Cashier User", "081234567891", "cashier"},
+  {"mechanic", "mechanic@showroom.com", "mechanic123", "Mechanic User", "081234567892", "mechanic"},
+}
+
+type seedModel struct {
+  brand, model  string
+  variants      []string
+  fuelType      string
+  transmissions []string
+  newPrice      float64
+}
+
+var seedModels = []seedModel{
+  {"Toyota", "Avanza", []string{"E", "G", "Veloz"}, "gasoline", []string{"manual", "automatic"}, 250e6},
+  {"Toyota", "Innova", []string{"G", "V", "Venturer"}, "diesel", []string{"manual", "automatic"}, 420e6},
+  {"Toyota", "Fortuner", []string{"G", "VRZ", "GR Sport"}, "diesel", []string{"automatic"}, 560e6},
+  {"Toyota", "Camry", []string{"V", "Hybrid"}, "hybrid", []string{"cvt"}, 760e6},
+  {"Toyota", "Yaris", []string{"G", "S GR Sport"}, "gasoline", []string{"manual", "cvt"}, 300e6},
+  {"Honda", "Brio", []string{"S", "E", "RS"}, "gasoline", []string{"manual", "cvt"}, 190e6},
+  {"Honda", "Jazz", []string{"S", "RS"}, "gasoline", []string{"manual", "cvt"}, 270e6},
+  {"Honda", "HR-V", []string{"S", "E", "SE"}, "gasoline", []string{"cvt"}, 380e6},
+  {"Honda", "CR-V", []string{"2.0", "1.5 Turbo Prestige"}, "gasoline", []string{"cvt"}, 550e6},
+  {"Honda", "Civic", []string{"E", "RS"}, "gasoline", []string{"manual", "cvt"}, 510e6},
+  {"Mitsubishi", "Xpander", []string{"GLS", "Exceed", "Ultimate"}, "gasoline", []string{"manual", "automatic"}, 280e6},
+  {"Mitsubishi", "Pajero Sport", []string{"Exceed", "Dakar"}, "diesel", []string{"automatic"}, 600e6},
+  {"Suzuki", "Ertiga", []string{"GL", "GX"}, "gasoline", []string{"manual", "automatic"}, 250e6},
+  {"Daihatsu", "Xenia", []string{"M", "X", "R"}, "gasoline", []string{"manual", "cvt"}, 220e6},
+  {"Hyundai", "Creta", []string{"Active", "Trend", "Prime"}, "gasoline", []string{"manual", "cvt"}, 330e6},
+  {"Hyundai", "Ioniq 5", []string{"Prime", "Signature"}, "electric", []string{"automatic"}, 780e6},
+  {"Nissan", "X-Trail", []string{"T", "VL"}, "gasoline", []string{"cvt"}, 500e6},
+  {"BMW", "320i", []string{"Sport", "M Sport"}, "gasoline", []string{"automatic"}, 920e6},
+  {"Mercedes-Benz", "C200", []string{"Avantgarde", "AMG Line"}, "gasoline", []string{"automatic"}, 1100e6},
+  {"Wuling", "Air ev", []string{"Standard", "Long Range"}, "electric", []string{"automatic"}, 260e6},
+}
+
+var seedSpareParts = []struct {
+  name, brand, unit string
+  cost              float64
+}{
+  {"Engine Oil 5W-30 (4L)", "Shell", "bottle", 380000},
+  {"Oil Filter", "Toyota Genuine Parts", "pcs", 45000},
+  {"Air Filter", "Sakura", "pcs", 90000},
+  {"Cabin Filter", "Denso", "pcs", 120000},
+  {"Brake Pad Set (Front)", "Bendix", "set", 450000},
+  {"Brake Pad Set (Rear)", "Bendix", "set", 380000},
+  {"Brake Disc", "Brembo", "pcs", 850000},
+  {"Spark Plug", "NGK", "pcs", 60000},
+  {"Battery 12V 45Ah", "GS Astra", "pcs", 950000},
+  {"Wiper Blade", "Bosch", "pair", 150000},
+  {"Timing Belt", "Gates", "pcs", 650000},
+  {"Radiator Coolant (1L)", "Prestone", "bottle", 70000},
+  {"Shock Absorber", "KYB", "pcs", 900000},
+  {"Tie Rod End", "555", "pcs", 280000},
+  {"Clutch Kit", "Exedy", "set", 2200000},
+  {"Tire 185/65 R15", "Bridgestone", "pcs", 850000},
+  {"Headlamp Bulb H4", "Philips", "pcs", 95000},
+  {"AC Compressor Belt", "Bando", "pcs", 180000},
+  {"Transmission Fluid (1L)", "Toyota Genuine Parts", "bottle", 160000},
+  {"Body Paint Touch-up", "Nippon Paint", "can", 250000},
+}
+
+var seedRepairs = []struct {
+  title       string
+  laborCost   float64
+  partIndexes []int // into seedSpareParts
+}{
+  {"Periodic service", 350000, []int{0, 1, 2, 7}},
+  {"Brake overhaul", 500000, []int{4, 5, 6}},
+  {"Battery and electrical check", 250000, []int{8, 16}},
+  {"Suspension repair", 900000, []int{12, 13}},
+  {"Timing belt replacement", 1200000, []int{10, 11}},
+  {"Clutch replacement", 1500000, []int{14}},
+  {"Tire replacement", 200000, []int{15}},
+  {"AC service", 450000, []int{3, 17}},
+  {"Transmission service", 600000, []int{18}},
+  {"Body and paint repair", 2500000, []int{19}},
+}
+
+var (
+  seedFirstNames = []string{"Budi", "Siti", "Agus", "Dewi", "Rizky", "Putri", "Andi", "Rina", "Hendra", "Lestari",
+    "Fajar", "Wulan", "Dimas", "Ayu", "Yusuf", "Indah", "Bayu", "Maya", "Arif", "Nur"}
+  seedLastNames = []string{"Santoso", "Wijaya", "Pratama", "Saputra", "Hidayat", "Kusuma", "Nugroho", "Setiawan",
+    "Halim", "Gunawan", "Siregar", "Nasution", "Hutapea", "Permana", "Rahman"}
+  seedCompanyWords = []string{"Maju", "Jaya", "Sentosa", "Abadi", "Makmur", "Sejahtera", "Mandiri", "Berkah", "Prima", "Utama"}
+  seedStreets      = []string{"Sudirman", "Thamrin", "Gatot Subroto", "Kuningan", "Casablanca", "Diponegoro",
+    "Ahmad Yani", "Pemuda", "Merdeka", "Asia Afrika"}
+  seedCities  = []string{"Jakarta", "Bandung", "Surabaya", "Bekasi", "Tangerang", "Depok", "Bogor", "Semarang"}
+  seedColors  = []string{"White", "Black", "Silver", "Grey", "Red", "Blue", "Brown"}
+  seedRegions = []string{"B", "B", "B", "D", "F", "L", "AB", "H"}
+)
+
+// SeedDemo generates a consistent demo data set in one transaction: users,
+// customers, spare parts, and vehicles with their purchases, repairs and
+// sales. Business codes continue from whatever is already in the database.
+func SeedDemo(db *sqlx.DB, opts SeedOptions) (*SeedResult, error) {
+  if opts.RandomSeed == 0 {
+    opts.RandomSeed = time.Now().UnixNano()
+  }
+  if opts.Months <= 0 {
+    opts.Months = 1
+  }
+
+  tx, err := db.Beginx()
   if err != nil {
-    return err
+    return nil, fmt.Errorf("failed to begin transaction: %w", err)
+  }
+  defer tx.Rollback()
+
+  s := &seeder{
+    tx:       tx,
+    rnd:      rand.New(rand.NewSource(opts.RandomSeed)),
+    now:      time.Now(),
+    counters: make(map[string]int),
+    result:   &SeedResult{},
+  }
+
+  if err := s.seedUsers(opts.Mechanics); err != nil {
+    return nil, err
   }
-  
-  if count > 0 {
-    log.Println("Demo data already exists, skipping seed")
-    return nil
+  if err := s.seedCustomers(opts.Customers); err != nil {
+    return nil, err
+  }
+  if err := s.seedSpareParts(opts.Vehicles); err != nil {
+    return nil, err
+  }
+  for i := 0; i < opts.Vehicles; i++ {
+    if err := s.seedVehicle(opts.Months); err != nil {
+      return nil, err
+    }
+  }
+
+  if err := tx.Commit(); err != nil {
+    return nil, fmt.Errorf("failed to commit demo data: %w", err)
   }
 
-  log.Println("Seeding demo data...")
+  return s.result, nil
+}
+
+type seeder struct {
+  tx       *sqlx.Tx
+  rnd      *rand.Rand
+  now      time.Time
+  counters map[string]int
+  result   *SeedResult
+
+  adminID     int
+  cashierID   int
+  mechanicIDs []int
+  customerIDs []int
+  parts       []seedPart
+}
+
+type seedPart struct {
+  id    int
+  cost  float64
+  stock int
+}
 
-  // Create demo users
-  users := []struct {
-    username, email, password, fullName, phone, role string
-  }{
-    {"admin", "admin@showroom.com", "admin123", "Admin User", "081234567890", "admin"},
-    {"cashier", "cashier@showroom.com", "cashier123", "Cashier User", "081234567891", "cashier"},
-    {"mechanic", "mechanic@showroom.com", "mechanic123", "Mechanic User", "081234567892", "mechanic"},
+func (s *seeder) seedUsers(extraMechanics int) error {
+  users := demoUsers
+  for i := 2; i <= extraMechanics; i++ {
+    users = append(users, struct {
+      username, email, password, fullName, phone, role string
+    }{
+      fmt.Sprintf("mechanic%d", i), fmt.Sprintf("mechanic%d@showroom.com", i), "mechanic123",
+      fmt.Sprintf("Mechanic %d", i), s.phone(), "mechanic",
+    })
   }
 
   for _, user := range users {
@@ -37,73 +220,408 @@ func SeedDemoData(db *sqlx.DB) error {
       return err
     }
 
-    _, err = db.Exec(`
+    result, err := s.tx.Exec(`
       INSERT INTO users (username, email, password_hash, full_name, phone, role, is_active)
       VALUES ($1, $2, $3, $4, $5, $6, true)
+      ON CONFLICT (username) DO NOTHING
     `, user.username, user.email, string(hashedPassword), user.fullName, user.phone, user.role)
-    
+    if err != nil {
+      return fmt.Errorf("failed to seed user %s: %w", user.username, err)
+    }
+    if rows, _ := result.RowsAffected(); rows > 0 {
+      s.result.Users++
+    }
+
+    var id int
+    if err := s.tx.Get(&id, `SELECT id FROM users WHERE username = $1`, user.username); err != nil {
+      return fmt.Errorf("failed to look up user %s: %w", user.username, err)
+    }
+
+    switch user.role {
+    case "admin":
+      s.adminID = id
+    case "cashier":
+      s.cashierID = id
+    case "mechanic":
+      s.mechanicIDs = append(s.mechanicIDs, id)
+    }
+  }
+
+  return nil
+}
+
+func (s *seeder) seedCustomers(count int) error {
+  for i := 0; i < count; i++ {
+    code, err := s.nextCode("customers", "customer_code", "CUST-")
     if err != nil {
       return err
     }
+
+    first, last := pick(s.rnd, seedFirstNames), pick(s.rnd, seedLastNames)
+    name, customerType := first+" "+last, "individual"
+    email := fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), s.rnd.Intn(1000))
+    idCard := s.digits(16)
+    if s.rnd.Float64() < 0.2 {
+      customerType = "corporate"
+      name = fmt.Sprintf("%s %s %s", pick(s.rnd, []string{"PT.", "CV."}), pick(s.rnd, seedCompanyWords), pick(s.rnd, seedCompanyWords))
+      email = fmt.Sprintf("info@%s%d.example.com", strings.ToLower(strings.Fields(name)[1]), s.rnd.Intn(1000))
+      idCard = s.digits(15) // NPWP
+    }
+    address := fmt.Sprintf("Jl. %s No. %d, %s", pick(s.rnd, seedStreets), 1+s.rnd.Intn(300), pick(s.rnd, seedCities))
+
+    var id int
+    err = s.tx.Get(&id, `
+      INSERT INTO customers (customer_code, name, phone, email, address, id_card_number, type, created_by, is_active)
+      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, true)
+      RETURNING id
+    `, code, name, s.phone(), email, address, idCard, customerType, s.cashierID)
+    if err != nil {
+      return fmt.Errorf("failed to seed customer: %w", err)
+    }
+
+    s.customerIDs = append(s.customerIDs, id)
+    s.result.Customers++
   }
 
-  // Create demo customers
-  customers := []struct {
-    code, name, phone, email, address, idCard, customerType string
-  }{
-    {"CUST-001", "John Doe", "081234567893", "john@email.com", "Jl. Sudirman No. 123, Jakarta", "3171234567890001", "individual"},
-    {"CUST-002", "Jane Smith", "081234567894", "jane@email.com", "Jl. Thamrin No. 456, Jakarta", "3171234567890002", "individual"},
-    {"CUST-003", "PT. Maju Jaya", "081234567895", "info@majujaya.com", "Jl. Gatot Subroto No. 789, Jakarta", "021234567890", "corporate"},
-    {"CUST-004", "Ahmad Rahman", "081234567896", "ahmad@email.com", "Jl. Kuningan No. 321, Jakarta", "3171234567890003", "individual"},
-    {"CUST-005", "CV. Berkah Motor", "081234567897", "info@berkahmotor.com", "Jl. Casablanca No. 654, Jakarta", "021234567891", "corporate"},
+  if len(s.customerIDs) == 0 {
+    if err := s.tx.Select(&s.customerIDs, `SELECT id FROM customers WHERE is_active = true`); err != nil {
+      return fmt.Errorf("failed to load customers: %w", err)
+    }
   }
 
-  for _, customer := range customers {
-    _, err = db.Exec(`
-      INSERT INTO customers (customer_code, name, phone, email, address, id_card_number, type, created_by, is_active)
-      VALUES ($1, $2, $3, $4, $5, $6, $7, 1, true)
-    `, customer.code, customer.name, customer.phone, customer.email, customer.address, customer.idCard, customer.customerType)
-    
+  return nil
+}
+
+func (s *seeder) seedSpareParts(vehicles int) error {
+  // Enough stock for the expected number of repairs, with some left over
+  stock := 20 + vehicles/5
+
+  for _, part := range seedSpareParts {
+    code, err := s.nextCode("spare_parts", "part_code", "PART-")
     if err != nil {
       return err
     }
+
+    var id int
+    err = s.tx.Get(&id, `
+      INSERT INTO spare_parts (part_code, name, brand, cost_price, selling_price, stock_quantity, min_stock_level, unit_measure, is_active)
+      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, true)
+      RETURNING id
+    `, code, part.name, part.brand, part.cost, roundTo(part.cost*1.3, 1000), stock, 5, part.unit)
+    if err != nil {
+      return fmt.Errorf("failed to seed spare part: %w", err)
+    }
+
+    s.parts = append(s.parts, seedPart{id: id, cost: part.cost, stock: stock})
+    s.result.SpareParts++
+  }
+
+  return nil
+}
+
+// seedVehicle buys one vehicle at a random point in the history window and
+// walks it through repairs and, if enough time has passed, a sale.
+func (s *seeder) seedVehicle(months int) error {
+  if len(s.customerIDs) == 0 {
+    return fmt.Errorf("no customers to buy vehicles from")
+  }
+
+  m := seedModels[s.rnd.Intn(len(seedModels))]
+  age := s.rnd.Intn(10)
+  year := s.now.Year() - age
+  mileage := age*(8000+s.rnd.Intn(12000)) + s.rnd.Intn(5000)
+  purchasePrice := roundTo(m.newPrice*math.Pow(0.87, float64(age))*(0.85+s.rnd.Float64()*0.15), 500000)
+
+  window := s.now.Sub(s.now.AddDate(0, -months, 0))
+  purchasedAt := s.businessHours(s.now.Add(-time.Duration(s.rnd.Int63n(int64(window)))))
+  sellerID := pick(s.rnd, s.customerIDs)
+
+  code, err := s.nextCode("vehicles", "vehicle_code", "VEH-")
+  if err != nil {
+    return err
+  }
+
+  var vehicleID int
+  err = s.tx.Get(&vehicleID, `
+    INSERT INTO vehicles (
+      vehicle_code, chassis_number, license_plate, brand, model, variant, year, mileage, color,
+      fuel_type, transmission, purchase_price, status, purchased_from_customer_id,
+      purchased_by_cashier, purchased_at, created_at, updated_at, purchase_notes, condition_notes
+    )
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, 'purchased', $13, $14, $15, $15, $15, $16, $17)
+    RETURNING id
+  `, code, s.chassis(), s.plate(), m.brand, m.model, pick(s.rnd, m.variants), year, mileage, pick(s.rnd, seedColors),
+    m.fuelType, pick(s.rnd, m.transmissions), purchasePrice, sellerID, s.cashierID, purchasedAt,
+    "Bought from private seller", pick(s.rnd, []string{"Good condition", "Minor scratches on rear bumper",
+      "Needs service", "Worn tires", "Small dent on left door"}))
+  if err != nil {
+    return fmt.Errorf("failed to seed vehicle: %w", err)
+  }
+  s.result.Vehicles++
+
+  if err := s.insertTransaction("purchase_transactions", "PUR", vehicleID, sellerID, purchasePrice, 0,
+    pick(s.rnd, []string{"cash", "transfer", "transfer", "check"}), purchasedAt); err != nil {
+    return err
+  }
+  s.result.Purchases++
+
+  // Repairs happen in the days after purchase
+  repairCost := 0.0
+  readyAt := purchasedAt.Add(time.Duration(1+s.rnd.Intn(3)) * 24 * time.Hour)
+  inRepair := false
+  if s.rnd.Float64() < 0.6 {
+    for n := 1 + s.rnd.Intn(2); n > 0; n-- {
+      cost, finishedAt, err := s.seedRepair(vehicleID, readyAt)
+      if err != nil {
+        return err
+      }
+      if finishedAt == nil {
+        inRepair = true
+        break
+      }
+      repairCost += cost
+      readyAt = *finishedAt
+    }
   }
 
-  // Create demo vehicles
-  vehicles := []struct {
-    code, chassis, plate, brand, model, variant string
-    year, mileage int
-    color, fuelType, transmission string
-    purchasePrice float64
-    status string
-    purchasedFromCustomerID int
-  }{
-    {"VEH-001", "MHKA1234567890123", "B 1234 ABC", "Honda", "Civic", "RS", 2022, 15000, "White", "gasoline", "manual", 350000000, "purchased", 1},
-    {"VEH-002", "WBAVA1234567890123", "B 5678 DEF", "BMW", "320i", "Sport", 2021, 25000, "Black", "gasoline", "automatic", 650000000, "in_repair", 2},
-    {"VEH-003", "JTDKN1234567890123", "B 9012 GHI", "Toyota", "Camry", "Hybrid", 2023, 8000, "Silver", "hybrid", "cvt", 550000000, "ready_to_sell", 3},
-    {"VEH-004", "KMHJ1234567890123", "B 3456 JKL", "Hyundai", "Tucson", "GLS", 2022, 20000, "Red", "gasoline", "automatic", 450000000, "purchased", 4},
-    {"VEH-005", "JN1AZ1234567890123", "B 7890 MNO", "Nissan", "X-Trail", "XT", 2021, 35000, "Blue", "gasoline", "cvt", 400000000, "ready_to_sell", 5},
-  }
-
-  for _, vehicle := range vehicles {
-    _, err = db.Exec(`
-      INSERT INTO vehicles (
-        vehicle_code, chassis_number, license_plate, brand, model, variant, 
-        year, mileage, color, fuel_type, transmission, purchase_price, 
-        status, purchased_from_customer_id, purchased_by_cashier, purchased_at,
-        purchase_notes, condition_notes
-      )
-      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, 2, CURRENT_TIMESTAMP, 
-              'Vehicle purchased in good condition', 'Minor scratches on rear bumper')
-    `, vehicle.code, vehicle.chassis, vehicle.plate, vehicle.brand, vehicle.model, vehicle.variant,
-       vehicle.year, vehicle.mileage, vehicle.color, vehicle.fuelType, vehicle.transmission,
-       vehicle.purchasePrice, vehicle.status, vehicle.purchasedFromCustomerID)
-    
+  status := "purchased"
+  var suggested, approved, final *float64
+  var soldTo *int
+  var soldAt *time.Time
+
+  if inRepair {
+    status = "in_repair"
+  } else if readyAt.Before(s.now) {
+    price := roundTo((purchasePrice+repairCost)*(1.12+s.rnd.Float64()*0.13), 1000000)
+    suggested, approved = &price, &price
+    status = "ready_to_sell"
+
+    if saleWindow := s.now.Sub(readyAt) - 72*time.Hour; saleWindow > 0 && s.rnd.Float64() < 0.6 {
+      at := s.businessHours(readyAt.Add(72*time.Hour + time.Duration(s.rnd.Int63n(int64(saleWindow)))))
+      buyerID := pick(s.rnd, s.customerIDs)
+      discount := 0.0
+      if s.rnd.Float64() < 0.4 {
+        discount = float64(1+s.rnd.Intn(10)) * 500000
+      }
+      finalPrice := price - discount
+
+      if err := s.insertTransaction("sales_transactions", "SAL", vehicleID, buyerID, price, discount,
+        pick(s.rnd, []string{"cash", "transfer", "transfer", "credit"}), at); err != nil {
+        return err
+      }
+      s.result.Sales++
+
+      status, final, soldTo, soldAt = "sold", &finalPrice, &buyerID, &at
+    } else if s.rnd.Float64() < 0.15 {
+      status = "reserved"
+    }
+  }
+
+  var approvedBy *int
+  if approved != nil {
+    approvedBy = &s.adminID
+  }
+  var soldBy *int
+  if soldTo != nil {
+    soldBy = &s.cashierID
+  }
+
+  _, err = s.tx.Exec(`
+    UPDATE vehicles
+    SET status = $1, total_repair_cost = $2, suggested_selling_price = $3, approved_selling_price = $4,
+        price_approved_by_admin = $5, final_selling_price = $6, sold_to_customer_id = $7,
+        sold_by_cashier = $8, sold_at = $9, updated_at = COALESCE($9, $10)
+    WHERE id = $11
+  `, status, repairCost, suggested, approved, approvedBy, final, soldTo, soldBy, soldAt, readyAt, vehicleID)
+  if err != nil {
+    return fmt.Errorf("failed to update seeded vehicle: %w", err)
+  }
+
+  return nil
+}
+
+// seedRepair creates one repair starting at startAt. It returns the total
+// cost and completion time, or a nil time if the repair is still running.
+func (s *seeder) seedRepair(vehicleID int, startAt time.Time) (float64, *time.Time, error) {
+  template := seedRepairs[s.rnd.Intn(len(seedRepairs))]
+  mechanicID := pick(s.rnd, s.mechanicIDs)
+  startAt = s.businessHours(startAt)
+  completedAt := s.businessHours(startAt.Add(time.Duration(1+s.rnd.Intn(5)) * 24 * time.Hour))
+
+  status := "completed"
+  var completed *time.Time
+  if completedAt.After(s.now) {
+    status = "in_progress"
+  } else {
+    completed = &completedAt
+  }
+
+  number, err := s.nextCode("repairs", "repair_number", "REP-"+startAt.Format("20060102")+"-")
+  if err != nil {
+    return 0, nil, err
+  }
+
+  labor := roundTo(template.laborCost*(0.8+s.rnd.Float64()*0.5), 50000)
+
+  var repairID int
+  err = s.tx.Get(&repairID, `
+    INSERT INTO repairs (repair_number, vehicle_id, title, labor_cost, status, mechanic_id, started_at, completed_at, created_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $7)
+    RETURNING id
+  `, number, vehicleID, template.title, labor, status, mechanicID, startAt, completed)
+  if err != nil {
+    return 0, nil, fmt.Errorf("failed to seed repair: %w", err)
+  }
+  s.result.Repairs++
+
+  partsCost := 0.0
+  for _, idx := range template.partIndexes {
+    part := &s.parts[idx]
+    quantity := 1 + s.rnd.Intn(2)
+    if part.stock < quantity {
+      continue
+    }
+
+    total := part.cost * float64(quantity)
+    _, err := s.tx.Exec(`
+      INSERT INTO repair_parts (repair_id, spare_part_id, quantity_used, unit_cost, total_cost, used_at)
+      VALUES ($1, $2, $3, $4, $5, $6)
+    `, repairID, part.id, quantity, part.cost, total, startAt)
     if err != nil {
-      return err
+      return 0, nil, fmt.Errorf("failed to seed repair part: %w", err)
+    }
+
+    _, err = s.tx.Exec(`
+      INSERT INTO stock_movements (spare_part_id, movement_type, reference_type, reference_id,
+                                   quantity_before, quantity_moved, quantity_after, movement_date, processed_by)
+      VALUES ($1, 'out', 'repair', $2, $3, $4, $5, $6, $7)
+    `, part.id, repairID, part.stock, quantity, part.stock-quantity, startAt, mechanicID)
+    if err != nil {
+      return 0, nil, fmt.Errorf("failed to seed stock movement: %w", err)
     }
+
+    _, err = s.tx.Exec(`UPDATE spare_parts SET stock_quantity = stock_quantity - $1 WHERE id = $2`, quantity, part.id)
+    if err != nil {
+      return 0, nil, fmt.Errorf("failed to update spare part stock: %w", err)
+    }
+
+    part.stock -= quantity
+    partsCost += total
+  }
+
+  totalCost := labor + partsCost
+  _, err = s.tx.Exec(`UPDATE repairs SET total_parts_cost = $1, total_cost = $2 WHERE id = $3`, partsCost, totalCost, repairID)
+  if err != nil {
+    return 0, nil, fmt.Errorf("failed to update repair costs: %w", err)
+  }
+
+  return totalCost, completed, nil
+}
+
+// insertTransaction writes a purchase or sales transaction numbered the way
+// the transaction repository does it, but dated in the past.
+func (s *seeder) insertTransaction(table, kind string, vehicleID, customerID int, price, discount float64,
+  paymentMethod string, at time.Time) error {
+  day := at.Format("20060102")
+
+  number, err := s.nextCode(table, "transaction_number", kind+"-"+day+"-")
+  if err != nil {
+    return err
+  }
+  invoice, err := s.nextCode(table, "invoice_number", "INV-"+kind+"-"+day+"-")
+  if err != nil {
+    return err
+  }
+
+  if table == "sales_transactions" {
+    _, err = s.tx.Exec(`
+      INSERT INTO sales_transactions (transaction_number, invoice_number, vehicle_id, customer_id, vehicle_price,
+                                      tax_amount, discount_amount, total_amount, payment_method, transaction_date,
+                                      cashier_id, status, created_at)
+      VALUES ($1, $2, $3, $4, $5, 0, $6, $7, $8, $9, $10, 'completed', $9)
+    `, number, invoice, vehicleID, customerID, price, discount, price-discount, paymentMethod, at, s.cashierID)
+  } else {
+    _, err = s.tx.Exec(`
+      INSERT INTO purchase_transactions (transaction_number, invoice_number, vehicle_id, customer_id, vehicle_price,
+                                         tax_amount, total_amount, payment_method, transaction_date, cashier_id,
+                                         status, created_at)
+      VALUES ($1, $2, $3, $4, $5, 0, $5, $6, $7, $8, 'completed', $7)
+    `, number, invoice, vehicleID, customerID, price, paymentMethod, at, s.cashierID)
+  }
+  if err != nil {
+    return fmt.Errorf("failed to seed %s: %w", table, err)
   }
 
-  log.Println("Demo data seeded successfully!")
   return nil
 }
+
+// nextCode returns prefix followed by the next number, continuing from the
+// highest number already stored under that prefix.
+func (s *seeder) nextCode(table, column, prefix string) (string, error) {
+  last, ok := s.counters[prefix]
+  if !ok {
+    query := fmt.Sprintf(`
+      SELECT COALESCE(MAX(CAST(SUBSTRING(%[1]s FROM $2) AS INTEGER)), 0)
+      FROM %[2]s
+      WHERE %[1]s LIKE $1 AND SUBSTRING(%[1]s FROM $2) ~ '^[0-9]+$'
+    `, column, table)
+    if err := s.tx.Get(&last, query, prefix+"%", len(prefix)+1); err != nil {
+      return "", fmt.Errorf("failed to get last %s: %w", column, err)
+    }
+  }
+
+  last++
+  s.counters[prefix] = last
+  return fmt.Sprintf("%s%03d", prefix, last), nil
+}
+
+func (s *seeder) phone() string {
+  return "08" + s.digits(10)
+}
+
+func (s *seeder) digits(n int) string {
+  b := make([]byte, n)
+  for i := range b {
+    b[i] = byte('0' + s.rnd.Intn(10))
+  }
+  b[0] = byte('1' + s.rnd.Intn(9))
+  return string(b)
+}
+
+func (s *seeder) chassis() string {
+  // VINs never use I, O or Q
+  const alphabet = "ABCDEFGHJKLMNPRSTUVWXYZ0123456789"
+  b := make([]byte, 17)
+  for i := range b {
+    b[i] = alphabet[s.rnd.Intn(len(alphabet))]
+  }
+  return string(b)
+}
+
+func (s *seeder) plate() string {
+  const letters = "ABCDEFGHJKLMNPRSTUVWXYZ"
+  suffix := make([]byte, 2+s.rnd.Intn(2))
+  for i := range suffix {
+    suffix[i] = letters[s.rnd.Intn(len(letters))]
+  }
+  return fmt.Sprintf("%s %d %s", pick(s.rnd, seedRegions), 1000+s.rnd.Intn(9000), suffix)
+}
+
+// businessHours moves t to a time between 09:00 and 17:00 on the same day,
+// never later than now.
+func (s *seeder) businessHours(t time.Time) time.Time {
+  day := time.Date(t.Year(), t.Month(), t.Day(), 9, 0, 0, 0, t.Location())
+  t = day.Add(time.Duration(s.rnd.Intn(8*60)) * time.Minute)
+  if t.After(s.now) {
+    return s.now
+  }
+  return t
+}
+
+func pick[T any](rnd *rand.Rand, values []T) T {
+  return values[rnd.Intn(len(values))]
+}
+
+func roundTo(value, unit float64) float64 {
+  return math.Round(value/unit) * unit
+}
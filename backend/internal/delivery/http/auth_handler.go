@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -21,21 +22,32 @@ func NewAuthHandler(authUsecase usecase.AuthUsecase) *AuthHandler {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req entity.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
-	ipAddress := c.ClientIP()
+	response, err := h.authUsecase.Login(c.Request.Context(), &req, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var req entity.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
 
-	response, err := h.authUsecase.Login(&req, ipAddress)
+	response, err := h.authUsecase.VerifyTwoFactor(c.Request.Context(), req.ChallengeToken, req.Code, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "Login failed",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -48,19 +60,13 @@ func (h *AuthHandler) Login(c *gin.Context) {
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req entity.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
-	user, err := h.authUsecase.Register(&req)
+	user, err := h.authUsecase.Register(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Registration failed",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -70,38 +76,105 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	})
 }
 
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req entity.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	response, err := h.authUsecase.Refresh(c.Request.Context(), req.RefreshToken, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
 func (h *AuthHandler) Logout(c *gin.Context) {
+	sessionValue, _ := c.Get("session")
+	session := sessionValue.(*entity.UserSession)
+
+	if err := h.authUsecase.Logout(c.Request.Context(), session.SessionToken); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Logged out successfully",
+	})
+}
+
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+	sessionValue, _ := c.Get("session")
+	session := sessionValue.(*entity.UserSession)
+
+	sessions, err := h.authUsecase.ListSessions(c.Request.Context(), user.ID, session.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sessions,
+	})
+}
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
 	userValue, _ := c.Get("user")
 	user := userValue.(*entity.User)
 
-	// In a real app, you might want to invalidate the token itself
-	// For simplicity, we'll just rely on the client deleting the token
-	// and the session becoming inactive on next login.
-	// We can also implement a session invalidation logic here.
-	_ = h.authUsecase.Logout(user.Username) // Example of invalidating session
+	if err := h.authUsecase.RevokeSession(c.Request.Context(), user.ID, id); err != nil {
+		c.Error(err)
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Logged out successfully",
+		"message": "Session revoked successfully",
+	})
+}
+
+func (h *AuthHandler) ForceLogout(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.authUsecase.ForceLogout(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "All sessions of the user have been revoked",
 	})
 }
 
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	userValue, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "Unauthorized",
-			"message": "User not found in context",
-		})
+		c.Error(entity.NewUnauthorizedError("user not found in context"))
 		return
 	}
 
 	user, ok := userValue.(*entity.User)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Internal Server Error",
-			"message": "Invalid user type in context",
-		})
+		c.Error(errors.New("invalid user type in context"))
 		return
 	}
 
@@ -110,3 +183,20 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		"data":    user,
 	})
 }
+
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.authUsecase.UnlockUser(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "User unlocked successfully",
+	})
+}
@@ -0,0 +1,185 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/usecase"
+)
+
+// maxDocumentSize bounds an uploaded customer document.
+const maxDocumentSize = 10 << 20
+
+type CustomerDocumentHandler struct {
+	documentUsecase usecase.CustomerDocumentUsecase
+}
+
+func NewCustomerDocumentHandler(documentUsecase usecase.CustomerDocumentUsecase) *CustomerDocumentHandler {
+	return &CustomerDocumentHandler{
+		documentUsecase: documentUsecase,
+	}
+}
+
+// Upload takes a multipart form with the file in "file" and the fields of
+// UploadCustomerDocumentRequest.
+func (h *CustomerDocumentHandler) Upload(c *gin.Context) {
+	customerID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxDocumentSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.Error(entity.NewFieldError("file", "must be at most 10 MB"))
+		} else {
+			c.Error(entity.NewFieldError("file", "is required; upload it as multipart/form-data"))
+		}
+		return
+	}
+	if header.Size > maxDocumentSize {
+		c.Error(entity.NewFieldError("file", "must be at most 10 MB"))
+		return
+	}
+
+	var req entity.UploadCustomerDocumentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.Error(fmt.Errorf("failed to open upload: %w", err))
+		return
+	}
+	defer file.Close()
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	document, err := h.documentUsecase.Upload(c.Request.Context(), customerID, &req,
+		usecase.DocumentFile{Name: header.Filename, Content: file}, user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    document,
+	})
+}
+
+func (h *CustomerDocumentHandler) List(c *gin.Context) {
+	customerID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	documents, err := h.documentUsecase.List(c.Request.Context(), customerID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    documents,
+	})
+}
+
+// Download sends the stored file as an attachment under its original name.
+func (h *CustomerDocumentHandler) Download(c *gin.Context) {
+	customerID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	id, ok := parseID(c, "documentId")
+	if !ok {
+		return
+	}
+
+	document, file, err := h.documentUsecase.Open(c.Request.Context(), customerID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, document.SizeBytes, document.ContentType, file, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": document.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, no-store",
+	})
+}
+
+func (h *CustomerDocumentHandler) Verify(c *gin.Context) {
+	customerID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	id, ok := parseID(c, "documentId")
+	if !ok {
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	document, err := h.documentUsecase.Verify(c.Request.Context(), customerID, id, user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    document,
+	})
+}
+
+func (h *CustomerDocumentHandler) Delete(c *gin.Context) {
+	customerID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	id, ok := parseID(c, "documentId")
+	if !ok {
+		return
+	}
+
+	if err := h.documentUsecase.Delete(c.Request.Context(), customerID, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Customer document deleted successfully",
+	})
+}
+
+func (h *CustomerDocumentHandler) KYCStatus(c *gin.Context) {
+	customerID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	status, err := h.documentUsecase.KYCStatus(c.Request.Context(), customerID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
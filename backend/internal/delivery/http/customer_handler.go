@@ -2,10 +2,10 @@ package http
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/spreadsheet"
 	"vehicle-showroom/internal/usecase"
 )
 
@@ -19,13 +19,21 @@ func NewCustomerHandler(customerUsecase usecase.CustomerUsecase) *CustomerHandle
 	}
 }
 
+var customerExportColumns = []exportColumn[entity.Customer]{
+	{label{"Customer Code", "Kode Pelanggan"}, spreadsheet.Text, func(cu *entity.Customer) interface{} { return cu.CustomerCode }},
+	{label{"Name", "Nama"}, spreadsheet.Text, func(cu *entity.Customer) interface{} { return cu.Name }},
+	{label{"Type", "Jenis"}, spreadsheet.Text, func(cu *entity.Customer) interface{} { return cu.Type }},
+	{label{"Phone", "Telepon"}, spreadsheet.Text, func(cu *entity.Customer) interface{} { return cu.Phone }},
+	{label{"Email", "Email"}, spreadsheet.Text, func(cu *entity.Customer) interface{} { return cu.Email }},
+	{label{"Address", "Alamat"}, spreadsheet.Text, func(cu *entity.Customer) interface{} { return cu.Address }},
+	{label{"ID Card Number", "Nomor KTP"}, spreadsheet.Text, func(cu *entity.Customer) interface{} { return cu.IDCardNumber }},
+	{label{"Created At", "Tanggal Dibuat"}, spreadsheet.DateTime, func(cu *entity.Customer) interface{} { return cu.CreatedAt }},
+}
+
 func (h *CustomerHandler) Create(c *gin.Context) {
 	var req entity.CreateCustomerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
@@ -33,38 +41,31 @@ func (h *CustomerHandler) Create(c *gin.Context) {
 	user := userValue.(*entity.User)
 	createdBy := user.ID
 
-	customer, err := h.customerUsecase.Create(&req, createdBy)
+	customer, duplicates, err := h.customerUsecase.Create(c.Request.Context(), &req, createdBy)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to create customer",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	response := gin.H{
 		"success": true,
 		"data":    customer,
-	})
+	}
+	if len(duplicates) > 0 {
+		response["warnings"] = gin.H{"possible_duplicates": duplicates}
+	}
+	c.JSON(http.StatusCreated, response)
 }
 
 func (h *CustomerHandler) GetByID(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid customer ID",
-			"message": "Customer ID must be a number",
-		})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
-	customer, err := h.customerUsecase.GetByID(id)
+	customer, err := h.customerUsecase.GetByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Customer not found",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -75,19 +76,36 @@ func (h *CustomerHandler) GetByID(c *gin.Context) {
 }
 
 func (h *CustomerHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	params, ok := listParams(c)
+	if !ok {
+		return
+	}
 	search := c.Query("search")
 
-	response, err := h.customerUsecase.List(page, limit, search)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to list customers",
-			"message": err.Error(),
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	if format != "" {
+		export(c, format, exportName("customers"), customerExportColumns, func(write func([]entity.Customer) error) error {
+			return eachPage(params, func(page entity.ListParams) (string, error) {
+				response, err := h.customerUsecase.List(c.Request.Context(), page, search)
+				if err != nil {
+					return "", err
+				}
+				return response.NextCursor, write(response.Customers)
+			})
 		})
 		return
 	}
 
+	response, err := h.customerUsecase.List(c.Request.Context(), params, search)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    response,
@@ -95,31 +113,20 @@ func (h *CustomerHandler) List(c *gin.Context) {
 }
 
 func (h *CustomerHandler) Update(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid customer ID",
-			"message": "Customer ID must be a number",
-		})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
 	var req entity.UpdateCustomerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
-	customer, err := h.customerUsecase.Update(id, &req)
+	customer, err := h.customerUsecase.Update(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to update customer",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -130,21 +137,13 @@ func (h *CustomerHandler) Update(c *gin.Context) {
 }
 
 func (h *CustomerHandler) Delete(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid customer ID",
-			"message": "Customer ID must be a number",
-		})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
-	if err := h.customerUsecase.Delete(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to delete customer",
-			"message": err.Error(),
-		})
+	if err := h.customerUsecase.Delete(c.Request.Context(), id); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -153,3 +152,112 @@ func (h *CustomerHandler) Delete(c *gin.Context) {
 		"message": "Customer deleted successfully",
 	})
 }
+
+func (h *CustomerHandler) Profile(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	profile, err := h.customerUsecase.GetProfile(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+func (h *CustomerHandler) AddNote(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req entity.CreateCustomerNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	note, err := h.customerUsecase.AddNote(c.Request.Context(), id, &req, user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    note,
+	})
+}
+
+func (h *CustomerHandler) Duplicates(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	duplicates, err := h.customerUsecase.FindDuplicates(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    duplicates,
+	})
+}
+
+// Merge folds the customer in the body into the one in the path.
+func (h *CustomerHandler) Merge(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req entity.MergeCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	merge, err := h.customerUsecase.Merge(c.Request.Context(), id, req.MergeCustomerID, user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    merge,
+	})
+}
+
+func (h *CustomerHandler) Merges(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	merges, err := h.customerUsecase.ListMerges(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    merges,
+	})
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/usecase"
+)
+
+type CustomerPrivacyHandler struct {
+	privacyUsecase usecase.CustomerPrivacyUsecase
+}
+
+func NewCustomerPrivacyHandler(privacyUsecase usecase.CustomerPrivacyUsecase) *CustomerPrivacyHandler {
+	return &CustomerPrivacyHandler{
+		privacyUsecase: privacyUsecase,
+	}
+}
+
+func (h *CustomerPrivacyHandler) Consents(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	consents, err := h.privacyUsecase.Consents(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    consents,
+	})
+}
+
+func (h *CustomerPrivacyHandler) RecordConsent(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req entity.RecordConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	consents, err := h.privacyUsecase.RecordConsent(c.Request.Context(), id, &req, user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    consents,
+	})
+}
+
+// Export sends the customer's personal data as a JSON attachment.
+func (h *CustomerPrivacyHandler) Export(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	export, err := h.privacyUsecase.Export(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-personal-data.json"`, export.Customer.CustomerCode))
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    export,
+	})
+}
+
+func (h *CustomerPrivacyHandler) Anonymize(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	result, err := h.privacyUsecase.Anonymize(c.Request.Context(), id, user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Customer anonymized successfully",
+		"data":    result,
+	})
+}
@@ -0,0 +1,255 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/usecase"
+)
+
+// Error codes are part of the API contract; clients switch on them, so
+// existing codes must never change meaning.
+const (
+	CodeValidation        = "VALIDATION_FAILED"
+	CodeUnauthorized      = "UNAUTHORIZED"
+	CodeForbidden         = "FORBIDDEN"
+	CodeNotFound          = "NOT_FOUND"
+	CodeConflict          = "CONFLICT"
+	CodeInsufficientStock = "INSUFFICIENT_STOCK"
+	CodeInvalidTransition = "INVALID_TRANSITION"
+	CodeAccountLocked     = "ACCOUNT_LOCKED"
+	CodeKYCRequired       = "KYC_REQUIRED"
+	CodeTooManyRequests   = "TOO_MANY_REQUESTS"
+	CodeTimeout           = "TIMEOUT"
+	CodeCancelled         = "REQUEST_CANCELLED"
+	CodeInternal          = "INTERNAL_ERROR"
+)
+
+// StatusClientClosedRequest is the nginx convention for a client that went
+// away before the response; nobody reads it, but it keeps these requests
+// apart from server errors in logs and metrics.
+const StatusClientClosedRequest = 499
+
+var errorMappings = []struct {
+	kind    error
+	status  int
+	code    string
+	message string // replaces the error text, which may come from the driver
+}{
+	{entity.ErrValidation, http.StatusBadRequest, CodeValidation, ""},
+	{entity.ErrUnauthorized, http.StatusUnauthorized, CodeUnauthorized, ""},
+	{entity.ErrForbidden, http.StatusForbidden, CodeForbidden, ""},
+	{entity.ErrNotFound, http.StatusNotFound, CodeNotFound, ""},
+	{entity.ErrConflict, http.StatusConflict, CodeConflict, ""},
+	{entity.ErrInsufficientStock, http.StatusConflict, CodeInsufficientStock, ""},
+	{entity.ErrInvalidTransition, http.StatusConflict, CodeInvalidTransition, ""},
+	{entity.ErrLocked, http.StatusLocked, CodeAccountLocked, ""},
+	{entity.ErrKYCRequired, http.StatusConflict, CodeKYCRequired, ""},
+	{entity.ErrTooManyRequests, http.StatusTooManyRequests, CodeTooManyRequests, ""},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, CodeTimeout, "the request took too long and was cancelled"},
+	{context.Canceled, StatusClientClosedRequest, CodeCancelled, "the request was cancelled by the client"},
+}
+
+// ErrorBody is the "error" member of every error response:
+//
+//	{"success": false, "error": {"code": "NOT_FOUND", "message": "vehicle not found", "request_id": "..."}}
+type ErrorBody struct {
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	Fields    []entity.FieldError    `json:"fields,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+}
+
+func init() {
+	// Report validation failures by JSON field name instead of Go field name
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	}
+}
+
+// ErrorHandler writes the error a handler recorded with c.Error, so handlers
+// do not pick status codes themselves. It must be registered before the
+// routes.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		writeError(c, c.Errors.Last().Err)
+	}
+}
+
+// NoRoute answers unknown paths with the standard envelope.
+func NoRoute(c *gin.Context) {
+	c.Error(entity.NewNotFoundError("route"))
+}
+
+// abortWithError records err and stops the handler chain; ErrorHandler
+// renders it.
+func abortWithError(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
+
+func writeError(c *gin.Context, err error) {
+	// A query interrupted by the request deadline or a client disconnect
+	// fails with a driver error ("canceling statement due to user request"),
+	// so classify it by why the context ended.
+	var domainErr *entity.Error
+	if ctxErr := c.Request.Context().Err(); ctxErr != nil && !errors.As(err, &domainErr) {
+		err = fmt.Errorf("%w: %v", ctxErr, err)
+	}
+
+	status, body := mapError(err)
+	body.RequestID = c.GetString("request_id")
+
+	var throttled *usecase.LoginThrottledError
+	var resetThrottled *usecase.ResetThrottledError
+	if errors.As(err, &throttled) {
+		c.Header("Retry-After", strconv.Itoa(int(throttled.RetryAfter.Seconds())+1))
+	} else if errors.As(err, &resetThrottled) {
+		c.Header("Retry-After", strconv.Itoa(int(resetThrottled.RetryAfter.Seconds())+1))
+	}
+
+	c.AbortWithStatusJSON(status, gin.H{
+		"success": false,
+		"error":   body,
+	})
+}
+
+func mapError(err error) (int, ErrorBody) {
+	for _, m := range errorMappings {
+		if !errors.Is(err, m.kind) {
+			continue
+		}
+
+		body := ErrorBody{Code: m.code, Message: err.Error()}
+		if m.message != "" {
+			body.Message = m.message
+		}
+		var domainErr *entity.Error
+		if errors.As(err, &domainErr) {
+			body.Message = domainErr.Message
+			body.Fields = domainErr.Fields
+			body.Details = domainErr.Details
+		}
+		return m.status, body
+	}
+
+	// Anything else is a bug or an infrastructure failure. The request log
+	// has the full error; the client only gets a generic message.
+	return http.StatusInternalServerError, ErrorBody{
+		Code:    CodeInternal,
+		Message: "an unexpected error occurred",
+	}
+}
+
+// bindingError turns a request binding failure into a validation error that
+// lists the offending fields.
+func bindingError(err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]entity.FieldError, 0, len(validationErrs))
+		messages := make([]string, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			field := entity.FieldError{Field: fe.Field(), Message: validationMessage(fe)}
+			fields = append(fields, field)
+			messages = append(messages, field.Field+" "+field.Message)
+		}
+		return entity.NewValidationError(strings.Join(messages, "; "), fields...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return entity.NewFieldError(typeErr.Field, "must be "+jsonTypeName(typeErr.Type))
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &entity.Error{Kind: entity.ErrValidation, Message: "request body is not valid JSON"}
+	}
+
+	return entity.NewValidationError(err.Error())
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	case reflect.String:
+		return "a string"
+	default:
+		return "an object"
+	}
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "len":
+		return "must have length " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte":
+		return "must be at least " + fe.Param()
+	case "lt":
+		return "must be less than " + fe.Param()
+	case "lte":
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	default:
+		return fmt.Sprintf("failed the %q rule", fe.Tag())
+	}
+}
+
+// parseID reads a numeric path parameter, recording a validation error when
+// it is not a number.
+func parseID(c *gin.Context, param string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(param))
+	if err != nil {
+		c.Error(entity.NewFieldError(param, "must be a number"))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,208 @@
+package http
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/spreadsheet"
+)
+
+// exportPageSize is the largest page the list usecases hand out; exports of
+// lists walk through all of them.
+const exportPageSize = 100
+
+// label is a column title in each language exports are offered in.
+type label struct {
+	en, id string
+}
+
+func (l label) in(lang string) string {
+	if lang == "id" {
+		return l.id
+	}
+	return l.en
+}
+
+// exportColumn is a column of an exported file and how to read it from a
+// row.
+type exportColumn[T any] struct {
+	title label
+	kind  spreadsheet.Kind
+	value func(*T) interface{}
+}
+
+// exportFormat reads format=csv|xlsx. An empty format (or json) means the
+// usual JSON response. On a bad value it records the error and returns
+// false.
+func exportFormat(c *gin.Context) (spreadsheet.Format, bool) {
+	switch format := strings.ToLower(c.Query("format")); format {
+	case "", "json":
+		return "", true
+	case string(spreadsheet.CSV), string(spreadsheet.XLSX):
+		return spreadsheet.Format(format), true
+	default:
+		c.Error(entity.NewFieldError("format", "must be one of: json, csv, xlsx"))
+		return "", false
+	}
+}
+
+// isExport tells Timeout to give the request the export budget.
+func isExport(c *gin.Context) bool {
+	format := strings.ToLower(c.Query("format"))
+	return format == string(spreadsheet.CSV) || format == string(spreadsheet.XLSX)
+}
+
+// exportLanguage picks the language of column titles from lang, else from
+// Accept-Language: Indonesian when asked for, English otherwise.
+func exportLanguage(c *gin.Context) string {
+	lang := c.Query("lang")
+	if lang == "" {
+		lang = c.GetHeader("Accept-Language")
+	}
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(lang)), "id") {
+		return "id"
+	}
+	return "en"
+}
+
+// export streams the rows each produces into a file download named
+// name.<format>. each calls write with batches of rows as it reads them, so
+// nothing but the current batch is held in memory.
+//
+// The response starts with the first batch. An error before that is
+// answered as usual; after it, the status is already sent, so the
+// connection is cut instead, which clients see as an incomplete download
+// rather than a short but well-formed file.
+func export[T any](c *gin.Context, format spreadsheet.Format, name string, columns []exportColumn[T], each func(write func([]T) error) error) {
+	lang := exportLanguage(c)
+	var writer spreadsheet.Writer
+	start := func() error {
+		titles := make([]spreadsheet.Column, len(columns))
+		for i, column := range columns {
+			titles[i] = spreadsheet.Column{Title: column.title.in(lang), Kind: column.kind}
+		}
+
+		// The server's write timeout would cut off a long download; the
+		// request deadline bounds it instead
+		deadline, _ := c.Request.Context().Deadline()
+		http.NewResponseController(c.Writer).SetWriteDeadline(deadline)
+
+		c.Header("Content-Type", format.ContentType())
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+		c.Status(http.StatusOK)
+
+		var err error
+		writer, err = spreadsheet.NewWriter(c.Writer, format, titles, spreadsheet.Options{DecimalComma: lang == "id"})
+		return err
+	}
+
+	values := make([]interface{}, len(columns))
+	err := each(func(rows []T) error {
+		if writer == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for i := range rows {
+			for j, column := range columns {
+				values[j] = column.value(&rows[i])
+			}
+			if err := writer.Write(values); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil && writer == nil {
+		// No rows: still a file with the header row
+		err = start()
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Disposition")
+		c.Error(err)
+		return
+	}
+	slog.ErrorContext(c.Request.Context(), "export failed after the response started",
+		slog.String("path", c.Request.URL.Path),
+		slog.String("error", err.Error()),
+	)
+	panic(http.ErrAbortHandler)
+}
+
+// eachPage walks a list from the top with keyset paging, passing each page
+// to list, which returns the cursor of the next one. Keyset pages are not
+// thrown off by rows added during the export, and the caller's filters and
+// sort carry over.
+func eachPage(params entity.ListParams, list func(entity.ListParams) (string, error)) error {
+	params.Keyset = true
+	params.Cursor = ""
+	params.Limit = exportPageSize
+	params.WithTotal = false
+	for {
+		next, err := list(params)
+		if err != nil || next == "" {
+			return err
+		}
+		params.Cursor = next
+	}
+}
+
+// exportName is name followed by today's date, e.g. vehicles-2024-05-01.
+func exportName(name string) string {
+	return name + "-" + time.Now().Format("2006-01-02")
+}
+
+// Related records may be missing from a row, e.g. a deleted cashier; their
+// cells are then left empty.
+
+func customerCode(customer *entity.Customer) interface{} {
+	if customer == nil {
+		return nil
+	}
+	return customer.CustomerCode
+}
+
+func customerName(customer *entity.Customer) interface{} {
+	if customer == nil {
+		return nil
+	}
+	return customer.Name
+}
+
+func vehicleCode(vehicle *entity.Vehicle) interface{} {
+	if vehicle == nil {
+		return nil
+	}
+	return vehicle.VehicleCode
+}
+
+// vehicleTitle is e.g. "Toyota Avanza G 2019".
+func vehicleTitle(vehicle *entity.Vehicle) interface{} {
+	if vehicle == nil {
+		return nil
+	}
+	parts := []string{vehicle.Brand, vehicle.Model}
+	if vehicle.Variant != nil && *vehicle.Variant != "" {
+		parts = append(parts, *vehicle.Variant)
+	}
+	return fmt.Sprintf("%s %d", strings.Join(parts, " "), vehicle.Year)
+}
+
+func userName(user *entity.User) interface{} {
+	if user == nil {
+		return nil
+	}
+	return user.FullName
+}
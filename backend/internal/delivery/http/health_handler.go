@@ -0,0 +1,38 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/usecase"
+)
+
+type HealthHandler struct {
+	healthUsecase usecase.HealthUsecase
+}
+
+func NewHealthHandler(healthUsecase usecase.HealthUsecase) *HealthHandler {
+	return &HealthHandler{
+		healthUsecase: healthUsecase,
+	}
+}
+
+// Live reports that the process is up and serving HTTP. It deliberately does
+// not touch the database, so a database outage does not get the server
+// restarted.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": entity.HealthStatusOK})
+}
+
+// Ready reports whether the server should receive traffic.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	status := h.healthUsecase.Ready(c.Request.Context())
+
+	code := http.StatusOK
+	if status.Status != entity.HealthStatusOK {
+		code = http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, status)
+}
@@ -0,0 +1,218 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/spreadsheet"
+)
+
+// maxImportSize bounds an uploaded import file.
+const maxImportSize = 10 << 20
+
+// readImport reads the CSV or XLSX file uploaded as the multipart field
+// "file" and decodes each row into a T. Columns are named after T's JSON
+// fields and every row is checked with T's binding rules, exactly as the
+// JSON endpoint for a single T would. Problems with the file as a whole are
+// recorded as an error and reported by returning false; problems with a row
+// are left in its Errors.
+func readImport[T any](c *gin.Context) ([]entity.ImportRow[T], bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.Error(entity.NewFieldError("file", "must be at most 10 MB"))
+		} else {
+			c.Error(entity.NewFieldError("file", "is required; upload it as multipart/form-data"))
+		}
+		return nil, false
+	}
+	if header.Size > maxImportSize {
+		c.Error(entity.NewFieldError("file", "must be at most 10 MB"))
+		return nil, false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.Error(fmt.Errorf("failed to open upload: %w", err))
+		return nil, false
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.Error(fmt.Errorf("failed to read upload: %w", err))
+		return nil, false
+	}
+
+	table, err := spreadsheet.Read(header.Filename, data)
+	if err != nil {
+		c.Error(entity.NewValidationError(err.Error(), entity.FieldError{Field: "file", Message: err.Error()}))
+		return nil, false
+	}
+
+	columns, err := importColumns(reflect.TypeOf((*T)(nil)).Elem(), table.Header)
+	if err != nil {
+		c.Error(err)
+		return nil, false
+	}
+
+	rows := make([]entity.ImportRow[T], len(table.Rows))
+	for i, row := range table.Rows {
+		rows[i].Row = row.Number
+		rows[i].Errors = decodeImportRow(columns, row, &rows[i].Data)
+	}
+	return rows, true
+}
+
+// importStatus is 201 once rows were written and 200 for a dry run.
+func importStatus(dryRun bool) int {
+	if dryRun {
+		return http.StatusOK
+	}
+	return http.StatusCreated
+}
+
+// importColumn maps a column of the file to a field of the row type.
+type importColumn struct {
+	name  string
+	index int // field index in the struct
+}
+
+// importColumns matches the header to the JSON fields of t. Unknown
+// columns are refused rather than ignored, since a misspelt header would
+// otherwise silently drop its data, and so are missing required ones.
+func importColumns(t reflect.Type, header []string) ([]importColumn, error) {
+	fields := map[string]int{}
+	var names, required []string
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.SplitN(t.Field(i).Tag.Get("json"), ",", 2)[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = i
+		names = append(names, name)
+		if strings.Contains(","+t.Field(i).Tag.Get("binding")+",", ",required,") {
+			required = append(required, name)
+		}
+	}
+
+	var problems []entity.FieldError
+	seen := map[string]bool{}
+	columns := make([]importColumn, len(header))
+	for i, name := range header {
+		index, ok := fields[name]
+		switch {
+		case name == "":
+			columns[i] = importColumn{index: -1}
+			continue
+		case !ok:
+			problems = append(problems, entity.FieldError{Field: name, Message: "is not a known column"})
+		case seen[name]:
+			problems = append(problems, entity.FieldError{Field: name, Message: "appears more than once"})
+		}
+		seen[name] = true
+		columns[i] = importColumn{name: name, index: index}
+	}
+	for _, name := range required {
+		if !seen[name] {
+			problems = append(problems, entity.FieldError{Field: name, Message: "is a required column"})
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, entity.NewValidationError("the header row does not match; columns are: "+strings.Join(names, ", "), problems...)
+	}
+	return columns, nil
+}
+
+// decodeImportRow fills dst from the row and validates it. Empty cells
+// leave their field unset.
+func decodeImportRow(columns []importColumn, row spreadsheet.Row, dst interface{}) []entity.FieldError {
+	v := reflect.ValueOf(dst).Elem()
+
+	var problems []entity.FieldError
+	bad := map[string]bool{}
+	for i, column := range columns {
+		value := row.Cell(i)
+		if column.index < 0 || value == "" {
+			continue
+		}
+		field := v.Field(column.index)
+		if err := setCell(field, value); err != nil {
+			problems = append(problems, entity.FieldError{Field: column.name, Message: "must be " + jsonTypeName(elemType(field.Type()))})
+			bad[column.name] = true
+		}
+	}
+
+	if err := binding.Validator.ValidateStruct(dst); err != nil {
+		var domainErr *entity.Error
+		if errors.As(bindingError(err), &domainErr) {
+			for _, fe := range domainErr.Fields {
+				// A cell that did not parse was already reported
+				if !bad[fe.Field] {
+					problems = append(problems, fe)
+				}
+			}
+		}
+	}
+	return problems
+}
+
+func elemType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// setCell parses value into field, allocating pointer fields.
+func setCell(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Ptr {
+		target := reflect.New(field.Type().Elem())
+		if err := setCell(target.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(target)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			// Spreadsheets may store whole numbers as 2019.0
+			f, ferr := strconv.ParseFloat(value, 64)
+			if ferr != nil || f != float64(int64(f)) {
+				return err
+			}
+			n = int64(f)
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
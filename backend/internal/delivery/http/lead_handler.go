@@ -0,0 +1,258 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/usecase"
+)
+
+type LeadHandler struct {
+	leadUsecase usecase.LeadUsecase
+}
+
+func NewLeadHandler(leadUsecase usecase.LeadUsecase) *LeadHandler {
+	return &LeadHandler{
+		leadUsecase: leadUsecase,
+	}
+}
+
+func (h *LeadHandler) Create(c *gin.Context) {
+	var req entity.CreateLeadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	lead, err := h.leadUsecase.Create(c.Request.Context(), &req, user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Lead created successfully",
+		"data":    lead,
+	})
+}
+
+func (h *LeadHandler) GetByID(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	lead, err := h.leadUsecase.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    lead,
+	})
+}
+
+func (h *LeadHandler) List(c *gin.Context) {
+	params, ok := listParams(c)
+	if !ok {
+		return
+	}
+
+	q := &queryParser{c: c}
+	filter := entity.LeadFilter{
+		Search:     c.Query("search"),
+		Status:     c.Query("status"),
+		AssignedTo: q.int("assigned_to"),
+		VehicleID:  q.int("vehicle_id"),
+		Open:       q.bool("open", false),
+	}
+	if err := q.err(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response, err := h.leadUsecase.List(c.Request.Context(), params, filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+func (h *LeadHandler) Update(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req entity.UpdateLeadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	lead, err := h.leadUsecase.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Lead updated successfully",
+		"data":    lead,
+	})
+}
+
+func (h *LeadHandler) UpdateStatus(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req entity.UpdateLeadStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	lead, err := h.leadUsecase.UpdateStatus(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    lead,
+	})
+}
+
+// Convert answers with the customer and a prefilled sales request; the
+// sale is not created.
+func (h *LeadHandler) Convert(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req entity.ConvertLeadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	conversion, err := h.leadUsecase.Convert(c.Request.Context(), id, &req, user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Lead converted successfully",
+		"data":    conversion,
+	})
+}
+
+func (h *LeadHandler) ScheduleTestDrive(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req entity.ScheduleTestDriveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	drive, err := h.leadUsecase.ScheduleTestDrive(c.Request.Context(), id, &req, user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Test drive scheduled successfully",
+		"data":    drive,
+	})
+}
+
+func (h *LeadHandler) UpdateTestDriveStatus(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	driveID, ok := parseID(c, "testDriveId")
+	if !ok {
+		return
+	}
+
+	var req entity.UpdateTestDriveStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	drive, err := h.leadUsecase.UpdateTestDriveStatus(c.Request.Context(), id, driveID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    drive,
+	})
+}
+
+// VehicleTestDrives lists the vehicle's scheduled test drives between from
+// (default now) and to (default open-ended).
+func (h *LeadHandler) VehicleTestDrives(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	q := &queryParser{c: c}
+	from, to := q.time("from"), q.time("to")
+	if err := q.err(); err != nil {
+		c.Error(err)
+		return
+	}
+	if from == nil {
+		now := time.Now()
+		from = &now
+	}
+
+	drives, err := h.leadUsecase.VehicleTestDrives(c.Request.Context(), id, *from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    drives,
+	})
+}
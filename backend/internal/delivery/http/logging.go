@@ -0,0 +1,170 @@
+package http
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/logger"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// Incoming request IDs are echoed into logs and headers, so only accept
+// short, plain tokens from upstream proxies.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// Error bodies are logged so failures can be traced without the client's
+// help; cap how much of a response is kept.
+const maxLoggedErrorBody = 4096
+
+// RequestID reuses the caller's X-Request-ID or generates one, returns it
+// in the response and stores it in the request context for logging.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+	}
+}
+
+// RequestLogger writes one structured line per request with the route,
+// status, latency and user. Error responses include their error message.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		writer := &errorCapture{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		status := c.Writer.Status()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if status >= http.StatusBadRequest {
+			attrs = append(attrs, errorAttrs(writer.body.Bytes())...)
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		case route == "/healthz" || route == "/readyz":
+			// Probes hit these every few seconds
+			level = slog.LevelDebug
+		}
+
+		// AuthMiddleware replaces c.Request, so its context now also carries
+		// the user ID
+		slog.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery turns panics into a 500 response and logs them with the stack
+// and request ID instead of Gin's plain-text dump. http.ErrAbortHandler is
+// passed on to net/http, which drops the connection without logging.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered interface{}) {
+		if recovered == http.ErrAbortHandler {
+			panic(recovered)
+		}
+		slog.ErrorContext(c.Request.Context(), "panic recovered",
+			slog.Any("panic", recovered),
+			slog.String("stack", string(debug.Stack())),
+		)
+		writeError(c, fmt.Errorf("panic: %v", recovered))
+	})
+}
+
+// errorCapture keeps a copy of error response bodies for the request log.
+type errorCapture struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *errorCapture) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *errorCapture) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to extend
+// the write deadline of an export.
+func (w *errorCapture) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *errorCapture) capture(b []byte) {
+	if w.Status() < http.StatusBadRequest {
+		return
+	}
+	if room := maxLoggedErrorBody - w.body.Len(); room > 0 {
+		if len(b) > room {
+			b = b[:room]
+		}
+		w.body.Write(b)
+	}
+}
+
+func errorAttrs(body []byte) []slog.Attr {
+	var payload struct {
+		Error ErrorBody `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil
+	}
+
+	var attrs []slog.Attr
+	if payload.Error.Code != "" {
+		attrs = append(attrs, slog.String("error_code", payload.Error.Code))
+	}
+	if payload.Error.Message != "" {
+		attrs = append(attrs, slog.String("error_message", payload.Error.Message))
+	}
+	return attrs
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
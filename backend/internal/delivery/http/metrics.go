@@ -0,0 +1,49 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/metrics"
+)
+
+// Metrics records the request count and latency of every request by route
+// template (not raw path, to keep the number of series bounded).
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		metrics.HTTPRequests.Inc(c.Request.Method, route, status)
+		metrics.HTTPRequestDuration.Observe(time.Since(start).Seconds(), c.Request.Method, route, status)
+	}
+}
+
+// MetricsHandler serves the metrics in the Prometheus text format. When a
+// token is configured, scrapers must send it as a bearer token.
+func MetricsHandler(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token != "" {
+			expected := "Bearer " + token
+			if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte(expected)) != 1 {
+				writeError(c, entity.NewUnauthorizedError("invalid metrics token"))
+				return
+			}
+		}
+
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Status(http.StatusOK)
+		metrics.Write(c.Writer)
+	}
+}
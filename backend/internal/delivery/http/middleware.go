@@ -1,11 +1,11 @@
 package http
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/logger"
 	"vehicle-showroom/internal/usecase"
 )
 
@@ -13,52 +13,84 @@ func AuthMiddleware(authUsecase usecase.AuthUsecase) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+			abortWithError(c, entity.NewUnauthorizedError("authorization header is required"))
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+			abortWithError(c, entity.NewUnauthorizedError("invalid authorization header format"))
 			return
 		}
 
 		tokenString := parts[1]
-		user, err := authUsecase.ValidateToken(tokenString)
+		user, session, err := authUsecase.ValidateToken(c.Request.Context(), tokenString)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token", "message": err.Error()})
+			abortWithError(c, err)
 			return
 		}
 
 		c.Set("user", user)
+		c.Set("session", session)
+		c.Request = c.Request.WithContext(logger.WithUserID(c.Request.Context(), user.ID))
 		c.Next()
 	}
 }
 
-func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
+// QueryToken lets clients that cannot set headers, such as browser
+// EventSource, pass the access token as ?access_token= instead; it runs
+// before AuthMiddleware. The request log records the path without the
+// query, so the token does not end up in it.
+func QueryToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := c.Query("access_token"); token != "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission allows the request through only if the authenticated
+// user's role holds every listed permission.
+func RequirePermission(roleUsecase usecase.RoleUsecase, permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userValue, exists := c.Get("user")
 		if !exists {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "User not found in context"})
+			abortWithError(c, entity.NewForbiddenError("user not found in context"))
 			return
 		}
 
 		user, ok := userValue.(*entity.User)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Invalid user type in context"})
+			abortWithError(c, entity.NewForbiddenError("invalid user type in context"))
 			return
 		}
 
-		isAllowed := false
-		for _, role := range allowedRoles {
-			if user.Role == role {
-				isAllowed = true
-				break
+		for _, permission := range permissions {
+			allowed, err := roleUsecase.HasPermission(c.Request.Context(), user.Role, permission)
+			if err != nil {
+				abortWithError(c, err)
+				return
+			}
+
+			if !allowed {
+				abortWithError(c, entity.NewForbiddenError("missing permission: "+permission))
+				return
 			}
 		}
 
-		if !isAllowed {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this resource"})
+		c.Next()
+	}
+}
+
+// RequireTwoFactorEnrollment blocks users that the 2FA policy applies to until
+// they have enabled it. The enrollment endpoints must not sit behind it.
+func RequireTwoFactorEnrollment(authUsecase usecase.AuthUsecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userValue, _ := c.Get("user")
+		user, ok := userValue.(*entity.User)
+		if ok && authUsecase.TwoFactorSetupRequired(user) {
+			abortWithError(c, entity.NewForbiddenError("enable two-factor authentication at /auth/2fa/setup to continue"))
 			return
 		}
 
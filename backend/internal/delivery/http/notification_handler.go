@@ -0,0 +1,141 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/usecase"
+)
+
+// streamHeartbeat is how often an idle event stream sends a comment, so
+// proxies do not time the connection out and dead clients are noticed.
+const streamHeartbeat = 25 * time.Second
+
+// streamRetry is how long EventSource clients wait before reconnecting.
+const streamRetry = 3 * time.Second
+
+type NotificationHandler struct {
+	notificationUsecase usecase.NotificationUsecase
+	streamMaxAge        time.Duration
+}
+
+// NewNotificationHandler ends each event stream after streamMaxAge, the
+// access token lifetime, so a revoked session stops receiving events; the
+// client reconnects with a fresh token.
+func NewNotificationHandler(notificationUsecase usecase.NotificationUsecase, streamMaxAge time.Duration) *NotificationHandler {
+	return &NotificationHandler{
+		notificationUsecase: notificationUsecase,
+		streamMaxAge:        streamMaxAge,
+	}
+}
+
+func (h *NotificationHandler) List(c *gin.Context) {
+	params, ok := listParams(c)
+	if !ok {
+		return
+	}
+
+	q := &queryParser{c: c}
+	unreadOnly := q.bool("unread", false)
+	if err := q.err(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	response, err := h.notificationUsecase.List(c.Request.Context(), user.ID, params, unreadOnly)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	notification, err := h.notificationUsecase.MarkRead(c.Request.Context(), user.ID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    notification,
+	})
+}
+
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	response, err := h.notificationUsecase.MarkAllRead(c.Request.Context(), user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// Stream sends the caller's events as Server-Sent Events: each has the
+// event type as its name and the JSON data. It runs until the client goes
+// away, the server shuts down, the client falls too far behind or
+// streamMaxAge passes.
+func (h *NotificationHandler) Stream(c *gin.Context) {
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	sub := h.notificationUsecase.Subscribe(user)
+	defer sub.Close()
+
+	// The server's write timeout would cut the stream off
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry.Milliseconds())
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	expired := time.After(h.streamMaxAge)
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-expired:
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event.Type, event.Data)
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
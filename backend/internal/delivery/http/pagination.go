@@ -0,0 +1,36 @@
+package http
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/entity"
+)
+
+// listParams reads the paging query parameters shared by the list
+// endpoints: page and limit, sort (e.g. "-year,price"), cursor and total.
+// Passing cursor, even empty for the first page, switches to keyset paging,
+// where page is ignored and the total is left out unless total=true.
+// On a bad parameter it records the error and returns false.
+func listParams(c *gin.Context) (entity.ListParams, bool) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	params := entity.ListParams{Page: page, Limit: limit}
+
+	params.Cursor, params.Keyset = c.GetQuery("cursor")
+	q := &queryParser{c: c}
+	params.WithTotal = q.bool("total", !params.Keyset)
+	if err := q.err(); err != nil {
+		c.Error(err)
+		return params, false
+	}
+
+	sort, err := entity.ParseSort(c.Query("sort"))
+	if err != nil {
+		c.Error(err)
+		return params, false
+	}
+	params.Sort = sort
+
+	return params, true
+}
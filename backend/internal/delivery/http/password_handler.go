@@ -0,0 +1,78 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/usecase"
+)
+
+type PasswordHandler struct {
+	passwordUsecase usecase.PasswordUsecase
+}
+
+func NewPasswordHandler(passwordUsecase usecase.PasswordUsecase) *PasswordHandler {
+	return &PasswordHandler{
+		passwordUsecase: passwordUsecase,
+	}
+}
+
+func (h *PasswordHandler) ChangePassword(c *gin.Context) {
+	var req entity.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+	sessionValue, _ := c.Get("session")
+	session := sessionValue.(*entity.UserSession)
+
+	if err := h.passwordUsecase.ChangePassword(c.Request.Context(), user, session.ID, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Password changed successfully, other sessions have been signed out",
+	})
+}
+
+func (h *PasswordHandler) ForgotPassword(c *gin.Context) {
+	var req entity.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	if err := h.passwordUsecase.RequestReset(c.Request.Context(), req.Email, c.ClientIP()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "If the email is registered, a reset link has been sent",
+	})
+}
+
+func (h *PasswordHandler) ResetPassword(c *gin.Context) {
+	var req entity.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	if err := h.passwordUsecase.ResetPassword(c.Request.Context(), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Password has been reset, please log in again",
+	})
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/entity"
+)
+
+// queryParser reads optional typed query parameters. Malformed values are
+// collected so that err reports all of them at once.
+type queryParser struct {
+	c      *gin.Context
+	fields []entity.FieldError
+}
+
+func (q *queryParser) invalid(name, message string) {
+	q.fields = append(q.fields, entity.FieldError{Field: name, Message: message})
+}
+
+// int returns nil when the parameter is absent or empty.
+func (q *queryParser) int(name string) *int {
+	value := q.c.Query(name)
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		q.invalid(name, "must be a whole number")
+		return nil
+	}
+	return &n
+}
+
+func (q *queryParser) float(name string) *float64 {
+	value := q.c.Query(name)
+	if value == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		q.invalid(name, "must be a number")
+		return nil
+	}
+	return &f
+}
+
+// time accepts RFC 3339 timestamps and plain dates, which mean midnight UTC.
+func (q *queryParser) time(name string) *time.Time {
+	value := q.c.Query(name)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	q.invalid(name, "must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+	return nil
+}
+
+// bool returns fallback when the parameter is absent.
+func (q *queryParser) bool(name string, fallback bool) bool {
+	value, ok := q.c.GetQuery(name)
+	if !ok {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		q.invalid(name, "must be true or false")
+		return fallback
+	}
+	return b
+}
+
+// list accepts the parameter repeated (brand=a&brand=b), comma-separated
+// (brand=a,b) or both.
+func (q *queryParser) list(name string) []string {
+	var values []string
+	for _, raw := range q.c.QueryArray(name) {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func (q *queryParser) err() error {
+	if len(q.fields) == 0 {
+		return nil
+	}
+	return entity.NewValidationError("invalid query parameters", q.fields...)
+}
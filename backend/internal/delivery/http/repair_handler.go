@@ -2,10 +2,10 @@ package http
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/spreadsheet"
 	"vehicle-showroom/internal/usecase"
 )
 
@@ -19,25 +19,34 @@ func NewRepairHandler(repairUsecase usecase.RepairUsecase) *RepairHandler {
 	}
 }
 
+var repairExportColumns = []exportColumn[entity.Repair]{
+	{label{"Repair Number", "Nomor Perbaikan"}, spreadsheet.Text, func(r *entity.Repair) interface{} { return r.RepairNumber }},
+	{label{"Vehicle Code", "Kode Kendaraan"}, spreadsheet.Text, func(r *entity.Repair) interface{} { return vehicleCode(r.Vehicle) }},
+	{label{"Vehicle", "Kendaraan"}, spreadsheet.Text, func(r *entity.Repair) interface{} { return vehicleTitle(r.Vehicle) }},
+	{label{"Title", "Judul"}, spreadsheet.Text, func(r *entity.Repair) interface{} { return r.Title }},
+	{label{"Status", "Status"}, spreadsheet.Text, func(r *entity.Repair) interface{} { return r.Status }},
+	{label{"Mechanic", "Mekanik"}, spreadsheet.Text, func(r *entity.Repair) interface{} { return userName(r.Mechanic) }},
+	{label{"Labor Cost", "Biaya Jasa"}, spreadsheet.Money, func(r *entity.Repair) interface{} { return r.LaborCost }},
+	{label{"Parts Cost", "Biaya Suku Cadang"}, spreadsheet.Money, func(r *entity.Repair) interface{} { return r.TotalPartsCost }},
+	{label{"Total Cost", "Total Biaya"}, spreadsheet.Money, func(r *entity.Repair) interface{} { return r.TotalCost }},
+	{label{"Started At", "Mulai"}, spreadsheet.DateTime, func(r *entity.Repair) interface{} { return r.StartedAt }},
+	{label{"Completed At", "Selesai"}, spreadsheet.DateTime, func(r *entity.Repair) interface{} { return r.CompletedAt }},
+	{label{"Created At", "Tanggal Dibuat"}, spreadsheet.DateTime, func(r *entity.Repair) interface{} { return r.CreatedAt }},
+}
+
 func (h *RepairHandler) Create(c *gin.Context) {
 	var req entity.CreateRepairRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
 	userValue, _ := c.Get("user")
 	user := userValue.(*entity.User)
 
-	repair, err := h.repairUsecase.Create(&req, user.ID)
+	repair, err := h.repairUsecase.Create(c.Request.Context(), &req, user.ID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to create repair",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -48,22 +57,14 @@ func (h *RepairHandler) Create(c *gin.Context) {
 }
 
 func (h *RepairHandler) GetByID(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid repair ID",
-			"message": "Repair ID must be a number",
-		})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
-	repair, err := h.repairUsecase.GetByID(id)
+	repair, err := h.repairUsecase.GetByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Repair not found",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -74,20 +75,37 @@ func (h *RepairHandler) GetByID(c *gin.Context) {
 }
 
 func (h *RepairHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	params, ok := listParams(c)
+	if !ok {
+		return
+	}
 	search := c.Query("search")
 	status := c.Query("status")
 
-	response, err := h.repairUsecase.List(page, limit, search, status)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to list repairs",
-			"message": err.Error(),
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	if format != "" {
+		export(c, format, exportName("repairs"), repairExportColumns, func(write func([]entity.Repair) error) error {
+			return eachPage(params, func(page entity.ListParams) (string, error) {
+				response, err := h.repairUsecase.List(c.Request.Context(), page, search, status)
+				if err != nil {
+					return "", err
+				}
+				return response.NextCursor, write(response.Repairs)
+			})
 		})
 		return
 	}
 
+	response, err := h.repairUsecase.List(c.Request.Context(), params, search, status)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    response,
@@ -95,31 +113,20 @@ func (h *RepairHandler) List(c *gin.Context) {
 }
 
 func (h *RepairHandler) Update(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid repair ID",
-			"message": "Repair ID must be a number",
-		})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
 	var req entity.UpdateRepairRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
-	repair, err := h.repairUsecase.Update(id, &req)
+	repair, err := h.repairUsecase.Update(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to update repair",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -130,31 +137,20 @@ func (h *RepairHandler) Update(c *gin.Context) {
 }
 
 func (h *RepairHandler) UpdateStatus(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid repair ID",
-			"message": "Repair ID must be a number",
-		})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
 	var req entity.UpdateRepairStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
-	repair, err := h.repairUsecase.UpdateStatus(id, req.Status)
+	repair, err := h.repairUsecase.UpdateStatus(c.Request.Context(), id, req.Status)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to update repair status",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -165,34 +161,23 @@ func (h *RepairHandler) UpdateStatus(c *gin.Context) {
 }
 
 func (h *RepairHandler) AddPart(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid repair ID",
-			"message": "Repair ID must be a number",
-		})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
 	var req entity.AddPartToRepairRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
 	userValue, _ := c.Get("user")
 	user := userValue.(*entity.User)
 
-	repairPart, err := h.repairUsecase.AddPart(id, &req, user.ID)
+	repairPart, err := h.repairUsecase.AddPart(c.Request.Context(), id, &req, user.ID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to add part to repair",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -203,31 +188,18 @@ func (h *RepairHandler) AddPart(c *gin.Context) {
 }
 
 func (h *RepairHandler) RemovePart(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid repair ID",
-			"message": "Repair ID must be a number",
-		})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
-	partIdStr := c.Param("partId")
-	partId, err := strconv.Atoi(partIdStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid part ID",
-			"message": "Part ID must be a number",
-		})
+	partId, ok := parseID(c, "partId")
+	if !ok {
 		return
 	}
 
-	if err := h.repairUsecase.RemovePart(id, partId); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to remove part from repair",
-			"message": err.Error(),
-		})
+	if err := h.repairUsecase.RemovePart(c.Request.Context(), id, partId); err != nil {
+		c.Error(err)
 		return
 	}
 
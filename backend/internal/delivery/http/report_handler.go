@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/spreadsheet"
 	"vehicle-showroom/internal/usecase"
 )
 
@@ -18,16 +19,39 @@ func NewReportHandler(reportUsecase usecase.ReportUsecase) *ReportHandler {
 	}
 }
 
+var profitabilityExportColumns = []exportColumn[entity.VehicleProfitability]{
+	{label{"Vehicle Code", "Kode Kendaraan"}, spreadsheet.Text, func(v *entity.VehicleProfitability) interface{} { return v.VehicleCode }},
+	{label{"Brand", "Merek"}, spreadsheet.Text, func(v *entity.VehicleProfitability) interface{} { return v.Brand }},
+	{label{"Model", "Model"}, spreadsheet.Text, func(v *entity.VehicleProfitability) interface{} { return v.Model }},
+	{label{"Year", "Tahun"}, spreadsheet.Integer, func(v *entity.VehicleProfitability) interface{} { return v.Year }},
+	{label{"Purchase Price", "Harga Beli"}, spreadsheet.Money, func(v *entity.VehicleProfitability) interface{} { return v.PurchasePrice }},
+	{label{"Repair Cost", "Biaya Perbaikan"}, spreadsheet.Money, func(v *entity.VehicleProfitability) interface{} { return v.TotalRepairCost }},
+	{label{"Selling Price", "Harga Jual"}, spreadsheet.Money, func(v *entity.VehicleProfitability) interface{} { return v.FinalSellingPrice }},
+	{label{"Profit", "Laba"}, spreadsheet.Money, func(v *entity.VehicleProfitability) interface{} { return v.Profit }},
+	{label{"Sold At", "Tanggal Terjual"}, spreadsheet.DateTime, func(v *entity.VehicleProfitability) interface{} { return v.SoldAt }},
+}
+
 func (h *ReportHandler) GetVehicleProfitability(c *gin.Context) {
 	var req entity.DateRangeRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date range", "message": err.Error()})
+		c.Error(bindingError(err))
+		return
+	}
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	if format != "" {
+		export(c, format, reportName("profitability", req), profitabilityExportColumns, func(write func([]entity.VehicleProfitability) error) error {
+			return h.reportUsecase.StreamVehicleProfitabilityReport(c.Request.Context(), req.StartDate, req.EndDate, write)
+		})
 		return
 	}
 
-	report, err := h.reportUsecase.GetVehicleProfitabilityReport(req.StartDate, req.EndDate)
+	report, err := h.reportUsecase.GetVehicleProfitabilityReport(c.Request.Context(), req.StartDate, req.EndDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate report", "message": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -37,13 +61,24 @@ func (h *ReportHandler) GetVehicleProfitability(c *gin.Context) {
 func (h *ReportHandler) GetSalesReport(c *gin.Context) {
 	var req entity.DateRangeRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date range", "message": err.Error()})
+		c.Error(bindingError(err))
+		return
+	}
+	format, ok := exportFormat(c)
+	if !ok {
 		return
 	}
 
-	report, err := h.reportUsecase.GetSalesReport(req.StartDate, req.EndDate)
+	if format != "" {
+		export(c, format, reportName("sales", req), salesExportColumns, func(write func([]entity.SalesTransaction) error) error {
+			return h.reportUsecase.StreamSalesReport(c.Request.Context(), req.StartDate, req.EndDate, write)
+		})
+		return
+	}
+
+	report, err := h.reportUsecase.GetSalesReport(c.Request.Context(), req.StartDate, req.EndDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate report", "message": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -53,15 +88,31 @@ func (h *ReportHandler) GetSalesReport(c *gin.Context) {
 func (h *ReportHandler) GetPurchaseReport(c *gin.Context) {
 	var req entity.DateRangeRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date range", "message": err.Error()})
+		c.Error(bindingError(err))
+		return
+	}
+	format, ok := exportFormat(c)
+	if !ok {
 		return
 	}
 
-	report, err := h.reportUsecase.GetPurchaseReport(req.StartDate, req.EndDate)
+	if format != "" {
+		export(c, format, reportName("purchases", req), purchaseExportColumns, func(write func([]entity.PurchaseTransaction) error) error {
+			return h.reportUsecase.StreamPurchaseReport(c.Request.Context(), req.StartDate, req.EndDate, write)
+		})
+		return
+	}
+
+	report, err := h.reportUsecase.GetPurchaseReport(c.Request.Context(), req.StartDate, req.EndDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate report", "message": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"success": true, "data": report})
 }
+
+// reportName is e.g. sales-report-2024-01-01-2024-03-31.
+func reportName(report string, req entity.DateRangeRequest) string {
+	return report + "-report-" + req.StartDate + "-" + req.EndDate
+}
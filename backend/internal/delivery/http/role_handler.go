@@ -0,0 +1,123 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/usecase"
+)
+
+type RoleHandler struct {
+	roleUsecase usecase.RoleUsecase
+}
+
+func NewRoleHandler(roleUsecase usecase.RoleUsecase) *RoleHandler {
+	return &RoleHandler{
+		roleUsecase: roleUsecase,
+	}
+}
+
+func (h *RoleHandler) List(c *gin.Context) {
+	roles, err := h.roleUsecase.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    roles,
+	})
+}
+
+func (h *RoleHandler) GetByID(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	role, err := h.roleUsecase.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    role,
+	})
+}
+
+func (h *RoleHandler) Create(c *gin.Context) {
+	var req entity.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	role, err := h.roleUsecase.Create(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    role,
+	})
+}
+
+func (h *RoleHandler) Update(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req entity.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	role, err := h.roleUsecase.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    role,
+	})
+}
+
+func (h *RoleHandler) Delete(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.roleUsecase.Delete(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Role deleted successfully",
+	})
+}
+
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.roleUsecase.ListPermissions(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    permissions,
+	})
+}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/usecase"
+)
+
+type SearchHandler struct {
+	searchUsecase usecase.SearchUsecase
+}
+
+func NewSearchHandler(searchUsecase usecase.SearchUsecase) *SearchHandler {
+	return &SearchHandler{
+		searchUsecase: searchUsecase,
+	}
+}
+
+func (h *SearchHandler) Search(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	types := (&queryParser{c: c}).list("types")
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	response, err := h.searchUsecase.Search(c.Request.Context(), user.Role, c.Query("q"), types, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
@@ -2,10 +2,10 @@ package http
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/spreadsheet"
 	"vehicle-showroom/internal/usecase"
 )
 
@@ -19,22 +19,27 @@ func NewSparePartHandler(sparePartUsecase usecase.SparePartUsecase) *SparePartHa
 	}
 }
 
+var sparePartExportColumns = []exportColumn[entity.SparePart]{
+	{label{"Part Code", "Kode Suku Cadang"}, spreadsheet.Text, func(p *entity.SparePart) interface{} { return p.PartCode }},
+	{label{"Name", "Nama"}, spreadsheet.Text, func(p *entity.SparePart) interface{} { return p.Name }},
+	{label{"Brand", "Merek"}, spreadsheet.Text, func(p *entity.SparePart) interface{} { return p.Brand }},
+	{label{"Unit", "Satuan"}, spreadsheet.Text, func(p *entity.SparePart) interface{} { return p.UnitMeasure }},
+	{label{"Cost Price", "Harga Pokok"}, spreadsheet.Money, func(p *entity.SparePart) interface{} { return p.CostPrice }},
+	{label{"Selling Price", "Harga Jual"}, spreadsheet.Money, func(p *entity.SparePart) interface{} { return p.SellingPrice }},
+	{label{"Stock", "Stok"}, spreadsheet.Quantity, func(p *entity.SparePart) interface{} { return p.StockQuantity }},
+	{label{"Minimum Stock", "Stok Minimum"}, spreadsheet.Quantity, func(p *entity.SparePart) interface{} { return p.MinStockLevel }},
+}
+
 func (h *SparePartHandler) Create(c *gin.Context) {
 	var req entity.CreateSparePartRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
-	sparePart, err := h.sparePartUsecase.Create(&req)
+	sparePart, err := h.sparePartUsecase.Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to create spare part",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -44,23 +49,42 @@ func (h *SparePartHandler) Create(c *gin.Context) {
 	})
 }
 
-func (h *SparePartHandler) GetByID(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
+// Import creates spare parts from an uploaded CSV or XLSX file;
+// dry_run=true only reports what would fail.
+func (h *SparePartHandler) Import(c *gin.Context) {
+	q := &queryParser{c: c}
+	dryRun := q.bool("dry_run", false)
+	if err := q.err(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	rows, ok := readImport[entity.CreateSparePartRequest](c)
+	if !ok {
+		return
+	}
+
+	result, err := h.sparePartUsecase.Import(c.Request.Context(), rows, dryRun)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid spare part ID",
-			"message": "Spare part ID must be a number",
-		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(importStatus(dryRun), gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+func (h *SparePartHandler) GetByID(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
-	sparePart, err := h.sparePartUsecase.GetByID(id)
+	sparePart, err := h.sparePartUsecase.GetByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Spare part not found",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -71,19 +95,36 @@ func (h *SparePartHandler) GetByID(c *gin.Context) {
 }
 
 func (h *SparePartHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	params, ok := listParams(c)
+	if !ok {
+		return
+	}
 	search := c.Query("search")
 
-	response, err := h.sparePartUsecase.List(page, limit, search)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to list spare parts",
-			"message": err.Error(),
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	if format != "" {
+		export(c, format, exportName("spare-parts"), sparePartExportColumns, func(write func([]entity.SparePart) error) error {
+			return eachPage(params, func(page entity.ListParams) (string, error) {
+				response, err := h.sparePartUsecase.List(c.Request.Context(), page, search)
+				if err != nil {
+					return "", err
+				}
+				return response.NextCursor, write(response.SpareParts)
+			})
 		})
 		return
 	}
 
+	response, err := h.sparePartUsecase.List(c.Request.Context(), params, search)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    response,
@@ -91,31 +132,20 @@ func (h *SparePartHandler) List(c *gin.Context) {
 }
 
 func (h *SparePartHandler) Update(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid spare part ID",
-			"message": "Spare part ID must be a number",
-		})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
 	var req entity.UpdateSparePartRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
-	sparePart, err := h.sparePartUsecase.Update(id, &req)
+	sparePart, err := h.sparePartUsecase.Update(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to update spare part",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -126,21 +156,13 @@ func (h *SparePartHandler) Update(c *gin.Context) {
 }
 
 func (h *SparePartHandler) Delete(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid spare part ID",
-			"message": "Spare part ID must be a number",
-		})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
-	if err := h.sparePartUsecase.Delete(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to delete spare part",
-			"message": err.Error(),
-		})
+	if err := h.sparePartUsecase.Delete(c.Request.Context(), id); err != nil {
+		c.Error(err)
 		return
 	}
 
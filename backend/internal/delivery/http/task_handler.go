@@ -0,0 +1,184 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/usecase"
+)
+
+type TaskHandler struct {
+	taskUsecase usecase.TaskUsecase
+}
+
+func NewTaskHandler(taskUsecase usecase.TaskUsecase) *TaskHandler {
+	return &TaskHandler{
+		taskUsecase: taskUsecase,
+	}
+}
+
+func (h *TaskHandler) Create(c *gin.Context) {
+	var req entity.CreateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	task, err := h.taskUsecase.Create(c.Request.Context(), &req, user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Task created successfully",
+		"data":    task,
+	})
+}
+
+func (h *TaskHandler) GetByID(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	task, err := h.taskUsecase.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    task,
+	})
+}
+
+func (h *TaskHandler) List(c *gin.Context) {
+	params, ok := listParams(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := taskFilter(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.taskUsecase.List(c.Request.Context(), params, filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// Mine lists the caller's tasks, pending ones only unless status is given.
+func (h *TaskHandler) Mine(c *gin.Context) {
+	params, ok := listParams(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := taskFilter(c)
+	if !ok {
+		return
+	}
+	if filter.Status == "" && c.Query("pending") == "" {
+		filter.Pending = true
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	response, err := h.taskUsecase.ListMine(c.Request.Context(), user.ID, params, filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+func (h *TaskHandler) Update(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req entity.UpdateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	task, err := h.taskUsecase.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Task updated successfully",
+		"data":    task,
+	})
+}
+
+func (h *TaskHandler) UpdateStatus(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req entity.UpdateTaskStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	task, err := h.taskUsecase.UpdateStatus(c.Request.Context(), id, &req, user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    task,
+	})
+}
+
+// taskFilter reads the list filters shared by /tasks and /me/tasks. On a
+// bad value it records the error and returns false.
+func taskFilter(c *gin.Context) (entity.TaskFilter, bool) {
+	q := &queryParser{c: c}
+	filter := entity.TaskFilter{
+		Status:     c.Query("status"),
+		AssignedTo: q.int("assigned_to"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   q.int("entity_id"),
+		Pending:    q.bool("pending", false),
+		DueBefore:  q.time("due_before"),
+	}
+	if err := q.err(); err != nil {
+		c.Error(err)
+		return filter, false
+	}
+	return filter, true
+}
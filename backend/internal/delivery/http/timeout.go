@@ -0,0 +1,62 @@
+package http
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout puts a deadline on the request context, which the usecases pass
+// down to every query, so a slow query is cancelled on Postgres instead of
+// running on after the client gave up. The budget is picked by the longest
+// route prefix in routes that matches the route template, falling back to
+// fallback; zero means no deadline. File exports (format=csv|xlsx) walk a
+// whole listing and get the export budget on any route.
+func Timeout(fallback, export time.Duration, routes map[string]time.Duration) gin.HandlerFunc {
+	prefixes := make([]string, 0, len(routes))
+	for prefix := range routes {
+		prefixes = append(prefixes, strings.TrimSuffix(prefix, "/"))
+	}
+	// Longest first, so /api/v1/reports/sales wins over /api/v1/reports
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	budgets := make(map[string]time.Duration, len(routes))
+	for prefix, d := range routes {
+		budgets[strings.TrimSuffix(prefix, "/")] = d
+	}
+
+	return func(c *gin.Context) {
+		budget := fallback
+		route := c.FullPath()
+		for _, prefix := range prefixes {
+			if route == prefix || strings.HasPrefix(route, prefix+"/") {
+				budget = budgets[prefix]
+				break
+			}
+		}
+		if isExport(c) {
+			budget = export
+		}
+
+		if budget <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), budget)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		// ErrorHandler renders the error after this returns and classifies it
+		// by the context; unless the deadline really passed, the deferred
+		// cancel must not make a failed request look cancelled
+		if ctx.Err() == nil {
+			c.Request = c.Request.WithContext(context.WithoutCancel(c.Request.Context()))
+		}
+	}
+}
@@ -2,10 +2,10 @@ package http
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/spreadsheet"
 	"vehicle-showroom/internal/usecase"
 )
 
@@ -19,13 +19,46 @@ func NewTransactionHandler(transactionUsecase usecase.TransactionUsecase) *Trans
 	}
 }
 
+// Sales and purchases export the same columns from the list and the report.
+var salesExportColumns = []exportColumn[entity.SalesTransaction]{
+	{label{"Transaction Number", "Nomor Transaksi"}, spreadsheet.Text, func(t *entity.SalesTransaction) interface{} { return t.TransactionNumber }},
+	{label{"Invoice Number", "Nomor Faktur"}, spreadsheet.Text, func(t *entity.SalesTransaction) interface{} { return t.InvoiceNumber }},
+	{label{"Transaction Date", "Tanggal Transaksi"}, spreadsheet.DateTime, func(t *entity.SalesTransaction) interface{} { return t.TransactionDate }},
+	{label{"Customer Code", "Kode Pelanggan"}, spreadsheet.Text, func(t *entity.SalesTransaction) interface{} { return customerCode(t.Customer) }},
+	{label{"Customer", "Pelanggan"}, spreadsheet.Text, func(t *entity.SalesTransaction) interface{} { return customerName(t.Customer) }},
+	{label{"Vehicle Code", "Kode Kendaraan"}, spreadsheet.Text, func(t *entity.SalesTransaction) interface{} { return vehicleCode(t.Vehicle) }},
+	{label{"Vehicle", "Kendaraan"}, spreadsheet.Text, func(t *entity.SalesTransaction) interface{} { return vehicleTitle(t.Vehicle) }},
+	{label{"Vehicle Price", "Harga Kendaraan"}, spreadsheet.Money, func(t *entity.SalesTransaction) interface{} { return t.VehiclePrice }},
+	{label{"Tax", "Pajak"}, spreadsheet.Money, func(t *entity.SalesTransaction) interface{} { return t.TaxAmount }},
+	{label{"Discount", "Diskon"}, spreadsheet.Money, func(t *entity.SalesTransaction) interface{} { return t.DiscountAmount }},
+	{label{"Total", "Total"}, spreadsheet.Money, func(t *entity.SalesTransaction) interface{} { return t.TotalAmount }},
+	{label{"Payment Method", "Metode Pembayaran"}, spreadsheet.Text, func(t *entity.SalesTransaction) interface{} { return t.PaymentMethod }},
+	{label{"Payment Reference", "Referensi Pembayaran"}, spreadsheet.Text, func(t *entity.SalesTransaction) interface{} { return t.PaymentReference }},
+	{label{"Status", "Status"}, spreadsheet.Text, func(t *entity.SalesTransaction) interface{} { return t.Status }},
+	{label{"Cashier", "Kasir"}, spreadsheet.Text, func(t *entity.SalesTransaction) interface{} { return userName(t.Cashier) }},
+}
+
+var purchaseExportColumns = []exportColumn[entity.PurchaseTransaction]{
+	{label{"Transaction Number", "Nomor Transaksi"}, spreadsheet.Text, func(t *entity.PurchaseTransaction) interface{} { return t.TransactionNumber }},
+	{label{"Invoice Number", "Nomor Faktur"}, spreadsheet.Text, func(t *entity.PurchaseTransaction) interface{} { return t.InvoiceNumber }},
+	{label{"Transaction Date", "Tanggal Transaksi"}, spreadsheet.DateTime, func(t *entity.PurchaseTransaction) interface{} { return t.TransactionDate }},
+	{label{"Seller Code", "Kode Penjual"}, spreadsheet.Text, func(t *entity.PurchaseTransaction) interface{} { return customerCode(t.Customer) }},
+	{label{"Seller", "Penjual"}, spreadsheet.Text, func(t *entity.PurchaseTransaction) interface{} { return customerName(t.Customer) }},
+	{label{"Vehicle Code", "Kode Kendaraan"}, spreadsheet.Text, func(t *entity.PurchaseTransaction) interface{} { return vehicleCode(t.Vehicle) }},
+	{label{"Vehicle", "Kendaraan"}, spreadsheet.Text, func(t *entity.PurchaseTransaction) interface{} { return vehicleTitle(t.Vehicle) }},
+	{label{"Vehicle Price", "Harga Kendaraan"}, spreadsheet.Money, func(t *entity.PurchaseTransaction) interface{} { return t.VehiclePrice }},
+	{label{"Tax", "Pajak"}, spreadsheet.Money, func(t *entity.PurchaseTransaction) interface{} { return t.TaxAmount }},
+	{label{"Total", "Total"}, spreadsheet.Money, func(t *entity.PurchaseTransaction) interface{} { return t.TotalAmount }},
+	{label{"Payment Method", "Metode Pembayaran"}, spreadsheet.Text, func(t *entity.PurchaseTransaction) interface{} { return t.PaymentMethod }},
+	{label{"Payment Reference", "Referensi Pembayaran"}, spreadsheet.Text, func(t *entity.PurchaseTransaction) interface{} { return t.PaymentReference }},
+	{label{"Status", "Status"}, spreadsheet.Text, func(t *entity.PurchaseTransaction) interface{} { return t.Status }},
+	{label{"Cashier", "Kasir"}, spreadsheet.Text, func(t *entity.PurchaseTransaction) interface{} { return userName(t.Cashier) }},
+}
+
 func (h *TransactionHandler) CreatePurchase(c *gin.Context) {
 	var req entity.CreatePurchaseTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
@@ -33,12 +66,9 @@ func (h *TransactionHandler) CreatePurchase(c *gin.Context) {
 	user := userValue.(*entity.User)
 	cashierID := user.ID
 
-	transaction, err := h.transactionUsecase.CreatePurchase(&req, cashierID)
+	transaction, err := h.transactionUsecase.CreatePurchase(c.Request.Context(), &req, cashierID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to create purchase transaction",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -49,22 +79,14 @@ func (h *TransactionHandler) CreatePurchase(c *gin.Context) {
 }
 
 func (h *TransactionHandler) GetPurchaseByID(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid transaction ID",
-			"message": "Transaction ID must be a number",
-		})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
-	transaction, err := h.transactionUsecase.GetPurchaseByID(id)
+	transaction, err := h.transactionUsecase.GetPurchaseByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Purchase transaction not found",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -75,19 +97,35 @@ func (h *TransactionHandler) GetPurchaseByID(c *gin.Context) {
 }
 
 func (h *TransactionHandler) ListPurchases(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	params, ok := listParams(c)
+	if !ok {
+		return
+	}
 	search := c.Query("search")
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
 
-	response, err := h.transactionUsecase.ListPurchases(page, limit, search)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to list purchase transactions",
-			"message": err.Error(),
+	if format != "" {
+		export(c, format, exportName("purchases"), purchaseExportColumns, func(write func([]entity.PurchaseTransaction) error) error {
+			return eachPage(params, func(page entity.ListParams) (string, error) {
+				response, err := h.transactionUsecase.ListPurchases(c.Request.Context(), page, search)
+				if err != nil {
+					return "", err
+				}
+				return response.NextCursor, write(response.Transactions.([]entity.PurchaseTransaction))
+			})
 		})
 		return
 	}
 
+	response, err := h.transactionUsecase.ListPurchases(c.Request.Context(), params, search)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    response,
@@ -97,10 +135,7 @@ func (h *TransactionHandler) ListPurchases(c *gin.Context) {
 func (h *TransactionHandler) CreateSales(c *gin.Context) {
 	var req entity.CreateSalesTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
@@ -108,12 +143,9 @@ func (h *TransactionHandler) CreateSales(c *gin.Context) {
 	user := userValue.(*entity.User)
 	cashierID := user.ID
 
-	transaction, err := h.transactionUsecase.CreateSales(&req, cashierID)
+	transaction, err := h.transactionUsecase.CreateSales(c.Request.Context(), &req, cashierID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to create sales transaction",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -124,22 +156,14 @@ func (h *TransactionHandler) CreateSales(c *gin.Context) {
 }
 
 func (h *TransactionHandler) GetSalesByID(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid transaction ID",
-			"message": "Transaction ID must be a number",
-		})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
-	transaction, err := h.transactionUsecase.GetSalesByID(id)
+	transaction, err := h.transactionUsecase.GetSalesByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Sales transaction not found",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -150,19 +174,35 @@ func (h *TransactionHandler) GetSalesByID(c *gin.Context) {
 }
 
 func (h *TransactionHandler) ListSales(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	params, ok := listParams(c)
+	if !ok {
+		return
+	}
 	search := c.Query("search")
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
 
-	response, err := h.transactionUsecase.ListSales(page, limit, search)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to list sales transactions",
-			"message": err.Error(),
+	if format != "" {
+		export(c, format, exportName("sales"), salesExportColumns, func(write func([]entity.SalesTransaction) error) error {
+			return eachPage(params, func(page entity.ListParams) (string, error) {
+				response, err := h.transactionUsecase.ListSales(c.Request.Context(), page, search)
+				if err != nil {
+					return "", err
+				}
+				return response.NextCursor, write(response.Transactions.([]entity.SalesTransaction))
+			})
 		})
 		return
 	}
 
+	response, err := h.transactionUsecase.ListSales(c.Request.Context(), params, search)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    response,
@@ -170,12 +210,9 @@ func (h *TransactionHandler) ListSales(c *gin.Context) {
 }
 
 func (h *TransactionHandler) GetDashboardStats(c *gin.Context) {
-	stats, err := h.transactionUsecase.GetDashboardStats()
+	stats, err := h.transactionUsecase.GetDashboardStats(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get dashboard stats",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/usecase"
+)
+
+type TwoFactorHandler struct {
+	twoFactorUsecase usecase.TwoFactorUsecase
+}
+
+func NewTwoFactorHandler(twoFactorUsecase usecase.TwoFactorUsecase) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorUsecase: twoFactorUsecase,
+	}
+}
+
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	status, err := h.twoFactorUsecase.Status(c.Request.Context(), user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	setup, err := h.twoFactorUsecase.Setup(c.Request.Context(), user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    setup,
+	})
+}
+
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	var req entity.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+	sessionValue, _ := c.Get("session")
+	session := sessionValue.(*entity.UserSession)
+
+	codes, err := h.twoFactorUsecase.Enable(c.Request.Context(), user, session.ID, req.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Two-factor authentication enabled and other sessions signed out. Store the recovery codes somewhere safe, they are shown only once",
+		"data":    gin.H{"recovery_codes": codes},
+	})
+}
+
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req entity.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	if err := h.twoFactorUsecase.Disable(c.Request.Context(), user, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req entity.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	codes, err := h.twoFactorUsecase.RegenerateRecoveryCodes(c.Request.Context(), user, req.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"recovery_codes": codes},
+	})
+}
+
+func (h *TwoFactorHandler) Reset(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorUsecase.Reset(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Two-factor authentication has been removed from the user and their sessions signed out",
+	})
+}
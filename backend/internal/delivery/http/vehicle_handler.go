@@ -2,10 +2,10 @@ package http
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/spreadsheet"
 	"vehicle-showroom/internal/usecase"
 )
 
@@ -19,13 +19,33 @@ func NewVehicleHandler(vehicleUsecase usecase.VehicleUsecase) *VehicleHandler {
 	}
 }
 
+var vehicleExportColumns = []exportColumn[entity.Vehicle]{
+	{label{"Vehicle Code", "Kode Kendaraan"}, spreadsheet.Text, func(v *entity.Vehicle) interface{} { return v.VehicleCode }},
+	{label{"Chassis Number", "Nomor Rangka"}, spreadsheet.Text, func(v *entity.Vehicle) interface{} { return v.ChassisNumber }},
+	{label{"License Plate", "Nomor Polisi"}, spreadsheet.Text, func(v *entity.Vehicle) interface{} { return v.LicensePlate }},
+	{label{"Brand", "Merek"}, spreadsheet.Text, func(v *entity.Vehicle) interface{} { return v.Brand }},
+	{label{"Model", "Model"}, spreadsheet.Text, func(v *entity.Vehicle) interface{} { return v.Model }},
+	{label{"Variant", "Varian"}, spreadsheet.Text, func(v *entity.Vehicle) interface{} { return v.Variant }},
+	{label{"Year", "Tahun"}, spreadsheet.Integer, func(v *entity.Vehicle) interface{} { return v.Year }},
+	{label{"Color", "Warna"}, spreadsheet.Text, func(v *entity.Vehicle) interface{} { return v.Color }},
+	{label{"Mileage (km)", "Jarak Tempuh (km)"}, spreadsheet.Quantity, func(v *entity.Vehicle) interface{} { return v.Mileage }},
+	{label{"Fuel Type", "Bahan Bakar"}, spreadsheet.Text, func(v *entity.Vehicle) interface{} { return v.FuelType }},
+	{label{"Transmission", "Transmisi"}, spreadsheet.Text, func(v *entity.Vehicle) interface{} { return v.Transmission }},
+	{label{"Status", "Status"}, spreadsheet.Text, func(v *entity.Vehicle) interface{} { return v.Status }},
+	{label{"Purchase Price", "Harga Beli"}, spreadsheet.Money, func(v *entity.Vehicle) interface{} { return v.PurchasePrice }},
+	{label{"Repair Cost", "Biaya Perbaikan"}, spreadsheet.Money, func(v *entity.Vehicle) interface{} { return v.TotalRepairCost }},
+	{label{"Suggested Price", "Harga Jual Saran"}, spreadsheet.Money, func(v *entity.Vehicle) interface{} { return v.SuggestedSellingPrice }},
+	{label{"Approved Price", "Harga Jual Disetujui"}, spreadsheet.Money, func(v *entity.Vehicle) interface{} { return v.ApprovedSellingPrice }},
+	{label{"Selling Price", "Harga Jual"}, spreadsheet.Money, func(v *entity.Vehicle) interface{} { return v.FinalSellingPrice }},
+	{label{"Purchased From", "Dibeli Dari"}, spreadsheet.Text, func(v *entity.Vehicle) interface{} { return customerName(v.PurchasedFromCustomer) }},
+	{label{"Purchased At", "Tanggal Beli"}, spreadsheet.DateTime, func(v *entity.Vehicle) interface{} { return v.PurchasedAt }},
+	{label{"Sold At", "Tanggal Terjual"}, spreadsheet.DateTime, func(v *entity.Vehicle) interface{} { return v.SoldAt }},
+}
+
 func (h *VehicleHandler) Create(c *gin.Context) {
 	var req entity.CreateVehicleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
@@ -33,12 +53,9 @@ func (h *VehicleHandler) Create(c *gin.Context) {
 	user := userValue.(*entity.User)
 	purchasedBy := user.ID
 
-	vehicle, err := h.vehicleUsecase.Create(&req, purchasedBy)
+	vehicle, err := h.vehicleUsecase.Create(c.Request.Context(), &req, purchasedBy)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to create vehicle",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -48,23 +65,45 @@ func (h *VehicleHandler) Create(c *gin.Context) {
 	})
 }
 
-func (h *VehicleHandler) GetByID(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
+// Import creates vehicles from an uploaded CSV or XLSX file;
+// dry_run=true only reports what would fail.
+func (h *VehicleHandler) Import(c *gin.Context) {
+	q := &queryParser{c: c}
+	dryRun := q.bool("dry_run", false)
+	if err := q.err(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	rows, ok := readImport[entity.CreateVehicleRequest](c)
+	if !ok {
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	result, err := h.vehicleUsecase.Import(c.Request.Context(), rows, user.ID, dryRun)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid vehicle ID",
-			"message": "Vehicle ID must be a number",
-		})
+		c.Error(err)
 		return
 	}
 
-	vehicle, err := h.vehicleUsecase.GetByID(id)
+	c.JSON(importStatus(dryRun), gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+func (h *VehicleHandler) GetByID(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	vehicle, err := h.vehicleUsecase.GetByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Vehicle not found",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -75,20 +114,59 @@ func (h *VehicleHandler) GetByID(c *gin.Context) {
 }
 
 func (h *VehicleHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	search := c.Query("search")
-	status := c.Query("status")
+	params, ok := listParams(c)
+	if !ok {
+		return
+	}
 
-	response, err := h.vehicleUsecase.List(page, limit, search, status)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to list vehicles",
-			"message": err.Error(),
+	q := &queryParser{c: c}
+	filter := entity.VehicleFilter{
+		Search:         c.Query("search"),
+		Status:         c.Query("status"),
+		Brands:         q.list("brand"),
+		Models:         q.list("model"),
+		FuelTypes:      q.list("fuel_type"),
+		Transmissions:  q.list("transmission"),
+		Colors:         q.list("color"),
+		YearMin:        q.int("year_min"),
+		YearMax:        q.int("year_max"),
+		PriceMin:       q.float("price_min"),
+		PriceMax:       q.float("price_max"),
+		MileageMin:     q.int("mileage_min"),
+		MileageMax:     q.int("mileage_max"),
+		DaysInStockMin: q.int("days_in_stock_min"),
+		DaysInStockMax: q.int("days_in_stock_max"),
+	}
+	withFacets := q.bool("facets", false)
+	if err := q.err(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	if format != "" {
+		export(c, format, exportName("vehicles"), vehicleExportColumns, func(write func([]entity.Vehicle) error) error {
+			return eachPage(params, func(page entity.ListParams) (string, error) {
+				response, err := h.vehicleUsecase.List(c.Request.Context(), page, filter, false)
+				if err != nil {
+					return "", err
+				}
+				return response.NextCursor, write(response.Vehicles)
+			})
 		})
 		return
 	}
 
+	response, err := h.vehicleUsecase.List(c.Request.Context(), params, filter, withFacets)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    response,
@@ -96,31 +174,20 @@ func (h *VehicleHandler) List(c *gin.Context) {
 }
 
 func (h *VehicleHandler) Update(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid vehicle ID",
-			"message": "Vehicle ID must be a number",
-		})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
 	var req entity.UpdateVehicleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
-	vehicle, err := h.vehicleUsecase.Update(id, &req)
+	vehicle, err := h.vehicleUsecase.Update(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to update vehicle",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -131,31 +198,20 @@ func (h *VehicleHandler) Update(c *gin.Context) {
 }
 
 func (h *VehicleHandler) UpdateStatus(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid vehicle ID",
-			"message": "Vehicle ID must be a number",
-		})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
 	var req entity.UpdateVehicleStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+		c.Error(bindingError(err))
 		return
 	}
 
-	vehicle, err := h.vehicleUsecase.UpdateStatus(id, req.Status)
+	vehicle, err := h.vehicleUsecase.UpdateStatus(c.Request.Context(), id, req.Status, req.CustomerID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to update vehicle status",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -165,22 +221,41 @@ func (h *VehicleHandler) UpdateStatus(c *gin.Context) {
 	})
 }
 
-func (h *VehicleHandler) Delete(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
+func (h *VehicleHandler) ApprovePrice(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req entity.ApproveVehiclePriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	userValue, _ := c.Get("user")
+	user := userValue.(*entity.User)
+
+	vehicle, err := h.vehicleUsecase.ApprovePrice(c.Request.Context(), id, req.ApprovedSellingPrice, user.ID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid vehicle ID",
-			"message": "Vehicle ID must be a number",
-		})
+		c.Error(err)
 		return
 	}
 
-	if err := h.vehicleUsecase.Delete(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to delete vehicle",
-			"message": err.Error(),
-		})
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    vehicle,
+	})
+}
+
+func (h *VehicleHandler) Delete(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.vehicleUsecase.Delete(c.Request.Context(), id); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -1,22 +1,24 @@
 package entity
 
 import (
+  "encoding/json"
   "time"
 )
 
 type Customer struct {
-  ID           int       `json:"id" db:"id"`
-  CustomerCode string    `json:"customer_code" db:"customer_code"`
-  Name         string    `json:"name" db:"name"`
-  Phone        *string   `json:"phone" db:"phone"`
-  Email        *string   `json:"email" db:"email"`
-  Address      *string   `json:"address" db:"address"`
-  IDCardNumber *string   `json:"id_card_number" db:"id_card_number"`
-  Type         string    `json:"type" db:"type"`
-  CreatedAt    time.Time `json:"created_at" db:"created_at"`
-  UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
-  CreatedBy    *int      `json:"created_by" db:"created_by"`
-  IsActive     bool      `json:"is_active" db:"is_active"`
+  ID           int        `json:"id" db:"id"`
+  CustomerCode string     `json:"customer_code" db:"customer_code"`
+  Name         string     `json:"name" db:"name"`
+  Phone        *string    `json:"phone" db:"phone"`
+  Email        *string    `json:"email" db:"email"`
+  Address      *string    `json:"address" db:"address"`
+  IDCardNumber *string    `json:"id_card_number" db:"id_card_number"`
+  Type         string     `json:"type" db:"type"`
+  CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+  UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+  CreatedBy    *int       `json:"created_by" db:"created_by"`
+  IsActive     bool       `json:"is_active" db:"is_active"`
+  AnonymizedAt *time.Time `json:"anonymized_at,omitempty" db:"anonymized_at"`
 }
 
 type CreateCustomerRequest struct {
@@ -38,8 +40,86 @@ type UpdateCustomerRequest struct {
 }
 
 type CustomerListResponse struct {
-  Customers []Customer `json:"customers"`
-  Total     int        `json:"total"`
-  Page      int        `json:"page"`
-  Limit     int        `json:"limit"`
+  Customers  []Customer `json:"customers"`
+  Total      *int       `json:"total,omitempty"`
+  HasMore    bool       `json:"has_more"`
+  NextCursor string     `json:"next_cursor,omitempty"`
+  Page       int        `json:"page,omitempty"`
+  Limit      int        `json:"limit"`
+}
+
+type CustomerNote struct {
+  ID         int       `json:"id" db:"id"`
+  CustomerID int       `json:"customer_id" db:"customer_id"`
+  Note       string    `json:"note" db:"note"`
+  CreatedBy  *int      `json:"created_by" db:"created_by"`
+  AuthorName *string   `json:"author_name" db:"author_name"`
+  CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateCustomerNoteRequest struct {
+  Note string `json:"note" binding:"required,max=2000"`
+}
+
+// CustomerStats sums up a customer's completed transactions.
+type CustomerStats struct {
+  // TotalSpent is what the customer paid us for vehicles, tax included
+  TotalSpent float64 `json:"total_spent" db:"total_spent"`
+  // TotalPaidToCustomer is what we paid the customer for vehicles
+  TotalPaidToCustomer float64 `json:"total_paid_to_customer" db:"total_paid_to_customer"`
+  // OutstandingCredit is the total of sales paid on credit. Repayments are
+  // not recorded, so this is what was financed rather than what is still owed
+  OutstandingCredit float64 `json:"outstanding_credit" db:"outstanding_credit"`
+  // LifetimeValue is the gross profit on the vehicles the customer bought:
+  // price net of discount less purchase price and repair cost
+  LifetimeValue     float64    `json:"lifetime_value" db:"lifetime_value"`
+  LastInteractionAt *time.Time `json:"last_interaction_at" db:"last_interaction_at"`
+}
+
+// CustomerProfile is everything staff need about a customer in one place.
+type CustomerProfile struct {
+  Customer         *Customer          `json:"customer"`
+  Stats            CustomerStats      `json:"stats"`
+  VehiclesSoldToUs []Vehicle          `json:"vehicles_sold_to_us"`
+  VehiclesBought   []SalesTransaction `json:"vehicles_bought"`
+  OpenReservations []Vehicle          `json:"open_reservations"`
+  Notes            []CustomerNote     `json:"notes"`
+}
+
+// Why a customer was flagged as a possible duplicate.
+const (
+  DuplicateIDCard = "id_card_number"
+  DuplicatePhone  = "name_and_phone"
+  DuplicateEmail  = "name_and_email"
+)
+
+// CustomerDuplicate is an existing customer that may be the same person: the
+// same ID card number, or a similar name with the same phone or email.
+type CustomerDuplicate struct {
+  Customer       Customer `json:"customer"`
+  Reasons        []string `json:"reasons"`
+  NameSimilarity float64  `json:"name_similarity"`
+}
+
+type MergeCustomerRequest struct {
+  MergeCustomerID int `json:"merge_customer_id" binding:"required,gt=0"`
+}
+
+// CustomerMerge records one customer merged into another: the merged row as
+// it was and how many records were moved over.
+type CustomerMerge struct {
+  ID                  int             `json:"id" db:"id"`
+  SurvivingCustomerID int             `json:"surviving_customer_id" db:"surviving_customer_id"`
+  MergedCustomerID    int             `json:"merged_customer_id" db:"merged_customer_id"`
+  MergedCustomer      json.RawMessage `json:"merged_customer" db:"merged_customer"`
+  VehiclesMoved       int             `json:"vehicles_moved" db:"vehicles_moved"`
+  PurchasesMoved      int             `json:"purchases_moved" db:"purchases_moved"`
+  SalesMoved          int             `json:"sales_moved" db:"sales_moved"`
+  NotesMoved          int             `json:"notes_moved" db:"notes_moved"`
+  DocumentsMoved      int             `json:"documents_moved" db:"documents_moved"`
+  LeadsMoved          int             `json:"leads_moved" db:"leads_moved"`
+  ConsentsMoved       int             `json:"consents_moved" db:"consents_moved"`
+  TasksMoved          int             `json:"tasks_moved" db:"tasks_moved"`
+  MergedBy            *int            `json:"merged_by" db:"merged_by"`
+  MergedAt            time.Time       `json:"merged_at" db:"merged_at"`
 }
@@ -0,0 +1,149 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// Customer document types. The NIK is the number on the Indonesian ID card
+// (KTP) and the NPWP the tax number; the deed is a company's deed of
+// establishment.
+const (
+	DocumentIDCard      = "id_card"
+	DocumentTaxNumber   = "tax_number"
+	DocumentCompanyDeed = "company_deed"
+	DocumentOther       = "other"
+)
+
+type CustomerDocument struct {
+	ID             int        `json:"id" db:"id"`
+	CustomerID     int        `json:"customer_id" db:"customer_id"`
+	Type           string     `json:"type" db:"type"`
+	DocumentNumber *string    `json:"document_number" db:"document_number"`
+	FileName       string     `json:"file_name" db:"file_name"`
+	ContentType    string     `json:"content_type" db:"content_type"`
+	SizeBytes      int64      `json:"size_bytes" db:"size_bytes"`
+	StorageKey     string     `json:"-" db:"storage_key"`
+	ExpiresAt      *time.Time `json:"expires_at" db:"expires_at"`
+	VerifiedBy     *int       `json:"verified_by" db:"verified_by"`
+	VerifiedAt     *time.Time `json:"verified_at" db:"verified_at"`
+	UploadedBy     *int       `json:"uploaded_by" db:"uploaded_by"`
+	UploadedAt     time.Time  `json:"uploaded_at" db:"uploaded_at"`
+}
+
+// UploadCustomerDocumentRequest holds the form fields sent with the file.
+// ExpiresAt is a date, YYYY-MM-DD.
+type UploadCustomerDocumentRequest struct {
+	Type           string  `form:"type" binding:"required,oneof=id_card tax_number company_deed other"`
+	DocumentNumber *string `form:"document_number"`
+	ExpiresAt      *string `form:"expires_at"`
+}
+
+// Valid tells whether the document counts towards KYC at t: verified and
+// not expired. A document is valid through its expiry date.
+func (d *CustomerDocument) Valid(t time.Time) bool {
+	return d.VerifiedAt != nil && !d.Expired(t)
+}
+
+func (d *CustomerDocument) Expired(t time.Time) bool {
+	return d.ExpiresAt != nil && !t.Before(d.ExpiresAt.AddDate(0, 0, 1))
+}
+
+// KYCStatus tells which required documents a customer still lacks.
+type KYCStatus struct {
+	Verified bool     `json:"verified"`
+	Missing  []string `json:"missing"`
+}
+
+// RequiredDocuments lists the documents a buyer of the given customer type
+// must have verified: everyone an ID card, corporates also their tax number
+// and company deed.
+func RequiredDocuments(customerType string) []string {
+	if customerType == "corporate" {
+		return []string{DocumentIDCard, DocumentTaxNumber, DocumentCompanyDeed}
+	}
+	return []string{DocumentIDCard}
+}
+
+// CheckKYC compares the customer's documents with those required at t.
+func CheckKYC(customerType string, documents []CustomerDocument, t time.Time) KYCStatus {
+	valid := map[string]bool{}
+	for i := range documents {
+		if documents[i].Valid(t) {
+			valid[documents[i].Type] = true
+		}
+	}
+
+	status := KYCStatus{Missing: []string{}}
+	for _, required := range RequiredDocuments(customerType) {
+		if !valid[required] {
+			status.Missing = append(status.Missing, required)
+		}
+	}
+	status.Verified = len(status.Missing) == 0
+	return status
+}
+
+// NormalizeNIK strips the separators people type into a NIK and checks its
+// format: 16 digits of region code, date of birth (day plus 40 for women),
+// and a serial that is not 0000. It does not prove the NIK was issued.
+func NormalizeNIK(value string) (string, bool) {
+	nik := stripSeparators(value)
+	if len(nik) != 16 || !allDigits(nik) {
+		return nik, false
+	}
+
+	province := atoi(nik[0:2])
+	day := atoi(nik[6:8])
+	month := atoi(nik[8:10])
+	if day > 40 {
+		day -= 40
+	}
+	switch {
+	case province < 11 || province > 99:
+		return nik, false
+	case nik[2:4] == "00" || nik[4:6] == "00":
+		return nik, false
+	case day < 1 || day > 31 || month < 1 || month > 12:
+		return nik, false
+	case nik[12:16] == "0000":
+		return nik, false
+	}
+	return nik, true
+}
+
+// NormalizeNPWP strips the separators of a tax number, as printed
+// 99.999.999.9-999.999, and checks its format: the 15 digits of the
+// original scheme or the 16 of the NIK-based one.
+func NormalizeNPWP(value string) (string, bool) {
+	npwp := stripSeparators(value)
+	return npwp, allDigits(npwp) && (len(npwp) == 15 || len(npwp) == 16)
+}
+
+func stripSeparators(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-', '/':
+			return -1
+		}
+		return r
+	}, value)
+}
+
+func allDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
+
+// atoi parses a run of digits already checked by allDigits.
+func atoi(digits string) int {
+	n := 0
+	for _, r := range digits {
+		n = n*10 + int(r-'0')
+	}
+	return n
+}
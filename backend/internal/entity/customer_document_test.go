@@ -0,0 +1,157 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalizeNIK(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   string
+		wantOK bool
+	}{
+		{"valid, born 12 May", "3171011205900001", "3171011205900001", true},
+		{"separators stripped", "31.7101 1205-90/0001", "3171011205900001", true},
+		{"woman, day plus 40", "3171015205900001", "3171015205900001", true},
+		{"woman born on the 1st", "3171014101900001", "3171014101900001", true},
+		{"woman born on the 31st", "3171017101900001", "3171017101900001", true},
+		{"woman, day 32 after subtracting 40", "3171017201900001", "3171017201900001", false},
+		{"day 40 is neither", "3171014001900001", "3171014001900001", false},
+		{"day 0", "3171010001900001", "3171010001900001", false},
+		{"day 32", "3171013201900001", "3171013201900001", false},
+		{"month 0", "3171011200900001", "3171011200900001", false},
+		{"month 12", "3171011212900001", "3171011212900001", true},
+		{"month 13", "3171011213900001", "3171011213900001", false},
+		{"province 11, the lowest code", "1171011205900001", "1171011205900001", true},
+		{"province below 11", "1071011205900001", "1071011205900001", false},
+		{"regency 00", "3100011205900001", "3100011205900001", false},
+		{"district 00", "3171001205900001", "3171001205900001", false},
+		{"serial 0000", "3171011205900000", "3171011205900000", false},
+		{"15 digits", "317101120590001", "317101120590001", false},
+		{"17 digits", "31710112059000011", "31710112059000011", false},
+		{"letters", "31710112059O0001", "31710112059O0001", false},
+		{"empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizeNIK(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("NormalizeNIK(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNormalizeNPWP(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   string
+		wantOK bool
+	}{
+		{"15 digits as printed", "01.234.567.8-901.234", "012345678901234", true},
+		{"16 digits, NIK based", "3171011205900001", "3171011205900001", true},
+		{"14 digits", "01.234.567.8-901.23", "01234567890123", false},
+		{"17 digits", "31710112059000011", "31710112059000011", false},
+		{"letters", "01.234.567.8-901.23A", "01234567890123A", false},
+		{"empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizeNPWP(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("NormalizeNPWP(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCheckKYC(t *testing.T) {
+	expiry := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	verified := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	doc := func(docType string, verifiedAt, expiresAt *time.Time) CustomerDocument {
+		return CustomerDocument{Type: docType, VerifiedAt: verifiedAt, ExpiresAt: expiresAt}
+	}
+
+	tests := []struct {
+		name         string
+		customerType string
+		documents    []CustomerDocument
+		at           time.Time
+		wantMissing  []string
+	}{
+		{
+			name:         "verified ID card without expiry",
+			customerType: "individual",
+			documents:    []CustomerDocument{doc(DocumentIDCard, &verified, nil)},
+			at:           expiry,
+			wantMissing:  []string{},
+		},
+		{
+			name:         "unverified ID card",
+			customerType: "individual",
+			documents:    []CustomerDocument{doc(DocumentIDCard, nil, nil)},
+			at:           expiry,
+			wantMissing:  []string{DocumentIDCard},
+		},
+		{
+			name:         "valid on the expiry day",
+			customerType: "individual",
+			documents:    []CustomerDocument{doc(DocumentIDCard, &verified, &expiry)},
+			at:           expiry.Add(23*time.Hour + 59*time.Minute),
+			wantMissing:  []string{},
+		},
+		{
+			name:         "expired the day after",
+			customerType: "individual",
+			documents:    []CustomerDocument{doc(DocumentIDCard, &verified, &expiry)},
+			at:           expiry.AddDate(0, 0, 1),
+			wantMissing:  []string{DocumentIDCard},
+		},
+		{
+			name:         "an expired copy does not hide a valid one",
+			customerType: "individual",
+			documents: []CustomerDocument{
+				doc(DocumentIDCard, &verified, &verified),
+				doc(DocumentIDCard, &verified, &expiry),
+			},
+			at:          expiry,
+			wantMissing: []string{},
+		},
+		{
+			name:         "corporate needs tax number and deed",
+			customerType: "corporate",
+			documents:    []CustomerDocument{doc(DocumentIDCard, &verified, nil), doc(DocumentOther, &verified, nil)},
+			at:           expiry,
+			wantMissing:  []string{DocumentTaxNumber, DocumentCompanyDeed},
+		},
+		{
+			name:         "corporate complete",
+			customerType: "corporate",
+			documents: []CustomerDocument{
+				doc(DocumentIDCard, &verified, nil),
+				doc(DocumentTaxNumber, &verified, nil),
+				doc(DocumentCompanyDeed, &verified, nil),
+			},
+			at:          expiry,
+			wantMissing: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := CheckKYC(tt.customerType, tt.documents, tt.at)
+			if !reflect.DeepEqual(status.Missing, tt.wantMissing) {
+				t.Errorf("Missing = %v, want %v", status.Missing, tt.wantMissing)
+			}
+			if status.Verified != (len(tt.wantMissing) == 0) {
+				t.Errorf("Verified = %v with %v missing", status.Verified, status.Missing)
+			}
+		})
+	}
+}
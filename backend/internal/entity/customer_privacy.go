@@ -0,0 +1,95 @@
+package entity
+
+import "time"
+
+// Marketing contact channels a customer can consent to.
+const (
+	ConsentEmail    = "email"
+	ConsentSMS      = "sms"
+	ConsentWhatsApp = "whatsapp"
+	ConsentPhone    = "phone"
+)
+
+// ConsentChannels lists every channel, in the order they are reported.
+var ConsentChannels = []string{ConsentEmail, ConsentSMS, ConsentWhatsApp, ConsentPhone}
+
+// AnonymizedCustomerName replaces the name of an anonymized customer.
+const AnonymizedCustomerName = "Anonymized customer"
+
+// AnonymizedTaskTitle replaces the title of a task about an anonymized
+// customer.
+const AnonymizedTaskTitle = "Follow-up with an anonymized customer"
+
+// CustomerConsent is one grant or withdrawal of consent, as recorded.
+type CustomerConsent struct {
+	ID         int       `json:"id" db:"id"`
+	CustomerID int       `json:"customer_id" db:"customer_id"`
+	Channel    string    `json:"channel" db:"channel"`
+	Granted    bool      `json:"granted" db:"granted"`
+	Source     *string   `json:"source" db:"source"`
+	RecordedBy *int      `json:"recorded_by" db:"recorded_by"`
+	RecordedAt time.Time `json:"recorded_at" db:"recorded_at"`
+}
+
+// RecordConsentRequest records the customer's answer for one channel.
+// Source says how it was given, e.g. "showroom form" or "phone call".
+type RecordConsentRequest struct {
+	Channel string  `json:"channel" binding:"required,oneof=email sms whatsapp phone"`
+	Granted *bool   `json:"granted" binding:"required"`
+	Source  *string `json:"source" binding:"omitempty,max=50"`
+}
+
+// CustomerConsents is the consent in force per channel, with the history
+// it comes from, latest first. A channel with no record is not consented
+// to: marketing contact is opt-in.
+type CustomerConsents struct {
+	Current map[string]bool   `json:"current"`
+	History []CustomerConsent `json:"history"`
+}
+
+// NewCustomerConsents derives the current consent from history, which must
+// be ordered latest first.
+func NewCustomerConsents(history []CustomerConsent) *CustomerConsents {
+	consents := &CustomerConsents{Current: make(map[string]bool, len(ConsentChannels)), History: history}
+	for _, channel := range ConsentChannels {
+		consents.Current[channel] = false
+	}
+	seen := map[string]bool{}
+	for _, consent := range history {
+		if !seen[consent.Channel] {
+			seen[consent.Channel] = true
+			consents.Current[consent.Channel] = consent.Granted
+		}
+	}
+	return consents
+}
+
+// CustomerDataExport is everything stored about a customer, for a subject
+// access request. Document files are listed, not included.
+type CustomerDataExport struct {
+	ExportedAt       time.Time             `json:"exported_at"`
+	Customer         *Customer             `json:"customer"`
+	Consents         []CustomerConsent     `json:"consents"`
+	Notes            []CustomerNote        `json:"notes"`
+	Documents        []CustomerDocument    `json:"documents"`
+	VehiclesSoldToUs []Vehicle             `json:"vehicles_sold_to_us"`
+	Purchases        []PurchaseTransaction `json:"purchase_transactions"`
+	Sales            []SalesTransaction    `json:"sales_transactions"`
+	Reservations     []Vehicle             `json:"open_reservations"`
+	Leads            []Lead                `json:"leads"`
+	Merges           []CustomerMerge       `json:"merged_records"`
+}
+
+// CustomerAnonymization reports what an anonymization removed.
+type CustomerAnonymization struct {
+	CustomerID        int       `json:"customer_id"`
+	CustomerIDs       []int     `json:"customer_ids"`
+	NotesDeleted      int       `json:"notes_deleted"`
+	DocumentsDeleted  int       `json:"documents_deleted"`
+	ReservationsEnded int       `json:"reservations_ended"`
+	TasksScrubbed     int       `json:"tasks_scrubbed"`
+	AnonymizedAt      time.Time `json:"anonymized_at"`
+	// StorageKeys are the document files to remove once the change is
+	// committed
+	StorageKeys []string `json:"-"`
+}
@@ -0,0 +1,119 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kinds of domain error. Usecases return them wrapped in *Error so callers
+// can test the kind with errors.Is and still show a specific message.
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrConflict          = errors.New("conflict")
+	ErrValidation        = errors.New("validation failed")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrForbidden         = errors.New("forbidden")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrInvalidTransition = errors.New("invalid state transition")
+	ErrTooManyRequests   = errors.New("too many requests")
+	ErrLocked            = errors.New("locked")
+	ErrKYCRequired       = errors.New("kyc required")
+)
+
+// FieldError describes one invalid input field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error with a message that is safe to show to users.
+type Error struct {
+	Kind    error
+	Message string
+	Fields  []FieldError
+	Details map[string]interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NewNotFoundError reports that the named resource does not exist, e.g.
+// NewNotFoundError("vehicle") reads "vehicle not found".
+func NewNotFoundError(resource string) *Error {
+	return &Error{Kind: ErrNotFound, Message: resource + " not found"}
+}
+
+func NewConflictError(format string, args ...interface{}) *Error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewValidationError(message string, fields ...FieldError) *Error {
+	return &Error{Kind: ErrValidation, Message: message, Fields: fields}
+}
+
+// NewFieldError is a validation error about a single field.
+func NewFieldError(field, message string) *Error {
+	return NewValidationError(field+" "+message, FieldError{Field: field, Message: message})
+}
+
+func NewUnauthorizedError(message string) *Error {
+	return &Error{Kind: ErrUnauthorized, Message: message}
+}
+
+func NewForbiddenError(message string) *Error {
+	return &Error{Kind: ErrForbidden, Message: message}
+}
+
+func NewInsufficientStockError(partID, available, requested int) *Error {
+	return &Error{
+		Kind:    ErrInsufficientStock,
+		Message: fmt.Sprintf("insufficient stock: available %d, requested %d", available, requested),
+		Details: map[string]interface{}{
+			"spare_part_id": partID,
+			"available":     available,
+			"requested":     requested,
+		},
+	}
+}
+
+// NewInvalidTransitionError reports a status change the current state does
+// not allow.
+func NewInvalidTransitionError(format string, args ...interface{}) *Error {
+	return &Error{Kind: ErrInvalidTransition, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewTestDriveConflictError reports a test drive that overlaps one already
+// scheduled on the same vehicle.
+func NewTestDriveConflictError(existing *TestDrive) *Error {
+	return &Error{
+		Kind: ErrConflict,
+		Message: fmt.Sprintf("vehicle already has a test drive from %s to %s",
+			existing.StartsAt.Format(time.RFC3339), existing.EndsAt.Format(time.RFC3339)),
+		Details: map[string]interface{}{
+			"test_drive_id": existing.ID,
+			"vehicle_id":    existing.VehicleID,
+			"starts_at":     existing.StartsAt,
+			"ends_at":       existing.EndsAt,
+		},
+	}
+}
+
+// NewKYCRequiredError reports a sale to a customer whose identity documents
+// are missing, unverified or expired.
+func NewKYCRequiredError(customerID int, missing []string) *Error {
+	return &Error{
+		Kind:    ErrKYCRequired,
+		Message: "customer has no verified KYC documents: " + strings.Join(missing, ", "),
+		Details: map[string]interface{}{
+			"customer_id": customerID,
+			"missing":     missing,
+		},
+	}
+}
@@ -0,0 +1,20 @@
+package entity
+
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+)
+
+// HealthCheck is the result of one readiness dependency check.
+type HealthCheck struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ReadinessStatus struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks"`
+	// Schema versions are reported so a failed rollout is easy to diagnose
+	SchemaVersion       int `json:"schema_version"`
+	LatestSchemaVersion int `json:"latest_schema_version"`
+}
@@ -0,0 +1,54 @@
+package entity
+
+import "fmt"
+
+// ImportRow is one decoded row of an import file. Row is its number in the
+// file; Errors holds the problems found so far, and a row with any is not
+// imported.
+type ImportRow[T any] struct {
+	Row    int
+	Data   T
+	Errors []FieldError
+}
+
+// ImportRowError is a problem with one field of one row.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ImportResult reports an import. A dry run checks every row without
+// writing; a real import writes all rows or, if any row has an error, none.
+type ImportResult struct {
+	DryRun  bool             `json:"dry_run"`
+	Rows    int              `json:"rows"`
+	Valid   int              `json:"valid"`
+	Created int              `json:"created"`
+	Codes   []string         `json:"codes,omitempty"`
+	Errors  []ImportRowError `json:"errors"`
+}
+
+// NewImportResult collects the errors of rows.
+func NewImportResult[T any](rows []ImportRow[T], dryRun bool) *ImportResult {
+	result := &ImportResult{DryRun: dryRun, Rows: len(rows), Errors: []ImportRowError{}}
+	for _, row := range rows {
+		if len(row.Errors) == 0 {
+			result.Valid++
+		}
+		for _, fe := range row.Errors {
+			result.Errors = append(result.Errors, ImportRowError{Row: row.Row, Field: fe.Field, Message: fe.Message})
+		}
+	}
+	return result
+}
+
+// Rejected is the error for a real import with invalid rows; the row errors
+// go in its details.
+func (r *ImportResult) Rejected() error {
+	return &Error{
+		Kind:    ErrValidation,
+		Message: fmt.Sprintf("%d of %d rows have errors; nothing was imported", r.Rows-r.Valid, r.Rows),
+		Details: map[string]interface{}{"errors": r.Errors},
+	}
+}
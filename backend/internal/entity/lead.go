@@ -0,0 +1,143 @@
+package entity
+
+import "time"
+
+// Lead pipeline stages, in order. Won and lost close the lead.
+const (
+	LeadNew         = "new"
+	LeadContacted   = "contacted"
+	LeadTestDrive   = "test_drive"
+	LeadNegotiation = "negotiation"
+	LeadWon         = "won"
+	LeadLost        = "lost"
+)
+
+// LeadClosed reports whether status ends the pipeline.
+func LeadClosed(status string) bool {
+	return status == LeadWon || status == LeadLost
+}
+
+// Test drive statuses. Only scheduled test drives hold the vehicle.
+const (
+	TestDriveScheduled = "scheduled"
+	TestDriveCompleted = "completed"
+	TestDriveCancelled = "cancelled"
+	TestDriveNoShow    = "no_show"
+)
+
+// MaxTestDriveDuration bounds a single test drive booking.
+const MaxTestDriveDuration = 4 * time.Hour
+
+type Lead struct {
+	ID          int        `json:"id" db:"id"`
+	LeadNumber  string     `json:"lead_number" db:"lead_number"`
+	Name        string     `json:"name" db:"name"`
+	Phone       *string    `json:"phone" db:"phone"`
+	Email       *string    `json:"email" db:"email"`
+	Source      string     `json:"source" db:"source"`
+	Status      string     `json:"status" db:"status"`
+	AssignedTo  *int       `json:"assigned_to" db:"assigned_to"`
+	Notes       *string    `json:"notes" db:"notes"`
+	LostReason  *string    `json:"lost_reason" db:"lost_reason"`
+	CustomerID  *int       `json:"customer_id" db:"customer_id"`
+	ConvertedAt *time.Time `json:"converted_at" db:"converted_at"`
+	CreatedBy   *int       `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Joined fields
+	Vehicles   []Vehicle   `json:"vehicles,omitempty"`
+	TestDrives []TestDrive `json:"test_drives,omitempty"`
+}
+
+type CreateLeadRequest struct {
+	Name       string  `json:"name" binding:"required,max=100"`
+	Phone      *string `json:"phone" binding:"omitempty,max=20"`
+	Email      *string `json:"email" binding:"omitempty,email,max=100"`
+	Source     string  `json:"source" binding:"required,oneof=walk_in phone website referral other"`
+	AssignedTo *int    `json:"assigned_to"`
+	Notes      *string `json:"notes"`
+	VehicleIDs []int   `json:"vehicle_ids"`
+}
+
+type UpdateLeadRequest struct {
+	Name       string  `json:"name" binding:"required,max=100"`
+	Phone      *string `json:"phone" binding:"omitempty,max=20"`
+	Email      *string `json:"email" binding:"omitempty,email,max=100"`
+	Source     string  `json:"source" binding:"required,oneof=walk_in phone website referral other"`
+	AssignedTo *int    `json:"assigned_to"`
+	Notes      *string `json:"notes"`
+	VehicleIDs []int   `json:"vehicle_ids"`
+}
+
+// UpdateLeadStatusRequest moves a lead along the pipeline. LostReason is
+// required when the lead is lost.
+type UpdateLeadStatusRequest struct {
+	Status     string  `json:"status" binding:"required,oneof=new contacted test_drive negotiation won lost"`
+	LostReason *string `json:"lost_reason"`
+}
+
+// ConvertLeadRequest turns a won lead into a customer. With CustomerID the
+// lead is linked to that existing customer instead of creating one; the
+// other customer fields are then ignored. VehicleID picks the vehicle the
+// sale is prefilled for and defaults to the lead's only vehicle.
+type ConvertLeadRequest struct {
+	CustomerID   *int    `json:"customer_id"`
+	Type         string  `json:"type" binding:"omitempty,oneof=individual corporate"`
+	Address      *string `json:"address"`
+	IDCardNumber *string `json:"id_card_number"`
+	VehicleID    *int    `json:"vehicle_id"`
+}
+
+// LeadConversion is the outcome of converting a lead. SalesRequest is a
+// CreateSalesTransactionRequest prefilled with the customer, the vehicle
+// and its approved price, for the cashier to complete; it is nil when the
+// vehicle is not for sale or none could be picked.
+type LeadConversion struct {
+	Lead               *Lead                          `json:"lead"`
+	Customer           *Customer                      `json:"customer"`
+	PossibleDuplicates []CustomerDuplicate            `json:"possible_duplicates,omitempty"`
+	SalesRequest       *CreateSalesTransactionRequest `json:"sales_request"`
+}
+
+// LeadFilter narrows the lead list. Open leaves out won and lost leads.
+type LeadFilter struct {
+	Search     string
+	Status     string
+	AssignedTo *int
+	VehicleID  *int
+	Open       bool
+}
+
+type LeadListResponse struct {
+	Leads      []Lead `json:"leads"`
+	Total      *int   `json:"total,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+}
+
+type TestDrive struct {
+	ID        int       `json:"id" db:"id"`
+	LeadID    int       `json:"lead_id" db:"lead_id"`
+	VehicleID int       `json:"vehicle_id" db:"vehicle_id"`
+	StartsAt  time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt    time.Time `json:"ends_at" db:"ends_at"`
+	Status    string    `json:"status" db:"status"`
+	Notes     *string   `json:"notes" db:"notes"`
+	CreatedBy *int      `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type ScheduleTestDriveRequest struct {
+	VehicleID int       `json:"vehicle_id" binding:"required"`
+	StartsAt  time.Time `json:"starts_at" binding:"required"`
+	EndsAt    time.Time `json:"ends_at" binding:"required,gtfield=StartsAt"`
+	Notes     *string   `json:"notes"`
+}
+
+type UpdateTestDriveStatusRequest struct {
+	Status string  `json:"status" binding:"required,oneof=completed cancelled no_show"`
+	Notes  *string `json:"notes"`
+}
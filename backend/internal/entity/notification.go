@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Notification types, stored with each notification so clients can pick an
+// icon or a link.
+const (
+	NotificationTaskAssigned   = "task_assigned"
+	NotificationTaskReminder   = "task_reminder"
+	NotificationTaskOverdue    = "task_overdue"
+	NotificationRepairAssigned = "repair_assigned"
+	NotificationPriceApproval  = "price_approval"
+)
+
+// NotificationEntityTask is the EntityType of notifications about a task;
+// the others use the kinds a task can link to.
+const NotificationEntityTask = "task"
+
+// Notification is an in-app message for one user. EntityType and EntityID
+// point at the record it is about.
+type Notification struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"user_id" db:"user_id"`
+	Type       string     `json:"type" db:"type"`
+	Title      string     `json:"title" db:"title"`
+	Body       *string    `json:"body" db:"body"`
+	EntityType *string    `json:"entity_type" db:"entity_type"`
+	EntityID   *int       `json:"entity_id" db:"entity_id"`
+	ReadAt     *time.Time `json:"read_at" db:"read_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// NewNotification is a notification to create for one or more users.
+type NewNotification struct {
+	Type       string
+	Title      string
+	Body       string
+	EntityType string
+	EntityID   int
+}
+
+type NotificationListResponse struct {
+	Notifications []Notification `json:"notifications"`
+	UnreadCount   int            `json:"unread_count"`
+	Total         *int           `json:"total,omitempty"`
+	HasMore       bool           `json:"has_more"`
+	NextCursor    string         `json:"next_cursor,omitempty"`
+	Page          int            `json:"page,omitempty"`
+	Limit         int            `json:"limit"`
+}
+
+type MarkNotificationsReadResponse struct {
+	Marked int `json:"marked"`
+}
+
+// Event types pushed over the event stream. A notification event carries
+// the new Notification; the others are domain events, sent to everyone
+// allowed to see them and not stored.
+const (
+	EventNotification    = "notification"
+	EventVehicleSold     = "vehicle.sold"
+	EventStockLow        = "stock.low"
+	EventRepairCompleted = "repair.completed"
+)
+
+// Event is a message for the clients connected to the event stream. It
+// goes to UserID when set, else to every user whose role holds Permission.
+type Event struct {
+	Type       string          `json:"type"`
+	UserID     int             `json:"user_id,omitempty"`
+	Permission string          `json:"permission,omitempty"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// VehicleSoldEvent is the data of a vehicle.sold event.
+type VehicleSoldEvent struct {
+	VehicleID         int     `json:"vehicle_id"`
+	VehicleCode       string  `json:"vehicle_code"`
+	Brand             string  `json:"brand"`
+	Model             string  `json:"model"`
+	TransactionID     int     `json:"transaction_id"`
+	TransactionNumber string  `json:"transaction_number"`
+	TotalAmount       float64 `json:"total_amount"`
+}
+
+// StockLowEvent is the data of a stock.low event, sent when a part's stock
+// drops to its minimum level or below.
+type StockLowEvent struct {
+	SparePartID   int    `json:"spare_part_id"`
+	PartCode      string `json:"part_code"`
+	Name          string `json:"name"`
+	StockQuantity int    `json:"stock_quantity"`
+	MinStockLevel int    `json:"min_stock_level"`
+}
+
+// RepairCompletedEvent is the data of a repair.completed event.
+type RepairCompletedEvent struct {
+	RepairID     int     `json:"repair_id"`
+	RepairNumber string  `json:"repair_number"`
+	VehicleID    int     `json:"vehicle_id"`
+	MechanicID   *int    `json:"mechanic_id"`
+	TotalCost    float64 `json:"total_cost"`
+}
@@ -0,0 +1,93 @@
+package entity
+
+import (
+	"strings"
+)
+
+// ListParams are the paging and sorting options shared by the list
+// endpoints. Offset paging uses Page; keyset paging (Keyset set) continues
+// after Cursor, the NextCursor of the previous page, or starts at the top
+// when Cursor is empty. Keyset pages stay stable while rows are added and
+// do not slow down on deep pages.
+type ListParams struct {
+	Page      int
+	Limit     int
+	Keyset    bool
+	Cursor    string
+	Sort      []SortField // empty means the list's default order
+	WithTotal bool        // also count all matching rows, which costs a second query
+}
+
+// SortField orders a list by one whitelisted field.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// PageInfo describes the page a list returned.
+type PageInfo struct {
+	Total      *int
+	HasMore    bool
+	NextCursor string // only in keyset mode, and only when HasMore
+}
+
+// Normalize fills in defaults and clamps the page size.
+func (p *ListParams) Normalize(defaultLimit, maxLimit int) {
+	if p.Page <= 0 {
+		p.Page = 1
+	}
+	if p.Limit <= 0 || p.Limit > maxLimit {
+		p.Limit = defaultLimit
+	}
+}
+
+// Offset is the number of rows offset paging skips.
+func (p ListParams) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// ParseSort parses a sort parameter such as "-year,price": comma-separated
+// field names, each descending when prefixed with "-". Whether the fields
+// exist is up to the list being sorted.
+func ParseSort(value string) ([]SortField, error) {
+	var fields []SortField
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if field.Field == "" {
+			return nil, NewFieldError("sort", "has an empty field name")
+		}
+		if seen[field.Field] {
+			return nil, NewFieldError("sort", "lists "+field.Field+" more than once")
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// FormatSort is the inverse of ParseSort.
+func FormatSort(fields []SortField) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		if f.Desc {
+			parts[i] = "-" + f.Field
+		} else {
+			parts[i] = f.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// ResponsePage is the page number to echo back; keyset pages have none.
+func (p ListParams) ResponsePage() int {
+	if p.Keyset {
+		return 0
+	}
+	return p.Page
+}
@@ -59,8 +59,10 @@ type AddPartToRepairRequest struct {
 }
 
 type RepairListResponse struct {
-	Repairs []Repair `json:"repairs"`
-	Total   int      `json:"total"`
-	Page    int      `json:"page"`
-	Limit   int      `json:"limit"`
+	Repairs    []Repair `json:"repairs"`
+	Total      *int     `json:"total,omitempty"`
+	HasMore    bool     `json:"has_more"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+	Page       int      `json:"page,omitempty"`
+	Limit      int      `json:"limit"`
 }
@@ -0,0 +1,144 @@
+package entity
+
+import "time"
+
+// Permission codes checked by the HTTP layer. New codes must also be added to
+// PermissionCatalog so they are synced into the permissions table.
+const (
+	PermCustomerView    = "customer.view"
+	PermCustomerCreate  = "customer.create"
+	PermCustomerUpdate  = "customer.update"
+	PermCustomerDelete  = "customer.delete"
+	PermCustomerMerge   = "customer.merge"
+	PermCustomerPrivacy = "customer.privacy"
+
+	PermCustomerDocumentView   = "customer.document.view"
+	PermCustomerDocumentVerify = "customer.document.verify"
+
+	PermLeadView   = "lead.view"
+	PermLeadCreate = "lead.create"
+	PermLeadUpdate = "lead.update"
+
+	PermTaskView   = "task.view"
+	PermTaskCreate = "task.create"
+	PermTaskUpdate = "task.update"
+
+	PermVehicleView         = "vehicle.view"
+	PermVehicleCreate       = "vehicle.create"
+	PermVehicleUpdate       = "vehicle.update"
+	PermVehicleStatusUpdate = "vehicle.status.update"
+	PermVehicleDelete       = "vehicle.delete"
+	PermVehiclePriceApprove = "vehicle.price.approve"
+
+	PermPurchaseView   = "purchase.view"
+	PermPurchaseCreate = "purchase.create"
+	PermSalesView      = "sales.view"
+	PermSalesCreate    = "sales.create"
+
+	PermDashboardView = "dashboard.view"
+	PermReportView    = "report.view"
+
+	PermSparePartView   = "spare_part.view"
+	PermSparePartCreate = "spare_part.create"
+	PermSparePartUpdate = "spare_part.update"
+	PermSparePartDelete = "spare_part.delete"
+
+	PermRepairView         = "repair.view"
+	PermRepairCreate       = "repair.create"
+	PermRepairUpdate       = "repair.update"
+	PermRepairStatusUpdate = "repair.status.update"
+	PermRepairPartsManage  = "repair.parts.manage"
+
+	PermUserManage = "user.manage"
+	PermRoleManage = "role.manage"
+)
+
+const (
+	RoleAdmin    = "admin"
+	RoleMechanic = "mechanic"
+	RoleCashier  = "cashier"
+)
+
+type PermissionDefinition struct {
+	Code        string
+	Description string
+	// Roles that receive the permission when it is first introduced. Later
+	// changes made through the roles API are never overwritten.
+	DefaultRoles []string
+}
+
+var PermissionCatalog = []PermissionDefinition{
+	{PermCustomerView, "View customers", []string{RoleAdmin, RoleCashier, RoleMechanic}},
+	{PermCustomerCreate, "Create customers", []string{RoleAdmin, RoleCashier}},
+	{PermCustomerUpdate, "Update customers", []string{RoleAdmin, RoleCashier}},
+	{PermCustomerDelete, "Delete customers", []string{RoleAdmin}},
+	{PermCustomerMerge, "Merge duplicate customers", []string{RoleAdmin}},
+	{PermCustomerPrivacy, "Export and anonymize customer personal data", []string{RoleAdmin}},
+	{PermCustomerDocumentView, "View customer identity documents", []string{RoleAdmin, RoleCashier}},
+	{PermCustomerDocumentVerify, "Verify customer identity documents", []string{RoleAdmin}},
+
+	{PermLeadView, "View leads and test drives", []string{RoleAdmin, RoleCashier}},
+	{PermLeadCreate, "Create leads", []string{RoleAdmin, RoleCashier}},
+	{PermLeadUpdate, "Update leads, schedule test drives and convert leads", []string{RoleAdmin, RoleCashier}},
+
+	{PermTaskView, "View everyone's tasks", []string{RoleAdmin, RoleCashier}},
+	{PermTaskCreate, "Create and assign tasks", []string{RoleAdmin, RoleCashier, RoleMechanic}},
+	{PermTaskUpdate, "Edit tasks and change the status of tasks assigned to others", []string{RoleAdmin, RoleCashier}},
+
+	{PermVehicleView, "View vehicles", []string{RoleAdmin, RoleCashier, RoleMechanic}},
+	{PermVehicleCreate, "Register vehicles", []string{RoleAdmin, RoleCashier}},
+	{PermVehicleUpdate, "Update vehicle details", []string{RoleAdmin, RoleCashier, RoleMechanic}},
+	{PermVehicleStatusUpdate, "Change vehicle status", []string{RoleAdmin, RoleCashier, RoleMechanic}},
+	{PermVehicleDelete, "Delete vehicles", []string{RoleAdmin}},
+	{PermVehiclePriceApprove, "Approve vehicle selling prices", []string{RoleAdmin}},
+
+	{PermPurchaseView, "View purchase transactions", []string{RoleAdmin, RoleCashier}},
+	{PermPurchaseCreate, "Create purchase transactions", []string{RoleAdmin, RoleCashier}},
+	{PermSalesView, "View sales transactions", []string{RoleAdmin, RoleCashier}},
+	{PermSalesCreate, "Create sales transactions", []string{RoleAdmin, RoleCashier}},
+
+	{PermDashboardView, "View dashboard statistics", []string{RoleAdmin}},
+	{PermReportView, "View financial reports", []string{RoleAdmin}},
+
+	{PermSparePartView, "View spare parts", []string{RoleAdmin, RoleMechanic}},
+	{PermSparePartCreate, "Create spare parts", []string{RoleAdmin, RoleMechanic}},
+	{PermSparePartUpdate, "Update spare parts", []string{RoleAdmin, RoleMechanic}},
+	{PermSparePartDelete, "Delete spare parts", []string{RoleAdmin}},
+
+	{PermRepairView, "View repairs", []string{RoleAdmin, RoleMechanic}},
+	{PermRepairCreate, "Create repairs", []string{RoleAdmin, RoleMechanic}},
+	{PermRepairUpdate, "Update repairs", []string{RoleAdmin, RoleMechanic}},
+	{PermRepairStatusUpdate, "Change repair status", []string{RoleAdmin, RoleMechanic}},
+	{PermRepairPartsManage, "Add and remove repair parts", []string{RoleAdmin, RoleMechanic}},
+
+	{PermUserManage, "Manage users and their sessions", []string{RoleAdmin}},
+	{PermRoleManage, "Manage roles and permissions", []string{RoleAdmin}},
+}
+
+type Role struct {
+	ID          int       `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description *string   `json:"description" db:"description"`
+	IsSystem    bool      `json:"is_system" db:"is_system"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	Permissions []string  `json:"permissions" db:"-"`
+}
+
+type Permission struct {
+	ID          int    `json:"id" db:"id"`
+	Code        string `json:"code" db:"code"`
+	Description string `json:"description" db:"description"`
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,min=3,max=20"`
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type UpdateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,min=3,max=20"`
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
@@ -0,0 +1,38 @@
+package entity
+
+// Kinds of record the global search returns
+const (
+	SearchTypeCustomer = "customer"
+	SearchTypeVehicle  = "vehicle"
+	SearchTypeSale     = "sale"
+	SearchTypePurchase = "purchase"
+	SearchTypeRepair   = "repair"
+)
+
+// SearchTypes lists every hit type with the permission needed to see it.
+var SearchTypes = []struct {
+	Type       string
+	Permission string
+}{
+	{SearchTypeCustomer, PermCustomerView},
+	{SearchTypeVehicle, PermVehicleView},
+	{SearchTypeSale, PermSalesView},
+	{SearchTypePurchase, PermPurchaseView},
+	{SearchTypeRepair, PermRepairView},
+}
+
+// SearchHit is one record matching a search. Score is between 0 and 1:
+// 1 for an exact code or number, 0.8 for part of one, 0.6-0.9 for a
+// full-text match and the trigram similarity for a fuzzy one.
+type SearchHit struct {
+	Type     string  `json:"type" db:"type"`
+	ID       int     `json:"id" db:"id"`
+	Title    string  `json:"title" db:"title"`
+	Subtitle string  `json:"subtitle" db:"subtitle"`
+	Score    float64 `json:"score" db:"score"`
+}
+
+type SearchResponse struct {
+	Query string      `json:"query"`
+	Hits  []SearchHit `json:"hits"`
+}
@@ -42,7 +42,9 @@ type UpdateSparePartRequest struct {
 
 type SparePartListResponse struct {
 	SpareParts []SparePart `json:"spare_parts"`
-	Total      int         `json:"total"`
-	Page       int         `json:"page"`
+	Total      *int        `json:"total,omitempty"`
+	HasMore    bool        `json:"has_more"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	Page       int         `json:"page,omitempty"`
 	Limit      int         `json:"limit"`
 }
@@ -0,0 +1,102 @@
+package entity
+
+import "time"
+
+// Task statuses. Open, in-progress and overdue tasks are pending; done and
+// cancelled tasks are closed.
+const (
+	TaskOpen       = "open"
+	TaskInProgress = "in_progress"
+	TaskOverdue    = "overdue"
+	TaskDone       = "done"
+	TaskCancelled  = "cancelled"
+)
+
+// TaskClosed reports whether status finishes the task.
+func TaskClosed(status string) bool {
+	return status == TaskDone || status == TaskCancelled
+}
+
+// Kinds of record a task can be about.
+const (
+	TaskEntityCustomer = "customer"
+	TaskEntityVehicle  = "vehicle"
+	TaskEntitySale     = "sale"
+	TaskEntityRepair   = "repair"
+	TaskEntityLead     = "lead"
+)
+
+type Task struct {
+	ID          int        `json:"id" db:"id"`
+	Title       string     `json:"title" db:"title"`
+	Description *string    `json:"description" db:"description"`
+	AssignedTo  int        `json:"assigned_to" db:"assigned_to"`
+	DueAt       time.Time  `json:"due_at" db:"due_at"`
+	RemindAt    *time.Time `json:"remind_at" db:"remind_at"`
+	Status      string     `json:"status" db:"status"`
+	EntityType  *string    `json:"entity_type" db:"entity_type"`
+	EntityID    *int       `json:"entity_id" db:"entity_id"`
+	RemindedAt  *time.Time `json:"reminded_at" db:"reminded_at"`
+	OverdueAt   *time.Time `json:"overdue_at" db:"overdue_at"`
+	CompletedAt *time.Time `json:"completed_at" db:"completed_at"`
+	CreatedBy   *int       `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Joined fields
+	Assignee *User `json:"assignee,omitempty"`
+}
+
+// CreateTaskRequest assigns a task, to the caller when AssignedTo is
+// omitted. EntityType and EntityID link it to a record and go together.
+type CreateTaskRequest struct {
+	Title       string     `json:"title" binding:"required,max=200"`
+	Description *string    `json:"description"`
+	AssignedTo  *int       `json:"assigned_to"`
+	DueAt       time.Time  `json:"due_at" binding:"required"`
+	RemindAt    *time.Time `json:"remind_at"`
+	EntityType  *string    `json:"entity_type" binding:"omitempty,oneof=customer vehicle sale repair lead"`
+	EntityID    *int       `json:"entity_id"`
+}
+
+type UpdateTaskRequest struct {
+	Title       string     `json:"title" binding:"required,max=200"`
+	Description *string    `json:"description"`
+	AssignedTo  int        `json:"assigned_to" binding:"required"`
+	DueAt       time.Time  `json:"due_at" binding:"required"`
+	RemindAt    *time.Time `json:"remind_at"`
+	EntityType  *string    `json:"entity_type" binding:"omitempty,oneof=customer vehicle sale repair lead"`
+	EntityID    *int       `json:"entity_id"`
+}
+
+// UpdateTaskStatusRequest cannot set overdue; only the scheduler does.
+type UpdateTaskStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=open in_progress done cancelled"`
+}
+
+// TaskFilter narrows the task list. Pending keeps open, in-progress and
+// overdue tasks; DueBefore keeps tasks due before that time.
+type TaskFilter struct {
+	Status     string
+	AssignedTo *int
+	EntityType string
+	EntityID   *int
+	Pending    bool
+	DueBefore  *time.Time
+}
+
+type TaskListResponse struct {
+	Tasks      []Task `json:"tasks"`
+	Total      *int   `json:"total,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+}
+
+// TaskDue is a task the scheduler just acted on, with the assignee to tell.
+type TaskDue struct {
+	Task
+	AssigneeEmail string `db:"assignee_email"`
+	AssigneeName  string `db:"assignee_name"`
+}
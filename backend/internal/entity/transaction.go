@@ -74,8 +74,10 @@ type CreateSalesTransactionRequest struct {
 
 type TransactionListResponse struct {
   Transactions interface{} `json:"transactions"`
-  Total        int         `json:"total"`
-  Page         int         `json:"page"`
+  Total        *int        `json:"total,omitempty"`
+  HasMore      bool        `json:"has_more"`
+  NextCursor   string      `json:"next_cursor,omitempty"`
+  Page         int         `json:"page,omitempty"`
   Limit        int         `json:"limit"`
 }
 
@@ -15,33 +15,144 @@ type User struct {
   IsActive     bool      `json:"is_active" db:"is_active"`
   CreatedAt    time.Time `json:"created_at" db:"created_at"`
   UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+
+  FailedLoginAttempts int        `json:"-" db:"failed_login_attempts"`
+  LockedUntil         *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+
+  TOTPSecret       *string `json:"-" db:"totp_secret"`
+  TwoFactorEnabled bool    `json:"two_factor_enabled" db:"totp_enabled"`
+  TOTPLastStep     *int64  `json:"-" db:"totp_last_step"`
 }
 
 type UserSession struct {
-  ID           int        `json:"id" db:"id"`
-  UserID       int        `json:"user_id" db:"user_id"`
-  SessionToken string     `json:"session_token" db:"session_token"`
-  LoginAt      time.Time  `json:"login_at" db:"login_at"`
-  LogoutAt     *time.Time `json:"logout_at" db:"logout_at"`
-  IPAddress    *string    `json:"ip_address" db:"ip_address"`
-  IsActive     bool       `json:"is_active" db:"is_active"`
+  ID               int        `json:"id" db:"id"`
+  UserID           int        `json:"user_id" db:"user_id"`
+  SessionToken     string     `json:"-" db:"session_token"`
+  RefreshTokenHash *string    `json:"-" db:"refresh_token_hash"`
+  RefreshExpiresAt *time.Time `json:"refresh_expires_at" db:"refresh_expires_at"`
+  LoginAt          time.Time  `json:"login_at" db:"login_at"`
+  LogoutAt         *time.Time `json:"logout_at" db:"logout_at"`
+  LastSeenAt       *time.Time `json:"last_seen_at" db:"last_seen_at"`
+  IPAddress        *string    `json:"ip_address" db:"ip_address"`
+  UserAgent        *string    `json:"user_agent" db:"user_agent"`
+  IsActive         bool       `json:"is_active" db:"is_active"`
+  RevokedReason    *string    `json:"revoked_reason,omitempty" db:"revoked_reason"`
+
+  // Set by the usecase for the session making the request
+  Current bool `json:"current" db:"-"`
+}
+
+// Login attempt failure reasons
+const (
+  LoginFailureUnknownUser     = "unknown_user"
+  LoginFailureInvalidPassword = "invalid_password"
+  LoginFailureLocked          = "account_locked"
+  LoginFailureThrottled       = "throttled"
+  LoginFailureInvalid2FACode  = "invalid_2fa_code"
+  // The password was right and a code is still owed; never counted
+  LoginFailure2FAPending      = "two_factor_pending"
+)
+
+type LoginAttempt struct {
+  ID            int       `json:"id" db:"id"`
+  Username      string    `json:"username" db:"username"`
+  UserID        *int      `json:"user_id" db:"user_id"`
+  IPAddress     *string   `json:"ip_address" db:"ip_address"`
+  UserAgent     *string   `json:"user_agent" db:"user_agent"`
+  Success       bool      `json:"success" db:"success"`
+  FailureReason *string   `json:"failure_reason" db:"failure_reason"`
+  AttemptedAt   time.Time `json:"attempted_at" db:"attempted_at"`
+}
+
+// LoginFailureStats summarizes counted failures for one key (username or IP).
+type LoginFailureStats struct {
+  Count         int        `db:"count"`
+  LastFailureAt *time.Time `db:"last_failure_at"`
 }
 
 type LoginRequest struct {
-  Username string `json:"username" binding:"required"`
+  Username string `json:"username" binding:"required,max=50"`
   Password string `json:"password" binding:"required"`
 }
 
 type RegisterRequest struct {
   Username string `json:"username" binding:"required,min=3,max=50"`
   Email    string `json:"email" binding:"required,email"`
-  Password string `json:"password" binding:"required,min=6"`
+  Password string `json:"password" binding:"required"`
   FullName string `json:"full_name" binding:"required"`
   Phone    string `json:"phone"`
-  Role     string `json:"role" binding:"required,oneof=admin mechanic cashier"`
+  Role     string `json:"role" binding:"required"`
+}
+
+type RefreshTokenRequest struct {
+  RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
+// LoginResponse carries either a token pair or, when the account has 2FA
+// enabled, only a challenge token to be exchanged at /auth/login/2fa.
 type LoginResponse struct {
-  Token string `json:"token"`
-  User  User   `json:"user"`
+  Token        string `json:"token,omitempty"`
+  RefreshToken string `json:"refresh_token,omitempty"`
+  ExpiresIn    int    `json:"expires_in"`
+  User         *User  `json:"user,omitempty"`
+
+  TwoFactorRequired      bool   `json:"two_factor_required,omitempty"`
+  ChallengeToken         string `json:"challenge_token,omitempty"`
+  TwoFactorSetupRequired bool   `json:"two_factor_setup_required,omitempty"`
+}
+
+type TwoFactorLoginRequest struct {
+  ChallengeToken string `json:"challenge_token" binding:"required"`
+  Code           string `json:"code" binding:"required"` // TOTP code or recovery code
+}
+
+type TwoFactorCodeRequest struct {
+  Code string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+  Password string `json:"password" binding:"required"`
+  Code     string `json:"code" binding:"required"`
+}
+
+type TwoFactorSetupResponse struct {
+  Secret          string `json:"secret"`
+  ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TwoFactorStatus struct {
+  Enabled                bool `json:"enabled"`
+  Required               bool `json:"required"`
+  RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+type ChangePasswordRequest struct {
+  CurrentPassword string `json:"current_password" binding:"required"`
+  NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+  Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+  Token       string `json:"token" binding:"required"`
+  NewPassword string `json:"new_password" binding:"required"`
+}
+
+type PasswordResetToken struct {
+  ID          int        `db:"id"`
+  UserID      int        `db:"user_id"`
+  TokenHash   string     `db:"token_hash"`
+  ExpiresAt   time.Time  `db:"expires_at"`
+  UsedAt      *time.Time `db:"used_at"`
+  RequestedIP *string    `db:"requested_ip"`
+  CreatedAt   time.Time  `db:"created_at"`
+}
+
+// PasswordResetRequestStats summarizes the reset requests for one key (email
+// or IP).
+type PasswordResetRequestStats struct {
+  Count         int        `db:"count"`
+  LastRequestAt *time.Time `db:"last_request_at"`
 }
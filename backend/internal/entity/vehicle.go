@@ -34,6 +34,8 @@ type Vehicle struct {
   UpdatedAt               time.Time  `json:"updated_at" db:"updated_at"`
   PurchaseNotes           *string    `json:"purchase_notes" db:"purchase_notes"`
   ConditionNotes          *string    `json:"condition_notes" db:"condition_notes"`
+  ReservedForCustomerID   *int       `json:"reserved_for_customer_id" db:"reserved_for_customer_id"`
+  ReservedAt              *time.Time `json:"reserved_at" db:"reserved_at"`
   
   // Joined fields
   PurchasedFromCustomer *Customer `json:"purchased_from_customer,omitempty"`
@@ -49,8 +51,8 @@ type CreateVehicleRequest struct {
   Year                    int      `json:"year" binding:"required,min=1900,max=2030"`
   Color                   *string  `json:"color"`
   Mileage                 *int     `json:"mileage"`
-  FuelType                *string  `json:"fuel_type"`
-  Transmission            *string  `json:"transmission"`
+  FuelType                *string  `json:"fuel_type" binding:"omitempty,oneof=gasoline diesel electric hybrid"`
+  Transmission            *string  `json:"transmission" binding:"omitempty,oneof=manual automatic cvt"`
   PurchasePrice           *float64 `json:"purchase_price"`
   PurchasedFromCustomerID *int     `json:"purchased_from_customer_id"`
   PurchaseNotes           *string  `json:"purchase_notes"`
@@ -65,8 +67,8 @@ type UpdateVehicleRequest struct {
   Year                    int      `json:"year" binding:"required,min=1900,max=2030"`
   Color                   *string  `json:"color"`
   Mileage                 *int     `json:"mileage"`
-  FuelType                *string  `json:"fuel_type"`
-  Transmission            *string  `json:"transmission"`
+  FuelType                *string  `json:"fuel_type" binding:"omitempty,oneof=gasoline diesel electric hybrid"`
+  Transmission            *string  `json:"transmission" binding:"omitempty,oneof=manual automatic cvt"`
   SuggestedSellingPrice   *float64 `json:"suggested_selling_price"`
   PurchaseNotes           *string  `json:"purchase_notes"`
   ConditionNotes          *string  `json:"condition_notes"`
@@ -74,11 +76,75 @@ type UpdateVehicleRequest struct {
 
 type UpdateVehicleStatusRequest struct {
   Status string `json:"status" binding:"required,oneof=purchased in_repair ready_to_sell reserved sold"`
+  // CustomerID is who a reserved vehicle is held for; only with reserved
+  CustomerID *int `json:"customer_id"`
+}
+
+type ApproveVehiclePriceRequest struct {
+  ApprovedSellingPrice float64 `json:"approved_selling_price" binding:"required,gt=0"`
 }
 
 type VehicleListResponse struct {
-  Vehicles []Vehicle `json:"vehicles"`
-  Total    int       `json:"total"`
-  Page     int       `json:"page"`
-  Limit    int       `json:"limit"`
+  Vehicles   []Vehicle `json:"vehicles"`
+  Total      *int      `json:"total,omitempty"`
+  HasMore    bool      `json:"has_more"`
+  NextCursor string    `json:"next_cursor,omitempty"`
+  Page       int       `json:"page,omitempty"`
+  Limit      int       `json:"limit"`
+  Facets     *VehicleFacets `json:"facets,omitempty"`
+}
+
+// VehicleFilter narrows the vehicle list. Zero fields do not filter; a
+// list field matches any of its values. Prices compare the approved selling
+// price, or the suggested one until a price is approved. Days in stock run
+// from purchase to sale, or to today for unsold vehicles.
+type VehicleFilter struct {
+  Search         string
+  Status         string
+  Brands         []string
+  Models         []string
+  FuelTypes      []string
+  Transmissions  []string
+  Colors         []string
+  YearMin        *int
+  YearMax        *int
+  PriceMin       *float64
+  PriceMax       *float64
+  MileageMin     *int
+  MileageMax     *int
+  DaysInStockMin *int
+  DaysInStockMax *int
+}
+
+// Validate rejects ranges whose minimum is above their maximum.
+func (f VehicleFilter) Validate() error {
+  var fields []FieldError
+  check := func(field string, inverted bool) {
+    if inverted {
+      fields = append(fields, FieldError{Field: field + "_min", Message: "must not be greater than " + field + "_max"})
+    }
+  }
+  check("year", f.YearMin != nil && f.YearMax != nil && *f.YearMin > *f.YearMax)
+  check("price", f.PriceMin != nil && f.PriceMax != nil && *f.PriceMin > *f.PriceMax)
+  check("mileage", f.MileageMin != nil && f.MileageMax != nil && *f.MileageMin > *f.MileageMax)
+  check("days_in_stock", f.DaysInStockMin != nil && f.DaysInStockMax != nil && *f.DaysInStockMin > *f.DaysInStockMax)
+  if len(fields) > 0 {
+    return NewValidationError("invalid vehicle filter", fields...)
+  }
+  return nil
+}
+
+// FacetCount is the number of vehicles with one value of a facet.
+type FacetCount struct {
+  Value string `json:"value" db:"value"`
+  Count int    `json:"count" db:"count"`
+}
+
+// VehicleFacets count the vehicles matching a filter per brand, fuel type
+// and transmission. Each facet ignores its own filter, so the counts show
+// what selecting another value would add.
+type VehicleFacets struct {
+  Brands        []FacetCount `json:"brands"`
+  FuelTypes     []FacetCount `json:"fuel_types"`
+  Transmissions []FacetCount `json:"transmissions"`
 }
@@ -0,0 +1,218 @@
+// Package events delivers entity.Events to the users connected to the event
+// stream. An event goes to one user or to everyone whose role holds a
+// permission; users who are not connected miss it, so anything they must
+// see later is also stored, as notifications are.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"vehicle-showroom/internal/config"
+	"vehicle-showroom/internal/database"
+	"vehicle-showroom/internal/entity"
+)
+
+// subscriptionBuffer is how many events a connection may fall behind before
+// it is dropped; the client reconnects and catches up from the stored
+// notifications.
+const subscriptionBuffer = 32
+
+// pgChannel is the LISTEN/NOTIFY channel of the postgres driver.
+const pgChannel = "showroom_events"
+
+// Broker publishes events and hands them to subscriptions.
+type Broker interface {
+	Publish(ctx context.Context, event entity.Event) error
+	Subscribe(user *entity.User) *Subscription
+	// Close ends every subscription, so open streams return.
+	Close()
+}
+
+// AllowFunc reports whether role holds permission.
+type AllowFunc func(ctx context.Context, role, permission string) (bool, error)
+
+func New(cfg config.EventsConfig, db *sqlx.DB, dbConfig config.DatabaseConfig, allow AllowFunc) (Broker, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return newHub(allow), nil
+	case "postgres":
+		listener, err := database.NewListener(dbConfig, pgChannel)
+		if err != nil {
+			return nil, err
+		}
+		b := &pgBroker{hub: newHub(allow), db: db, listener: listener, done: make(chan struct{})}
+		go b.relay()
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unknown events driver: %s", cfg.Driver)
+	}
+}
+
+// Subscription receives the events meant for one connected user. Events is
+// closed when the subscription ends: on Close, on Broker.Close, or when the
+// user falls too far behind.
+type Subscription struct {
+	Events <-chan entity.Event
+
+	events chan entity.Event
+	userID int
+	role   string
+	hub    *hub
+}
+
+func (s *Subscription) Close() {
+	s.hub.remove(s)
+}
+
+// hub fans events out to the subscriptions of this process.
+type hub struct {
+	allow AllowFunc
+
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+func newHub(allow AllowFunc) *hub {
+	return &hub{allow: allow, subs: map[*Subscription]struct{}{}}
+}
+
+func (h *hub) Publish(ctx context.Context, event entity.Event) error {
+	h.deliver(ctx, event)
+	return nil
+}
+
+func (h *hub) Subscribe(user *entity.User) *Subscription {
+	events := make(chan entity.Event, subscriptionBuffer)
+	sub := &Subscription{Events: events, events: events, userID: user.ID, role: user.Role, hub: h}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(events)
+		return sub
+	}
+	h.subs[sub] = struct{}{}
+	return sub
+}
+
+func (h *hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		h.drop(sub)
+	}
+	h.closed = true
+}
+
+func (h *hub) remove(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drop(sub)
+}
+
+// drop ends sub; h.mu must be held.
+func (h *hub) drop(sub *Subscription) {
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
+
+// deliver hands event to the subscriptions it is meant for, without
+// blocking on any of them.
+func (h *hub) deliver(ctx context.Context, event entity.Event) {
+	var allowed map[string]bool
+	if event.UserID == 0 && event.Permission != "" {
+		// Look the roles up outside the lock; it may query the database
+		h.mu.Lock()
+		roles := map[string]bool{}
+		for sub := range h.subs {
+			roles[sub.role] = false
+		}
+		h.mu.Unlock()
+
+		for role := range roles {
+			ok, err := h.allow(ctx, role, event.Permission)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to check event permission",
+					slog.String("role", role),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+			roles[role] = ok
+		}
+		allowed = roles
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		if event.UserID != 0 && sub.userID != event.UserID {
+			continue
+		}
+		if allowed != nil && !allowed[sub.role] {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			slog.WarnContext(ctx, "event stream subscriber fell behind, dropping it",
+				slog.Int("user_id", sub.userID),
+				slog.String("event", event.Type),
+			)
+			h.drop(sub)
+		}
+	}
+}
+
+// pgBroker sends events through PostgreSQL NOTIFY, and every server's
+// listener hands them to its own subscriptions, the sender's included.
+type pgBroker struct {
+	*hub
+	db       *sqlx.DB
+	listener *pq.Listener
+	done     chan struct{}
+}
+
+func (b *pgBroker) Publish(ctx context.Context, event entity.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	if _, err := b.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, pgChannel, string(payload)); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+func (b *pgBroker) Close() {
+	b.listener.Close()
+	<-b.done
+	b.hub.Close()
+}
+
+func (b *pgBroker) relay() {
+	defer close(b.done)
+	for n := range b.listener.Notify {
+		if n == nil {
+			// Reconnected; whatever was sent meanwhile is gone
+			continue
+		}
+
+		var event entity.Event
+		if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+			slog.Error("failed to decode event", slog.String("error", err.Error()))
+			continue
+		}
+		b.hub.deliver(context.Background(), event)
+	}
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"io"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Default is the registry served on /metrics.
+var Default = NewRegistry()
+
+// HTTP metrics, recorded by the request middleware
+var (
+	HTTPRequests = Default.NewCounter("http_requests_total",
+		"HTTP requests handled, by method, route and status.", "method", "route", "status")
+	HTTPRequestDuration = Default.NewHistogram("http_request_duration_seconds",
+		"HTTP request latency in seconds, by method, route and status.", DefaultBuckets, "method", "route", "status")
+)
+
+// Business metrics, recorded by the usecases when the event is committed
+var (
+	VehiclesPurchased = Default.NewCounter("showroom_vehicles_purchased_total",
+		"Vehicles bought from customers.")
+	VehiclesSold = Default.NewCounter("showroom_vehicles_sold_total",
+		"Vehicles sold to customers.")
+	RepairsCompleted = Default.NewCounter("showroom_repairs_completed_total",
+		"Repairs marked as completed.")
+	StockOuts = Default.NewCounter("showroom_stock_out_events_total",
+		"Times a spare part's stock dropped to zero.")
+	LoginFailures = Default.NewCounter("showroom_login_failures_total",
+		"Rejected login attempts, by reason.", "reason")
+	TaskReminders = Default.NewCounter("showroom_task_reminders_total",
+		"Task reminders sent to assignees.")
+	TasksOverdue = Default.NewCounter("showroom_tasks_overdue_total",
+		"Tasks marked overdue by the scheduler.")
+)
+
+// RegisterDBStats exports the connection pool statistics of db.
+func RegisterDBStats(db *sqlx.DB) {
+	Default.Register(func() []Sample {
+		stats := db.Stats()
+		return []Sample{
+			{Name: "db_max_open_connections", Type: "gauge", Help: "Maximum number of open connections to the database.", Value: float64(stats.MaxOpenConnections)},
+			{Name: "db_open_connections", Type: "gauge", Help: "Established connections, in use and idle.", Value: float64(stats.OpenConnections)},
+			{Name: "db_in_use_connections", Type: "gauge", Help: "Connections currently in use.", Value: float64(stats.InUse)},
+			{Name: "db_idle_connections", Type: "gauge", Help: "Idle connections.", Value: float64(stats.Idle)},
+			{Name: "db_wait_count_total", Type: "counter", Help: "Connections waited for.", Value: float64(stats.WaitCount)},
+			{Name: "db_wait_duration_seconds_total", Type: "counter", Help: "Total time blocked waiting for a connection.", Value: stats.WaitDuration.Seconds()},
+			{Name: "db_max_idle_closed_total", Type: "counter", Help: "Connections closed due to SetMaxIdleConns.", Value: float64(stats.MaxIdleClosed)},
+			{Name: "db_max_idle_time_closed_total", Type: "counter", Help: "Connections closed due to SetConnMaxIdleTime.", Value: float64(stats.MaxIdleTimeClosed)},
+			{Name: "db_max_lifetime_closed_total", Type: "counter", Help: "Connections closed due to SetConnMaxLifetime.", Value: float64(stats.MaxLifetimeClosed)},
+		}
+	})
+}
+
+// Write writes the default registry.
+func Write(w io.Writer) error {
+	return Default.Write(w)
+}
@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return b.String()
+}
+
+func TestCounterHeaderAndZeroValue(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("jobs_total", "Jobs run.\nBackslash \\ kept.")
+
+	want := "# HELP jobs_total Jobs run.\\nBackslash \\\\ kept.\n" +
+		"# TYPE jobs_total counter\n" +
+		"jobs_total 0\n"
+	if got := render(t, r); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCounterLabelsEscapedAndSorted(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("requests_total", "Requests.", "method", "path")
+	c.Inc("POST", "/b")
+	c.Add(2, "GET", `/a"quoted"`)
+	c.Inc("GET", "/back\\slash\nnewline")
+	c.Inc("POST", "/b")
+
+	want := "# HELP requests_total Requests.\n" +
+		"# TYPE requests_total counter\n" +
+		`requests_total{method="GET",path="/a\"quoted\""} 2` + "\n" +
+		`requests_total{method="GET",path="/back\\slash\nnewline"} 1` + "\n" +
+		`requests_total{method="POST",path="/b"} 2` + "\n"
+	if got := render(t, r); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCounterWrongLabelCountPanics(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("requests_total", "Requests.", "method")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Inc with too many label values did not panic")
+		}
+	}()
+	c.Inc("GET", "/extra")
+}
+
+func TestHistogramCumulativeBuckets(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	h.Observe(0.05, "/b")
+	h.Observe(0.1, "/b") // bucket bounds are inclusive
+	h.Observe(0.5, "/b")
+	h.Observe(3, "/b") // above every bucket, only in +Inf
+	h.Observe(2, "/a")
+
+	want := "# HELP latency_seconds Latency.\n" +
+		"# TYPE latency_seconds histogram\n" +
+		`latency_seconds_bucket{route="/a",le="0.1"} 0` + "\n" +
+		`latency_seconds_bucket{route="/a",le="1"} 0` + "\n" +
+		`latency_seconds_bucket{route="/a",le="+Inf"} 1` + "\n" +
+		`latency_seconds_sum{route="/a"} 2` + "\n" +
+		`latency_seconds_count{route="/a"} 1` + "\n" +
+		`latency_seconds_bucket{route="/b",le="0.1"} 2` + "\n" +
+		`latency_seconds_bucket{route="/b",le="1"} 3` + "\n" +
+		`latency_seconds_bucket{route="/b",le="+Inf"} 4` + "\n" +
+		`latency_seconds_sum{route="/b"} 3.65` + "\n" +
+		`latency_seconds_count{route="/b"} 4` + "\n"
+	if got := render(t, r); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHistogramWithoutLabels(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("size_bytes", "Size.", []float64{10})
+	h.Observe(4)
+
+	want := "# HELP size_bytes Size.\n" +
+		"# TYPE size_bytes histogram\n" +
+		`size_bytes_bucket{le="10"} 1` + "\n" +
+		`size_bytes_bucket{le="+Inf"} 1` + "\n" +
+		"size_bytes_sum 4\n" +
+		"size_bytes_count 1\n"
+	if got := render(t, r); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCollectFuncGauges(t *testing.T) {
+	r := NewRegistry()
+	calls := 0
+	r.Register(func() []Sample {
+		calls++
+		return []Sample{
+			{Name: "pool_connections", Help: "Connections.", Type: "gauge", Labels: map[string]string{"state": "idle", "db": "main"}, Value: 3},
+			{Name: "pool_connections", Help: "Connections.", Type: "gauge", Labels: map[string]string{"state": "in_use", "db": "main"}, Value: float64(calls)},
+			{Name: "pool_max", Help: "Max connections.", Type: "gauge", Value: 25},
+		}
+	})
+
+	want := func(inUse string) string {
+		return "# HELP pool_connections Connections.\n" +
+			"# TYPE pool_connections gauge\n" +
+			`pool_connections{db="main",state="idle"} 3` + "\n" +
+			`pool_connections{db="main",state="in_use"} ` + inUse + "\n" +
+			"# HELP pool_max Max connections.\n" +
+			"# TYPE pool_max gauge\n" +
+			"pool_max 25\n"
+	}
+
+	// Collected again on every scrape
+	if got := render(t, r); got != want("1") {
+		t.Errorf("first scrape got:\n%s\nwant:\n%s", got, want("1"))
+	}
+	if got := render(t, r); got != want("2") {
+		t.Errorf("second scrape got:\n%s\nwant:\n%s", got, want("2"))
+	}
+}
+
+func TestWriteKeepsRegistrationOrder(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("b_total", "B.")
+	r.NewCounter("a_total", "A.")
+
+	got := render(t, r)
+	if strings.Index(got, "b_total") > strings.Index(got, "a_total") {
+		t.Errorf("metrics not in registration order:\n%s", got)
+	}
+}
@@ -0,0 +1,72 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"vehicle-showroom/internal/config"
+)
+
+// Message is a channel-agnostic outbound message.
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Notifier delivers messages to users. Implementations for real channels
+// (SMTP, WhatsApp, ...) can be added without touching the usecases.
+type Notifier interface {
+	Send(msg Message) error
+}
+
+func New(cfg config.NotifierConfig) (Notifier, error) {
+	switch cfg.Driver {
+	case "", "log":
+		return &logNotifier{}, nil
+	case "file":
+		return &fileNotifier{path: cfg.FilePath}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier driver: %s", cfg.Driver)
+	}
+}
+
+// logNotifier writes messages to the server log. Meant for local development.
+type logNotifier struct{}
+
+func (n *logNotifier) Send(msg Message) error {
+	slog.Info("notification", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
+
+// fileNotifier appends one JSON object per message to a file.
+type fileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (n *fileNotifier) Send(msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open notification file: %w", err)
+	}
+	defer f.Close()
+
+	record := struct {
+		SentAt time.Time `json:"sent_at"`
+		Message
+	}{time.Now(), msg}
+
+	if err := json.NewEncoder(f).Encode(record); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+
+	return nil
+}
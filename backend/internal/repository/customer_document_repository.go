@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"vehicle-showroom/internal/entity"
+)
+
+type CustomerDocumentRepository interface {
+	Create(ctx context.Context, document *entity.CustomerDocument) error
+	GetByID(ctx context.Context, customerID, id int) (*entity.CustomerDocument, error)
+	ListByCustomer(ctx context.Context, customerID int) ([]entity.CustomerDocument, error)
+	Verify(ctx context.Context, id, verifiedBy int) error
+	Delete(ctx context.Context, id int) error
+}
+
+type customerDocumentRepository struct {
+	db *sqlx.DB
+}
+
+func NewCustomerDocumentRepository(db *sqlx.DB) CustomerDocumentRepository {
+	return &customerDocumentRepository{db: db}
+}
+
+const customerDocumentColumns = `
+	id, customer_id, type, document_number, file_name, content_type, size_bytes,
+	storage_key, expires_at, verified_by, verified_at, uploaded_by, uploaded_at
+`
+
+func (r *customerDocumentRepository) Create(ctx context.Context, document *entity.CustomerDocument) error {
+	query := `
+		INSERT INTO customer_documents (
+			customer_id, type, document_number, file_name, content_type, size_bytes,
+			storage_key, expires_at, uploaded_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, uploaded_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query,
+		document.CustomerID,
+		document.Type,
+		document.DocumentNumber,
+		document.FileName,
+		document.ContentType,
+		document.SizeBytes,
+		document.StorageKey,
+		document.ExpiresAt,
+		document.UploadedBy,
+	).Scan(&document.ID, &document.UploadedAt)
+	if err != nil {
+		return wrapWriteError("create customer document", err)
+	}
+
+	return nil
+}
+
+// GetByID only finds the document among customerID's, so a document id
+// cannot be read through another customer's URL.
+func (r *customerDocumentRepository) GetByID(ctx context.Context, customerID, id int) (*entity.CustomerDocument, error) {
+	document := &entity.CustomerDocument{}
+	query := `SELECT ` + customerDocumentColumns + ` FROM customer_documents WHERE id = $1 AND customer_id = $2`
+
+	err := r.db.GetContext(ctx, document, query, id, customerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get customer document by id: %w", err)
+	}
+
+	return document, nil
+}
+
+func (r *customerDocumentRepository) ListByCustomer(ctx context.Context, customerID int) ([]entity.CustomerDocument, error) {
+	query := `
+		SELECT ` + customerDocumentColumns + `
+		FROM customer_documents
+		WHERE customer_id = $1
+		ORDER BY uploaded_at DESC, id DESC
+	`
+
+	documents := []entity.CustomerDocument{}
+	if err := r.db.SelectContext(ctx, &documents, query, customerID); err != nil {
+		return nil, fmt.Errorf("failed to list customer documents: %w", err)
+	}
+
+	return documents, nil
+}
+
+func (r *customerDocumentRepository) Verify(ctx context.Context, id, verifiedBy int) error {
+	query := `UPDATE customer_documents SET verified_by = $1, verified_at = CURRENT_TIMESTAMP WHERE id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, verifiedBy, id); err != nil {
+		return wrapWriteError("verify customer document", err)
+	}
+
+	return nil
+}
+
+func (r *customerDocumentRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM customer_documents WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete customer document: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"vehicle-showroom/internal/entity"
+)
+
+// duplicateNameSimilarity is the pg_trgm similarity from which two names
+// count as the same when the phone or email matches too. Spelling variants
+// such as "Sutisna"/"Sutisno" score about 0.6.
+const duplicateNameSimilarity = 0.5
+
+// Identifiers are compared normalized: ID card numbers without separators
+// and in upper case, phone numbers as digits with +62 read as 0, and emails
+// trimmed and in lower case. The candidate's values in $2..$4 go through the
+// same expressions as the stored ones.
+const findDuplicatesQuery = `
+	SELECT * FROM (
+		SELECT c.id, c.customer_code, c.name, c.phone, c.email, c.address, c.id_card_number, c.type,
+		       c.created_at, c.updated_at, c.created_by, c.is_active,
+		       COALESCE(NULLIF(UPPER(regexp_replace(c.id_card_number, '[^0-9A-Za-z]', '', 'g')), '')
+		         = NULLIF(UPPER(regexp_replace($2, '[^0-9A-Za-z]', '', 'g')), ''), false) AS id_card_match,
+		       COALESCE(NULLIF(regexp_replace(regexp_replace(c.phone, '\D', '', 'g'), '^62', '0'), '')
+		         = NULLIF(regexp_replace(regexp_replace($3, '\D', '', 'g'), '^62', '0'), ''), false) AS phone_match,
+		       COALESCE(NULLIF(LOWER(TRIM(c.email)), '') = NULLIF(LOWER(TRIM($4)), ''), false) AS email_match,
+		       similarity(c.name, $1)::float8 AS name_similarity
+		FROM customers c
+		WHERE c.is_active = true AND c.id <> $5
+	) m
+	WHERE id_card_match OR (name_similarity >= $6 AND (phone_match OR email_match))
+	ORDER BY id_card_match DESC, name_similarity DESC, id
+	LIMIT 10
+`
+
+type duplicateRow struct {
+	entity.Customer
+	IDCardMatch    bool    `db:"id_card_match"`
+	PhoneMatch     bool    `db:"phone_match"`
+	EmailMatch     bool    `db:"email_match"`
+	NameSimilarity float64 `db:"name_similarity"`
+}
+
+// FindDuplicates lists active customers that may be the same person as
+// candidate, strongest match first. candidate.ID is left out, so an
+// existing customer can be checked against the others.
+func (r *customerRepository) FindDuplicates(ctx context.Context, candidate *entity.Customer) ([]entity.CustomerDuplicate, error) {
+	var rows []duplicateRow
+	err := r.db.SelectContext(ctx, &rows, findDuplicatesQuery,
+		candidate.Name, candidate.IDCardNumber, candidate.Phone, candidate.Email, candidate.ID, duplicateNameSimilarity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate customers: %w", err)
+	}
+
+	duplicates := make([]entity.CustomerDuplicate, len(rows))
+	for i, row := range rows {
+		duplicates[i] = entity.CustomerDuplicate{Customer: row.Customer, Reasons: []string{}, NameSimilarity: row.NameSimilarity}
+		if row.IDCardMatch {
+			duplicates[i].Reasons = append(duplicates[i].Reasons, entity.DuplicateIDCard)
+		}
+		if row.NameSimilarity >= duplicateNameSimilarity {
+			if row.PhoneMatch {
+				duplicates[i].Reasons = append(duplicates[i].Reasons, entity.DuplicatePhone)
+			}
+			if row.EmailMatch {
+				duplicates[i].Reasons = append(duplicates[i].Reasons, entity.DuplicateEmail)
+			}
+		}
+	}
+	return duplicates, nil
+}
+
+// Merge moves everything that refers to mergedID over to survivorID,
+// fills the survivor's empty contact fields from the merged customer,
+// deactivates the merged customer and records the merge, all in one
+// transaction. Both customers must be active.
+func (r *customerRepository) Merge(ctx context.Context, survivorID, mergedID, mergedBy int) (*entity.CustomerMerge, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	// Locked in id order so that two merges of the same pair cannot deadlock
+	var active int
+	err = tx.GetContext(ctx, &active, `
+		SELECT COUNT(*) FROM (
+			SELECT id FROM customers WHERE id IN ($1, $2) AND is_active = true ORDER BY id FOR UPDATE
+		) locked
+	`, survivorID, mergedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock customers: %w", err)
+	}
+	if active != 2 {
+		return nil, entity.NewNotFoundError("customer")
+	}
+
+	merge := &entity.CustomerMerge{SurvivingCustomerID: survivorID, MergedCustomerID: mergedID, MergedBy: &mergedBy}
+
+	// Snapshot before anything changes
+	err = tx.GetContext(ctx, &merge.MergedCustomer, `SELECT to_jsonb(c) FROM customers c WHERE id = $1`, mergedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot customer: %w", err)
+	}
+
+	moves := []struct {
+		query string
+		count *int
+	}{
+		{`UPDATE vehicles SET purchased_from_customer_id = $1 WHERE purchased_from_customer_id = $2`, &merge.VehiclesMoved},
+		{`UPDATE vehicles SET sold_to_customer_id = $1 WHERE sold_to_customer_id = $2`, &merge.VehiclesMoved},
+		{`UPDATE vehicles SET reserved_for_customer_id = $1 WHERE reserved_for_customer_id = $2`, &merge.VehiclesMoved},
+		{`UPDATE purchase_transactions SET customer_id = $1 WHERE customer_id = $2`, &merge.PurchasesMoved},
+		{`UPDATE sales_transactions SET customer_id = $1 WHERE customer_id = $2`, &merge.SalesMoved},
+		{`UPDATE customer_notes SET customer_id = $1 WHERE customer_id = $2`, &merge.NotesMoved},
+		{`UPDATE customer_documents SET customer_id = $1 WHERE customer_id = $2`, &merge.DocumentsMoved},
+		{`UPDATE leads SET customer_id = $1 WHERE customer_id = $2`, &merge.LeadsMoved},
+		{`UPDATE customer_consents SET customer_id = $1 WHERE customer_id = $2`, &merge.ConsentsMoved},
+		{`UPDATE tasks SET entity_id = $1 WHERE entity_type = 'customer' AND entity_id = $2`, &merge.TasksMoved},
+	}
+	for _, move := range moves {
+		if err := execCount(ctx, tx, move.count, move.query, survivorID, mergedID); err != nil {
+			return nil, fmt.Errorf("failed to move customer records: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE customers s
+		SET phone = COALESCE(NULLIF(s.phone, ''), m.phone),
+		    email = COALESCE(NULLIF(s.email, ''), m.email),
+		    address = COALESCE(NULLIF(s.address, ''), m.address),
+		    id_card_number = COALESCE(NULLIF(s.id_card_number, ''), m.id_card_number),
+		    updated_at = CURRENT_TIMESTAMP
+		FROM customers m
+		WHERE s.id = $1 AND m.id = $2
+	`, survivorID, mergedID)
+	if err != nil {
+		return nil, wrapWriteError("update surviving customer", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE customers SET is_active = false, updated_at = CURRENT_TIMESTAMP WHERE id = $1`, mergedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deactivate merged customer: %w", err)
+	}
+
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO customer_merges (
+			surviving_customer_id, merged_customer_id, merged_customer,
+			vehicles_moved, purchases_moved, sales_moved, notes_moved, documents_moved, leads_moved, consents_moved,
+			tasks_moved, merged_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, merged_at
+	`, survivorID, mergedID, []byte(merge.MergedCustomer),
+		merge.VehiclesMoved, merge.PurchasesMoved, merge.SalesMoved, merge.NotesMoved, merge.DocumentsMoved, merge.LeadsMoved,
+		merge.ConsentsMoved, merge.TasksMoved, mergedBy,
+	).Scan(&merge.ID, &merge.MergedAt)
+	if err != nil {
+		return nil, wrapWriteError("record customer merge", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit customer merge: %w", err)
+	}
+	return merge, nil
+}
+
+// ListMerges lists the customers merged into customerID, latest first.
+func (r *customerRepository) ListMerges(ctx context.Context, customerID int) ([]entity.CustomerMerge, error) {
+	query := `
+		SELECT id, surviving_customer_id, merged_customer_id, merged_customer,
+		       vehicles_moved, purchases_moved, sales_moved, notes_moved, documents_moved, leads_moved,
+		       consents_moved, tasks_moved, merged_by, merged_at
+		FROM customer_merges
+		WHERE surviving_customer_id = $1
+		ORDER BY merged_at DESC, id DESC
+	`
+	merges := []entity.CustomerMerge{}
+	if err := r.db.SelectContext(ctx, &merges, query, customerID); err != nil {
+		return nil, fmt.Errorf("failed to list customer merges: %w", err)
+	}
+	return merges, nil
+}
+
+// execCount runs query and adds the number of rows it touched to count.
+func execCount(ctx context.Context, tx *sqlx.Tx, count *int, query string, args ...interface{}) error {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	*count += int(n)
+	return nil
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"vehicle-showroom/internal/entity"
+)
+
+// anonymizedCustomersQuery finds the customer in $1 and every customer
+// merged into it, directly or through an earlier merge: their personal data
+// lives on in the survivor and must go with it.
+const anonymizedCustomersQuery = `
+	WITH RECURSIVE merged(id) AS (
+		SELECT $1::int
+		UNION
+		SELECT cm.merged_customer_id FROM customer_merges cm JOIN merged ON cm.surviving_customer_id = merged.id
+	)
+	SELECT id FROM merged ORDER BY id
+`
+
+// GetForPrivacy gets the customer whether active or not: a deleted customer
+// is only deactivated, and their data can still be exported and scrubbed.
+func (r *customerRepository) GetForPrivacy(ctx context.Context, id int) (*entity.Customer, error) {
+	customer := &entity.Customer{}
+	query := `
+		SELECT id, customer_code, name, phone, email, address, id_card_number, type,
+		       created_at, updated_at, created_by, is_active, anonymized_at
+		FROM customers
+		WHERE id = $1
+	`
+	if err := r.db.GetContext(ctx, customer, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get customer by id: %w", err)
+	}
+	return customer, nil
+}
+
+func (r *customerRepository) RecordConsent(ctx context.Context, consent *entity.CustomerConsent) error {
+	query := `
+		INSERT INTO customer_consents (customer_id, channel, granted, source, recorded_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, recorded_at
+	`
+	err := r.db.QueryRowxContext(ctx, query,
+		consent.CustomerID, consent.Channel, consent.Granted, consent.Source, consent.RecordedBy,
+	).Scan(&consent.ID, &consent.RecordedAt)
+	if err != nil {
+		return wrapWriteError("record customer consent", err)
+	}
+	return nil
+}
+
+// ListConsents lists the customer's consent history, latest first.
+func (r *customerRepository) ListConsents(ctx context.Context, customerID int) ([]entity.CustomerConsent, error) {
+	query := `
+		SELECT id, customer_id, channel, granted, source, recorded_by, recorded_at
+		FROM customer_consents
+		WHERE customer_id = $1
+		ORDER BY recorded_at DESC, id DESC
+	`
+	consents := []entity.CustomerConsent{}
+	if err := r.db.SelectContext(ctx, &consents, query, customerID); err != nil {
+		return nil, fmt.Errorf("failed to list customer consents: %w", err)
+	}
+	return consents, nil
+}
+
+// PurchasesByCustomer lists the purchase transactions in which the
+// showroom bought from the customer, with their vehicles, latest first.
+func (r *customerRepository) PurchasesByCustomer(ctx context.Context, customerID int) ([]entity.PurchaseTransaction, error) {
+	query := `
+		SELECT id, transaction_number, invoice_number, vehicle_id, customer_id,
+		       vehicle_price, tax_amount, total_amount, payment_method,
+		       payment_reference, transaction_date, cashier_id, status,
+		       notes, created_at
+		FROM purchase_transactions
+		WHERE customer_id = $1
+		ORDER BY transaction_date DESC, id DESC
+	`
+	purchases := []entity.PurchaseTransaction{}
+	if err := r.db.SelectContext(ctx, &purchases, query, customerID); err != nil {
+		return nil, fmt.Errorf("failed to list purchases from customer: %w", err)
+	}
+
+	vehicleIDs := make([]int, len(purchases))
+	for i, purchase := range purchases {
+		vehicleIDs[i] = purchase.VehicleID
+	}
+	vehicles, err := loadByID(ctx, r.db, relatedVehicleQuery, vehicleIDs, func(v *entity.Vehicle) int { return v.ID })
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vehicles: %w", err)
+	}
+	for i := range purchases {
+		purchases[i].Vehicle = vehicles[purchases[i].VehicleID]
+	}
+	return purchases, nil
+}
+
+// Anonymize scrubs the personal data of the customer and of the customers
+// merged into it, in one transaction. The customer rows, codes and
+// transactions stay so the books still balance; notes, documents, open
+// reservations, the contact details in merge snapshots and converted leads,
+// and the text of tasks about them go. Consent history holds no personal data and is kept as evidence.
+// The customer may already be deactivated, as deleting only does that, but
+// must not be anonymized already; it ends up deactivated. The returned
+// StorageKeys are the document files still to be removed.
+func (r *customerRepository) Anonymize(ctx context.Context, id, anonymizedBy int) (*entity.CustomerAnonymization, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	var anonymizedAt *time.Time
+	err = tx.GetContext(ctx, &anonymizedAt, `SELECT anonymized_at FROM customers WHERE id = $1 FOR UPDATE`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.NewNotFoundError("customer")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock customer: %w", err)
+	}
+	if anonymizedAt != nil {
+		return nil, entity.NewConflictError("customer has already been anonymized")
+	}
+
+	result := &entity.CustomerAnonymization{CustomerID: id, CustomerIDs: []int{}, StorageKeys: []string{}}
+	if err := tx.SelectContext(ctx, &result.CustomerIDs, anonymizedCustomersQuery, id); err != nil {
+		return nil, fmt.Errorf("failed to find merged customers: %w", err)
+	}
+	ids := pq.Array(result.CustomerIDs)
+
+	err = tx.GetContext(ctx, &result.AnonymizedAt, `
+		UPDATE customers
+		SET name = $1, phone = NULL, email = NULL, address = NULL, id_card_number = NULL,
+		    is_active = false, anonymized_at = CURRENT_TIMESTAMP, anonymized_by = $2,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = ANY($3)
+		RETURNING anonymized_at
+	`, entity.AnonymizedCustomerName, anonymizedBy, ids)
+	if err != nil {
+		return nil, wrapWriteError("anonymize customer", err)
+	}
+
+	if err := tx.SelectContext(ctx, &result.StorageKeys,
+		`DELETE FROM customer_documents WHERE customer_id = ANY($1) RETURNING storage_key`, ids); err != nil {
+		return nil, fmt.Errorf("failed to delete customer documents: %w", err)
+	}
+	result.DocumentsDeleted = len(result.StorageKeys)
+
+	if err := execCount(ctx, tx, &result.NotesDeleted,
+		`DELETE FROM customer_notes WHERE customer_id = ANY($1)`, ids); err != nil {
+		return nil, fmt.Errorf("failed to delete customer notes: %w", err)
+	}
+
+	// A reserved vehicle goes back on sale; the hold was for a person who
+	// is no longer on record
+	if err := execCount(ctx, tx, &result.ReservationsEnded, `
+		UPDATE vehicles
+		SET status = CASE WHEN status = 'reserved' THEN 'ready_to_sell' ELSE status END,
+		    reserved_for_customer_id = NULL, reserved_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE reserved_for_customer_id = ANY($1)
+	`, ids); err != nil {
+		return nil, fmt.Errorf("failed to end reservations: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE leads
+		SET name = $1, phone = NULL, email = NULL, notes = NULL, lost_reason = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE customer_id = ANY($2)
+	`, entity.AnonymizedCustomerName, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scrub converted leads: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE customer_merges
+		SET merged_customer = (merged_customer - 'phone' - 'email' - 'address' - 'id_card_number')
+		    || jsonb_build_object('name', $1::text)
+		WHERE surviving_customer_id = ANY($2) OR merged_customer_id = ANY($2)
+	`, entity.AnonymizedCustomerName, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scrub merge records: %w", err)
+	}
+
+	// Task titles and descriptions are free text that may name the customer;
+	// the tasks stay linked so the follow-up history is not lost
+	if err := execCount(ctx, tx, &result.TasksScrubbed, `
+		UPDATE tasks
+		SET title = $1, description = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE (entity_type = 'customer' AND entity_id = ANY($2))
+		   OR (entity_type = 'lead' AND entity_id IN (SELECT id FROM leads WHERE customer_id = ANY($2)))
+	`, entity.AnonymizedTaskTitle, ids); err != nil {
+		return nil, fmt.Errorf("failed to scrub customer tasks: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit customer anonymization: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"vehicle-showroom/internal/entity"
+)
+
+// Deleting a customer only deactivates it; its personal data must still be
+// reachable for export and anonymization.
+func TestAnonymizeDeletedCustomer(t *testing.T) {
+	db, _ := openCountingTestDB(t)
+	repo := NewCustomerRepository(db)
+	ctx := context.Background()
+
+	var userID int
+	if err := db.Get(&userID, `SELECT MIN(id) FROM users`); err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := repo.GenerateCustomerCode(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	phone := "081234567890"
+	customer := &entity.Customer{CustomerCode: code, Name: "Siti Rahma", Phone: &phone, Type: "individual", IsActive: true}
+	if err := repo.Create(ctx, customer); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, customer.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := repo.GetForPrivacy(ctx, customer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == nil || found.IsActive || found.Name != "Siti Rahma" {
+		t.Fatalf("GetForPrivacy = %+v, want the deleted customer", found)
+	}
+
+	if _, err := repo.Anonymize(ctx, customer.ID, userID); err != nil {
+		t.Fatalf("Anonymize deleted customer: %v", err)
+	}
+	found, err = repo.GetForPrivacy(ctx, customer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.AnonymizedAt == nil || found.Phone != nil || found.Name != entity.AnonymizedCustomerName {
+		t.Errorf("after Anonymize = %+v", found)
+	}
+
+	if _, err := repo.Anonymize(ctx, customer.ID, userID); !errors.Is(err, entity.ErrConflict) {
+		t.Errorf("second Anonymize err = %v, want a conflict", err)
+	}
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"vehicle-showroom/internal/entity"
+)
+
+// The customer profile gathers a customer's history from the vehicles,
+// transactions and notes tables. A customer's history is short, so these
+// lists are not paged.
+
+const (
+	profileVehicleColumns = `
+		id, vehicle_code, chassis_number, license_plate, brand, model, variant,
+		year, color, mileage, fuel_type, transmission, purchase_price,
+		total_repair_cost, suggested_selling_price, approved_selling_price,
+		final_selling_price, status, purchased_from_customer_id, sold_to_customer_id,
+		purchased_by_cashier, sold_by_cashier, price_approved_by_admin,
+		purchased_at, sold_at, created_at, updated_at, purchase_notes, condition_notes,
+		reserved_for_customer_id, reserved_at
+	`
+	// Gross profit follows the profitability report, with the price taken
+	// from the sale itself
+	customerStatsQuery = `
+		SELECT
+			COALESCE((SELECT SUM(total_amount) FROM sales_transactions
+				WHERE customer_id = $1 AND status = 'completed'), 0) AS total_spent,
+			COALESCE((SELECT SUM(total_amount) FROM purchase_transactions
+				WHERE customer_id = $1 AND status = 'completed'), 0) AS total_paid_to_customer,
+			COALESCE((SELECT SUM(total_amount) FROM sales_transactions
+				WHERE customer_id = $1 AND status = 'completed' AND payment_method = 'credit'), 0) AS outstanding_credit,
+			COALESCE((SELECT SUM(st.vehicle_price - st.discount_amount - COALESCE(v.purchase_price, 0) - v.total_repair_cost)
+				FROM sales_transactions st JOIN vehicles v ON v.id = st.vehicle_id
+				WHERE st.customer_id = $1 AND st.status = 'completed'), 0) AS lifetime_value,
+			GREATEST(
+				(SELECT MAX(transaction_date) FROM sales_transactions WHERE customer_id = $1),
+				(SELECT MAX(transaction_date) FROM purchase_transactions WHERE customer_id = $1),
+				(SELECT MAX(created_at) FROM customer_notes WHERE customer_id = $1),
+				(SELECT MAX(reserved_at) FROM vehicles WHERE reserved_for_customer_id = $1)
+			) AS last_interaction_at
+	`
+)
+
+// VehiclesSoldToUs lists the vehicles bought from the customer, latest
+// first.
+func (r *customerRepository) VehiclesSoldToUs(ctx context.Context, customerID int) ([]entity.Vehicle, error) {
+	query := `SELECT ` + profileVehicleColumns + `
+		FROM vehicles
+		WHERE purchased_from_customer_id = $1
+		ORDER BY COALESCE(purchased_at, created_at) DESC, id DESC
+	`
+	vehicles := []entity.Vehicle{}
+	if err := r.db.SelectContext(ctx, &vehicles, query, customerID); err != nil {
+		return nil, fmt.Errorf("failed to list vehicles sold by customer: %w", err)
+	}
+	return vehicles, nil
+}
+
+// ReservedVehicles lists the vehicles currently held for the customer.
+func (r *customerRepository) ReservedVehicles(ctx context.Context, customerID int) ([]entity.Vehicle, error) {
+	query := `SELECT ` + profileVehicleColumns + `
+		FROM vehicles
+		WHERE reserved_for_customer_id = $1 AND status = 'reserved'
+		ORDER BY reserved_at DESC, id DESC
+	`
+	vehicles := []entity.Vehicle{}
+	if err := r.db.SelectContext(ctx, &vehicles, query, customerID); err != nil {
+		return nil, fmt.Errorf("failed to list vehicles reserved for customer: %w", err)
+	}
+	return vehicles, nil
+}
+
+// SalesByCustomer lists the customer's sales transactions with their
+// vehicles, latest first.
+func (r *customerRepository) SalesByCustomer(ctx context.Context, customerID int) ([]entity.SalesTransaction, error) {
+	query := `
+		SELECT id, transaction_number, invoice_number, vehicle_id, customer_id,
+		       vehicle_price, tax_amount, discount_amount, total_amount,
+		       payment_method, payment_reference, transaction_date, cashier_id,
+		       status, notes, created_at
+		FROM sales_transactions
+		WHERE customer_id = $1
+		ORDER BY transaction_date DESC, id DESC
+	`
+	sales := []entity.SalesTransaction{}
+	if err := r.db.SelectContext(ctx, &sales, query, customerID); err != nil {
+		return nil, fmt.Errorf("failed to list sales to customer: %w", err)
+	}
+
+	vehicleIDs := make([]int, len(sales))
+	for i, sale := range sales {
+		vehicleIDs[i] = sale.VehicleID
+	}
+	vehicles, err := loadByID(ctx, r.db, relatedVehicleQuery, vehicleIDs, func(v *entity.Vehicle) int { return v.ID })
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vehicles: %w", err)
+	}
+	for i := range sales {
+		sales[i].Vehicle = vehicles[sales[i].VehicleID]
+	}
+	return sales, nil
+}
+
+// Stats sums up the customer's transactions; see entity.CustomerStats.
+func (r *customerRepository) Stats(ctx context.Context, customerID int) (*entity.CustomerStats, error) {
+	stats := &entity.CustomerStats{}
+	if err := r.db.GetContext(ctx, stats, customerStatsQuery, customerID); err != nil {
+		return nil, fmt.Errorf("failed to get customer stats: %w", err)
+	}
+	return stats, nil
+}
+
+// ListNotes lists the notes on the customer, latest first.
+func (r *customerRepository) ListNotes(ctx context.Context, customerID int) ([]entity.CustomerNote, error) {
+	query := `
+		SELECT n.id, n.customer_id, n.note, n.created_by, u.full_name AS author_name, n.created_at
+		FROM customer_notes n
+		LEFT JOIN users u ON u.id = n.created_by
+		WHERE n.customer_id = $1
+		ORDER BY n.created_at DESC, n.id DESC
+	`
+	notes := []entity.CustomerNote{}
+	if err := r.db.SelectContext(ctx, &notes, query, customerID); err != nil {
+		return nil, fmt.Errorf("failed to list customer notes: %w", err)
+	}
+	return notes, nil
+}
+
+func (r *customerRepository) AddNote(ctx context.Context, note *entity.CustomerNote) error {
+	query := `
+		INSERT INTO customer_notes (customer_id, note, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowxContext(ctx, query, note.CustomerID, note.Note, note.CreatedBy).
+		Scan(&note.ID, &note.CreatedAt)
+	if err != nil {
+		return wrapWriteError("add customer note", err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+  "context"
   "database/sql"
   "fmt"
   "strings"
@@ -10,13 +11,27 @@ import (
 )
 
 type CustomerRepository interface {
-  Create(customer *entity.Customer) error
-  GetByID(id int) (*entity.Customer, error)
-  GetByCode(code string) (*entity.Customer, error)
-  List(page, limit int, search string) ([]entity.Customer, int, error)
-  Update(customer *entity.Customer) error
-  Delete(id int) error
-  GenerateCustomerCode() (string, error)
+  Create(ctx context.Context, customer *entity.Customer) error
+  GetByID(ctx context.Context, id int) (*entity.Customer, error)
+  GetForPrivacy(ctx context.Context, id int) (*entity.Customer, error)
+  GetByCode(ctx context.Context, code string) (*entity.Customer, error)
+  List(ctx context.Context, params entity.ListParams, search string) ([]entity.Customer, *entity.PageInfo, error)
+  Update(ctx context.Context, customer *entity.Customer) error
+  Delete(ctx context.Context, id int) error
+  GenerateCustomerCode(ctx context.Context) (string, error)
+  VehiclesSoldToUs(ctx context.Context, customerID int) ([]entity.Vehicle, error)
+  ReservedVehicles(ctx context.Context, customerID int) ([]entity.Vehicle, error)
+  SalesByCustomer(ctx context.Context, customerID int) ([]entity.SalesTransaction, error)
+  Stats(ctx context.Context, customerID int) (*entity.CustomerStats, error)
+  ListNotes(ctx context.Context, customerID int) ([]entity.CustomerNote, error)
+  AddNote(ctx context.Context, note *entity.CustomerNote) error
+  FindDuplicates(ctx context.Context, candidate *entity.Customer) ([]entity.CustomerDuplicate, error)
+  Merge(ctx context.Context, survivorID, mergedID, mergedBy int) (*entity.CustomerMerge, error)
+  ListMerges(ctx context.Context, customerID int) ([]entity.CustomerMerge, error)
+  PurchasesByCustomer(ctx context.Context, customerID int) ([]entity.PurchaseTransaction, error)
+  RecordConsent(ctx context.Context, consent *entity.CustomerConsent) error
+  ListConsents(ctx context.Context, customerID int) ([]entity.CustomerConsent, error)
+  Anonymize(ctx context.Context, id, anonymizedBy int) (*entity.CustomerAnonymization, error)
 }
 
 type customerRepository struct {
@@ -27,14 +42,18 @@ func NewCustomerRepository(db *sqlx.DB) CustomerRepository {
   return &customerRepository{db: db}
 }
 
-func (r *customerRepository) Create(customer *entity.Customer) error {
+func (r *customerRepository) Create(ctx context.Context, customer *entity.Customer) error {
+  return insertCustomer(ctx, r.db, customer)
+}
+
+func insertCustomer(ctx context.Context, q sqlx.QueryerContext, customer *entity.Customer) error {
   query := `
     INSERT INTO customers (customer_code, name, phone, email, address, id_card_number, type, created_by, is_active)
     VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
     RETURNING id, created_at, updated_at
   `
   
-  err := r.db.QueryRow(
+  err := q.QueryRowxContext(ctx,
     query,
     customer.CustomerCode,
     customer.Name,
@@ -48,13 +67,13 @@ func (r *customerRepository) Create(customer *entity.Customer) error {
   ).Scan(&customer.ID, &customer.CreatedAt, &customer.UpdatedAt)
   
   if err != nil {
-    return fmt.Errorf("failed to create customer: %w", err)
+    return wrapWriteError("create customer", err)
   }
   
   return nil
 }
 
-func (r *customerRepository) GetByID(id int) (*entity.Customer, error) {
+func (r *customerRepository) GetByID(ctx context.Context, id int) (*entity.Customer, error) {
   customer := &entity.Customer{}
   query := `
     SELECT id, customer_code, name, phone, email, address, id_card_number, type, 
@@ -63,7 +82,7 @@ func (r *customerRepository) GetByID(id int) (*entity.Customer, error) {
     WHERE id = $1 AND is_active = true
   `
   
-  err := r.db.Get(customer, query, id)
+  err := r.db.GetContext(ctx, customer, query, id)
   if err != nil {
     if err == sql.ErrNoRows {
       return nil, nil
@@ -74,7 +93,7 @@ func (r *customerRepository) GetByID(id int) (*entity.Customer, error) {
   return customer, nil
 }
 
-func (r *customerRepository) GetByCode(code string) (*entity.Customer, error) {
+func (r *customerRepository) GetByCode(ctx context.Context, code string) (*entity.Customer, error) {
   customer := &entity.Customer{}
   query := `
     SELECT id, customer_code, name, phone, email, address, id_card_number, type, 
@@ -83,7 +102,7 @@ func (r *customerRepository) GetByCode(code string) (*entity.Customer, error) {
     WHERE customer_code = $1 AND is_active = true
   `
   
-  err := r.db.Get(customer, query, code)
+  err := r.db.GetContext(ctx, customer, query, code)
   if err != nil {
     if err == sql.ErrNoRows {
       return nil, nil
@@ -94,51 +113,66 @@ func (r *customerRepository) GetByCode(code string) (*entity.Customer, error) {
   return customer, nil
 }
 
-func (r *customerRepository) List(page, limit int, search string) ([]entity.Customer, int, error) {
-  offset := (page - 1) * limit
-  
+var customerSort = listSort[entity.Customer]{
+  keys: map[string]sortKey[entity.Customer]{
+    "created_at":    {"created_at", func(c *entity.Customer) interface{} { return c.CreatedAt }},
+    "name":          {"name", func(c *entity.Customer) interface{} { return c.Name }},
+    "customer_code": {"customer_code", func(c *entity.Customer) interface{} { return c.CustomerCode }},
+  },
+  defaultSort: []entity.SortField{{Field: "created_at", Desc: true}},
+  idExpr:      "id",
+  id:          func(c *entity.Customer) int { return c.ID },
+}
+
+func (r *customerRepository) List(ctx context.Context, params entity.ListParams, search string) ([]entity.Customer, *entity.PageInfo, error) {
   whereClause := "WHERE is_active = true"
   args := []interface{}{}
   argIndex := 1
   
   if search != "" {
     whereClause += fmt.Sprintf(" AND (name ILIKE $%d OR customer_code ILIKE $%d OR phone ILIKE $%d OR email ILIKE $%d)", 
-                              argIndex, argIndex+1, argIndex+2, argIndex+3)
+      argIndex, argIndex+1, argIndex+2, argIndex+3)
     searchPattern := "%" + search + "%"
     args = append(args, searchPattern, searchPattern, searchPattern, searchPattern)
     argIndex += 4
   }
   
-  // Get total count
-  countQuery := fmt.Sprintf("SELECT COUNT(*) FROM customers %s", whereClause)
-  var total int
-  err := r.db.Get(&total, countQuery, args...)
+  plan, err := customerSort.plan(params, argIndex)
   if err != nil {
-    return nil, 0, fmt.Errorf("failed to get customer count: %w", err)
+    return nil, nil, err
+  }
+  
+  total, err := countTotal(params, func() (int, error) {
+    var total int
+    countQuery := fmt.Sprintf("SELECT COUNT(*) FROM customers %s", whereClause)
+    if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+      return 0, fmt.Errorf("failed to get customer count: %w", err)
+    }
+    return total, nil
+  })
+  if err != nil {
+    return nil, nil, err
   }
   
-  // Get customers
   query := fmt.Sprintf(`
     SELECT id, customer_code, name, phone, email, address, id_card_number, type, 
            created_at, updated_at, created_by, is_active
     FROM customers
+    %s%s
     %s
-    ORDER BY created_at DESC
-    LIMIT $%d OFFSET $%d
-  `, whereClause, argIndex, argIndex+1)
-  
-  args = append(args, limit, offset)
+    %s
+  `, whereClause, plan.keyset, plan.orderBy, plan.limit)
   
   var customers []entity.Customer
-  err = r.db.Select(&customers, query, args...)
+  err = r.db.SelectContext(ctx, &customers, query, append(args, plan.args...)...)
   if err != nil {
-    return nil, 0, fmt.Errorf("failed to list customers: %w", err)
+    return nil, nil, fmt.Errorf("failed to list customers: %w", err)
   }
   
-  return customers, total, nil
+  return customerSort.page(customers, plan, total)
 }
 
-func (r *customerRepository) Update(customer *entity.Customer) error {
+func (r *customerRepository) Update(ctx context.Context, customer *entity.Customer) error {
   query := `
     UPDATE customers
     SET name = $1, phone = $2, email = $3, address = $4, id_card_number = $5, 
@@ -146,19 +180,19 @@ func (r *customerRepository) Update(customer *entity.Customer) error {
     WHERE id = $7
   `
   
-  _, err := r.db.Exec(query, customer.Name, customer.Phone, customer.Email, 
+  _, err := r.db.ExecContext(ctx, query, customer.Name, customer.Phone, customer.Email, 
                      customer.Address, customer.IDCardNumber, customer.Type, customer.ID)
   if err != nil {
-    return fmt.Errorf("failed to update customer: %w", err)
+    return wrapWriteError("update customer", err)
   }
   
   return nil
 }
 
-func (r *customerRepository) Delete(id int) error {
+func (r *customerRepository) Delete(ctx context.Context, id int) error {
   query := `UPDATE customers SET is_active = false WHERE id = $1`
   
-  _, err := r.db.Exec(query, id)
+  _, err := r.db.ExecContext(ctx, query, id)
   if err != nil {
     return fmt.Errorf("failed to delete customer: %w", err)
   }
@@ -166,17 +200,17 @@ func (r *customerRepository) Delete(id int) error {
   return nil
 }
 
-func (r *customerRepository) GenerateCustomerCode() (string, error) {
+func (r *customerRepository) GenerateCustomerCode(ctx context.Context) (string, error) {
   var lastCode string
   query := `
     SELECT customer_code 
     FROM customers 
     WHERE customer_code LIKE 'CUST-%' 
-    ORDER BY customer_code DESC 
+    ORDER BY LENGTH(customer_code) DESC, customer_code DESC 
     LIMIT 1
   `
   
-  err := r.db.Get(&lastCode, query)
+  err := r.db.GetContext(ctx, &lastCode, query)
   if err != nil && err != sql.ErrNoRows {
     return "", fmt.Errorf("failed to get last customer code: %w", err)
   }
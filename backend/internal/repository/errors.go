@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"vehicle-showroom/internal/entity"
+)
+
+// Postgres reports the offending column in the error detail, e.g.
+// `Key (chassis_number)=(MH4...) already exists.`
+var constraintKey = regexp.MustCompile(`^Key \(([^)]+)\)`)
+
+// wrapWriteError wraps a failed INSERT or UPDATE. Unique and foreign key
+// violations become domain errors, since they are caused by the input rather
+// than by the database.
+func wrapWriteError(action string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		field := "record"
+		if match := constraintKey.FindStringSubmatch(pqErr.Detail); match != nil {
+			field = match[1]
+		}
+
+		switch pqErr.Code {
+		case "23505": // unique_violation
+			return &entity.Error{
+				Kind:    entity.ErrConflict,
+				Message: field + " already exists",
+				Fields:  []entity.FieldError{{Field: field, Message: "already exists"}},
+			}
+		case "23503": // foreign_key_violation
+			return entity.NewFieldError(field, "refers to a record that does not exist")
+		}
+	}
+
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
+// rollback is deferred right after BeginTxx and does nothing once the
+// transaction is committed. Nobody receives its error, so a failed rollback
+// is logged here with the request context.
+func rollback(ctx context.Context, tx *sqlx.Tx) {
+	if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		slog.WarnContext(ctx, "failed to roll back transaction", slog.String("error", err.Error()))
+	}
+}
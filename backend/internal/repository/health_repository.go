@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"vehicle-showroom/internal/database"
+)
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	SchemaVersions(ctx context.Context) (current, latest int, err error)
+}
+
+type healthRepository struct {
+	db       *sqlx.DB
+	migrator *database.Migrator
+}
+
+func NewHealthRepository(db *sqlx.DB, migrator *database.Migrator) HealthRepository {
+	return &healthRepository{db: db, migrator: migrator}
+}
+
+func (r *healthRepository) Ping(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// SchemaVersions returns the version applied to the database and the newest
+// version embedded in this binary.
+func (r *healthRepository) SchemaVersions(ctx context.Context) (int, int, error) {
+	current, err := r.migrator.CurrentVersion(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	return current, r.migrator.LatestVersion(), nil
+}
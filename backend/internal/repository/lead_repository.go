@@ -0,0 +1,501 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"vehicle-showroom/internal/entity"
+)
+
+type LeadRepository interface {
+	Create(ctx context.Context, lead *entity.Lead, vehicleIDs []int) error
+	GetByID(ctx context.Context, id int) (*entity.Lead, error)
+	ListByCustomer(ctx context.Context, customerID int) ([]entity.Lead, error)
+	List(ctx context.Context, params entity.ListParams, filter entity.LeadFilter) ([]entity.Lead, *entity.PageInfo, error)
+	Update(ctx context.Context, lead *entity.Lead, vehicleIDs []int) error
+	UpdateStatus(ctx context.Context, id int, status string, lostReason *string) error
+	MarkConverted(ctx context.Context, id, customerID int) error
+	ConvertToNewCustomer(ctx context.Context, id int, customer *entity.Customer) error
+	GenerateLeadNumber(ctx context.Context) (string, error)
+	ScheduleTestDrive(ctx context.Context, drive *entity.TestDrive) (*entity.TestDrive, error)
+	GetTestDrive(ctx context.Context, leadID, id int) (*entity.TestDrive, error)
+	UpdateTestDriveStatus(ctx context.Context, id int, status string, notes *string) error
+	ListTestDrivesByVehicle(ctx context.Context, vehicleID int, from time.Time, to *time.Time) ([]entity.TestDrive, error)
+}
+
+type leadRepository struct {
+	db *sqlx.DB
+}
+
+func NewLeadRepository(db *sqlx.DB) LeadRepository {
+	return &leadRepository{db: db}
+}
+
+const (
+	leadColumns = `
+		l.id, l.lead_number, l.name, l.phone, l.email, l.source, l.status, l.assigned_to,
+		l.notes, l.lost_reason, l.customer_id, l.converted_at, l.created_by, l.created_at, l.updated_at
+	`
+	testDriveColumns = `
+		id, lead_id, vehicle_id, starts_at, ends_at, status, notes, created_by, created_at
+	`
+)
+
+// Create inserts the lead with its vehicles of interest in one transaction.
+func (r *leadRepository) Create(ctx context.Context, lead *entity.Lead, vehicleIDs []int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	query := `
+		INSERT INTO leads (lead_number, name, phone, email, source, status, assigned_to, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+	err = tx.QueryRowxContext(ctx, query,
+		lead.LeadNumber,
+		lead.Name,
+		lead.Phone,
+		lead.Email,
+		lead.Source,
+		lead.Status,
+		lead.AssignedTo,
+		lead.Notes,
+		lead.CreatedBy,
+	).Scan(&lead.ID, &lead.CreatedAt, &lead.UpdatedAt)
+	if err != nil {
+		return wrapWriteError("create lead", err)
+	}
+
+	if err := setLeadVehicles(ctx, tx, lead.ID, vehicleIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit lead: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns the lead with its vehicles and test drives.
+func (r *leadRepository) GetByID(ctx context.Context, id int) (*entity.Lead, error) {
+	lead := &entity.Lead{}
+	query := `SELECT ` + leadColumns + ` FROM leads l WHERE l.id = $1`
+
+	err := r.db.GetContext(ctx, lead, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get lead by id: %w", err)
+	}
+
+	leads := []entity.Lead{*lead}
+	if err := attachLeadVehicles(ctx, r.db, leads); err != nil {
+		return nil, err
+	}
+
+	query = `SELECT ` + testDriveColumns + ` FROM test_drives WHERE lead_id = $1 ORDER BY starts_at DESC, id DESC`
+	if err := r.db.SelectContext(ctx, &leads[0].TestDrives, query, id); err != nil {
+		return nil, fmt.Errorf("failed to get test drives: %w", err)
+	}
+
+	return &leads[0], nil
+}
+
+// ListByCustomer lists the leads converted into customerID, latest first,
+// with their vehicles and test drives.
+func (r *leadRepository) ListByCustomer(ctx context.Context, customerID int) ([]entity.Lead, error) {
+	leads := []entity.Lead{}
+	query := `SELECT ` + leadColumns + ` FROM leads l WHERE l.customer_id = $1 ORDER BY l.created_at DESC, l.id DESC`
+	if err := r.db.SelectContext(ctx, &leads, query, customerID); err != nil {
+		return nil, fmt.Errorf("failed to list leads by customer: %w", err)
+	}
+	if len(leads) == 0 {
+		return leads, nil
+	}
+
+	if err := attachLeadVehicles(ctx, r.db, leads); err != nil {
+		return nil, err
+	}
+
+	leadIDs := make([]int, len(leads))
+	for i, lead := range leads {
+		leadIDs[i] = lead.ID
+	}
+	var drives []entity.TestDrive
+	query = `SELECT ` + testDriveColumns + ` FROM test_drives WHERE lead_id = ANY($1) ORDER BY starts_at DESC, id DESC`
+	if err := r.db.SelectContext(ctx, &drives, query, pq.Array(leadIDs)); err != nil {
+		return nil, fmt.Errorf("failed to get test drives: %w", err)
+	}
+
+	byLead := make(map[int][]entity.TestDrive, len(leads))
+	for _, drive := range drives {
+		byLead[drive.LeadID] = append(byLead[drive.LeadID], drive)
+	}
+	for i := range leads {
+		leads[i].TestDrives = byLead[leads[i].ID]
+	}
+	return leads, nil
+}
+
+var leadSort = listSort[entity.Lead]{
+	keys: map[string]sortKey[entity.Lead]{
+		"created_at":  {"l.created_at", func(l *entity.Lead) interface{} { return l.CreatedAt }},
+		"updated_at":  {"l.updated_at", func(l *entity.Lead) interface{} { return l.UpdatedAt }},
+		"lead_number": {"l.lead_number", func(l *entity.Lead) interface{} { return l.LeadNumber }},
+		"name":        {"l.name", func(l *entity.Lead) interface{} { return l.Name }},
+		"status":      {"l.status", func(l *entity.Lead) interface{} { return l.Status }},
+	},
+	defaultSort: []entity.SortField{{Field: "created_at", Desc: true}},
+	idExpr:      "l.id",
+	id:          func(l *entity.Lead) int { return l.ID },
+}
+
+func (r *leadRepository) List(ctx context.Context, params entity.ListParams, filter entity.LeadFilter) ([]entity.Lead, *entity.PageInfo, error) {
+	w := &whereBuilder{}
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		w.add("(l.lead_number ILIKE $? OR l.name ILIKE $? OR l.phone ILIKE $? OR l.email ILIKE $?)",
+			pattern, pattern, pattern, pattern)
+	}
+	if filter.Status != "" {
+		w.add("l.status = $?", filter.Status)
+	}
+	if filter.AssignedTo != nil {
+		w.add("l.assigned_to = $?", *filter.AssignedTo)
+	}
+	if filter.VehicleID != nil {
+		w.add("EXISTS (SELECT 1 FROM lead_vehicles lv WHERE lv.lead_id = l.id AND lv.vehicle_id = $?)", *filter.VehicleID)
+	}
+	if filter.Open {
+		w.add("l.status NOT IN ('won', 'lost')")
+	}
+
+	plan, err := leadSort.plan(params, w.nextArg())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total, err := countTotal(params, func() (int, error) {
+		var total int
+		countQuery := `SELECT COUNT(*) FROM leads l ` + w.clause()
+		if err := r.db.GetContext(ctx, &total, countQuery, w.args...); err != nil {
+			return 0, fmt.Errorf("failed to get lead count: %w", err)
+		}
+		return total, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM leads l
+		%s%s
+		%s
+		%s
+	`, leadColumns, w.clause(), plan.keyset, plan.orderBy, plan.limit)
+
+	var leads []entity.Lead
+	if err := r.db.SelectContext(ctx, &leads, query, append(w.args, plan.args...)...); err != nil {
+		return nil, nil, fmt.Errorf("failed to list leads: %w", err)
+	}
+
+	leads, page, err := leadSort.page(leads, plan, total)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := attachLeadVehicles(ctx, r.db, leads); err != nil {
+		return nil, nil, err
+	}
+
+	return leads, page, nil
+}
+
+// Update saves the lead's details and replaces its vehicles of interest.
+func (r *leadRepository) Update(ctx context.Context, lead *entity.Lead, vehicleIDs []int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	query := `
+		UPDATE leads
+		SET name = $1, phone = $2, email = $3, source = $4, assigned_to = $5, notes = $6,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7
+	`
+	_, err = tx.ExecContext(ctx, query, lead.Name, lead.Phone, lead.Email, lead.Source,
+		lead.AssignedTo, lead.Notes, lead.ID)
+	if err != nil {
+		return wrapWriteError("update lead", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM lead_vehicles WHERE lead_id = $1 AND vehicle_id <> ALL($2)`,
+		lead.ID, pq.Array(vehicleIDs))
+	if err != nil {
+		return fmt.Errorf("failed to update lead vehicles: %w", err)
+	}
+	if err := setLeadVehicles(ctx, tx, lead.ID, vehicleIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit lead: %w", err)
+	}
+	return nil
+}
+
+// UpdateStatus keeps lost_reason only while the lead is lost.
+func (r *leadRepository) UpdateStatus(ctx context.Context, id int, status string, lostReason *string) error {
+	query := `
+		UPDATE leads
+		SET status = $1, lost_reason = CASE WHEN $1 = 'lost' THEN $2 END, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`
+	if _, err := r.db.ExecContext(ctx, query, status, lostReason, id); err != nil {
+		return wrapWriteError("update lead status", err)
+	}
+	return nil
+}
+
+// MarkConverted links the lead to its customer. A lead converts once; a
+// second conversion, even a concurrent one, is a conflict.
+func (r *leadRepository) MarkConverted(ctx context.Context, id, customerID int) error {
+	return markLeadConverted(ctx, r.db, id, customerID)
+}
+
+// ConvertToNewCustomer creates customer and links the lead to it in one
+// transaction, so a conversion that loses a race or fails leaves no
+// customer behind.
+func (r *leadRepository) ConvertToNewCustomer(ctx context.Context, id int, customer *entity.Customer) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	if err := insertCustomer(ctx, tx, customer); err != nil {
+		return err
+	}
+	if err := markLeadConverted(ctx, tx, id, customer.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit lead conversion: %w", err)
+	}
+	return nil
+}
+
+func markLeadConverted(ctx context.Context, e sqlx.ExecerContext, id, customerID int) error {
+	query := `
+		UPDATE leads
+		SET customer_id = $1, converted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND customer_id IS NULL
+	`
+	result, err := e.ExecContext(ctx, query, customerID, id)
+	if err != nil {
+		return wrapWriteError("convert lead", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to convert lead: %w", err)
+	}
+	if n == 0 {
+		return entity.NewConflictError("lead is already converted")
+	}
+	return nil
+}
+
+func (r *leadRepository) GenerateLeadNumber(ctx context.Context) (string, error) {
+	today := time.Now().Format("20060102")
+	var lastNumber string
+	query := `
+		SELECT lead_number
+		FROM leads
+		WHERE lead_number LIKE 'LEAD-' || $1 || '-%'
+		ORDER BY LENGTH(lead_number) DESC, lead_number DESC
+		LIMIT 1
+	`
+
+	err := r.db.GetContext(ctx, &lastNumber, query, today)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to get last lead number: %w", err)
+	}
+
+	nextNumber := 1
+	if lastNumber != "" {
+		parts := strings.Split(lastNumber, "-")
+		if len(parts) == 3 {
+			var num int
+			fmt.Sscanf(parts[2], "%d", &num)
+			nextNumber = num + 1
+		}
+	}
+
+	return fmt.Sprintf("LEAD-%s-%03d", today, nextNumber), nil
+}
+
+// ScheduleTestDrive books the vehicle unless a scheduled test drive overlaps
+// the slot; that test drive is returned instead and nothing is written. The
+// vehicle row is locked so two bookings of the same vehicle are checked one
+// after the other.
+func (r *leadRepository) ScheduleTestDrive(ctx context.Context, drive *entity.TestDrive) (*entity.TestDrive, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	var vehicleID int
+	err = tx.GetContext(ctx, &vehicleID, `SELECT id FROM vehicles WHERE id = $1 FOR UPDATE`, drive.VehicleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entity.NewNotFoundError("vehicle")
+		}
+		return nil, fmt.Errorf("failed to lock vehicle: %w", err)
+	}
+
+	conflict := &entity.TestDrive{}
+	query := `
+		SELECT ` + testDriveColumns + `
+		FROM test_drives
+		WHERE vehicle_id = $1 AND status = 'scheduled' AND starts_at < $3 AND ends_at > $2
+		ORDER BY starts_at
+		LIMIT 1
+	`
+	err = tx.GetContext(ctx, conflict, query, drive.VehicleID, drive.StartsAt, drive.EndsAt)
+	if err == nil {
+		return conflict, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check test drive schedule: %w", err)
+	}
+
+	query = `
+		INSERT INTO test_drives (lead_id, vehicle_id, starts_at, ends_at, status, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowxContext(ctx, query,
+		drive.LeadID,
+		drive.VehicleID,
+		drive.StartsAt,
+		drive.EndsAt,
+		drive.Status,
+		drive.Notes,
+		drive.CreatedBy,
+	).Scan(&drive.ID, &drive.CreatedAt)
+	if err != nil {
+		return nil, wrapWriteError("schedule test drive", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit test drive: %w", err)
+	}
+	return nil, nil
+}
+
+// GetTestDrive only finds the test drive among leadID's.
+func (r *leadRepository) GetTestDrive(ctx context.Context, leadID, id int) (*entity.TestDrive, error) {
+	drive := &entity.TestDrive{}
+	query := `SELECT ` + testDriveColumns + ` FROM test_drives WHERE id = $1 AND lead_id = $2`
+
+	err := r.db.GetContext(ctx, drive, query, id, leadID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get test drive by id: %w", err)
+	}
+	return drive, nil
+}
+
+// UpdateTestDriveStatus replaces the notes only when notes is set.
+func (r *leadRepository) UpdateTestDriveStatus(ctx context.Context, id int, status string, notes *string) error {
+	query := `UPDATE test_drives SET status = $1, notes = COALESCE($2, notes) WHERE id = $3`
+	if _, err := r.db.ExecContext(ctx, query, status, notes, id); err != nil {
+		return wrapWriteError("update test drive status", err)
+	}
+	return nil
+}
+
+// ListTestDrivesByVehicle lists the vehicle's scheduled test drives that end
+// after from and, with to, start before it, earliest first.
+func (r *leadRepository) ListTestDrivesByVehicle(ctx context.Context, vehicleID int, from time.Time, to *time.Time) ([]entity.TestDrive, error) {
+	query := `
+		SELECT ` + testDriveColumns + `
+		FROM test_drives
+		WHERE vehicle_id = $1 AND status = 'scheduled' AND ends_at > $2 AND ($3::timestamp IS NULL OR starts_at < $3)
+		ORDER BY starts_at, id
+	`
+	drives := []entity.TestDrive{}
+	if err := r.db.SelectContext(ctx, &drives, query, vehicleID, from, to); err != nil {
+		return nil, fmt.Errorf("failed to list test drives: %w", err)
+	}
+	return drives, nil
+}
+
+// setLeadVehicles adds the vehicles the lead is not yet linked to.
+func setLeadVehicles(ctx context.Context, tx *sqlx.Tx, leadID int, vehicleIDs []int) error {
+	if len(vehicleIDs) == 0 {
+		return nil
+	}
+	query := `
+		INSERT INTO lead_vehicles (lead_id, vehicle_id)
+		SELECT $1, unnest($2::int[])
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, query, leadID, pq.Array(vehicleIDs)); err != nil {
+		return wrapWriteError("link lead vehicles", err)
+	}
+	return nil
+}
+
+// attachLeadVehicles fills Vehicles with one query for all the leads.
+func attachLeadVehicles(ctx context.Context, db *sqlx.DB, leads []entity.Lead) error {
+	if len(leads) == 0 {
+		return nil
+	}
+
+	leadIDs := make([]int, len(leads))
+	for i, lead := range leads {
+		leadIDs[i] = lead.ID
+	}
+
+	var rows []struct {
+		LeadID int `db:"lead_id"`
+		entity.Vehicle
+	}
+	query := `
+		SELECT lv.lead_id, v.id, v.vehicle_code, v.chassis_number, v.license_plate, v.brand, v.model,
+		       v.variant, v.year, v.color, v.status, v.approved_selling_price
+		FROM lead_vehicles lv
+		JOIN vehicles v ON v.id = lv.vehicle_id
+		WHERE lv.lead_id = ANY($1)
+		ORDER BY lv.added_at, v.id
+	`
+	if err := db.SelectContext(ctx, &rows, query, pq.Array(leadIDs)); err != nil {
+		return fmt.Errorf("failed to load lead vehicles: %w", err)
+	}
+
+	byLead := make(map[int][]entity.Vehicle, len(leads))
+	for _, row := range rows {
+		byLead[row.LeadID] = append(byLead[row.LeadID], row.Vehicle)
+	}
+	for i := range leads {
+		leads[i].Vehicles = byLead[leads[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"vehicle-showroom/internal/entity"
+)
+
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *entity.LoginAttempt) error
+	GetFailureStatsByIP(ctx context.Context, ipAddress string, since time.Time) (*entity.LoginFailureStats, error)
+	GetFailureStatsByUsername(ctx context.Context, username string, since time.Time) (*entity.LoginFailureStats, error)
+}
+
+type loginAttemptRepository struct {
+	db *sqlx.DB
+}
+
+func NewLoginAttemptRepository(db *sqlx.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+// Only wrong credentials count towards throttling; attempts rejected because
+// of throttling or a lock are logged but must not extend the penalty.
+const countedFailureReasons = `('` + entity.LoginFailureUnknownUser + `', '` + entity.LoginFailureInvalidPassword + `', '` +
+	entity.LoginFailureInvalid2FACode + `')`
+
+func (r *loginAttemptRepository) Create(ctx context.Context, attempt *entity.LoginAttempt) error {
+	query := `
+		INSERT INTO login_attempts (username, user_id, ip_address, user_agent, success, failure_reason, attempted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx,
+		query,
+		attempt.Username,
+		attempt.UserID,
+		attempt.IPAddress,
+		attempt.UserAgent,
+		attempt.Success,
+		attempt.FailureReason,
+		attempt.AttemptedAt,
+	).Scan(&attempt.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to record login attempt: %w", err)
+	}
+
+	return nil
+}
+
+func (r *loginAttemptRepository) GetFailureStatsByIP(ctx context.Context, ipAddress string, since time.Time) (*entity.LoginFailureStats, error) {
+	stats := &entity.LoginFailureStats{}
+	query := `
+		SELECT COUNT(*) AS count, MAX(attempted_at) AS last_failure_at
+		FROM login_attempts
+		WHERE ip_address = $1 AND success = false AND attempted_at > $2
+		  AND failure_reason IN ` + countedFailureReasons
+
+	if err := r.db.GetContext(ctx, stats, query, ipAddress, since); err != nil {
+		return nil, fmt.Errorf("failed to get login failures by ip: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetFailureStatsByUsername counts failures since the later of `since` and
+// the username's last successful login.
+func (r *loginAttemptRepository) GetFailureStatsByUsername(ctx context.Context, username string, since time.Time) (*entity.LoginFailureStats, error) {
+	stats := &entity.LoginFailureStats{}
+	query := `
+		SELECT COUNT(*) AS count, MAX(attempted_at) AS last_failure_at
+		FROM login_attempts
+		WHERE username = $1 AND success = false
+		  AND failure_reason IN ` + countedFailureReasons + `
+		  AND attempted_at > GREATEST($2, COALESCE(
+		    (SELECT MAX(attempted_at) FROM login_attempts WHERE username = $1 AND success = true), $2))
+	`
+
+	if err := r.db.GetContext(ctx, stats, query, username, since); err != nil {
+		return nil, fmt.Errorf("failed to get login failures by username: %w", err)
+	}
+
+	return stats, nil
+}
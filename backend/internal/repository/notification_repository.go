@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"vehicle-showroom/internal/entity"
+)
+
+type NotificationRepository interface {
+	CreateForUsers(ctx context.Context, userIDs []int, n entity.NewNotification) ([]entity.Notification, error)
+	UserIDsWithPermission(ctx context.Context, permission string) ([]int, error)
+	List(ctx context.Context, userID int, params entity.ListParams, unreadOnly bool) ([]entity.Notification, *entity.PageInfo, error)
+	CountUnread(ctx context.Context, userID int) (int, error)
+	MarkRead(ctx context.Context, userID, id int, now time.Time) (*entity.Notification, error)
+	MarkAllRead(ctx context.Context, userID int, now time.Time) (int, error)
+}
+
+type notificationRepository struct {
+	db *sqlx.DB
+}
+
+func NewNotificationRepository(db *sqlx.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+const notificationColumns = `
+	id, user_id, type, title, body, entity_type, entity_id, read_at, created_at
+`
+
+// CreateForUsers stores a copy of n for each user, in one statement.
+func (r *notificationRepository) CreateForUsers(ctx context.Context, userIDs []int, n entity.NewNotification) ([]entity.Notification, error) {
+	notifications := []entity.Notification{}
+	if len(userIDs) == 0 {
+		return notifications, nil
+	}
+
+	query := `
+		INSERT INTO notifications (user_id, type, title, body, entity_type, entity_id)
+		SELECT user_id, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, 0)
+		FROM unnest($1::int[]) AS user_id
+		RETURNING ` + notificationColumns
+
+	err := r.db.SelectContext(ctx, &notifications, query,
+		pq.Array(userIDs), n.Type, n.Title, n.Body, n.EntityType, n.EntityID)
+	if err != nil {
+		return nil, wrapWriteError("create notifications", err)
+	}
+	return notifications, nil
+}
+
+// UserIDsWithPermission returns the active users whose role holds
+// permission.
+func (r *notificationRepository) UserIDsWithPermission(ctx context.Context, permission string) ([]int, error) {
+	ids := []int{}
+	query := `
+		SELECT u.id
+		FROM users u
+		JOIN roles ro ON ro.name = u.role
+		JOIN role_permissions rp ON rp.role_id = ro.id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE p.code = $1 AND u.is_active = true
+		ORDER BY u.id
+	`
+	if err := r.db.SelectContext(ctx, &ids, query, permission); err != nil {
+		return nil, fmt.Errorf("failed to get users with permission: %w", err)
+	}
+	return ids, nil
+}
+
+var notificationSort = listSort[entity.Notification]{
+	keys: map[string]sortKey[entity.Notification]{
+		"created_at": {"created_at", func(n *entity.Notification) interface{} { return n.CreatedAt }},
+	},
+	defaultSort: []entity.SortField{{Field: "created_at", Desc: true}},
+	idExpr:      "id",
+	id:          func(n *entity.Notification) int { return n.ID },
+}
+
+func (r *notificationRepository) List(ctx context.Context, userID int, params entity.ListParams, unreadOnly bool) ([]entity.Notification, *entity.PageInfo, error) {
+	w := &whereBuilder{}
+	w.add("user_id = $?", userID)
+	if unreadOnly {
+		w.add("read_at IS NULL")
+	}
+
+	plan, err := notificationSort.plan(params, w.nextArg())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total, err := countTotal(params, func() (int, error) {
+		var total int
+		countQuery := `SELECT COUNT(*) FROM notifications ` + w.clause()
+		if err := r.db.GetContext(ctx, &total, countQuery, w.args...); err != nil {
+			return 0, fmt.Errorf("failed to get notification count: %w", err)
+		}
+		return total, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM notifications
+		%s%s
+		%s
+		%s
+	`, notificationColumns, w.clause(), plan.keyset, plan.orderBy, plan.limit)
+
+	var notifications []entity.Notification
+	if err := r.db.SelectContext(ctx, &notifications, query, append(w.args, plan.args...)...); err != nil {
+		return nil, nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	return notificationSort.page(notifications, plan, total)
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+	if err := r.db.GetContext(ctx, &count, query, userID); err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks one of the user's notifications read, keeping the first
+// read time. It returns nil when the user has no such notification.
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, id int, now time.Time) (*entity.Notification, error) {
+	notification := &entity.Notification{}
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, $3)
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + notificationColumns
+
+	err := r.db.GetContext(ctx, notification, query, id, userID, now)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, wrapWriteError("mark notification read", err)
+	}
+	return notification, nil
+}
+
+// MarkAllRead marks the user's unread notifications read and returns how
+// many there were.
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID int, now time.Time) (int, error) {
+	query := `UPDATE notifications SET read_at = $2 WHERE user_id = $1 AND read_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, userID, now)
+	if err != nil {
+		return 0, wrapWriteError("mark notifications read", err)
+	}
+	marked, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return int(marked), nil
+}
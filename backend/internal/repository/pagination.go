@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"vehicle-showroom/internal/entity"
+)
+
+// sortKey is a field a list may be sorted by. expr must never be NULL
+// (wrap nullable columns in COALESCE), or keyset comparisons would skip
+// rows; value must return what expr evaluates to for a scanned row, since
+// it becomes part of the next cursor.
+type sortKey[T any] struct {
+	expr  string
+	value func(*T) interface{}
+}
+
+// listSort is the sort whitelist of one list. Rows are always ordered by id
+// last, so the order is total and keyset paging never skips or repeats a
+// row.
+type listSort[T any] struct {
+	keys        map[string]sortKey[T]
+	defaultSort []entity.SortField
+	idExpr      string
+	id          func(*T) int
+}
+
+// pagePlan holds the SQL fragments for one page. keyset is appended to the
+// WHERE clause of the row query (not the count query); limit follows the
+// ORDER BY. args are numbered after the caller's filter arguments.
+type pagePlan struct {
+	sort    []entity.SortField
+	keyset  string
+	orderBy string
+	limit   string
+	args    []interface{}
+	params  entity.ListParams
+}
+
+// cursor is the position after the last row of a page: the values of the
+// sort fields and the id, plus the sort it was made for.
+type cursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+// plan validates params against the whitelist and builds the clauses.
+// argIndex is the next free $n placeholder.
+func (s listSort[T]) plan(params entity.ListParams, argIndex int) (*pagePlan, error) {
+	fields := params.Sort
+	if len(fields) == 0 {
+		fields = s.defaultSort
+	}
+
+	plan := &pagePlan{sort: fields, params: params}
+	exprs := make([]string, 0, len(fields)+1)
+	order := make([]string, 0, len(fields)+1)
+	for _, f := range fields {
+		key, ok := s.keys[f.Field]
+		if !ok {
+			return nil, entity.NewFieldError("sort", fmt.Sprintf("cannot sort by %q; use one of: %s", f.Field, strings.Join(s.fieldNames(), ", ")))
+		}
+		exprs = append(exprs, key.expr)
+		order = append(order, key.expr+direction(f.Desc))
+	}
+	// The id tie-breaker follows the direction of the last sort field
+	idDesc := len(fields) > 0 && fields[len(fields)-1].Desc
+	exprs = append(exprs, s.idExpr)
+	order = append(order, s.idExpr+direction(idDesc))
+	plan.orderBy = "ORDER BY " + strings.Join(order, ", ")
+
+	if params.Keyset && params.Cursor != "" {
+		values, err := decodeCursor(params.Cursor, entity.FormatSort(fields), len(exprs))
+		if err != nil {
+			return nil, err
+		}
+
+		descs := make([]bool, 0, len(exprs))
+		for _, f := range fields {
+			descs = append(descs, f.Desc)
+		}
+		descs = append(descs, idDesc)
+
+		placeholders := make([]string, len(values))
+		for i, v := range values {
+			placeholders[i] = fmt.Sprintf("$%d", argIndex)
+			plan.args = append(plan.args, v)
+			argIndex++
+		}
+
+		// (a > x) OR (a = x AND b < y) OR (a = x AND b = y AND id > z)
+		var alternatives []string
+		for i := range exprs {
+			terms := make([]string, 0, i+1)
+			for j := 0; j < i; j++ {
+				terms = append(terms, fmt.Sprintf("%s = %s", exprs[j], placeholders[j]))
+			}
+			op := ">"
+			if descs[i] {
+				op = "<"
+			}
+			terms = append(terms, fmt.Sprintf("%s %s %s", exprs[i], op, placeholders[i]))
+			alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+		}
+		plan.keyset = " AND (" + strings.Join(alternatives, " OR ") + ")"
+	}
+
+	// One row more than asked for tells whether there is a next page
+	if params.Keyset {
+		plan.limit = fmt.Sprintf("LIMIT $%d", argIndex)
+		plan.args = append(plan.args, params.Limit+1)
+	} else {
+		plan.limit = fmt.Sprintf("LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+		plan.args = append(plan.args, params.Limit+1, params.Offset())
+	}
+
+	return plan, nil
+}
+
+// page trims the look-ahead row and builds the page info. total is only
+// reported when it was counted.
+func (s listSort[T]) page(rows []T, plan *pagePlan, total *int) ([]T, *entity.PageInfo, error) {
+	info := &entity.PageInfo{Total: total}
+	if len(rows) <= plan.params.Limit {
+		return rows, info, nil
+	}
+
+	rows = rows[:plan.params.Limit]
+	info.HasMore = true
+	if plan.params.Keyset {
+		last := &rows[len(rows)-1]
+		values := make([]interface{}, 0, len(plan.sort)+1)
+		for _, f := range plan.sort {
+			values = append(values, s.keys[f.Field].value(last))
+		}
+		values = append(values, s.id(last))
+
+		next, err := encodeCursor(cursor{Sort: entity.FormatSort(plan.sort), Values: values})
+		if err != nil {
+			return nil, nil, err
+		}
+		info.NextCursor = next
+	}
+	return rows, info, nil
+}
+
+func (s listSort[T]) fieldNames() []string {
+	names := make([]string, 0, len(s.keys))
+	for name := range s.keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+func encodeCursor(c cursor) (string, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor returns the cursor's values, which are bound as query
+// parameters and never spliced into SQL. A cursor made for another sort
+// order is rejected rather than silently misapplied.
+func decodeCursor(value, sortSpec string, count int) ([]interface{}, error) {
+	invalid := entity.NewFieldError("cursor", "is not a cursor returned by this list")
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, invalid
+	}
+
+	var c cursor
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&c); err != nil {
+		return nil, invalid
+	}
+	if c.Sort != sortSpec {
+		return nil, entity.NewFieldError("cursor", "was made for sort "+c.Sort+"; pass the same sort or start over")
+	}
+	if len(c.Values) != count {
+		return nil, invalid
+	}
+	for _, v := range c.Values {
+		switch v.(type) {
+		case string, json.Number, bool:
+		default:
+			return nil, invalid
+		}
+	}
+	return c.Values, nil
+}
+
+// countTotal runs the count query only when the caller asked for a total.
+func countTotal(params entity.ListParams, count func() (int, error)) (*int, error) {
+	if !params.WithTotal {
+		return nil, nil
+	}
+	total, err := count()
+	if err != nil {
+		return nil, err
+	}
+	return &total, nil
+}
+
+// derefOr mirrors COALESCE for the value side of a sort key.
+func derefOr[T any](p *T, fallback T) T {
+	if p == nil {
+		return fallback
+	}
+	return *p
+}
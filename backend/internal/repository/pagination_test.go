@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"vehicle-showroom/internal/entity"
+)
+
+type testRow struct {
+	ID    int
+	Year  int
+	Price float64
+}
+
+var testSort = listSort[testRow]{
+	keys: map[string]sortKey[testRow]{
+		"year":  {expr: "v.year", value: func(r *testRow) interface{} { return r.Year }},
+		"price": {expr: "v.price", value: func(r *testRow) interface{} { return r.Price }},
+	},
+	defaultSort: []entity.SortField{{Field: "year", Desc: true}},
+	idExpr:      "v.id",
+	id:          func(r *testRow) int { return r.ID },
+}
+
+func mustCursor(t *testing.T, sort string, values ...interface{}) string {
+	t.Helper()
+	c, err := encodeCursor(cursor{Sort: sort, Values: values})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestPlanKeysetMixedDirections(t *testing.T) {
+	tests := []struct {
+		name        string
+		sort        []entity.SortField
+		values      []interface{}
+		wantOrderBy string
+		wantKeyset  string
+	}{
+		{
+			name:        "descending then ascending",
+			sort:        []entity.SortField{{Field: "year", Desc: true}, {Field: "price"}},
+			values:      []interface{}{2020, 150000000, 7},
+			wantOrderBy: "ORDER BY v.year DESC, v.price ASC, v.id ASC",
+			wantKeyset: " AND ((v.year < $3) OR (v.year = $3 AND v.price > $4) OR " +
+				"(v.year = $3 AND v.price = $4 AND v.id > $5))",
+		},
+		{
+			name:        "ascending then descending, id follows the last field",
+			sort:        []entity.SortField{{Field: "price"}, {Field: "year", Desc: true}},
+			values:      []interface{}{150000000, 2020, 7},
+			wantOrderBy: "ORDER BY v.price ASC, v.year DESC, v.id DESC",
+			wantKeyset: " AND ((v.price > $3) OR (v.price = $3 AND v.year < $4) OR " +
+				"(v.price = $3 AND v.year = $4 AND v.id < $5))",
+		},
+		{
+			name:        "default sort",
+			values:      []interface{}{2020, 7},
+			wantOrderBy: "ORDER BY v.year DESC, v.id DESC",
+			wantKeyset:  " AND ((v.year < $3) OR (v.year = $3 AND v.id < $4))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := tt.sort
+			if fields == nil {
+				fields = testSort.defaultSort
+			}
+			params := entity.ListParams{
+				Limit:  20,
+				Sort:   tt.sort,
+				Keyset: true,
+				Cursor: mustCursor(t, entity.FormatSort(fields), tt.values...),
+			}
+
+			// Two filter arguments come first
+			plan, err := testSort.plan(params, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan.orderBy != tt.wantOrderBy {
+				t.Errorf("orderBy = %q, want %q", plan.orderBy, tt.wantOrderBy)
+			}
+			if plan.keyset != tt.wantKeyset {
+				t.Errorf("keyset = %q, want %q", plan.keyset, tt.wantKeyset)
+			}
+
+			wantLimit := fmt.Sprintf("LIMIT $%d", 3+len(tt.values))
+			if plan.limit != wantLimit {
+				t.Errorf("limit = %q, want %q", plan.limit, wantLimit)
+			}
+			if n := len(plan.args); n != len(tt.values)+1 || plan.args[n-1] != 21 {
+				t.Errorf("args = %v, want the %d cursor values and 21", plan.args, len(tt.values))
+			}
+		})
+	}
+}
+
+func TestPlanOffsetMode(t *testing.T) {
+	plan, err := testSort.plan(entity.ListParams{Page: 3, Limit: 10}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.keyset != "" {
+		t.Errorf("keyset = %q, want none", plan.keyset)
+	}
+	if plan.limit != "LIMIT $1 OFFSET $2" {
+		t.Errorf("limit = %q", plan.limit)
+	}
+	if !reflect.DeepEqual(plan.args, []interface{}{11, 20}) {
+		t.Errorf("args = %v, want [11 20]", plan.args)
+	}
+}
+
+func TestPlanRejectsUnknownSortField(t *testing.T) {
+	_, err := testSort.plan(entity.ListParams{Limit: 10, Sort: []entity.SortField{{Field: "id; DROP TABLE"}}}, 1)
+	if !errors.Is(err, entity.ErrValidation) {
+		t.Fatalf("plan = %v, want a validation error", err)
+	}
+	if !strings.Contains(err.Error(), "price, year") {
+		t.Errorf("error %q does not list the sortable fields", err)
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		cursor  string
+		wantErr string
+	}{
+		{"matching sort", mustCursor(t, "-year,price", 2020, 150000000, 7), ""},
+		{"different sort", mustCursor(t, "price", 150000000, 7), "cursor was made for sort price; pass the same sort or start over"},
+		{"same fields, other direction", mustCursor(t, "year,price", 2020, 150000000, 7), "cursor was made for sort year,price; pass the same sort or start over"},
+		{"wrong number of values", mustCursor(t, "-year,price", 2020, 7), "cursor is not a cursor returned by this list"},
+		{"nested value", mustCursor(t, "-year,price", 2020, []int{1}, 7), "cursor is not a cursor returned by this list"},
+		{"not base64", "***", "cursor is not a cursor returned by this list"},
+		{"not json", "bm90IGpzb24", "cursor is not a cursor returned by this list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := decodeCursor(tt.cursor, "-year,price", 3)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("decodeCursor = %v", err)
+				}
+				if len(values) != 3 {
+					t.Errorf("values = %v, want 3", values)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("decodeCursor = %v, want %q", err, tt.wantErr)
+			}
+			if !errors.Is(err, entity.ErrValidation) {
+				t.Errorf("decodeCursor = %v, want a validation error", err)
+			}
+		})
+	}
+}
+
+func TestPageBuildsNextCursor(t *testing.T) {
+	params := entity.ListParams{Limit: 2, Keyset: true, Sort: []entity.SortField{{Field: "year", Desc: true}, {Field: "price"}}}
+	plan, err := testSort.plan(params, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := []testRow{{ID: 9, Year: 2022, Price: 1}, {ID: 4, Year: 2021, Price: 2.5}, {ID: 5, Year: 2021, Price: 3}}
+	page, info, err := testSort.page(rows, plan, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || !info.HasMore {
+		t.Fatalf("page = %v, has more %v; want 2 rows and more", page, info.HasMore)
+	}
+
+	// The cursor resumes after the last row kept, under the same sort
+	values, err := decodeCursor(info.NextCursor, "-year,price", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := []string{fmt.Sprint(values[0]), fmt.Sprint(values[1]), fmt.Sprint(values[2])}; !reflect.DeepEqual(got, []string{"2021", "2.5", "4"}) {
+		t.Errorf("cursor values = %v, want [2021 2.5 4]", got)
+	}
+}
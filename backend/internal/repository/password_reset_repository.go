@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"vehicle-showroom/internal/entity"
+)
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *entity.PasswordResetToken) error
+	GetByHash(ctx context.Context, hash string) (*entity.PasswordResetToken, error)
+	Redeem(ctx context.Context, id, userID int, passwordHash string) (bool, error)
+	InvalidateByUserID(ctx context.Context, userID int) error
+	RecordRequest(ctx context.Context, email, ipAddress string, at time.Time) error
+	GetRequestStatsByEmail(ctx context.Context, email string, since time.Time) (*entity.PasswordResetRequestStats, error)
+	GetRequestStatsByIP(ctx context.Context, ipAddress string, since time.Time) (*entity.PasswordResetRequestStats, error)
+}
+
+type passwordResetRepository struct {
+	db *sqlx.DB
+}
+
+func NewPasswordResetRepository(db *sqlx.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, token *entity.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, requested_ip)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, token.UserID, token.TokenHash, token.ExpiresAt, token.RequestedIP).
+		Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *passwordResetRepository) GetByHash(ctx context.Context, hash string) (*entity.PasswordResetToken, error) {
+	token := &entity.PasswordResetToken{}
+	query := `
+		SELECT id, user_id, token_hash, expires_at, used_at, requested_ip, created_at
+		FROM password_reset_tokens
+		WHERE token_hash = $1
+	`
+
+	err := r.db.GetContext(ctx, token, query, hash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get password reset token: %w", err)
+	}
+
+	return token, nil
+}
+
+// Redeem consumes the token and sets the user's new password in one
+// transaction, and reports whether this call was the one that consumed it,
+// so a token can never be redeemed twice nor burned by a failed update. A
+// reset proves mailbox ownership, so it also clears a login lockout.
+func (r *passwordResetRepository) Redeem(ctx context.Context, id, userID int, passwordHash string) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark password reset token used: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark password reset token used: %w", err)
+	}
+	if rows != 1 {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users
+		SET password_hash = $1, failed_login_attempts = 0, locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, passwordHash, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit password reset: %w", err)
+	}
+	return true, nil
+}
+
+func (r *passwordResetRepository) InvalidateByUserID(ctx context.Context, userID int) error {
+	query := `UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND used_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+
+	return nil
+}
+
+// RecordRequest logs a forgot-password request. Emails are stored lowercased
+// so changing the case does not get around the per-email limit.
+func (r *passwordResetRepository) RecordRequest(ctx context.Context, email, ipAddress string, at time.Time) error {
+	query := `INSERT INTO password_reset_requests (email, ip_address, requested_at) VALUES (LOWER($1), $2, $3)`
+
+	if _, err := r.db.ExecContext(ctx, query, email, ipAddress, at); err != nil {
+		return fmt.Errorf("failed to record password reset request: %w", err)
+	}
+
+	return nil
+}
+
+func (r *passwordResetRepository) GetRequestStatsByEmail(ctx context.Context, email string, since time.Time) (*entity.PasswordResetRequestStats, error) {
+	stats := &entity.PasswordResetRequestStats{}
+	query := `
+		SELECT COUNT(*) AS count, MAX(requested_at) AS last_request_at
+		FROM password_reset_requests
+		WHERE email = LOWER($1) AND requested_at > $2
+	`
+
+	if err := r.db.GetContext(ctx, stats, query, email, since); err != nil {
+		return nil, fmt.Errorf("failed to get password reset requests by email: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (r *passwordResetRepository) GetRequestStatsByIP(ctx context.Context, ipAddress string, since time.Time) (*entity.PasswordResetRequestStats, error) {
+	stats := &entity.PasswordResetRequestStats{}
+	query := `
+		SELECT COUNT(*) AS count, MAX(requested_at) AS last_request_at
+		FROM password_reset_requests
+		WHERE ip_address = $1 AND requested_at > $2
+	`
+
+	if err := r.db.GetContext(ctx, stats, query, ipAddress, since); err != nil {
+		return nil, fmt.Errorf("failed to get password reset requests by ip: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"vehicle-showroom/internal/entity"
+)
+
+// Listings attach related records with one query per related table for the
+// whole page (WHERE id = ANY($1)) rather than one query per row.
+
+const (
+	relatedVehicleQuery = `
+		SELECT id, vehicle_code, chassis_number, license_plate, brand, model, variant, year, color
+		FROM vehicles WHERE id = ANY($1)
+	`
+	relatedCustomerQuery = `
+		SELECT id, customer_code, name, phone, email, type
+		FROM customers WHERE id = ANY($1)
+	`
+	relatedUserQuery = `
+		SELECT id, username, full_name, role
+		FROM users WHERE id = ANY($1)
+	`
+	relatedSparePartQuery = `
+		SELECT id, part_code, name, description, brand, unit_measure
+		FROM spare_parts WHERE id = ANY($1)
+	`
+)
+
+// loadByID runs query with the distinct ids as $1 and indexes the rows by
+// their id.
+func loadByID[T any](ctx context.Context, db *sqlx.DB, query string, ids []int, id func(*T) int) (map[int]*T, error) {
+	ids = distinctIDs(ids)
+	byID := make(map[int]*T, len(ids))
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	var rows []T
+	if err := db.SelectContext(ctx, &rows, query, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		byID[id(&rows[i])] = &rows[i]
+	}
+	return byID, nil
+}
+
+func distinctIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	distinct := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			distinct = append(distinct, id)
+		}
+	}
+	return distinct
+}
+
+func loadVehicles(ctx context.Context, db *sqlx.DB, ids []int) (map[int]*entity.Vehicle, error) {
+	vehicles, err := loadByID(ctx, db, relatedVehicleQuery, ids, func(v *entity.Vehicle) int { return v.ID })
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vehicles: %w", err)
+	}
+	return vehicles, nil
+}
+
+func loadCustomers(ctx context.Context, db *sqlx.DB, ids []int) (map[int]*entity.Customer, error) {
+	customers, err := loadByID(ctx, db, relatedCustomerQuery, ids, func(c *entity.Customer) int { return c.ID })
+	if err != nil {
+		return nil, fmt.Errorf("failed to load customers: %w", err)
+	}
+	return customers, nil
+}
+
+func loadUsers(ctx context.Context, db *sqlx.DB, ids []int) (map[int]*entity.User, error) {
+	users, err := loadByID(ctx, db, relatedUserQuery, ids, func(u *entity.User) int { return u.ID })
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+	return users, nil
+}
+
+// transactionRelations holds the records shared by purchase and sales rows.
+type transactionRelations struct {
+	vehicles  map[int]*entity.Vehicle
+	customers map[int]*entity.Customer
+	cashiers  map[int]*entity.User
+}
+
+func loadTransactionRelations(ctx context.Context, db *sqlx.DB, vehicleIDs, customerIDs, cashierIDs []int) (*transactionRelations, error) {
+	var rel transactionRelations
+	var err error
+	if rel.vehicles, err = loadVehicles(ctx, db, vehicleIDs); err != nil {
+		return nil, err
+	}
+	if rel.customers, err = loadCustomers(ctx, db, customerIDs); err != nil {
+		return nil, err
+	}
+	if rel.cashiers, err = loadUsers(ctx, db, cashierIDs); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// attachPurchaseRelations fills Vehicle, Customer and Cashier with three
+// queries regardless of the number of transactions.
+func attachPurchaseRelations(ctx context.Context, db *sqlx.DB, transactions []entity.PurchaseTransaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	vehicleIDs := make([]int, len(transactions))
+	customerIDs := make([]int, len(transactions))
+	cashierIDs := make([]int, len(transactions))
+	for i, tx := range transactions {
+		vehicleIDs[i], customerIDs[i], cashierIDs[i] = tx.VehicleID, tx.CustomerID, tx.CashierID
+	}
+
+	rel, err := loadTransactionRelations(ctx, db, vehicleIDs, customerIDs, cashierIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range transactions {
+		tx := &transactions[i]
+		tx.Vehicle = rel.vehicles[tx.VehicleID]
+		tx.Customer = rel.customers[tx.CustomerID]
+		tx.Cashier = rel.cashiers[tx.CashierID]
+	}
+	return nil
+}
+
+// attachSalesRelations is attachPurchaseRelations for sales.
+func attachSalesRelations(ctx context.Context, db *sqlx.DB, transactions []entity.SalesTransaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	vehicleIDs := make([]int, len(transactions))
+	customerIDs := make([]int, len(transactions))
+	cashierIDs := make([]int, len(transactions))
+	for i, tx := range transactions {
+		vehicleIDs[i], customerIDs[i], cashierIDs[i] = tx.VehicleID, tx.CustomerID, tx.CashierID
+	}
+
+	rel, err := loadTransactionRelations(ctx, db, vehicleIDs, customerIDs, cashierIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range transactions {
+		tx := &transactions[i]
+		tx.Vehicle = rel.vehicles[tx.VehicleID]
+		tx.Customer = rel.customers[tx.CustomerID]
+		tx.Cashier = rel.cashiers[tx.CashierID]
+	}
+	return nil
+}
+
+// loadRepairParts returns the parts used by each repair, newest first, with
+// their spare part details: two queries for any number of repairs.
+func loadRepairParts(ctx context.Context, db *sqlx.DB, repairIDs []int) (map[int][]entity.RepairPart, error) {
+	byRepair := make(map[int][]entity.RepairPart, len(repairIDs))
+	if len(repairIDs) == 0 {
+		return byRepair, nil
+	}
+
+	var parts []entity.RepairPart
+	query := `
+		SELECT rp.id, rp.repair_id, rp.spare_part_id, rp.quantity_used, rp.unit_cost,
+		       rp.total_cost, rp.used_at, rp.notes
+		FROM repair_parts rp
+		WHERE rp.repair_id = ANY($1)
+		ORDER BY rp.used_at DESC
+	`
+	if err := db.SelectContext(ctx, &parts, query, pq.Array(distinctIDs(repairIDs))); err != nil {
+		return nil, fmt.Errorf("failed to get repair parts: %w", err)
+	}
+
+	sparePartIDs := make([]int, len(parts))
+	for i, part := range parts {
+		sparePartIDs[i] = part.SparePartID
+	}
+	spareParts, err := loadByID(ctx, db, relatedSparePartQuery, sparePartIDs, func(p *entity.SparePart) int { return p.ID })
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spare parts: %w", err)
+	}
+
+	for _, part := range parts {
+		part.SparePart = spareParts[part.SparePartID]
+		byRepair[part.RepairID] = append(byRepair[part.RepairID], part)
+	}
+	return byRepair, nil
+}
+
+// attachRepairRelations fills Vehicle, Mechanic and RepairParts with four
+// queries regardless of the number of repairs.
+func attachRepairRelations(ctx context.Context, db *sqlx.DB, repairs []entity.Repair) error {
+	if len(repairs) == 0 {
+		return nil
+	}
+
+	repairIDs := make([]int, len(repairs))
+	vehicleIDs := make([]int, len(repairs))
+	var mechanicIDs []int
+	for i, repair := range repairs {
+		repairIDs[i] = repair.ID
+		vehicleIDs[i] = repair.VehicleID
+		if repair.MechanicID != nil {
+			mechanicIDs = append(mechanicIDs, *repair.MechanicID)
+		}
+	}
+
+	vehicles, err := loadVehicles(ctx, db, vehicleIDs)
+	if err != nil {
+		return err
+	}
+	mechanics, err := loadUsers(ctx, db, mechanicIDs)
+	if err != nil {
+		return err
+	}
+	parts, err := loadRepairParts(ctx, db, repairIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range repairs {
+		repair := &repairs[i]
+		repair.Vehicle = vehicles[repair.VehicleID]
+		if repair.MechanicID != nil {
+			repair.Mechanic = mechanics[*repair.MechanicID]
+		}
+		repair.RepairParts = parts[repair.ID]
+	}
+	return nil
+}
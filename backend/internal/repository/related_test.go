@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"vehicle-showroom/internal/config"
+	"vehicle-showroom/internal/database"
+	"vehicle-showroom/internal/entity"
+)
+
+// The query count tests need a disposable PostgreSQL database, named by
+// TEST_DB_NAME; the other DB_* settings apply. It is migrated, and demo data
+// is seeded into it when it has too few sales.
+const (
+	smallPage = 5
+	largePage = 40
+)
+
+func openCountingTestDB(tb testing.TB) (*sqlx.DB, *database.QueryCounter) {
+	tb.Helper()
+
+	name := os.Getenv("TEST_DB_NAME")
+	if name == "" {
+		tb.Skip("TEST_DB_NAME is not set")
+	}
+	cfg := config.New().Database
+	cfg.Name = name
+
+	counter := &database.QueryCounter{}
+	db, err := database.NewCountingPostgreSQL(cfg, counter)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { db.Close() })
+
+	if err := database.RunMigrations(db); err != nil {
+		tb.Fatal(err)
+	}
+
+	var sales int
+	if err := db.Get(&sales, `SELECT COUNT(*) FROM sales_transactions`); err != nil {
+		tb.Fatal(err)
+	}
+	if sales < largePage {
+		opts := database.SeedOptions{Customers: 50, Vehicles: 150, Mechanics: 2, Months: 24, RandomSeed: 1}
+		if _, err := database.SeedDemo(db, opts); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	return db, counter
+}
+
+// countQueries returns how many statements fn sent.
+func countQueries(tb testing.TB, counter *database.QueryCounter, fn func() error) int64 {
+	tb.Helper()
+	counter.Reset()
+	if err := fn(); err != nil {
+		tb.Fatal(err)
+	}
+	return counter.Count()
+}
+
+// loadSalesPerRow is how sales listings loaded their vehicle, customer and
+// cashier before the related records were batched: three queries per row.
+func loadSalesPerRow(ctx context.Context, db *sqlx.DB, transactions []entity.SalesTransaction) error {
+	for i := range transactions {
+		tx := &transactions[i]
+		tx.Vehicle, tx.Customer, tx.Cashier = &entity.Vehicle{}, &entity.Customer{}, &entity.User{}
+		if err := db.GetContext(ctx, tx.Vehicle, `
+			SELECT id, vehicle_code, chassis_number, license_plate, brand, model, variant, year, color
+			FROM vehicles WHERE id = $1
+		`, tx.VehicleID); err != nil {
+			return err
+		}
+		if err := db.GetContext(ctx, tx.Customer, `
+			SELECT id, customer_code, name, phone, email, type
+			FROM customers WHERE id = $1
+		`, tx.CustomerID); err != nil {
+			return err
+		}
+		if err := db.GetContext(ctx, tx.Cashier, `
+			SELECT id, username, full_name, role
+			FROM users WHERE id = $1
+		`, tx.CashierID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestSalesRelationsQueryCount(t *testing.T) {
+	db, counter := openCountingTestDB(t)
+	ctx := context.Background()
+	transactionRepo := NewTransactionRepository(db)
+
+	var batched, perRow []int64
+	for _, limit := range []int{smallPage, largePage} {
+		sales, _, err := transactionRepo.ListSales(ctx, entity.ListParams{Page: 1, Limit: limit}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sales) != limit {
+			t.Fatalf("ListSales returned %d rows, want %d", len(sales), limit)
+		}
+
+		batched = append(batched, countQueries(t, counter, func() error {
+			return attachSalesRelations(ctx, db, sales)
+		}))
+		perRow = append(perRow, countQueries(t, counter, func() error {
+			return loadSalesPerRow(ctx, db, sales)
+		}))
+	}
+
+	if batched[0] != 3 || batched[1] != 3 {
+		t.Errorf("batched loader sent %v queries for %d and %d rows, want 3 each", batched, smallPage, largePage)
+	}
+	if perRow[0] != 3*smallPage || perRow[1] != 3*largePage {
+		t.Errorf("per-row loader sent %v queries for %d and %d rows, want %d and %d",
+			perRow, smallPage, largePage, 3*smallPage, 3*largePage)
+	}
+}
+
+func TestListingQueryCountIsConstant(t *testing.T) {
+	db, counter := openCountingTestDB(t)
+	ctx := context.Background()
+	transactionRepo := NewTransactionRepository(db)
+	repairRepo := NewRepairRepository(db)
+
+	listings := []struct {
+		name string
+		list func(params entity.ListParams) error
+	}{
+		{"ListSales", func(params entity.ListParams) error {
+			_, _, err := transactionRepo.ListSales(ctx, params, "")
+			return err
+		}},
+		{"ListPurchases", func(params entity.ListParams) error {
+			_, _, err := transactionRepo.ListPurchases(ctx, params, "")
+			return err
+		}},
+		{"Repairs List", func(params entity.ListParams) error {
+			_, _, err := repairRepo.List(ctx, params, "", "")
+			return err
+		}},
+	}
+
+	for _, l := range listings {
+		t.Run(l.name, func(t *testing.T) {
+			small := countQueries(t, counter, func() error {
+				return l.list(entity.ListParams{Page: 1, Limit: smallPage, WithTotal: true})
+			})
+			large := countQueries(t, counter, func() error {
+				return l.list(entity.ListParams{Page: 1, Limit: largePage, WithTotal: true})
+			})
+			if small != large {
+				t.Errorf("%d queries for %d rows but %d for %d rows", small, smallPage, large, largePage)
+			}
+		})
+	}
+}
+
+func BenchmarkSalesRelations(b *testing.B) {
+	db, counter := openCountingTestDB(b)
+	ctx := context.Background()
+
+	sales, _, err := NewTransactionRepository(db).ListSales(ctx, entity.ListParams{Page: 1, Limit: largePage}, "")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	loaders := []struct {
+		name string
+		load func(context.Context, *sqlx.DB, []entity.SalesTransaction) error
+	}{
+		{"batched", attachSalesRelations},
+		{"per-row", loadSalesPerRow},
+	}
+	for _, l := range loaders {
+		b.Run(l.name, func(b *testing.B) {
+			counter.Reset()
+			for i := 0; i < b.N; i++ {
+				if err := l.load(ctx, db, sales); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(counter.Count())/float64(b.N), "queries/op")
+		})
+	}
+}
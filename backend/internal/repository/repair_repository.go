@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -11,16 +12,16 @@ import (
 )
 
 type RepairRepository interface {
-	Create(repair *entity.Repair) error
-	GetByID(id int) (*entity.Repair, error)
-	List(page, limit int, search, status string) ([]entity.Repair, int, error)
-	Update(repair *entity.Repair) error
-	UpdateStatus(id int, status string) error
-	AddPart(repairPart *entity.RepairPart) error
-	RemovePart(repairId, partId int) error
-	GetRepairParts(repairId int) ([]entity.RepairPart, error)
-	UpdateRepairCosts(repairId int) error
-	GenerateRepairNumber() (string, error)
+	Create(ctx context.Context, repair *entity.Repair) error
+	GetByID(ctx context.Context, id int) (*entity.Repair, error)
+	List(ctx context.Context, params entity.ListParams, search, status string) ([]entity.Repair, *entity.PageInfo, error)
+	Update(ctx context.Context, repair *entity.Repair) error
+	UpdateStatus(ctx context.Context, id int, status string) error
+	AddPart(ctx context.Context, repairPart *entity.RepairPart) error
+	RemovePart(ctx context.Context, repairId, partId int) error
+	GetRepairParts(ctx context.Context, repairId int) ([]entity.RepairPart, error)
+	UpdateRepairCosts(ctx context.Context, repairId int) error
+	GenerateRepairNumber(ctx context.Context) (string, error)
 }
 
 type repairRepository struct {
@@ -31,7 +32,7 @@ func NewRepairRepository(db *sqlx.DB) RepairRepository {
 	return &repairRepository{db: db}
 }
 
-func (r *repairRepository) Create(repair *entity.Repair) error {
+func (r *repairRepository) Create(ctx context.Context, repair *entity.Repair) error {
 	query := `
 		INSERT INTO repairs (repair_number, vehicle_id, title, description, labor_cost,
 		                    total_parts_cost, total_cost, status, mechanic_id, work_notes)
@@ -39,7 +40,7 @@ func (r *repairRepository) Create(repair *entity.Repair) error {
 		RETURNING id, created_at
 	`
 
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		query,
 		repair.RepairNumber,
 		repair.VehicleID,
@@ -54,13 +55,13 @@ func (r *repairRepository) Create(repair *entity.Repair) error {
 	).Scan(&repair.ID, &repair.CreatedAt)
 
 	if err != nil {
-		return fmt.Errorf("failed to create repair: %w", err)
+		return wrapWriteError("create repair", err)
 	}
 
 	return nil
 }
 
-func (r *repairRepository) GetByID(id int) (*entity.Repair, error) {
+func (r *repairRepository) GetByID(ctx context.Context, id int) (*entity.Repair, error) {
 	repair := &entity.Repair{}
 	query := `
 		SELECT r.id, r.repair_number, r.vehicle_id, r.title, r.description, r.labor_cost,
@@ -70,7 +71,7 @@ func (r *repairRepository) GetByID(id int) (*entity.Repair, error) {
 		WHERE r.id = $1
 	`
 
-	err := r.db.Get(repair, query, id)
+	err := r.db.GetContext(ctx, repair, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -78,15 +79,27 @@ func (r *repairRepository) GetByID(id int) (*entity.Repair, error) {
 		return nil, fmt.Errorf("failed to get repair by id: %w", err)
 	}
 
-	// Load related data
-	r.loadRepairRelatedData(repair)
+	repairs := []entity.Repair{*repair}
+	if err := attachRepairRelations(ctx, r.db, repairs); err != nil {
+		return nil, err
+	}
 
-	return repair, nil
+	return &repairs[0], nil
 }
 
-func (r *repairRepository) List(page, limit int, search, status string) ([]entity.Repair, int, error) {
-	offset := (page - 1) * limit
+var repairSort = listSort[entity.Repair]{
+	keys: map[string]sortKey[entity.Repair]{
+		"created_at":    {"r.created_at", func(r *entity.Repair) interface{} { return r.CreatedAt }},
+		"repair_number": {"r.repair_number", func(r *entity.Repair) interface{} { return r.RepairNumber }},
+		"total_cost":    {"r.total_cost", func(r *entity.Repair) interface{} { return r.TotalCost }},
+		"status":        {"r.status", func(r *entity.Repair) interface{} { return r.Status }},
+	},
+	defaultSort: []entity.SortField{{Field: "created_at", Desc: true}},
+	idExpr:      "r.id",
+	id:          func(r *entity.Repair) int { return r.ID },
+}
 
+func (r *repairRepository) List(ctx context.Context, params entity.ListParams, search, status string) ([]entity.Repair, *entity.PageInfo, error) {
 	whereClause := "WHERE 1=1"
 	args := []interface{}{}
 	argIndex := 1
@@ -105,65 +118,74 @@ func (r *repairRepository) List(page, limit int, search, status string) ([]entit
 		argIndex++
 	}
 
-	// Get total count
-	countQuery := fmt.Sprintf(`
-		SELECT COUNT(*) 
-		FROM repairs r
-		LEFT JOIN vehicles v ON r.vehicle_id = v.id
-		%s
-	`, whereClause)
+	plan, err := repairSort.plan(params, argIndex)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	var total int
-	err := r.db.Get(&total, countQuery, args...)
+	total, err := countTotal(params, func() (int, error) {
+		var total int
+		countQuery := fmt.Sprintf(`
+			SELECT COUNT(*) 
+			FROM repairs r
+			LEFT JOIN vehicles v ON r.vehicle_id = v.id
+			%s
+		`, whereClause)
+		if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+			return 0, fmt.Errorf("failed to get repair count: %w", err)
+		}
+		return total, nil
+	})
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get repair count: %w", err)
+		return nil, nil, err
 	}
 
-	// Get repairs
 	query := fmt.Sprintf(`
 		SELECT r.id, r.repair_number, r.vehicle_id, r.title, r.description, r.labor_cost,
 		       r.total_parts_cost, r.total_cost, r.status, r.mechanic_id, r.started_at,
 		       r.completed_at, r.created_at, r.work_notes
 		FROM repairs r
 		LEFT JOIN vehicles v ON r.vehicle_id = v.id
+		%s%s
 		%s
-		ORDER BY r.created_at DESC
-		LIMIT $%d OFFSET $%d
-	`, whereClause, argIndex, argIndex+1)
-
-	args = append(args, limit, offset)
+		%s
+	`, whereClause, plan.keyset, plan.orderBy, plan.limit)
 
 	var repairs []entity.Repair
-	err = r.db.Select(&repairs, query, args...)
+	err = r.db.SelectContext(ctx, &repairs, query, append(args, plan.args...)...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list repairs: %w", err)
+		return nil, nil, fmt.Errorf("failed to list repairs: %w", err)
 	}
 
-	// Load related data for each repair
-	for i := range repairs {
-		r.loadRepairRelatedData(&repairs[i])
+	repairs, page, err := repairSort.page(repairs, plan, total)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return repairs, total, nil
+	if err := attachRepairRelations(ctx, r.db, repairs); err != nil {
+		return nil, nil, err
+	}
+
+	return repairs, page, nil
 }
 
-func (r *repairRepository) Update(repair *entity.Repair) error {
+func (r *repairRepository) Update(ctx context.Context, repair *entity.Repair) error {
 	query := `
 		UPDATE repairs
 		SET title = $1, description = $2, labor_cost = $3, mechanic_id = $4, work_notes = $5
 		WHERE id = $6
 	`
 
-	_, err := r.db.Exec(query, repair.Title, repair.Description, repair.LaborCost,
+	_, err := r.db.ExecContext(ctx, query, repair.Title, repair.Description, repair.LaborCost,
 		repair.MechanicID, repair.WorkNotes, repair.ID)
 	if err != nil {
-		return fmt.Errorf("failed to update repair: %w", err)
+		return wrapWriteError("update repair", err)
 	}
 
 	return nil
 }
 
-func (r *repairRepository) UpdateStatus(id int, status string) error {
+func (r *repairRepository) UpdateStatus(ctx context.Context, id int, status string) error {
 	var query string
 	var args []interface{}
 
@@ -178,7 +200,7 @@ func (r *repairRepository) UpdateStatus(id int, status string) error {
 		args = []interface{}{status, id}
 	}
 
-	_, err := r.db.Exec(query, args...)
+	_, err := r.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update repair status: %w", err)
 	}
@@ -186,14 +208,14 @@ func (r *repairRepository) UpdateStatus(id int, status string) error {
 	return nil
 }
 
-func (r *repairRepository) AddPart(repairPart *entity.RepairPart) error {
+func (r *repairRepository) AddPart(ctx context.Context, repairPart *entity.RepairPart) error {
 	query := `
 		INSERT INTO repair_parts (repair_id, spare_part_id, quantity_used, unit_cost, total_cost, notes)
 		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, used_at
 	`
 
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		query,
 		repairPart.RepairID,
 		repairPart.SparePartID,
@@ -210,10 +232,10 @@ func (r *repairRepository) AddPart(repairPart *entity.RepairPart) error {
 	return nil
 }
 
-func (r *repairRepository) RemovePart(repairId, partId int) error {
+func (r *repairRepository) RemovePart(ctx context.Context, repairId, partId int) error {
 	query := `DELETE FROM repair_parts WHERE repair_id = $1 AND id = $2`
 
-	_, err := r.db.Exec(query, repairId, partId)
+	_, err := r.db.ExecContext(ctx, query, repairId, partId)
 	if err != nil {
 		return fmt.Errorf("failed to remove part from repair: %w", err)
 	}
@@ -221,41 +243,20 @@ func (r *repairRepository) RemovePart(repairId, partId int) error {
 	return nil
 }
 
-func (r *repairRepository) GetRepairParts(repairId int) ([]entity.RepairPart, error) {
-	var parts []entity.RepairPart
-	query := `
-		SELECT rp.id, rp.repair_id, rp.spare_part_id, rp.quantity_used, rp.unit_cost,
-		       rp.total_cost, rp.used_at, rp.notes
-		FROM repair_parts rp
-		WHERE rp.repair_id = $1
-		ORDER BY rp.used_at DESC
-	`
-
-	err := r.db.Select(&parts, query, repairId)
+func (r *repairRepository) GetRepairParts(ctx context.Context, repairId int) ([]entity.RepairPart, error) {
+	parts, err := loadRepairParts(ctx, r.db, []int{repairId})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get repair parts: %w", err)
+		return nil, err
 	}
 
-	// Load spare part details for each part
-	for i := range parts {
-		sparePart := &entity.SparePart{}
-		sparePartQuery := `
-			SELECT id, part_code, name, description, brand, unit_measure
-			FROM spare_parts WHERE id = $1
-		`
-		if err := r.db.Get(sparePart, sparePartQuery, parts[i].SparePartID); err == nil {
-			parts[i].SparePart = sparePart
-		}
-	}
-
-	return parts, nil
+	return parts[repairId], nil
 }
 
-func (r *repairRepository) UpdateRepairCosts(repairId int) error {
+func (r *repairRepository) UpdateRepairCosts(ctx context.Context, repairId int) error {
 	// Calculate total parts cost
 	var totalPartsCost sql.NullFloat64
 	partsQuery := `SELECT COALESCE(SUM(total_cost), 0) FROM repair_parts WHERE repair_id = $1`
-	err := r.db.Get(&totalPartsCost, partsQuery, repairId)
+	err := r.db.GetContext(ctx, &totalPartsCost, partsQuery, repairId)
 	if err != nil {
 		return fmt.Errorf("failed to calculate total parts cost: %w", err)
 	}
@@ -263,7 +264,7 @@ func (r *repairRepository) UpdateRepairCosts(repairId int) error {
 	// Get labor cost
 	var laborCost sql.NullFloat64
 	laborQuery := `SELECT COALESCE(labor_cost, 0) FROM repairs WHERE id = $1`
-	err = r.db.Get(&laborCost, laborQuery, repairId)
+	err = r.db.GetContext(ctx, &laborCost, laborQuery, repairId)
 	if err != nil {
 		return fmt.Errorf("failed to get labor cost: %w", err)
 	}
@@ -276,7 +277,7 @@ func (r *repairRepository) UpdateRepairCosts(repairId int) error {
 		WHERE id = $3
 	`
 
-	_, err = r.db.Exec(updateQuery, totalPartsCost.Float64, totalCost, repairId)
+	_, err = r.db.ExecContext(ctx, updateQuery, totalPartsCost.Float64, totalCost, repairId)
 	if err != nil {
 		return fmt.Errorf("failed to update repair costs: %w", err)
 	}
@@ -284,18 +285,18 @@ func (r *repairRepository) UpdateRepairCosts(repairId int) error {
 	return nil
 }
 
-func (r *repairRepository) GenerateRepairNumber() (string, error) {
+func (r *repairRepository) GenerateRepairNumber(ctx context.Context) (string, error) {
 	today := time.Now().Format("20060102")
 	var lastNumber string
 	query := `
 		SELECT repair_number 
 		FROM repairs 
 		WHERE repair_number LIKE 'REP-' || $1 || '-%' 
-		ORDER BY repair_number DESC 
+		ORDER BY LENGTH(repair_number) DESC, repair_number DESC 
 		LIMIT 1
 	`
 
-	err := r.db.Get(&lastNumber, query, today)
+	err := r.db.GetContext(ctx, &lastNumber, query, today)
 	if err != nil && err != sql.ErrNoRows {
 		return "", fmt.Errorf("failed to get last repair number: %w", err)
 	}
@@ -312,33 +313,3 @@ func (r *repairRepository) GenerateRepairNumber() (string, error) {
 
 	return fmt.Sprintf("REP-%s-%03d", today, nextNumber), nil
 }
-
-func (r *repairRepository) loadRepairRelatedData(repair *entity.Repair) {
-	// Load vehicle
-	vehicle := &entity.Vehicle{}
-	vehicleQuery := `
-		SELECT id, vehicle_code, brand, model, variant, year
-		FROM vehicles WHERE id = $1
-	`
-	if err := r.db.Get(vehicle, vehicleQuery, repair.VehicleID); err == nil {
-		repair.Vehicle = vehicle
-	}
-
-	// Load mechanic
-	if repair.MechanicID != nil {
-		mechanic := &entity.User{}
-		mechanicQuery := `
-			SELECT id, username, full_name, role
-			FROM users WHERE id = $1
-		`
-		if err := r.db.Get(mechanic, mechanicQuery, *repair.MechanicID); err == nil {
-			repair.Mechanic = mechanic
-		}
-	}
-
-	// Load repair parts
-	parts, err := r.GetRepairParts(repair.ID)
-	if err == nil {
-		repair.RepairParts = parts
-	}
-}
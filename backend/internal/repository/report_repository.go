@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -9,22 +10,22 @@ import (
 )
 
 type ReportRepository interface {
-	GetVehicleProfitabilityReport(startDate, endDate time.Time) ([]entity.VehicleProfitability, error)
-	GetSalesReport(startDate, endDate time.Time) ([]entity.SalesTransaction, error)
-	GetPurchaseReport(startDate, endDate time.Time) ([]entity.PurchaseTransaction, error)
+	GetVehicleProfitabilityReport(ctx context.Context, startDate, endDate time.Time) ([]entity.VehicleProfitability, error)
+	GetSalesReport(ctx context.Context, startDate, endDate time.Time) ([]entity.SalesTransaction, error)
+	GetPurchaseReport(ctx context.Context, startDate, endDate time.Time) ([]entity.PurchaseTransaction, error)
+
+	// The Stream variants hand the same rows to fn in batches as they are
+	// read, for exports too large to hold in memory
+	StreamVehicleProfitabilityReport(ctx context.Context, startDate, endDate time.Time, fn func([]entity.VehicleProfitability) error) error
+	StreamSalesReport(ctx context.Context, startDate, endDate time.Time, fn func([]entity.SalesTransaction) error) error
+	StreamPurchaseReport(ctx context.Context, startDate, endDate time.Time, fn func([]entity.PurchaseTransaction) error) error
 }
 
-type reportRepository struct {
-	db *sqlx.DB
-}
-
-func NewReportRepository(db *sqlx.DB) ReportRepository {
-	return &reportRepository{db: db}
-}
+// reportBatchSize is the number of rows a Stream method hands over at once.
+const reportBatchSize = 500
 
-func (r *reportRepository) GetVehicleProfitabilityReport(startDate, endDate time.Time) ([]entity.VehicleProfitability, error) {
-	var report []entity.VehicleProfitability
-	query := `
+const (
+	vehicleProfitabilityQuery = `
         SELECT
             v.id AS vehicle_id,
             v.vehicle_code,
@@ -40,16 +41,7 @@ func (r *reportRepository) GetVehicleProfitabilityReport(startDate, endDate time
         WHERE v.status = 'sold' AND v.sold_at BETWEEN $1 AND $2
         ORDER BY v.sold_at DESC
     `
-	err := r.db.Select(&report, query, startDate, endDate)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get vehicle profitability report: %w", err)
-	}
-	return report, nil
-}
-
-func (r *reportRepository) GetSalesReport(startDate, endDate time.Time) ([]entity.SalesTransaction, error) {
-	var transactions []entity.SalesTransaction
-	query := `
+	salesReportQuery = `
         SELECT
             st.id, st.transaction_number, st.invoice_number, st.vehicle_id, st.customer_id,
             st.vehicle_price, st.tax_amount, st.discount_amount, st.total_amount,
@@ -59,22 +51,7 @@ func (r *reportRepository) GetSalesReport(startDate, endDate time.Time) ([]entit
         WHERE st.transaction_date BETWEEN $1 AND $2
         ORDER BY st.transaction_date DESC
     `
-	err := r.db.Select(&transactions, query, startDate, endDate)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get sales report: %w", err)
-	}
-
-	// Load related data for each transaction
-	for i := range transactions {
-		r.loadSalesRelatedData(&transactions[i])
-	}
-
-	return transactions, nil
-}
-
-func (r *reportRepository) GetPurchaseReport(startDate, endDate time.Time) ([]entity.PurchaseTransaction, error) {
-	var transactions []entity.PurchaseTransaction
-	query := `
+	purchaseReportQuery = `
         SELECT
             pt.id, pt.transaction_number, pt.invoice_number, pt.vehicle_id, pt.customer_id,
             pt.vehicle_price, pt.tax_amount, pt.total_amount, pt.payment_method,
@@ -84,62 +61,118 @@ func (r *reportRepository) GetPurchaseReport(startDate, endDate time.Time) ([]en
         WHERE pt.transaction_date BETWEEN $1 AND $2
         ORDER BY pt.transaction_date DESC
     `
-	err := r.db.Select(&transactions, query, startDate, endDate)
+)
+
+type reportRepository struct {
+	db *sqlx.DB
+}
+
+func NewReportRepository(db *sqlx.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+func (r *reportRepository) GetVehicleProfitabilityReport(ctx context.Context, startDate, endDate time.Time) ([]entity.VehicleProfitability, error) {
+	var report []entity.VehicleProfitability
+	err := r.db.SelectContext(ctx, &report, vehicleProfitabilityQuery, startDate, endDate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get purchase report: %w", err)
+		return nil, fmt.Errorf("failed to get vehicle profitability report: %w", err)
+	}
+	return report, nil
+}
+
+func (r *reportRepository) GetSalesReport(ctx context.Context, startDate, endDate time.Time) ([]entity.SalesTransaction, error) {
+	var transactions []entity.SalesTransaction
+	err := r.db.SelectContext(ctx, &transactions, salesReportQuery, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales report: %w", err)
 	}
 
-	// Load related data for each transaction
-	for i := range transactions {
-		r.loadPurchaseRelatedData(&transactions[i])
+	if err := attachSalesRelations(ctx, r.db, transactions); err != nil {
+		return nil, err
 	}
 
 	return transactions, nil
 }
 
-// Helper functions to load related data (can be moved to a shared place if needed)
-func (r *reportRepository) loadPurchaseRelatedData(tx *entity.PurchaseTransaction) {
-	// Load vehicle
-	vehicle := &entity.Vehicle{}
-	vehicleQuery := `SELECT id, vehicle_code, brand, model FROM vehicles WHERE id = $1`
-	if err := r.db.Get(vehicle, vehicleQuery, tx.VehicleID); err == nil {
-		tx.Vehicle = vehicle
+func (r *reportRepository) GetPurchaseReport(ctx context.Context, startDate, endDate time.Time) ([]entity.PurchaseTransaction, error) {
+	var transactions []entity.PurchaseTransaction
+	err := r.db.SelectContext(ctx, &transactions, purchaseReportQuery, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purchase report: %w", err)
 	}
 
-	// Load customer
-	customer := &entity.Customer{}
-	customerQuery := `SELECT id, name FROM customers WHERE id = $1`
-	if err := r.db.Get(customer, customerQuery, tx.CustomerID); err == nil {
-		tx.Customer = customer
+	if err := attachPurchaseRelations(ctx, r.db, transactions); err != nil {
+		return nil, err
 	}
 
-	// Load cashier
-	cashier := &entity.User{}
-	cashierQuery := `SELECT id, full_name FROM users WHERE id = $1`
-	if err := r.db.Get(cashier, cashierQuery, tx.CashierID); err == nil {
-		tx.Cashier = cashier
+	return transactions, nil
+}
+
+func (r *reportRepository) StreamVehicleProfitabilityReport(ctx context.Context, startDate, endDate time.Time, fn func([]entity.VehicleProfitability) error) error {
+	err := streamRows(ctx, r.db, vehicleProfitabilityQuery, []interface{}{startDate, endDate}, fn)
+	if err != nil {
+		return fmt.Errorf("failed to stream vehicle profitability report: %w", err)
 	}
+	return nil
 }
 
-func (r *reportRepository) loadSalesRelatedData(tx *entity.SalesTransaction) {
-	// Load vehicle
-	vehicle := &entity.Vehicle{}
-	vehicleQuery := `SELECT id, vehicle_code, brand, model FROM vehicles WHERE id = $1`
-	if err := r.db.Get(vehicle, vehicleQuery, tx.VehicleID); err == nil {
-		tx.Vehicle = vehicle
+func (r *reportRepository) StreamSalesReport(ctx context.Context, startDate, endDate time.Time, fn func([]entity.SalesTransaction) error) error {
+	err := streamRows(ctx, r.db, salesReportQuery, []interface{}{startDate, endDate}, func(transactions []entity.SalesTransaction) error {
+		if err := attachSalesRelations(ctx, r.db, transactions); err != nil {
+			return err
+		}
+		return fn(transactions)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stream sales report: %w", err)
 	}
+	return nil
+}
 
-	// Load customer
-	customer := &entity.Customer{}
-	customerQuery := `SELECT id, name FROM customers WHERE id = $1`
-	if err := r.db.Get(customer, customerQuery, tx.CustomerID); err == nil {
-		tx.Customer = customer
+func (r *reportRepository) StreamPurchaseReport(ctx context.Context, startDate, endDate time.Time, fn func([]entity.PurchaseTransaction) error) error {
+	err := streamRows(ctx, r.db, purchaseReportQuery, []interface{}{startDate, endDate}, func(transactions []entity.PurchaseTransaction) error {
+		if err := attachPurchaseRelations(ctx, r.db, transactions); err != nil {
+			return err
+		}
+		return fn(transactions)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stream purchase report: %w", err)
 	}
+	return nil
+}
 
-	// Load cashier
-	cashier := &entity.User{}
-	cashierQuery := `SELECT id, full_name FROM users WHERE id = $1`
-	if err := r.db.Get(cashier, cashierQuery, tx.CashierID); err == nil {
-		tx.Cashier = cashier
+// streamRows scans the result of query into batches of reportBatchSize and
+// passes each to fn while the query is still running, so memory does not
+// grow with the result. The batch slice is reused; fn must not keep it.
+// Relations are attached per batch on a second connection, since the first
+// is busy with the open result.
+func streamRows[T any](ctx context.Context, db *sqlx.DB, query string, args []interface{}, fn func([]T) error) error {
+	rows, err := db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	batch := make([]T, 0, reportBatchSize)
+	for rows.Next() {
+		var row T
+		if err := rows.StructScan(&row); err != nil {
+			return err
+		}
+		batch = append(batch, row)
+		if len(batch) == reportBatchSize {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return fn(batch)
 	}
+	return nil
 }
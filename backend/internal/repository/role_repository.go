@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"vehicle-showroom/internal/entity"
+)
+
+type RoleRepository interface {
+	Create(ctx context.Context, role *entity.Role) error
+	GetByID(ctx context.Context, id int) (*entity.Role, error)
+	GetByName(ctx context.Context, name string) (*entity.Role, error)
+	List(ctx context.Context) ([]entity.Role, error)
+	Update(ctx context.Context, role *entity.Role) error
+	Delete(ctx context.Context, id int) error
+	CountUsers(ctx context.Context, roleName string) (int, error)
+	ListPermissions(ctx context.Context) ([]entity.Permission, error)
+	GetPermissionCodes(ctx context.Context, roleName string) ([]string, error)
+}
+
+type roleRepository struct {
+	db *sqlx.DB
+}
+
+func NewRoleRepository(db *sqlx.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) Create(ctx context.Context, role *entity.Role) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	query := `
+		INSERT INTO roles (name, description, is_system)
+		VALUES ($1, $2, false)
+		RETURNING id, is_system, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query, role.Name, role.Description).Scan(&role.ID, &role.IsSystem, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		return wrapWriteError("create role", err)
+	}
+
+	if err := r.replacePermissions(ctx, tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit role: %w", err)
+	}
+
+	return nil
+}
+
+func (r *roleRepository) GetByID(ctx context.Context, id int) (*entity.Role, error) {
+	role := &entity.Role{}
+	query := `
+		SELECT id, name, description, is_system, created_at, updated_at
+		FROM roles
+		WHERE id = $1
+	`
+
+	err := r.db.GetContext(ctx, role, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get role by id: %w", err)
+	}
+
+	role.Permissions, err = r.GetPermissionCodes(ctx, role.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*entity.Role, error) {
+	role := &entity.Role{}
+	query := `
+		SELECT id, name, description, is_system, created_at, updated_at
+		FROM roles
+		WHERE name = $1
+	`
+
+	err := r.db.GetContext(ctx, role, query, name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get role by name: %w", err)
+	}
+
+	role.Permissions, err = r.GetPermissionCodes(ctx, role.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (r *roleRepository) List(ctx context.Context) ([]entity.Role, error) {
+	var roles []entity.Role
+	query := `
+		SELECT id, name, description, is_system, created_at, updated_at
+		FROM roles
+		ORDER BY id
+	`
+
+	if err := r.db.SelectContext(ctx, &roles, query); err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	// Load all grants in one query and distribute them
+	var grants []struct {
+		RoleID int    `db:"role_id"`
+		Code   string `db:"code"`
+	}
+	grantsQuery := `
+		SELECT rp.role_id, p.code
+		FROM role_permissions rp
+		JOIN permissions p ON p.id = rp.permission_id
+		ORDER BY p.code
+	`
+	if err := r.db.SelectContext(ctx, &grants, grantsQuery); err != nil {
+		return nil, fmt.Errorf("failed to list role permissions: %w", err)
+	}
+
+	byRole := make(map[int][]string)
+	for _, g := range grants {
+		byRole[g.RoleID] = append(byRole[g.RoleID], g.Code)
+	}
+	for i := range roles {
+		roles[i].Permissions = byRole[roles[i].ID]
+		if roles[i].Permissions == nil {
+			roles[i].Permissions = []string{}
+		}
+	}
+
+	return roles, nil
+}
+
+func (r *roleRepository) Update(ctx context.Context, role *entity.Role) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	query := `
+		UPDATE roles
+		SET name = $1, description = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`
+
+	if _, err := tx.ExecContext(ctx, query, role.Name, role.Description, role.ID); err != nil {
+		return wrapWriteError("update role", err)
+	}
+
+	if err := r.replacePermissions(ctx, tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit role: %w", err)
+	}
+
+	return nil
+}
+
+func (r *roleRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM roles WHERE id = $1 AND is_system = false`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	return nil
+}
+
+func (r *roleRepository) CountUsers(ctx context.Context, roleName string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM users WHERE role = $1 AND is_active = true`
+
+	if err := r.db.GetContext(ctx, &count, query, roleName); err != nil {
+		return 0, fmt.Errorf("failed to count users with role: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *roleRepository) ListPermissions(ctx context.Context) ([]entity.Permission, error) {
+	var permissions []entity.Permission
+	query := `SELECT id, code, description FROM permissions ORDER BY code`
+
+	if err := r.db.SelectContext(ctx, &permissions, query); err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+
+	return permissions, nil
+}
+
+func (r *roleRepository) GetPermissionCodes(ctx context.Context, roleName string) ([]string, error) {
+	codes := []string{}
+	query := `
+		SELECT p.code
+		FROM role_permissions rp
+		JOIN roles ro ON ro.id = rp.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE ro.name = $1
+		ORDER BY p.code
+	`
+
+	if err := r.db.SelectContext(ctx, &codes, query, roleName); err != nil {
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+
+	return codes, nil
+}
+
+func (r *roleRepository) replacePermissions(ctx context.Context, tx *sqlx.Tx, roleID int, codes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, roleID); err != nil {
+		return fmt.Errorf("failed to clear role permissions: %w", err)
+	}
+
+	query := `
+		INSERT INTO role_permissions (role_id, permission_id)
+		SELECT $1, id FROM permissions WHERE code = ANY($2)
+	`
+	if _, err := tx.ExecContext(ctx, query, roleID, pq.Array(codes)); err != nil {
+		return fmt.Errorf("failed to grant role permissions: %w", err)
+	}
+
+	return nil
+}
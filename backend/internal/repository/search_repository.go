@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"vehicle-showroom/internal/entity"
+)
+
+type SearchRepository interface {
+	Search(ctx context.Context, query string, types []string, limit int) ([]entity.SearchHit, error)
+}
+
+type searchRepository struct {
+	db *sqlx.DB
+}
+
+func NewSearchRepository(db *sqlx.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// searchQueries select the hits of one type. $1 is the search text, $2 the
+// same wrapped in % for ILIKE and $3 the limit. The to_tsvector and trigram
+// expressions match the indexes of migration 0003.
+var searchQueries = map[string]string{
+	entity.SearchTypeCustomer: `
+		SELECT 'customer' AS type, id, name AS title,
+		       customer_code || COALESCE(' · ' || phone, '') AS subtitle,
+		       GREATEST(
+		         CASE WHEN LOWER(customer_code) = LOWER($1) OR phone = $1 OR id_card_number = $1 THEN 1 ELSE 0 END,
+		         CASE WHEN customer_code ILIKE $2 OR phone ILIKE $2 OR email ILIKE $2 OR id_card_number ILIKE $2 THEN 0.8 ELSE 0 END,
+		         CASE WHEN to_tsvector('simple', name || ' ' || COALESCE(email, '')) @@ websearch_to_tsquery('simple', $1)
+		           THEN 0.6 + 0.3 * ts_rank(to_tsvector('simple', name || ' ' || COALESCE(email, '')), websearch_to_tsquery('simple', $1), 32)
+		           ELSE 0 END,
+		         similarity(name, $1)
+		       )::float8 AS score
+		FROM customers
+		WHERE is_active = true AND (
+		  to_tsvector('simple', name || ' ' || COALESCE(email, '')) @@ websearch_to_tsquery('simple', $1)
+		  OR name % $1
+		  OR customer_code ILIKE $2 OR phone ILIKE $2 OR email ILIKE $2 OR id_card_number ILIKE $2
+		)
+		ORDER BY score DESC
+		LIMIT $3`,
+
+	// Plates are compared without spaces, so "B1234ABC" finds "B 1234 ABC"
+	entity.SearchTypeVehicle: `
+		SELECT 'vehicle' AS type, id,
+		       brand || ' ' || model || COALESCE(' ' || variant, '') || ' (' || year || ')' AS title,
+		       vehicle_code || COALESCE(' · ' || license_plate, '') || ' · ' || status AS subtitle,
+		       GREATEST(
+		         CASE WHEN LOWER(vehicle_code) = LOWER($1) OR LOWER(chassis_number) = LOWER($1)
+		                OR LOWER(REPLACE(license_plate, ' ', '')) = LOWER(REPLACE($1, ' ', '')) THEN 1 ELSE 0 END,
+		         CASE WHEN vehicle_code ILIKE $2 OR chassis_number ILIKE $2
+		                OR REPLACE(license_plate, ' ', '') ILIKE REPLACE($2, ' ', '') THEN 0.8 ELSE 0 END,
+		         CASE WHEN to_tsvector('simple', brand || ' ' || model || ' ' || COALESCE(variant, '') || ' ' || COALESCE(color, '')) @@ websearch_to_tsquery('simple', $1)
+		           THEN 0.6 + 0.3 * ts_rank(to_tsvector('simple', brand || ' ' || model || ' ' || COALESCE(variant, '') || ' ' || COALESCE(color, '')), websearch_to_tsquery('simple', $1), 32)
+		           ELSE 0 END,
+		         similarity(brand || ' ' || model, $1)
+		       )::float8 AS score
+		FROM vehicles
+		WHERE to_tsvector('simple', brand || ' ' || model || ' ' || COALESCE(variant, '') || ' ' || COALESCE(color, '')) @@ websearch_to_tsquery('simple', $1)
+		   OR (brand || ' ' || model) % $1
+		   OR vehicle_code ILIKE $2 OR chassis_number ILIKE $2
+		   OR REPLACE(license_plate, ' ', '') ILIKE REPLACE($2, ' ', '')
+		ORDER BY score DESC
+		LIMIT $3`,
+
+	entity.SearchTypeSale: `
+		SELECT 'sale' AS type, st.id, st.invoice_number AS title,
+		       st.transaction_number || COALESCE(' · ' || c.name, '') AS subtitle,
+		       GREATEST(
+		         CASE WHEN LOWER(st.invoice_number) = LOWER($1) OR LOWER(st.transaction_number) = LOWER($1) THEN 1 ELSE 0 END,
+		         CASE WHEN st.invoice_number ILIKE $2 OR st.transaction_number ILIKE $2 THEN 0.8 ELSE 0 END,
+		         similarity(st.invoice_number, $1),
+		         similarity(st.transaction_number, $1)
+		       )::float8 AS score
+		FROM sales_transactions st
+		LEFT JOIN customers c ON st.customer_id = c.id
+		WHERE st.invoice_number ILIKE $2 OR st.transaction_number ILIKE $2
+		   OR st.invoice_number % $1 OR st.transaction_number % $1
+		ORDER BY score DESC
+		LIMIT $3`,
+
+	entity.SearchTypePurchase: `
+		SELECT 'purchase' AS type, pt.id, pt.invoice_number AS title,
+		       pt.transaction_number || COALESCE(' · ' || c.name, '') AS subtitle,
+		       GREATEST(
+		         CASE WHEN LOWER(pt.invoice_number) = LOWER($1) OR LOWER(pt.transaction_number) = LOWER($1) THEN 1 ELSE 0 END,
+		         CASE WHEN pt.invoice_number ILIKE $2 OR pt.transaction_number ILIKE $2 THEN 0.8 ELSE 0 END,
+		         similarity(pt.invoice_number, $1),
+		         similarity(pt.transaction_number, $1)
+		       )::float8 AS score
+		FROM purchase_transactions pt
+		LEFT JOIN customers c ON pt.customer_id = c.id
+		WHERE pt.invoice_number ILIKE $2 OR pt.transaction_number ILIKE $2
+		   OR pt.invoice_number % $1 OR pt.transaction_number % $1
+		ORDER BY score DESC
+		LIMIT $3`,
+
+	entity.SearchTypeRepair: `
+		SELECT 'repair' AS type, id, title,
+		       repair_number || ' · ' || status AS subtitle,
+		       GREATEST(
+		         CASE WHEN LOWER(repair_number) = LOWER($1) THEN 1 ELSE 0 END,
+		         CASE WHEN repair_number ILIKE $2 THEN 0.8 ELSE 0 END,
+		         CASE WHEN to_tsvector('simple', title || ' ' || COALESCE(description, '')) @@ websearch_to_tsquery('simple', $1)
+		           THEN 0.6 + 0.3 * ts_rank(to_tsvector('simple', title || ' ' || COALESCE(description, '')), websearch_to_tsquery('simple', $1), 32)
+		           ELSE 0 END,
+		         similarity(title, $1)
+		       )::float8 AS score
+		FROM repairs
+		WHERE to_tsvector('simple', title || ' ' || COALESCE(description, '')) @@ websearch_to_tsquery('simple', $1)
+		   OR title % $1
+		   OR repair_number ILIKE $2
+		ORDER BY score DESC
+		LIMIT $3`,
+}
+
+// Search runs the queries of the given types as one statement and returns
+// the best limit hits across them.
+func (r *searchRepository) Search(ctx context.Context, query string, types []string, limit int) ([]entity.SearchHit, error) {
+	hits := []entity.SearchHit{}
+	if len(types) == 0 {
+		return hits, nil
+	}
+
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		sql, ok := searchQueries[t]
+		if !ok {
+			return nil, fmt.Errorf("unknown search type %q", t)
+		}
+		parts = append(parts, "("+sql+")")
+	}
+	statement := strings.Join(parts, "\nUNION ALL\n") + "\nORDER BY score DESC, title\nLIMIT $3"
+
+	if err := r.db.SelectContext(ctx, &hits, statement, query, "%"+query+"%", limit); err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	return hits, nil
+}
@@ -1,18 +1,29 @@
 package repository
 
 import (
+  "context"
   "database/sql"
   "fmt"
+  "time"
 
   "github.com/jmoiron/sqlx"
   "vehicle-showroom/internal/entity"
 )
 
 type SessionRepository interface {
-  Create(session *entity.UserSession) error
-  GetByToken(token string) (*entity.UserSession, error)
-  UpdateLogout(token string) error
-  DeleteByUserID(userID int) error
+  Create(ctx context.Context, session *entity.UserSession) error
+  GetByID(ctx context.Context, id int) (*entity.UserSession, error)
+  GetByToken(ctx context.Context, token string) (*entity.UserSession, error)
+  GetByRefreshTokenHash(ctx context.Context, hash string) (*entity.UserSession, error)
+  GetSessionIDByRotatedHash(ctx context.Context, hash string) (int, error)
+  RotateRefreshToken(ctx context.Context, sessionID int, oldHash, newHash string, expiresAt time.Time, ipAddress, userAgent string) (bool, error)
+  Touch(ctx context.Context, sessionID int) error
+  ListActiveByUserID(ctx context.Context, userID int) ([]entity.UserSession, error)
+  Revoke(ctx context.Context, sessionID int, reason string) error
+  UpdateLogout(ctx context.Context, token string) error
+  RevokeByUserID(ctx context.Context, userID int, reason string) error
+  RevokeOthersByUserID(ctx context.Context, userID, keepSessionID int, reason string) error
+  PurgeExpired(ctx context.Context, before time.Time) (int64, error)
 }
 
 type sessionRepository struct {
@@ -23,73 +34,252 @@ func NewSessionRepository(db *sqlx.DB) SessionRepository {
   return &sessionRepository{db: db}
 }
 
-func (r *sessionRepository) Create(session *entity.UserSession) error {
+const sessionColumns = `
+  id, user_id, session_token, refresh_token_hash, refresh_expires_at, login_at, logout_at,
+  last_seen_at, ip_address, user_agent, is_active, revoked_reason
+`
+
+func (r *sessionRepository) Create(ctx context.Context, session *entity.UserSession) error {
   query := `
-    INSERT INTO user_sessions (user_id, session_token, ip_address, is_active)
-    VALUES ($1, $2, $3, $4)
-    RETURNING id, login_at
+    INSERT INTO user_sessions (user_id, session_token, refresh_token_hash, refresh_expires_at,
+                               ip_address, user_agent, is_active, last_seen_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP)
+    RETURNING id, login_at, last_seen_at
   `
-  
-  err := r.db.QueryRow(
+
+  err := r.db.QueryRowContext(ctx,
     query,
     session.UserID,
     session.SessionToken,
+    session.RefreshTokenHash,
+    session.RefreshExpiresAt,
     session.IPAddress,
+    session.UserAgent,
     session.IsActive,
-  ).Scan(&session.ID, &session.LoginAt)
-  
+  ).Scan(&session.ID, &session.LoginAt, &session.LastSeenAt)
+
   if err != nil {
     return fmt.Errorf("failed to create session: %w", err)
   }
-  
+
   return nil
 }
 
-func (r *sessionRepository) GetByToken(token string) (*entity.UserSession, error) {
+func (r *sessionRepository) GetByID(ctx context.Context, id int) (*entity.UserSession, error) {
   session := &entity.UserSession{}
-  query := `
-    SELECT id, user_id, session_token, login_at, logout_at, ip_address, is_active
-    FROM user_sessions
-    WHERE session_token = $1 AND is_active = true
-  `
-  
-  err := r.db.Get(session, query, token)
+  query := `SELECT ` + sessionColumns + ` FROM user_sessions WHERE id = $1`
+
+  err := r.db.GetContext(ctx, session, query, id)
+  if err != nil {
+    if err == sql.ErrNoRows {
+      return nil, nil
+    }
+    return nil, fmt.Errorf("failed to get session by id: %w", err)
+  }
+
+  return session, nil
+}
+
+func (r *sessionRepository) GetByToken(ctx context.Context, token string) (*entity.UserSession, error) {
+  session := &entity.UserSession{}
+  query := `SELECT ` + sessionColumns + ` FROM user_sessions WHERE session_token = $1 AND is_active = true`
+
+  err := r.db.GetContext(ctx, session, query, token)
   if err != nil {
     if err == sql.ErrNoRows {
       return nil, nil
     }
     return nil, fmt.Errorf("failed to get session by token: %w", err)
   }
-  
+
+  return session, nil
+}
+
+func (r *sessionRepository) GetByRefreshTokenHash(ctx context.Context, hash string) (*entity.UserSession, error) {
+  session := &entity.UserSession{}
+  query := `SELECT ` + sessionColumns + ` FROM user_sessions WHERE refresh_token_hash = $1`
+
+  err := r.db.GetContext(ctx, session, query, hash)
+  if err != nil {
+    if err == sql.ErrNoRows {
+      return nil, nil
+    }
+    return nil, fmt.Errorf("failed to get session by refresh token: %w", err)
+  }
+
   return session, nil
 }
 
-func (r *sessionRepository) UpdateLogout(token string) error {
+// GetSessionIDByRotatedHash returns the session a previously rotated refresh
+// token belonged to, or 0 if the hash was never issued.
+func (r *sessionRepository) GetSessionIDByRotatedHash(ctx context.Context, hash string) (int, error) {
+  var sessionID int
+  query := `SELECT session_id FROM session_refresh_tokens WHERE token_hash = $1`
+
+  err := r.db.GetContext(ctx, &sessionID, query, hash)
+  if err != nil {
+    if err == sql.ErrNoRows {
+      return 0, nil
+    }
+    return 0, fmt.Errorf("failed to look up rotated refresh token: %w", err)
+  }
+
+  return sessionID, nil
+}
+
+// RotateRefreshToken swaps the session's refresh token hash only if it still
+// matches oldHash, so two concurrent refreshes with the same token cannot both
+// succeed. It reports whether the swap happened.
+func (r *sessionRepository) RotateRefreshToken(ctx context.Context, sessionID int, oldHash, newHash string, expiresAt time.Time, ipAddress, userAgent string) (bool, error) {
+  tx, err := r.db.BeginTxx(ctx, nil)
+  if err != nil {
+    return false, fmt.Errorf("failed to begin transaction: %w", err)
+  }
+  defer rollback(ctx, tx)
+
+  result, err := tx.ExecContext(ctx, `
+    UPDATE user_sessions
+    SET refresh_token_hash = $1, refresh_expires_at = $2, ip_address = $3, user_agent = $4,
+        last_seen_at = CURRENT_TIMESTAMP
+    WHERE id = $5 AND refresh_token_hash = $6 AND is_active = true
+  `, newHash, expiresAt, ipAddress, userAgent, sessionID, oldHash)
+  if err != nil {
+    return false, fmt.Errorf("failed to rotate refresh token: %w", err)
+  }
+
+  rows, err := result.RowsAffected()
+  if err != nil {
+    return false, fmt.Errorf("failed to rotate refresh token: %w", err)
+  }
+  if rows == 0 {
+    return false, nil
+  }
+
+  _, err = tx.ExecContext(ctx, `INSERT INTO session_refresh_tokens (token_hash, session_id) VALUES ($1, $2)`, oldHash, sessionID)
+  if err != nil {
+    return false, fmt.Errorf("failed to record rotated refresh token: %w", err)
+  }
+
+  if err := tx.Commit(); err != nil {
+    return false, fmt.Errorf("failed to commit refresh token rotation: %w", err)
+  }
+
+  return true, nil
+}
+
+func (r *sessionRepository) Touch(ctx context.Context, sessionID int) error {
+  query := `
+    UPDATE user_sessions
+    SET last_seen_at = CURRENT_TIMESTAMP
+    WHERE id = $1 AND (last_seen_at IS NULL OR last_seen_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')
+  `
+
+  _, err := r.db.ExecContext(ctx, query, sessionID)
+  if err != nil {
+    return fmt.Errorf("failed to touch session: %w", err)
+  }
+
+  return nil
+}
+
+func (r *sessionRepository) ListActiveByUserID(ctx context.Context, userID int) ([]entity.UserSession, error) {
+  var sessions []entity.UserSession
+  query := `
+    SELECT ` + sessionColumns + `
+    FROM user_sessions
+    WHERE user_id = $1 AND is_active = true
+      AND (refresh_expires_at IS NULL OR refresh_expires_at > CURRENT_TIMESTAMP)
+    ORDER BY last_seen_at DESC NULLS LAST
+  `
+
+  err := r.db.SelectContext(ctx, &sessions, query, userID)
+  if err != nil {
+    return nil, fmt.Errorf("failed to list sessions: %w", err)
+  }
+
+  return sessions, nil
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, sessionID int, reason string) error {
   query := `
     UPDATE user_sessions
-    SET logout_at = CURRENT_TIMESTAMP, is_active = false
+    SET logout_at = CURRENT_TIMESTAMP, is_active = false, revoked_reason = $1
+    WHERE id = $2 AND is_active = true
+  `
+
+  _, err := r.db.ExecContext(ctx, query, reason, sessionID)
+  if err != nil {
+    return fmt.Errorf("failed to revoke session: %w", err)
+  }
+
+  return nil
+}
+
+func (r *sessionRepository) UpdateLogout(ctx context.Context, token string) error {
+  query := `
+    UPDATE user_sessions
+    SET logout_at = CURRENT_TIMESTAMP, is_active = false, revoked_reason = 'logout'
     WHERE session_token = $1
   `
-  
-  _, err := r.db.Exec(query, token)
+
+  _, err := r.db.ExecContext(ctx, query, token)
   if err != nil {
     return fmt.Errorf("failed to update logout: %w", err)
   }
-  
+
   return nil
 }
 
-func (r *sessionRepository) DeleteByUserID(userID int) error {
+func (r *sessionRepository) RevokeByUserID(ctx context.Context, userID int, reason string) error {
   query := `
     UPDATE user_sessions
-    SET is_active = false
-    WHERE user_id = $1
+    SET logout_at = CURRENT_TIMESTAMP, is_active = false, revoked_reason = $1
+    WHERE user_id = $2 AND is_active = true
   `
-  
-  _, err := r.db.Exec(query, userID)
+
+  _, err := r.db.ExecContext(ctx, query, reason, userID)
   if err != nil {
-    return fmt.Errorf("failed to delete sessions by user id: %w", err)
+    return fmt.Errorf("failed to revoke sessions by user id: %w", err)
   }
-  
+
   return nil
 }
+
+func (r *sessionRepository) RevokeOthersByUserID(ctx context.Context, userID, keepSessionID int, reason string) error {
+  query := `
+    UPDATE user_sessions
+    SET logout_at = CURRENT_TIMESTAMP, is_active = false, revoked_reason = $1
+    WHERE user_id = $2 AND id <> $3 AND is_active = true
+  `
+
+  _, err := r.db.ExecContext(ctx, query, reason, userID, keepSessionID)
+  if err != nil {
+    return fmt.Errorf("failed to revoke other sessions: %w", err)
+  }
+
+  return nil
+}
+
+// PurgeExpired deletes sessions that can no longer be used and ended before
+// the cutoff: revoked or logged out, refresh token expired, or legacy
+// sessions without a refresh token.
+func (r *sessionRepository) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+  query := `
+    DELETE FROM user_sessions
+    WHERE (is_active = false AND COALESCE(logout_at, login_at) < $1)
+       OR refresh_expires_at < $1
+       OR (refresh_expires_at IS NULL AND login_at < $1)
+  `
+
+  result, err := r.db.ExecContext(ctx, query, before)
+  if err != nil {
+    return 0, fmt.Errorf("failed to purge sessions: %w", err)
+  }
+
+  deleted, err := result.RowsAffected()
+  if err != nil {
+    return 0, fmt.Errorf("failed to purge sessions: %w", err)
+  }
+
+  return deleted, nil
+}
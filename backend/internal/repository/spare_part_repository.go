@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -10,14 +11,15 @@ import (
 )
 
 type SparePartRepository interface {
-	Create(sparePart *entity.SparePart) error
-	GetByID(id int) (*entity.SparePart, error)
-	GetByCode(code string) (*entity.SparePart, error)
-	List(page, limit int, search string) ([]entity.SparePart, int, error)
-	Update(sparePart *entity.SparePart) error
-	Delete(id int) error
-	UpdateStock(id int, quantity int) error
-	GeneratePartCode() (string, error)
+	Create(ctx context.Context, sparePart *entity.SparePart) error
+	CreateBatch(ctx context.Context, spareParts []*entity.SparePart) error
+	GetByID(ctx context.Context, id int) (*entity.SparePart, error)
+	GetByCode(ctx context.Context, code string) (*entity.SparePart, error)
+	List(ctx context.Context, params entity.ListParams, search string) ([]entity.SparePart, *entity.PageInfo, error)
+	Update(ctx context.Context, sparePart *entity.SparePart) error
+	Delete(ctx context.Context, id int) error
+	UpdateStock(ctx context.Context, id int, quantity int) error
+	GeneratePartCode(ctx context.Context) (string, error)
 }
 
 type sparePartRepository struct {
@@ -28,7 +30,42 @@ func NewSparePartRepository(db *sqlx.DB) SparePartRepository {
 	return &sparePartRepository{db: db}
 }
 
-func (r *sparePartRepository) Create(sparePart *entity.SparePart) error {
+func (r *sparePartRepository) Create(ctx context.Context, sparePart *entity.SparePart) error {
+	return insertSparePart(ctx, r.db, sparePart)
+}
+
+// CreateBatch inserts spare parts in one transaction, each with the next
+// part code from the same generator as single creates. The table is locked
+// against other inserts meanwhile so that the codes cannot collide.
+func (r *sparePartRepository) CreateBatch(ctx context.Context, spareParts []*entity.SparePart) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE spare_parts IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("failed to lock spare_parts: %w", err)
+	}
+
+	for _, sparePart := range spareParts {
+		code, err := nextPartCode(ctx, tx)
+		if err != nil {
+			return err
+		}
+		sparePart.PartCode = code
+		if err := insertSparePart(ctx, tx, sparePart); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit spare part import: %w", err)
+	}
+	return nil
+}
+
+func insertSparePart(ctx context.Context, q sqlx.QueryerContext, sparePart *entity.SparePart) error {
 	query := `
 		INSERT INTO spare_parts (part_code, name, description, brand, cost_price, selling_price, 
 		                        stock_quantity, min_stock_level, unit_measure, is_active)
@@ -36,7 +73,7 @@ func (r *sparePartRepository) Create(sparePart *entity.SparePart) error {
 		RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRow(
+	err := q.QueryRowxContext(ctx,
 		query,
 		sparePart.PartCode,
 		sparePart.Name,
@@ -51,13 +88,13 @@ func (r *sparePartRepository) Create(sparePart *entity.SparePart) error {
 	).Scan(&sparePart.ID, &sparePart.CreatedAt, &sparePart.UpdatedAt)
 
 	if err != nil {
-		return fmt.Errorf("failed to create spare part: %w", err)
+		return wrapWriteError("create spare part", err)
 	}
 
 	return nil
 }
 
-func (r *sparePartRepository) GetByID(id int) (*entity.SparePart, error) {
+func (r *sparePartRepository) GetByID(ctx context.Context, id int) (*entity.SparePart, error) {
 	sparePart := &entity.SparePart{}
 	query := `
 		SELECT id, part_code, name, description, brand, cost_price, selling_price,
@@ -66,7 +103,7 @@ func (r *sparePartRepository) GetByID(id int) (*entity.SparePart, error) {
 		WHERE id = $1 AND is_active = true
 	`
 
-	err := r.db.Get(sparePart, query, id)
+	err := r.db.GetContext(ctx, sparePart, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -77,7 +114,7 @@ func (r *sparePartRepository) GetByID(id int) (*entity.SparePart, error) {
 	return sparePart, nil
 }
 
-func (r *sparePartRepository) GetByCode(code string) (*entity.SparePart, error) {
+func (r *sparePartRepository) GetByCode(ctx context.Context, code string) (*entity.SparePart, error) {
 	sparePart := &entity.SparePart{}
 	query := `
 		SELECT id, part_code, name, description, brand, cost_price, selling_price,
@@ -86,7 +123,7 @@ func (r *sparePartRepository) GetByCode(code string) (*entity.SparePart, error)
 		WHERE part_code = $1 AND is_active = true
 	`
 
-	err := r.db.Get(sparePart, query, code)
+	err := r.db.GetContext(ctx, sparePart, query, code)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -97,9 +134,20 @@ func (r *sparePartRepository) GetByCode(code string) (*entity.SparePart, error)
 	return sparePart, nil
 }
 
-func (r *sparePartRepository) List(page, limit int, search string) ([]entity.SparePart, int, error) {
-	offset := (page - 1) * limit
+var sparePartSort = listSort[entity.SparePart]{
+	keys: map[string]sortKey[entity.SparePart]{
+		"created_at":     {"created_at", func(p *entity.SparePart) interface{} { return p.CreatedAt }},
+		"name":           {"name", func(p *entity.SparePart) interface{} { return p.Name }},
+		"part_code":      {"part_code", func(p *entity.SparePart) interface{} { return p.PartCode }},
+		"stock_quantity": {"stock_quantity", func(p *entity.SparePart) interface{} { return p.StockQuantity }},
+		"selling_price":  {"selling_price", func(p *entity.SparePart) interface{} { return p.SellingPrice }},
+	},
+	defaultSort: []entity.SortField{{Field: "created_at", Desc: true}},
+	idExpr:      "id",
+	id:          func(p *entity.SparePart) int { return p.ID },
+}
 
+func (r *sparePartRepository) List(ctx context.Context, params entity.ListParams, search string) ([]entity.SparePart, *entity.PageInfo, error) {
 	whereClause := "WHERE is_active = true"
 	args := []interface{}{}
 	argIndex := 1
@@ -112,36 +160,42 @@ func (r *sparePartRepository) List(page, limit int, search string) ([]entity.Spa
 		argIndex += 4
 	}
 
-	// Get total count
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM spare_parts %s", whereClause)
-	var total int
-	err := r.db.Get(&total, countQuery, args...)
+	plan, err := sparePartSort.plan(params, argIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total, err := countTotal(params, func() (int, error) {
+		var total int
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM spare_parts %s", whereClause)
+		if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+			return 0, fmt.Errorf("failed to get spare part count: %w", err)
+		}
+		return total, nil
+	})
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get spare part count: %w", err)
+		return nil, nil, err
 	}
 
-	// Get spare parts
 	query := fmt.Sprintf(`
 		SELECT id, part_code, name, description, brand, cost_price, selling_price,
 		       stock_quantity, min_stock_level, unit_measure, created_at, updated_at, is_active
 		FROM spare_parts
+		%s%s
 		%s
-		ORDER BY created_at DESC
-		LIMIT $%d OFFSET $%d
-	`, whereClause, argIndex, argIndex+1)
-
-	args = append(args, limit, offset)
+		%s
+	`, whereClause, plan.keyset, plan.orderBy, plan.limit)
 
 	var spareParts []entity.SparePart
-	err = r.db.Select(&spareParts, query, args...)
+	err = r.db.SelectContext(ctx, &spareParts, query, append(args, plan.args...)...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list spare parts: %w", err)
+		return nil, nil, fmt.Errorf("failed to list spare parts: %w", err)
 	}
 
-	return spareParts, total, nil
+	return sparePartSort.page(spareParts, plan, total)
 }
 
-func (r *sparePartRepository) Update(sparePart *entity.SparePart) error {
+func (r *sparePartRepository) Update(ctx context.Context, sparePart *entity.SparePart) error {
 	query := `
 		UPDATE spare_parts
 		SET name = $1, description = $2, brand = $3, cost_price = $4, selling_price = $5,
@@ -149,20 +203,20 @@ func (r *sparePartRepository) Update(sparePart *entity.SparePart) error {
 		WHERE id = $9
 	`
 
-	_, err := r.db.Exec(query, sparePart.Name, sparePart.Description, sparePart.Brand,
+	_, err := r.db.ExecContext(ctx, query, sparePart.Name, sparePart.Description, sparePart.Brand,
 		sparePart.CostPrice, sparePart.SellingPrice, sparePart.MinStockLevel,
 		sparePart.UnitMeasure, sparePart.IsActive, sparePart.ID)
 	if err != nil {
-		return fmt.Errorf("failed to update spare part: %w", err)
+		return wrapWriteError("update spare part", err)
 	}
 
 	return nil
 }
 
-func (r *sparePartRepository) Delete(id int) error {
+func (r *sparePartRepository) Delete(ctx context.Context, id int) error {
 	query := `UPDATE spare_parts SET is_active = false WHERE id = $1`
 
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete spare part: %w", err)
 	}
@@ -170,10 +224,10 @@ func (r *sparePartRepository) Delete(id int) error {
 	return nil
 }
 
-func (r *sparePartRepository) UpdateStock(id int, quantity int) error {
+func (r *sparePartRepository) UpdateStock(ctx context.Context, id int, quantity int) error {
 	query := `UPDATE spare_parts SET stock_quantity = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
 
-	_, err := r.db.Exec(query, quantity, id)
+	_, err := r.db.ExecContext(ctx, query, quantity, id)
 	if err != nil {
 		return fmt.Errorf("failed to update spare part stock: %w", err)
 	}
@@ -181,17 +235,21 @@ func (r *sparePartRepository) UpdateStock(id int, quantity int) error {
 	return nil
 }
 
-func (r *sparePartRepository) GeneratePartCode() (string, error) {
+func (r *sparePartRepository) GeneratePartCode(ctx context.Context) (string, error) {
+	return nextPartCode(ctx, r.db)
+}
+
+func nextPartCode(ctx context.Context, q sqlx.QueryerContext) (string, error) {
 	var lastCode string
 	query := `
 		SELECT part_code 
 		FROM spare_parts 
 		WHERE part_code LIKE 'PART-%' 
-		ORDER BY part_code DESC 
+		ORDER BY LENGTH(part_code) DESC, part_code DESC 
 		LIMIT 1
 	`
 
-	err := r.db.Get(&lastCode, query)
+	err := sqlx.GetContext(ctx, q, &lastCode, query)
 	if err != nil && err != sql.ErrNoRows {
 		return "", fmt.Errorf("failed to get last part code: %w", err)
 	}
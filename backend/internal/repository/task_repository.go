@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"vehicle-showroom/internal/entity"
+)
+
+type TaskRepository interface {
+	Create(ctx context.Context, task *entity.Task) error
+	GetByID(ctx context.Context, id int) (*entity.Task, error)
+	List(ctx context.Context, params entity.ListParams, filter entity.TaskFilter) ([]entity.Task, *entity.PageInfo, error)
+	Update(ctx context.Context, task *entity.Task, now time.Time) error
+	UpdateStatus(ctx context.Context, id int, status string, now time.Time) error
+	EntityExists(ctx context.Context, entityType string, id int) (bool, error)
+	ClaimReminders(ctx context.Context, now time.Time, limit int) ([]entity.TaskDue, error)
+	MarkOverdue(ctx context.Context, now time.Time, limit int) ([]entity.TaskDue, error)
+}
+
+type taskRepository struct {
+	db *sqlx.DB
+}
+
+func NewTaskRepository(db *sqlx.DB) TaskRepository {
+	return &taskRepository{db: db}
+}
+
+const taskColumns = `
+	t.id, t.title, t.description, t.assigned_to, t.due_at, t.remind_at, t.status,
+	t.entity_type, t.entity_id, t.reminded_at, t.overdue_at, t.completed_at,
+	t.created_by, t.created_at, t.updated_at
+`
+
+// taskEntityTables maps the kinds of record a task links to onto their
+// tables. Only these names are ever put into a query.
+var taskEntityTables = map[string]string{
+	entity.TaskEntityCustomer: "customers",
+	entity.TaskEntityVehicle:  "vehicles",
+	entity.TaskEntitySale:     "sales_transactions",
+	entity.TaskEntityRepair:   "repairs",
+	entity.TaskEntityLead:     "leads",
+}
+
+func (r *taskRepository) Create(ctx context.Context, task *entity.Task) error {
+	query := `
+		INSERT INTO tasks (title, description, assigned_to, due_at, remind_at, status, entity_type, entity_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRowxContext(ctx, query,
+		task.Title,
+		task.Description,
+		task.AssignedTo,
+		task.DueAt,
+		task.RemindAt,
+		task.Status,
+		task.EntityType,
+		task.EntityID,
+		task.CreatedBy,
+	).Scan(&task.ID, &task.CreatedAt, &task.UpdatedAt)
+	if err != nil {
+		return wrapWriteError("create task", err)
+	}
+	return nil
+}
+
+func (r *taskRepository) GetByID(ctx context.Context, id int) (*entity.Task, error) {
+	task := &entity.Task{}
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.id = $1`
+
+	err := r.db.GetContext(ctx, task, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get task by id: %w", err)
+	}
+
+	tasks := []entity.Task{*task}
+	if err := attachTaskAssignees(ctx, r.db, tasks); err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
+}
+
+var taskSort = listSort[entity.Task]{
+	keys: map[string]sortKey[entity.Task]{
+		"due_at":     {"t.due_at", func(t *entity.Task) interface{} { return t.DueAt }},
+		"created_at": {"t.created_at", func(t *entity.Task) interface{} { return t.CreatedAt }},
+		"status":     {"t.status", func(t *entity.Task) interface{} { return t.Status }},
+		"title":      {"t.title", func(t *entity.Task) interface{} { return t.Title }},
+	},
+	defaultSort: []entity.SortField{{Field: "due_at"}},
+	idExpr:      "t.id",
+	id:          func(t *entity.Task) int { return t.ID },
+}
+
+func (r *taskRepository) List(ctx context.Context, params entity.ListParams, filter entity.TaskFilter) ([]entity.Task, *entity.PageInfo, error) {
+	w := &whereBuilder{}
+	if filter.Status != "" {
+		w.add("t.status = $?", filter.Status)
+	}
+	if filter.Pending {
+		w.add("t.status IN ('open', 'in_progress', 'overdue')")
+	}
+	if filter.AssignedTo != nil {
+		w.add("t.assigned_to = $?", *filter.AssignedTo)
+	}
+	if filter.EntityType != "" {
+		w.add("t.entity_type = $?", filter.EntityType)
+	}
+	if filter.EntityID != nil {
+		w.add("t.entity_id = $?", *filter.EntityID)
+	}
+	if filter.DueBefore != nil {
+		w.add("t.due_at < $?", *filter.DueBefore)
+	}
+
+	plan, err := taskSort.plan(params, w.nextArg())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total, err := countTotal(params, func() (int, error) {
+		var total int
+		countQuery := `SELECT COUNT(*) FROM tasks t ` + w.clause()
+		if err := r.db.GetContext(ctx, &total, countQuery, w.args...); err != nil {
+			return 0, fmt.Errorf("failed to get task count: %w", err)
+		}
+		return total, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM tasks t
+		%s%s
+		%s
+		%s
+	`, taskColumns, w.clause(), plan.keyset, plan.orderBy, plan.limit)
+
+	var tasks []entity.Task
+	if err := r.db.SelectContext(ctx, &tasks, query, append(w.args, plan.args...)...); err != nil {
+		return nil, nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	tasks, page, err := taskSort.page(tasks, plan, total)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := attachTaskAssignees(ctx, r.db, tasks); err != nil {
+		return nil, nil, err
+	}
+	return tasks, page, nil
+}
+
+// Update saves the task's details. A new reminder time re-arms the
+// reminder, and an overdue task given a due date after now is open again.
+func (r *taskRepository) Update(ctx context.Context, task *entity.Task, now time.Time) error {
+	query := `
+		UPDATE tasks
+		SET title = $1, description = $2, assigned_to = $3, due_at = $4, remind_at = $5,
+		    entity_type = $6, entity_id = $7,
+		    reminded_at = CASE WHEN remind_at IS DISTINCT FROM $5 THEN NULL ELSE reminded_at END,
+		    status = CASE WHEN status = 'overdue' AND $4 > $8 THEN 'open' ELSE status END,
+		    overdue_at = CASE WHEN status = 'overdue' AND $4 > $8 THEN NULL ELSE overdue_at END,
+		    updated_at = $8
+		WHERE id = $9
+	`
+	_, err := r.db.ExecContext(ctx, query, task.Title, task.Description, task.AssignedTo, task.DueAt,
+		task.RemindAt, task.EntityType, task.EntityID, now, task.ID)
+	if err != nil {
+		return wrapWriteError("update task", err)
+	}
+	return nil
+}
+
+// UpdateStatus stamps completed_at on done tasks. Reopening a task clears
+// overdue_at, so the scheduler flags it again if it is still past due.
+func (r *taskRepository) UpdateStatus(ctx context.Context, id int, status string, now time.Time) error {
+	query := `
+		UPDATE tasks
+		SET status = $1,
+		    completed_at = CASE WHEN $1 = 'done' THEN $2 END,
+		    overdue_at = CASE WHEN $1 IN ('open', 'in_progress') THEN NULL ELSE overdue_at END,
+		    updated_at = $2
+		WHERE id = $3
+	`
+	if _, err := r.db.ExecContext(ctx, query, status, now, id); err != nil {
+		return wrapWriteError("update task status", err)
+	}
+	return nil
+}
+
+func (r *taskRepository) EntityExists(ctx context.Context, entityType string, id int) (bool, error) {
+	table, ok := taskEntityTables[entityType]
+	if !ok {
+		return false, nil
+	}
+
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)`, table)
+	if err := r.db.GetContext(ctx, &exists, query, id); err != nil {
+		return false, fmt.Errorf("failed to check task %s: %w", entityType, err)
+	}
+	return exists, nil
+}
+
+// The scheduler claims due tasks with UPDATE ... RETURNING over rows locked
+// with SKIP LOCKED, so when several servers run it each task is claimed by
+// exactly one of them.
+const (
+	claimRemindersQuery = `
+		UPDATE tasks t
+		SET reminded_at = $1
+		FROM users u
+		WHERE u.id = t.assigned_to AND t.id IN (
+			SELECT id FROM tasks
+			WHERE status IN ('open', 'in_progress') AND reminded_at IS NULL
+			      AND remind_at <= $1 AND due_at > $1
+			ORDER BY remind_at, id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + taskColumns + `, u.email AS assignee_email, u.full_name AS assignee_name
+	`
+	markOverdueQuery = `
+		UPDATE tasks t
+		SET status = 'overdue', overdue_at = $1, updated_at = $1
+		FROM users u
+		WHERE u.id = t.assigned_to AND t.id IN (
+			SELECT id FROM tasks
+			WHERE status IN ('open', 'in_progress') AND due_at <= $1
+			ORDER BY due_at, id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + taskColumns + `, u.email AS assignee_email, u.full_name AS assignee_name
+	`
+)
+
+// ClaimReminders stamps reminded_at on up to limit tasks whose reminder is
+// due and returns them. Tasks already past due are left to MarkOverdue.
+func (r *taskRepository) ClaimReminders(ctx context.Context, now time.Time, limit int) ([]entity.TaskDue, error) {
+	tasks := []entity.TaskDue{}
+	if err := r.db.SelectContext(ctx, &tasks, claimRemindersQuery, now, limit); err != nil {
+		return nil, fmt.Errorf("failed to claim task reminders: %w", err)
+	}
+	return tasks, nil
+}
+
+// MarkOverdue flags up to limit pending tasks past their due date as
+// overdue and returns them.
+func (r *taskRepository) MarkOverdue(ctx context.Context, now time.Time, limit int) ([]entity.TaskDue, error) {
+	tasks := []entity.TaskDue{}
+	if err := r.db.SelectContext(ctx, &tasks, markOverdueQuery, now, limit); err != nil {
+		return nil, fmt.Errorf("failed to mark tasks overdue: %w", err)
+	}
+	return tasks, nil
+}
+
+// attachTaskAssignees fills Assignee with one query for all the tasks.
+func attachTaskAssignees(ctx context.Context, db *sqlx.DB, tasks []entity.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	userIDs := make([]int, len(tasks))
+	for i, task := range tasks {
+		userIDs[i] = task.AssignedTo
+	}
+	users, err := loadUsers(ctx, db, userIDs)
+	if err != nil {
+		return err
+	}
+	for i := range tasks {
+		tasks[i].Assignee = users[tasks[i].AssignedTo]
+	}
+	return nil
+}
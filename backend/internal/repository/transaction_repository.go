@@ -1,6 +1,7 @@
 package repository
 
 import (
+  "context"
   "database/sql"
   "fmt"
   "strings"
@@ -12,23 +13,23 @@ import (
 
 type TransactionRepository interface {
   // Purchase Transactions
-  CreatePurchase(tx *entity.PurchaseTransaction) error
-  GetPurchaseByID(id int) (*entity.PurchaseTransaction, error)
-  ListPurchases(page, limit int, search string) ([]entity.PurchaseTransaction, int, error)
+  CreatePurchase(ctx context.Context, tx *entity.PurchaseTransaction) error
+  GetPurchaseByID(ctx context.Context, id int) (*entity.PurchaseTransaction, error)
+  ListPurchases(ctx context.Context, params entity.ListParams, search string) ([]entity.PurchaseTransaction, *entity.PageInfo, error)
   
   // Sales Transactions
-  CreateSales(tx *entity.SalesTransaction) error
-  GetSalesByID(id int) (*entity.SalesTransaction, error)
-  ListSales(page, limit int, search string) ([]entity.SalesTransaction, int, error)
+  CreateSales(ctx context.Context, tx *entity.SalesTransaction) error
+  GetSalesByID(ctx context.Context, id int) (*entity.SalesTransaction, error)
+  ListSales(ctx context.Context, params entity.ListParams, search string) ([]entity.SalesTransaction, *entity.PageInfo, error)
   
   // Transaction Numbers
-  GeneratePurchaseTransactionNumber() (string, error)
-  GenerateSalesTransactionNumber() (string, error)
-  GeneratePurchaseInvoiceNumber() (string, error)
-  GenerateSalesInvoiceNumber() (string, error)
+  GeneratePurchaseTransactionNumber(ctx context.Context) (string, error)
+  GenerateSalesTransactionNumber(ctx context.Context) (string, error)
+  GeneratePurchaseInvoiceNumber(ctx context.Context) (string, error)
+  GenerateSalesInvoiceNumber(ctx context.Context) (string, error)
   
   // Dashboard Stats
-  GetDashboardStats() (*entity.DashboardStats, error)
+  GetDashboardStats(ctx context.Context) (*entity.DashboardStats, error)
 }
 
 type transactionRepository struct {
@@ -39,7 +40,7 @@ func NewTransactionRepository(db *sqlx.DB) TransactionRepository {
   return &transactionRepository{db: db}
 }
 
-func (r *transactionRepository) CreatePurchase(tx *entity.PurchaseTransaction) error {
+func (r *transactionRepository) CreatePurchase(ctx context.Context, tx *entity.PurchaseTransaction) error {
   query := `
     INSERT INTO purchase_transactions (
       transaction_number, invoice_number, vehicle_id, customer_id, vehicle_price,
@@ -50,7 +51,7 @@ func (r *transactionRepository) CreatePurchase(tx *entity.PurchaseTransaction) e
     RETURNING id, created_at
   `
   
-  err := r.db.QueryRow(
+  err := r.db.QueryRowContext(ctx,
     query,
     tx.TransactionNumber,
     tx.InvoiceNumber,
@@ -68,13 +69,13 @@ func (r *transactionRepository) CreatePurchase(tx *entity.PurchaseTransaction) e
   ).Scan(&tx.ID, &tx.CreatedAt)
   
   if err != nil {
-    return fmt.Errorf("failed to create purchase transaction: %w", err)
+    return wrapWriteError("create purchase transaction", err)
   }
   
   return nil
 }
 
-func (r *transactionRepository) GetPurchaseByID(id int) (*entity.PurchaseTransaction, error) {
+func (r *transactionRepository) GetPurchaseByID(ctx context.Context, id int) (*entity.PurchaseTransaction, error) {
   tx := &entity.PurchaseTransaction{}
   query := `
     SELECT pt.id, pt.transaction_number, pt.invoice_number, pt.vehicle_id, pt.customer_id,
@@ -85,7 +86,7 @@ func (r *transactionRepository) GetPurchaseByID(id int) (*entity.PurchaseTransac
     WHERE pt.id = $1
   `
   
-  err := r.db.Get(tx, query, id)
+  err := r.db.GetContext(ctx, tx, query, id)
   if err != nil {
     if err == sql.ErrNoRows {
       return nil, nil
@@ -93,43 +94,62 @@ func (r *transactionRepository) GetPurchaseByID(id int) (*entity.PurchaseTransac
     return nil, fmt.Errorf("failed to get purchase transaction by id: %w", err)
   }
   
-  // Load related data
-  r.loadPurchaseRelatedData(tx)
+  transactions := []entity.PurchaseTransaction{*tx}
+  if err := attachPurchaseRelations(ctx, r.db, transactions); err != nil {
+    return nil, err
+  }
   
-  return tx, nil
+  return &transactions[0], nil
 }
 
-func (r *transactionRepository) ListPurchases(page, limit int, search string) ([]entity.PurchaseTransaction, int, error) {
-  offset := (page - 1) * limit
-  
+var purchaseSort = listSort[entity.PurchaseTransaction]{
+  keys: map[string]sortKey[entity.PurchaseTransaction]{
+    "created_at":         {"pt.created_at", func(t *entity.PurchaseTransaction) interface{} { return t.CreatedAt }},
+    "transaction_date":   {"pt.transaction_date", func(t *entity.PurchaseTransaction) interface{} { return t.TransactionDate }},
+    "transaction_number": {"pt.transaction_number", func(t *entity.PurchaseTransaction) interface{} { return t.TransactionNumber }},
+    "total_amount":       {"pt.total_amount", func(t *entity.PurchaseTransaction) interface{} { return t.TotalAmount }},
+  },
+  defaultSort: []entity.SortField{{Field: "created_at", Desc: true}},
+  idExpr:      "pt.id",
+  id:          func(t *entity.PurchaseTransaction) int { return t.ID },
+}
+
+func (r *transactionRepository) ListPurchases(ctx context.Context, params entity.ListParams, search string) ([]entity.PurchaseTransaction, *entity.PageInfo, error) {
   whereClause := "WHERE 1=1"
   args := []interface{}{}
   argIndex := 1
   
   if search != "" {
     whereClause += fmt.Sprintf(" AND (pt.transaction_number ILIKE $%d OR pt.invoice_number ILIKE $%d OR c.name ILIKE $%d OR v.brand ILIKE $%d OR v.model ILIKE $%d)", 
-                              argIndex, argIndex+1, argIndex+2, argIndex+3, argIndex+4)
+      argIndex, argIndex+1, argIndex+2, argIndex+3, argIndex+4)
     searchPattern := "%" + search + "%"
     args = append(args, searchPattern, searchPattern, searchPattern, searchPattern, searchPattern)
     argIndex += 5
   }
   
-  // Get total count
-  countQuery := fmt.Sprintf(`
-    SELECT COUNT(*) 
-    FROM purchase_transactions pt
-    LEFT JOIN customers c ON pt.customer_id = c.id
-    LEFT JOIN vehicles v ON pt.vehicle_id = v.id
-    %s
-  `, whereClause)
-  
-  var total int
-  err := r.db.Get(&total, countQuery, args...)
+  plan, err := purchaseSort.plan(params, argIndex)
+  if err != nil {
+    return nil, nil, err
+  }
+  
+  total, err := countTotal(params, func() (int, error) {
+    var total int
+    countQuery := fmt.Sprintf(`
+      SELECT COUNT(*) 
+      FROM purchase_transactions pt
+      LEFT JOIN customers c ON pt.customer_id = c.id
+      LEFT JOIN vehicles v ON pt.vehicle_id = v.id
+      %s
+    `, whereClause)
+    if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+      return 0, fmt.Errorf("failed to get purchase transaction count: %w", err)
+    }
+    return total, nil
+  })
   if err != nil {
-    return nil, 0, fmt.Errorf("failed to get purchase transaction count: %w", err)
+    return nil, nil, err
   }
   
-  // Get transactions
   query := fmt.Sprintf(`
     SELECT pt.id, pt.transaction_number, pt.invoice_number, pt.vehicle_id, pt.customer_id,
            pt.vehicle_price, pt.tax_amount, pt.total_amount, pt.payment_method,
@@ -138,28 +158,30 @@ func (r *transactionRepository) ListPurchases(page, limit int, search string) ([
     FROM purchase_transactions pt
     LEFT JOIN customers c ON pt.customer_id = c.id
     LEFT JOIN vehicles v ON pt.vehicle_id = v.id
+    %s%s
     %s
-    ORDER BY pt.created_at DESC
-    LIMIT $%d OFFSET $%d
-  `, whereClause, argIndex, argIndex+1)
-  
-  args = append(args, limit, offset)
+    %s
+  `, whereClause, plan.keyset, plan.orderBy, plan.limit)
   
   var transactions []entity.PurchaseTransaction
-  err = r.db.Select(&transactions, query, args...)
+  err = r.db.SelectContext(ctx, &transactions, query, append(args, plan.args...)...)
   if err != nil {
-    return nil, 0, fmt.Errorf("failed to list purchase transactions: %w", err)
+    return nil, nil, fmt.Errorf("failed to list purchase transactions: %w", err)
   }
   
-  // Load related data for each transaction
-  for i := range transactions {
-    r.loadPurchaseRelatedData(&transactions[i])
+  transactions, page, err := purchaseSort.page(transactions, plan, total)
+  if err != nil {
+    return nil, nil, err
+  }
+  
+  if err := attachPurchaseRelations(ctx, r.db, transactions); err != nil {
+    return nil, nil, err
   }
   
-  return transactions, total, nil
+  return transactions, page, nil
 }
 
-func (r *transactionRepository) CreateSales(tx *entity.SalesTransaction) error {
+func (r *transactionRepository) CreateSales(ctx context.Context, tx *entity.SalesTransaction) error {
   query := `
     INSERT INTO sales_transactions (
       transaction_number, invoice_number, vehicle_id, customer_id, vehicle_price,
@@ -170,7 +192,7 @@ func (r *transactionRepository) CreateSales(tx *entity.SalesTransaction) error {
     RETURNING id, created_at
   `
   
-  err := r.db.QueryRow(
+  err := r.db.QueryRowContext(ctx,
     query,
     tx.TransactionNumber,
     tx.InvoiceNumber,
@@ -189,13 +211,13 @@ func (r *transactionRepository) CreateSales(tx *entity.SalesTransaction) error {
   ).Scan(&tx.ID, &tx.CreatedAt)
   
   if err != nil {
-    return fmt.Errorf("failed to create sales transaction: %w", err)
+    return wrapWriteError("create sales transaction", err)
   }
   
   return nil
 }
 
-func (r *transactionRepository) GetSalesByID(id int) (*entity.SalesTransaction, error) {
+func (r *transactionRepository) GetSalesByID(ctx context.Context, id int) (*entity.SalesTransaction, error) {
   tx := &entity.SalesTransaction{}
   query := `
     SELECT st.id, st.transaction_number, st.invoice_number, st.vehicle_id, st.customer_id,
@@ -206,7 +228,7 @@ func (r *transactionRepository) GetSalesByID(id int) (*entity.SalesTransaction,
     WHERE st.id = $1
   `
   
-  err := r.db.Get(tx, query, id)
+  err := r.db.GetContext(ctx, tx, query, id)
   if err != nil {
     if err == sql.ErrNoRows {
       return nil, nil
@@ -214,43 +236,62 @@ func (r *transactionRepository) GetSalesByID(id int) (*entity.SalesTransaction,
     return nil, fmt.Errorf("failed to get sales transaction by id: %w", err)
   }
   
-  // Load related data
-  r.loadSalesRelatedData(tx)
+  transactions := []entity.SalesTransaction{*tx}
+  if err := attachSalesRelations(ctx, r.db, transactions); err != nil {
+    return nil, err
+  }
   
-  return tx, nil
+  return &transactions[0], nil
 }
 
-func (r *transactionRepository) ListSales(page, limit int, search string) ([]entity.SalesTransaction, int, error) {
-  offset := (page - 1) * limit
-  
+var saleSort = listSort[entity.SalesTransaction]{
+  keys: map[string]sortKey[entity.SalesTransaction]{
+    "created_at":         {"st.created_at", func(t *entity.SalesTransaction) interface{} { return t.CreatedAt }},
+    "transaction_date":   {"st.transaction_date", func(t *entity.SalesTransaction) interface{} { return t.TransactionDate }},
+    "transaction_number": {"st.transaction_number", func(t *entity.SalesTransaction) interface{} { return t.TransactionNumber }},
+    "total_amount":       {"st.total_amount", func(t *entity.SalesTransaction) interface{} { return t.TotalAmount }},
+  },
+  defaultSort: []entity.SortField{{Field: "created_at", Desc: true}},
+  idExpr:      "st.id",
+  id:          func(t *entity.SalesTransaction) int { return t.ID },
+}
+
+func (r *transactionRepository) ListSales(ctx context.Context, params entity.ListParams, search string) ([]entity.SalesTransaction, *entity.PageInfo, error) {
   whereClause := "WHERE 1=1"
   args := []interface{}{}
   argIndex := 1
   
   if search != "" {
     whereClause += fmt.Sprintf(" AND (st.transaction_number ILIKE $%d OR st.invoice_number ILIKE $%d OR c.name ILIKE $%d OR v.brand ILIKE $%d OR v.model ILIKE $%d)", 
-                              argIndex, argIndex+1, argIndex+2, argIndex+3, argIndex+4)
+      argIndex, argIndex+1, argIndex+2, argIndex+3, argIndex+4)
     searchPattern := "%" + search + "%"
     args = append(args, searchPattern, searchPattern, searchPattern, searchPattern, searchPattern)
     argIndex += 5
   }
   
-  // Get total count
-  countQuery := fmt.Sprintf(`
-    SELECT COUNT(*) 
-    FROM sales_transactions st
-    LEFT JOIN customers c ON st.customer_id = c.id
-    LEFT JOIN vehicles v ON st.vehicle_id = v.id
-    %s
-  `, whereClause)
-  
-  var total int
-  err := r.db.Get(&total, countQuery, args...)
+  plan, err := saleSort.plan(params, argIndex)
+  if err != nil {
+    return nil, nil, err
+  }
+  
+  total, err := countTotal(params, func() (int, error) {
+    var total int
+    countQuery := fmt.Sprintf(`
+      SELECT COUNT(*) 
+      FROM sales_transactions st
+      LEFT JOIN customers c ON st.customer_id = c.id
+      LEFT JOIN vehicles v ON st.vehicle_id = v.id
+      %s
+    `, whereClause)
+    if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+      return 0, fmt.Errorf("failed to get sale transaction count: %w", err)
+    }
+    return total, nil
+  })
   if err != nil {
-    return nil, 0, fmt.Errorf("failed to get sales transaction count: %w", err)
+    return nil, nil, err
   }
   
-  // Get transactions
   query := fmt.Sprintf(`
     SELECT st.id, st.transaction_number, st.invoice_number, st.vehicle_id, st.customer_id,
            st.vehicle_price, st.tax_amount, st.discount_amount, st.total_amount,
@@ -259,39 +300,41 @@ func (r *transactionRepository) ListSales(page, limit int, search string) ([]ent
     FROM sales_transactions st
     LEFT JOIN customers c ON st.customer_id = c.id
     LEFT JOIN vehicles v ON st.vehicle_id = v.id
+    %s%s
     %s
-    ORDER BY st.created_at DESC
-    LIMIT $%d OFFSET $%d
-  `, whereClause, argIndex, argIndex+1)
-  
-  args = append(args, limit, offset)
+    %s
+  `, whereClause, plan.keyset, plan.orderBy, plan.limit)
   
   var transactions []entity.SalesTransaction
-  err = r.db.Select(&transactions, query, args...)
+  err = r.db.SelectContext(ctx, &transactions, query, append(args, plan.args...)...)
   if err != nil {
-    return nil, 0, fmt.Errorf("failed to list sales transactions: %w", err)
+    return nil, nil, fmt.Errorf("failed to list sale transactions: %w", err)
   }
   
-  // Load related data for each transaction
-  for i := range transactions {
-    r.loadSalesRelatedData(&transactions[i])
+  transactions, page, err := saleSort.page(transactions, plan, total)
+  if err != nil {
+    return nil, nil, err
   }
   
-  return transactions, total, nil
+  if err := attachSalesRelations(ctx, r.db, transactions); err != nil {
+    return nil, nil, err
+  }
+  
+  return transactions, page, nil
 }
 
-func (r *transactionRepository) GeneratePurchaseTransactionNumber() (string, error) {
+func (r *transactionRepository) GeneratePurchaseTransactionNumber(ctx context.Context) (string, error) {
   today := time.Now().Format("20060102")
   var lastNumber string
   query := `
     SELECT transaction_number 
     FROM purchase_transactions 
     WHERE transaction_number LIKE 'PUR-' || $1 || '-%' 
-    ORDER BY transaction_number DESC 
+    ORDER BY LENGTH(transaction_number) DESC, transaction_number DESC 
     LIMIT 1
   `
   
-  err := r.db.Get(&lastNumber, query, today)
+  err := r.db.GetContext(ctx, &lastNumber, query, today)
   if err != nil && err != sql.ErrNoRows {
     return "", fmt.Errorf("failed to get last purchase transaction number: %w", err)
   }
@@ -309,18 +352,18 @@ func (r *transactionRepository) GeneratePurchaseTransactionNumber() (string, err
   return fmt.Sprintf("PUR-%s-%03d", today, nextNumber), nil
 }
 
-func (r *transactionRepository) GenerateSalesTransactionNumber() (string, error) {
+func (r *transactionRepository) GenerateSalesTransactionNumber(ctx context.Context) (string, error) {
   today := time.Now().Format("20060102")
   var lastNumber string
   query := `
     SELECT transaction_number 
     FROM sales_transactions 
     WHERE transaction_number LIKE 'SAL-' || $1 || '-%' 
-    ORDER BY transaction_number DESC 
+    ORDER BY LENGTH(transaction_number) DESC, transaction_number DESC 
     LIMIT 1
   `
   
-  err := r.db.Get(&lastNumber, query, today)
+  err := r.db.GetContext(ctx, &lastNumber, query, today)
   if err != nil && err != sql.ErrNoRows {
     return "", fmt.Errorf("failed to get last sales transaction number: %w", err)
   }
@@ -338,18 +381,18 @@ func (r *transactionRepository) GenerateSalesTransactionNumber() (string, error)
   return fmt.Sprintf("SAL-%s-%03d", today, nextNumber), nil
 }
 
-func (r *transactionRepository) GeneratePurchaseInvoiceNumber() (string, error) {
+func (r *transactionRepository) GeneratePurchaseInvoiceNumber(ctx context.Context) (string, error) {
   today := time.Now().Format("20060102")
   var lastNumber string
   query := `
     SELECT invoice_number 
     FROM purchase_transactions 
     WHERE invoice_number LIKE 'INV-PUR-' || $1 || '-%' 
-    ORDER BY invoice_number DESC 
+    ORDER BY LENGTH(invoice_number) DESC, invoice_number DESC 
     LIMIT 1
   `
   
-  err := r.db.Get(&lastNumber, query, today)
+  err := r.db.GetContext(ctx, &lastNumber, query, today)
   if err != nil && err != sql.ErrNoRows {
     return "", fmt.Errorf("failed to get last purchase invoice number: %w", err)
   }
@@ -367,18 +410,18 @@ func (r *transactionRepository) GeneratePurchaseInvoiceNumber() (string, error)
   return fmt.Sprintf("INV-PUR-%s-%03d", today, nextNumber), nil
 }
 
-func (r *transactionRepository) GenerateSalesInvoiceNumber() (string, error) {
+func (r *transactionRepository) GenerateSalesInvoiceNumber(ctx context.Context) (string, error) {
   today := time.Now().Format("20060102")
   var lastNumber string
   query := `
     SELECT invoice_number 
     FROM sales_transactions 
     WHERE invoice_number LIKE 'INV-SAL-' || $1 || '-%' 
-    ORDER BY invoice_number DESC 
+    ORDER BY LENGTH(invoice_number) DESC, invoice_number DESC 
     LIMIT 1
   `
   
-  err := r.db.Get(&lastNumber, query, today)
+  err := r.db.GetContext(ctx, &lastNumber, query, today)
   if err != nil && err != sql.ErrNoRows {
     return "", fmt.Errorf("failed to get last sales invoice number: %w", err)
   }
@@ -396,51 +439,51 @@ func (r *transactionRepository) GenerateSalesInvoiceNumber() (string, error) {
   return fmt.Sprintf("INV-SAL-%s-%03d", today, nextNumber), nil
 }
 
-func (r *transactionRepository) GetDashboardStats() (*entity.DashboardStats, error) {
+func (r *transactionRepository) GetDashboardStats(ctx context.Context) (*entity.DashboardStats, error) {
   stats := &entity.DashboardStats{}
   
   // Get vehicle counts
-  err := r.db.Get(&stats.TotalVehicles, "SELECT COUNT(*) FROM vehicles")
+  err := r.db.GetContext(ctx, &stats.TotalVehicles, "SELECT COUNT(*) FROM vehicles")
   if err != nil {
     return nil, fmt.Errorf("failed to get total vehicles: %w", err)
   }
   
-  err = r.db.Get(&stats.VehiclesForSale, "SELECT COUNT(*) FROM vehicles WHERE status = 'ready_to_sell'")
+  err = r.db.GetContext(ctx, &stats.VehiclesForSale, "SELECT COUNT(*) FROM vehicles WHERE status = 'ready_to_sell'")
   if err != nil {
     return nil, fmt.Errorf("failed to get vehicles for sale: %w", err)
   }
   
-  err = r.db.Get(&stats.VehiclesInRepair, "SELECT COUNT(*) FROM vehicles WHERE status = 'in_repair'")
+  err = r.db.GetContext(ctx, &stats.VehiclesInRepair, "SELECT COUNT(*) FROM vehicles WHERE status = 'in_repair'")
   if err != nil {
     return nil, fmt.Errorf("failed to get vehicles in repair: %w", err)
   }
   
-  err = r.db.Get(&stats.VehiclesSold, "SELECT COUNT(*) FROM vehicles WHERE status = 'sold'")
+  err = r.db.GetContext(ctx, &stats.VehiclesSold, "SELECT COUNT(*) FROM vehicles WHERE status = 'sold'")
   if err != nil {
     return nil, fmt.Errorf("failed to get vehicles sold: %w", err)
   }
   
   // Get customer count
-  err = r.db.Get(&stats.TotalCustomers, "SELECT COUNT(*) FROM customers WHERE is_active = true")
+  err = r.db.GetContext(ctx, &stats.TotalCustomers, "SELECT COUNT(*) FROM customers WHERE is_active = true")
   if err != nil {
     return nil, fmt.Errorf("failed to get total customers: %w", err)
   }
   
   // Get today's transactions
   today := time.Now().Format("2006-01-02")
-  err = r.db.Get(&stats.TodayPurchases, "SELECT COUNT(*) FROM purchase_transactions WHERE DATE(transaction_date) = $1", today)
+  err = r.db.GetContext(ctx, &stats.TodayPurchases, "SELECT COUNT(*) FROM purchase_transactions WHERE DATE(transaction_date) = $1", today)
   if err != nil {
     return nil, fmt.Errorf("failed to get today purchases: %w", err)
   }
   
-  err = r.db.Get(&stats.TodaySales, "SELECT COUNT(*) FROM sales_transactions WHERE DATE(transaction_date) = $1", today)
+  err = r.db.GetContext(ctx, &stats.TodaySales, "SELECT COUNT(*) FROM sales_transactions WHERE DATE(transaction_date) = $1", today)
   if err != nil {
     return nil, fmt.Errorf("failed to get today sales: %w", err)
   }
   
   // Get today's revenue
   var todayRevenue sql.NullFloat64
-  err = r.db.Get(&todayRevenue, "SELECT COALESCE(SUM(total_amount), 0) FROM sales_transactions WHERE DATE(transaction_date) = $1", today)
+  err = r.db.GetContext(ctx, &todayRevenue, "SELECT COALESCE(SUM(total_amount), 0) FROM sales_transactions WHERE DATE(transaction_date) = $1", today)
   if err != nil {
     return nil, fmt.Errorf("failed to get today revenue: %w", err)
   }
@@ -449,7 +492,7 @@ func (r *transactionRepository) GetDashboardStats() (*entity.DashboardStats, err
   // Get monthly revenue
   monthStart := time.Now().Format("2006-01-01")
   var monthlyRevenue sql.NullFloat64
-  err = r.db.Get(&monthlyRevenue, "SELECT COALESCE(SUM(total_amount), 0) FROM sales_transactions WHERE transaction_date >= $1", monthStart)
+  err = r.db.GetContext(ctx, &monthlyRevenue, "SELECT COALESCE(SUM(total_amount), 0) FROM sales_transactions WHERE transaction_date >= $1", monthStart)
   if err != nil {
     return nil, fmt.Errorf("failed to get monthly revenue: %w", err)
   }
@@ -457,12 +500,12 @@ func (r *transactionRepository) GetDashboardStats() (*entity.DashboardStats, err
   
   // Calculate total profit (simplified: sales - purchases)
   var totalSales, totalPurchases sql.NullFloat64
-  err = r.db.Get(&totalSales, "SELECT COALESCE(SUM(total_amount), 0) FROM sales_transactions")
+  err = r.db.GetContext(ctx, &totalSales, "SELECT COALESCE(SUM(total_amount), 0) FROM sales_transactions")
   if err != nil {
     return nil, fmt.Errorf("failed to get total sales: %w", err)
   }
   
-  err = r.db.Get(&totalPurchases, "SELECT COALESCE(SUM(total_amount), 0) FROM purchase_transactions")
+  err = r.db.GetContext(ctx, &totalPurchases, "SELECT COALESCE(SUM(total_amount), 0) FROM purchase_transactions")
   if err != nil {
     return nil, fmt.Errorf("failed to get total purchases: %w", err)
   }
@@ -471,73 +514,3 @@ func (r *transactionRepository) GetDashboardStats() (*entity.DashboardStats, err
   
   return stats, nil
 }
-
-func (r *transactionRepository) loadPurchaseRelatedData(tx *entity.PurchaseTransaction) {
-  // Load vehicle
-  vehicle := &entity.Vehicle{}
-  vehicleQuery := `
-    SELECT id, vehicle_code, chassis_number, license_plate, brand, model, variant, year, color
-    FROM vehicles WHERE id = $1
-  `
-  err := r.db.Get(vehicle, vehicleQuery, tx.VehicleID)
-  if err == nil {
-    tx.Vehicle = vehicle
-  }
-  
-  // Load customer
-  customer := &entity.Customer{}
-  customerQuery := `
-    SELECT id, customer_code, name, phone, email, type
-    FROM customers WHERE id = $1
-  `
-  err = r.db.Get(customer, customerQuery, tx.CustomerID)
-  if err == nil {
-    tx.Customer = customer
-  }
-  
-  // Load cashier
-  cashier := &entity.User{}
-  cashierQuery := `
-    SELECT id, username, full_name, role
-    FROM users WHERE id = $1
-  `
-  err = r.db.Get(cashier, cashierQuery, tx.CashierID)
-  if err == nil {
-    tx.Cashier = cashier
-  }
-}
-
-func (r *transactionRepository) loadSalesRelatedData(tx *entity.SalesTransaction) {
-  // Load vehicle
-  vehicle := &entity.Vehicle{}
-  vehicleQuery := `
-    SELECT id, vehicle_code, chassis_number, license_plate, brand, model, variant, year, color
-    FROM vehicles WHERE id = $1
-  `
-  err := r.db.Get(vehicle, vehicleQuery, tx.VehicleID)
-  if err == nil {
-    tx.Vehicle = vehicle
-  }
-  
-  // Load customer
-  customer := &entity.Customer{}
-  customerQuery := `
-    SELECT id, customer_code, name, phone, email, type
-    FROM customers WHERE id = $1
-  `
-  err = r.db.Get(customer, customerQuery, tx.CustomerID)
-  if err == nil {
-    tx.Customer = customer
-  }
-  
-  // Load cashier
-  cashier := &entity.User{}
-  cashierQuery := `
-    SELECT id, username, full_name, role
-    FROM users WHERE id = $1
-  `
-  err = r.db.Get(cashier, cashierQuery, tx.CashierID)
-  if err == nil {
-    tx.Cashier = cashier
-  }
-}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type TwoFactorRepository interface {
+	SetSecret(ctx context.Context, userID int, secret string) error
+	Enable(ctx context.Context, userID int, recoveryCodeHashes []string) error
+	Disable(ctx context.Context, userID int) error
+	ConsumeStep(ctx context.Context, userID int, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID int, hashes []string) error
+	UseRecoveryCode(ctx context.Context, userID int, hash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID int) (int, error)
+}
+
+type twoFactorRepository struct {
+	db *sqlx.DB
+}
+
+func NewTwoFactorRepository(db *sqlx.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+// SetSecret stores a secret that is not active until Enable is called.
+func (r *twoFactorRepository) SetSecret(ctx context.Context, userID int, secret string) error {
+	query := `
+		UPDATE users
+		SET totp_secret = $1, totp_enabled = false, totp_last_step = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, secret, userID); err != nil {
+		return fmt.Errorf("failed to store totp secret: %w", err)
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) Enable(ctx context.Context, userID int, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	query := `UPDATE users SET totp_enabled = true, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND totp_secret IS NOT NULL`
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	if err := r.replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit two-factor enrollment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) Disable(ctx context.Context, userID int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	query := `
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled = false, totp_last_step = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit two-factor removal: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeStep records step as the last used TOTP step. It reports false when
+// the step (or a later one) was already used, which rejects replayed codes.
+func (r *twoFactorRepository) ConsumeStep(ctx context.Context, userID int, step int64) (bool, error) {
+	query := `
+		UPDATE users
+		SET totp_last_step = $1
+		WHERE id = $2 AND (totp_last_step IS NULL OR totp_last_step < $1)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to record totp step: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to record totp step: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, hashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	if err := r.replaceRecoveryCodes(ctx, tx, userID, hashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks a matching unused code as used and reports whether
+// one was found.
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID int, hash string) (bool, error) {
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, hash)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+func (r *twoFactorRepository) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+
+	if err := r.db.GetContext(ctx, &count, query, userID); err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *twoFactorRepository) replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userID int, hashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear recovery codes: %w", err)
+	}
+
+	for _, hash := range hashes {
+		query := `INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)`
+		if _, err := tx.ExecContext(ctx, query, userID, hash); err != nil {
+			return fmt.Errorf("failed to store recovery code: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -1,20 +1,25 @@
 package repository
 
 import (
+  "context"
   "database/sql"
   "fmt"
+  "time"
 
   "github.com/jmoiron/sqlx"
   "vehicle-showroom/internal/entity"
 )
 
 type UserRepository interface {
-  Create(user *entity.User) error
-  GetByUsername(username string) (*entity.User, error)
-  GetByEmail(email string) (*entity.User, error)
-  GetByID(id int) (*entity.User, error)
-  Update(user *entity.User) error
-  Delete(id int) error
+  Create(ctx context.Context, user *entity.User) error
+  GetByUsername(ctx context.Context, username string) (*entity.User, error)
+  GetByEmail(ctx context.Context, email string) (*entity.User, error)
+  GetByID(ctx context.Context, id int) (*entity.User, error)
+  Update(ctx context.Context, user *entity.User) error
+  Delete(ctx context.Context, id int) error
+  RecordFailedLogin(ctx context.Context, id int, maxAttempts int, lockUntil, now time.Time) (*entity.User, error)
+  ResetFailedLogins(ctx context.Context, id int) error
+  UpdatePassword(ctx context.Context, id int, passwordHash string) error
 }
 
 type userRepository struct {
@@ -25,14 +30,19 @@ func NewUserRepository(db *sqlx.DB) UserRepository {
   return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(user *entity.User) error {
+const userColumns = `
+  id, username, email, password_hash, full_name, phone, role, is_active, created_at, updated_at,
+  failed_login_attempts, locked_until, totp_secret, totp_enabled, totp_last_step
+`
+
+func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
   query := `
     INSERT INTO users (username, email, password_hash, full_name, phone, role, is_active)
     VALUES ($1, $2, $3, $4, $5, $6, $7)
     RETURNING id, created_at, updated_at
   `
   
-  err := r.db.QueryRow(
+  err := r.db.QueryRowContext(ctx,
     query,
     user.Username,
     user.Email,
@@ -44,21 +54,21 @@ func (r *userRepository) Create(user *entity.User) error {
   ).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
   
   if err != nil {
-    return fmt.Errorf("failed to create user: %w", err)
+    return wrapWriteError("create user", err)
   }
   
   return nil
 }
 
-func (r *userRepository) GetByUsername(username string) (*entity.User, error) {
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
   user := &entity.User{}
   query := `
-    SELECT id, username, email, password_hash, full_name, phone, role, is_active, created_at, updated_at
+    SELECT ` + userColumns + `
     FROM users
     WHERE username = $1 AND is_active = true
   `
   
-  err := r.db.Get(user, query, username)
+  err := r.db.GetContext(ctx, user, query, username)
   if err != nil {
     if err == sql.ErrNoRows {
       return nil, nil
@@ -69,15 +79,15 @@ func (r *userRepository) GetByUsername(username string) (*entity.User, error) {
   return user, nil
 }
 
-func (r *userRepository) GetByEmail(email string) (*entity.User, error) {
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
   user := &entity.User{}
   query := `
-    SELECT id, username, email, password_hash, full_name, phone, role, is_active, created_at, updated_at
+    SELECT ` + userColumns + `
     FROM users
     WHERE email = $1 AND is_active = true
   `
   
-  err := r.db.Get(user, query, email)
+  err := r.db.GetContext(ctx, user, query, email)
   if err != nil {
     if err == sql.ErrNoRows {
       return nil, nil
@@ -88,15 +98,15 @@ func (r *userRepository) GetByEmail(email string) (*entity.User, error) {
   return user, nil
 }
 
-func (r *userRepository) GetByID(id int) (*entity.User, error) {
+func (r *userRepository) GetByID(ctx context.Context, id int) (*entity.User, error) {
   user := &entity.User{}
   query := `
-    SELECT id, username, email, password_hash, full_name, phone, role, is_active, created_at, updated_at
+    SELECT ` + userColumns + `
     FROM users
     WHERE id = $1 AND is_active = true
   `
   
-  err := r.db.Get(user, query, id)
+  err := r.db.GetContext(ctx, user, query, id)
   if err != nil {
     if err == sql.ErrNoRows {
       return nil, nil
@@ -107,28 +117,82 @@ func (r *userRepository) GetByID(id int) (*entity.User, error) {
   return user, nil
 }
 
-func (r *userRepository) Update(user *entity.User) error {
+func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
   query := `
     UPDATE users
     SET username = $1, email = $2, full_name = $3, phone = $4, role = $5, updated_at = CURRENT_TIMESTAMP
     WHERE id = $6
   `
   
-  _, err := r.db.Exec(query, user.Username, user.Email, user.FullName, user.Phone, user.Role, user.ID)
+  _, err := r.db.ExecContext(ctx, query, user.Username, user.Email, user.FullName, user.Phone, user.Role, user.ID)
   if err != nil {
-    return fmt.Errorf("failed to update user: %w", err)
+    return wrapWriteError("update user", err)
   }
   
   return nil
 }
 
-func (r *userRepository) Delete(id int) error {
+func (r *userRepository) Delete(ctx context.Context, id int) error {
   query := `UPDATE users SET is_active = false WHERE id = $1`
   
-  _, err := r.db.Exec(query, id)
+  _, err := r.db.ExecContext(ctx, query, id)
   if err != nil {
     return fmt.Errorf("failed to delete user: %w", err)
   }
   
   return nil
 }
+
+// RecordFailedLogin increments the consecutive failure counter and locks the
+// account once it reaches maxAttempts. A lock that expired before now starts
+// the count over, so the next wrong password does not lock again at once.
+// The counter and lock are updated in one statement so parallel attempts
+// cannot slip past the threshold.
+func (r *userRepository) RecordFailedLogin(ctx context.Context, id int, maxAttempts int, lockUntil, now time.Time) (*entity.User, error) {
+  user := &entity.User{}
+  query := `
+    UPDATE users
+    SET failed_login_attempts = CASE
+          WHEN locked_until IS NOT NULL AND locked_until <= $4 THEN 1
+          ELSE failed_login_attempts + 1
+        END,
+        locked_until = CASE
+          WHEN (CASE
+                  WHEN locked_until IS NOT NULL AND locked_until <= $4 THEN 1
+                  ELSE failed_login_attempts + 1
+                END) >= $2 THEN $3
+          WHEN locked_until IS NOT NULL AND locked_until <= $4 THEN NULL
+          ELSE locked_until
+        END
+    WHERE id = $1
+    RETURNING ` + userColumns
+  
+  err := r.db.GetContext(ctx, user, query, id, maxAttempts, lockUntil, now)
+  if err != nil {
+    return nil, fmt.Errorf("failed to record failed login: %w", err)
+  }
+  
+  return user, nil
+}
+
+func (r *userRepository) ResetFailedLogins(ctx context.Context, id int) error {
+  query := `UPDATE users SET failed_login_attempts = 0, locked_until = NULL WHERE id = $1`
+  
+  _, err := r.db.ExecContext(ctx, query, id)
+  if err != nil {
+    return fmt.Errorf("failed to reset failed logins: %w", err)
+  }
+  
+  return nil
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+  query := `UPDATE users SET password_hash = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+  
+  _, err := r.db.ExecContext(ctx, query, passwordHash, id)
+  if err != nil {
+    return fmt.Errorf("failed to update password: %w", err)
+  }
+  
+  return nil
+}
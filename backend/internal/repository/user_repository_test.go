@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRecordFailedLoginAfterLockExpires(t *testing.T) {
+	db, _ := openCountingTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	var userID int
+	if err := db.Get(&userID, `SELECT MIN(id) FROM users`); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repo.ResetFailedLogins(ctx, userID) })
+
+	now := time.Now()
+	lockUntil := now.Add(15 * time.Minute)
+	if _, err := db.Exec(`UPDATE users SET failed_login_attempts = 5, locked_until = $1 WHERE id = $2`,
+		now.Add(-time.Minute), userID); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := repo.RecordFailedLogin(ctx, userID, 5, lockUntil, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.FailedLoginAttempts != 1 || user.LockedUntil != nil {
+		t.Fatalf("after an expired lock: attempts = %d, locked_until = %v; want 1 and unlocked",
+			user.FailedLoginAttempts, user.LockedUntil)
+	}
+
+	for i := 0; i < 4; i++ {
+		if user, err = repo.RecordFailedLogin(ctx, userID, 5, lockUntil, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if user.FailedLoginAttempts != 5 || user.LockedUntil == nil {
+		t.Errorf("after 5 failures: attempts = %d, locked_until = %v; want a new lock",
+			user.FailedLoginAttempts, user.LockedUntil)
+	}
+}
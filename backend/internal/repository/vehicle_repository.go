@@ -1,23 +1,32 @@
 package repository
 
 import (
+  "context"
   "database/sql"
   "fmt"
   "strings"
 
   "github.com/jmoiron/sqlx"
+  "github.com/lib/pq"
   "vehicle-showroom/internal/entity"
 )
 
 type VehicleRepository interface {
-  Create(vehicle *entity.Vehicle) error
-  GetByID(id int) (*entity.Vehicle, error)
-  GetByCode(code string) (*entity.Vehicle, error)
-  List(page, limit int, search, status string) ([]entity.Vehicle, int, error)
-  Update(vehicle *entity.Vehicle) error
-  UpdateStatus(id int, status string) error
-  Delete(id int) error
-  GenerateVehicleCode() (string, error)
+  Create(ctx context.Context, vehicle *entity.Vehicle) error
+  CreateBatch(ctx context.Context, vehicles []*entity.Vehicle) error
+  GetByID(ctx context.Context, id int) (*entity.Vehicle, error)
+  GetByCode(ctx context.Context, code string) (*entity.Vehicle, error)
+  ExistingChassisNumbers(ctx context.Context, chassisNumbers []string) (map[string]bool, error)
+  List(ctx context.Context, params entity.ListParams, filter entity.VehicleFilter) ([]entity.Vehicle, *entity.PageInfo, error)
+  Facets(ctx context.Context, filter entity.VehicleFilter) (*entity.VehicleFacets, error)
+  Update(ctx context.Context, vehicle *entity.Vehicle) error
+  UpdateStatus(ctx context.Context, id int, status string) error
+  Reserve(ctx context.Context, id int, customerID *int) error
+  RecordPurchase(ctx context.Context, vehicle *entity.Vehicle) error
+  RecordSale(ctx context.Context, vehicle *entity.Vehicle) error
+  ApprovePrice(ctx context.Context, id int, price float64, approvedBy int) error
+  Delete(ctx context.Context, id int) error
+  GenerateVehicleCode(ctx context.Context) (string, error)
 }
 
 type vehicleRepository struct {
@@ -28,7 +37,42 @@ func NewVehicleRepository(db *sqlx.DB) VehicleRepository {
   return &vehicleRepository{db: db}
 }
 
-func (r *vehicleRepository) Create(vehicle *entity.Vehicle) error {
+func (r *vehicleRepository) Create(ctx context.Context, vehicle *entity.Vehicle) error {
+  return insertVehicle(ctx, r.db, vehicle)
+}
+
+// CreateBatch inserts vehicles in one transaction, each with the next
+// vehicle code from the same generator as single creates. The table is locked
+// against other inserts meanwhile so that the codes cannot collide.
+func (r *vehicleRepository) CreateBatch(ctx context.Context, vehicles []*entity.Vehicle) error {
+  tx, err := r.db.BeginTxx(ctx, nil)
+  if err != nil {
+    return fmt.Errorf("failed to begin transaction: %w", err)
+  }
+  defer rollback(ctx, tx)
+
+  if _, err := tx.ExecContext(ctx, `LOCK TABLE vehicles IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+    return fmt.Errorf("failed to lock vehicles: %w", err)
+  }
+
+  for _, vehicle := range vehicles {
+    code, err := nextVehicleCode(ctx, tx)
+    if err != nil {
+      return err
+    }
+    vehicle.VehicleCode = code
+    if err := insertVehicle(ctx, tx, vehicle); err != nil {
+      return err
+    }
+  }
+
+  if err := tx.Commit(); err != nil {
+    return fmt.Errorf("failed to commit vehicle import: %w", err)
+  }
+  return nil
+}
+
+func insertVehicle(ctx context.Context, q sqlx.QueryerContext, vehicle *entity.Vehicle) error {
   query := `
     INSERT INTO vehicles (
       vehicle_code, chassis_number, license_plate, brand, model, variant, year, color, mileage,
@@ -39,7 +83,7 @@ func (r *vehicleRepository) Create(vehicle *entity.Vehicle) error {
     RETURNING id, created_at, updated_at
   `
   
-  err := r.db.QueryRow(
+  err := q.QueryRowxContext(ctx,
     query,
     vehicle.VehicleCode,
     vehicle.ChassisNumber,
@@ -62,13 +106,13 @@ func (r *vehicleRepository) Create(vehicle *entity.Vehicle) error {
   ).Scan(&vehicle.ID, &vehicle.CreatedAt, &vehicle.UpdatedAt)
   
   if err != nil {
-    return fmt.Errorf("failed to create vehicle: %w", err)
+    return wrapWriteError("create vehicle", err)
   }
   
   return nil
 }
 
-func (r *vehicleRepository) GetByID(id int) (*entity.Vehicle, error) {
+func (r *vehicleRepository) GetByID(ctx context.Context, id int) (*entity.Vehicle, error) {
   vehicle := &entity.Vehicle{}
   query := `
     SELECT v.id, v.vehicle_code, v.chassis_number, v.license_plate, v.brand, v.model, v.variant,
@@ -76,12 +120,13 @@ func (r *vehicleRepository) GetByID(id int) (*entity.Vehicle, error) {
            v.total_repair_cost, v.suggested_selling_price, v.approved_selling_price,
            v.final_selling_price, v.status, v.purchased_from_customer_id, v.sold_to_customer_id,
            v.purchased_by_cashier, v.sold_by_cashier, v.price_approved_by_admin,
-           v.purchased_at, v.sold_at, v.created_at, v.updated_at, v.purchase_notes, v.condition_notes
+           v.purchased_at, v.sold_at, v.created_at, v.updated_at, v.purchase_notes, v.condition_notes,
+           v.reserved_for_customer_id, v.reserved_at
     FROM vehicles v
     WHERE v.id = $1
   `
   
-  err := r.db.Get(vehicle, query, id)
+  err := r.db.GetContext(ctx, vehicle, query, id)
   if err != nil {
     if err == sql.ErrNoRows {
       return nil, nil
@@ -98,7 +143,7 @@ func (r *vehicleRepository) GetByID(id int) (*entity.Vehicle, error) {
       FROM customers
       WHERE id = $1
     `
-    err = r.db.Get(customer, customerQuery, *vehicle.PurchasedFromCustomerID)
+    err = r.db.GetContext(ctx, customer, customerQuery, *vehicle.PurchasedFromCustomerID)
     if err == nil {
       vehicle.PurchasedFromCustomer = customer
     }
@@ -112,7 +157,7 @@ func (r *vehicleRepository) GetByID(id int) (*entity.Vehicle, error) {
       FROM customers
       WHERE id = $1
     `
-    err = r.db.Get(customer, customerQuery, *vehicle.SoldToCustomerID)
+    err = r.db.GetContext(ctx, customer, customerQuery, *vehicle.SoldToCustomerID)
     if err == nil {
       vehicle.SoldToCustomer = customer
     }
@@ -121,7 +166,7 @@ func (r *vehicleRepository) GetByID(id int) (*entity.Vehicle, error) {
   return vehicle, nil
 }
 
-func (r *vehicleRepository) GetByCode(code string) (*entity.Vehicle, error) {
+func (r *vehicleRepository) GetByCode(ctx context.Context, code string) (*entity.Vehicle, error) {
   vehicle := &entity.Vehicle{}
   query := `
     SELECT id, vehicle_code, chassis_number, license_plate, brand, model, variant,
@@ -129,12 +174,13 @@ func (r *vehicleRepository) GetByCode(code string) (*entity.Vehicle, error) {
            total_repair_cost, suggested_selling_price, approved_selling_price,
            final_selling_price, status, purchased_from_customer_id, sold_to_customer_id,
            purchased_by_cashier, sold_by_cashier, price_approved_by_admin,
-           purchased_at, sold_at, created_at, updated_at, purchase_notes, condition_notes
+           purchased_at, sold_at, created_at, updated_at, purchase_notes, condition_notes,
+           reserved_for_customer_id, reserved_at
     FROM vehicles
     WHERE vehicle_code = $1
   `
   
-  err := r.db.Get(vehicle, query, code)
+  err := r.db.GetContext(ctx, vehicle, query, code)
   if err != nil {
     if err == sql.ErrNoRows {
       return nil, nil
@@ -145,78 +191,195 @@ func (r *vehicleRepository) GetByCode(code string) (*entity.Vehicle, error) {
   return vehicle, nil
 }
 
-func (r *vehicleRepository) List(page, limit int, search, status string) ([]entity.Vehicle, int, error) {
-  offset := (page - 1) * limit
+// ExistingChassisNumbers reports which of chassisNumbers are already taken.
+func (r *vehicleRepository) ExistingChassisNumbers(ctx context.Context, chassisNumbers []string) (map[string]bool, error) {
+  existing := make(map[string]bool)
+  if len(chassisNumbers) == 0 {
+    return existing, nil
+  }
   
-  whereClause := "WHERE 1=1"
-  args := []interface{}{}
-  argIndex := 1
+  var taken []string
+  query := `SELECT chassis_number FROM vehicles WHERE chassis_number = ANY($1)`
+  if err := r.db.SelectContext(ctx, &taken, query, pq.Array(chassisNumbers)); err != nil {
+    return nil, fmt.Errorf("failed to check chassis numbers: %w", err)
+  }
+  for _, number := range taken {
+    existing[number] = true
+  }
   
-  if search != "" {
-    whereClause += fmt.Sprintf(" AND (v.brand ILIKE $%d OR v.model ILIKE $%d OR v.vehicle_code ILIKE $%d OR v.chassis_number ILIKE $%d OR v.license_plate ILIKE $%d)", 
-                              argIndex, argIndex+1, argIndex+2, argIndex+3, argIndex+4)
-    searchPattern := "%" + search + "%"
-    args = append(args, searchPattern, searchPattern, searchPattern, searchPattern, searchPattern)
-    argIndex += 5
+  return existing, nil
+}
+
+const (
+  // vehiclePriceExpr is the price a buyer sees: approved if set, else suggested
+  vehiclePriceExpr = "COALESCE(v.approved_selling_price, v.suggested_selling_price, 0)"
+  // vehicleDaysInStockExpr counts from purchase to sale, or to today if unsold
+  vehicleDaysInStockExpr = "EXTRACT(DAY FROM COALESCE(v.sold_at, NOW()) - COALESCE(v.purchased_at, v.created_at))"
+)
+
+var vehicleSort = listSort[entity.Vehicle]{
+  keys: map[string]sortKey[entity.Vehicle]{
+    "created_at":   {"v.created_at", func(v *entity.Vehicle) interface{} { return v.CreatedAt }},
+    "year":         {"v.year", func(v *entity.Vehicle) interface{} { return v.Year }},
+    "brand":        {"v.brand", func(v *entity.Vehicle) interface{} { return v.Brand }},
+    "model":        {"v.model", func(v *entity.Vehicle) interface{} { return v.Model }},
+    "vehicle_code": {"v.vehicle_code", func(v *entity.Vehicle) interface{} { return v.VehicleCode }},
+    "mileage":      {"COALESCE(v.mileage, 0)", func(v *entity.Vehicle) interface{} { return derefOr(v.Mileage, 0) }},
+    "price": {vehiclePriceExpr, func(v *entity.Vehicle) interface{} {
+      return derefOr(v.ApprovedSellingPrice, derefOr(v.SuggestedSellingPrice, 0))
+    }},
+  },
+  defaultSort: []entity.SortField{{Field: "created_at", Desc: true}},
+  idExpr:      "v.id",
+  id:          func(v *entity.Vehicle) int { return v.ID },
+}
+
+// Facet dimensions, by the column they count
+const (
+  facetBrand        = "v.brand"
+  facetFuelType     = "v.fuel_type"
+  facetTransmission = "v.transmission"
+)
+
+// vehicleWhere builds the conditions for filter. The condition on the facet
+// column skip is left out, so that facet counts the alternatives to the
+// values already selected.
+func vehicleWhere(filter entity.VehicleFilter, skip string) *whereBuilder {
+  w := &whereBuilder{}
+  
+  if filter.Search != "" {
+    searchPattern := "%" + filter.Search + "%"
+    w.add("(v.brand ILIKE $? OR v.model ILIKE $? OR v.vehicle_code ILIKE $? OR v.chassis_number ILIKE $? OR v.license_plate ILIKE $?)",
+      searchPattern, searchPattern, searchPattern, searchPattern, searchPattern)
+  }
+  if filter.Status != "" {
+    w.add("v.status = $?", filter.Status)
+  }
+  
+  // Text filters match case-insensitively, as the search does
+  anyOf := func(column string, values []string) {
+    if len(values) == 0 || column == skip {
+      return
+    }
+    lowered := make([]string, len(values))
+    for i, value := range values {
+      lowered[i] = strings.ToLower(value)
+    }
+    w.add(fmt.Sprintf("LOWER(%s) = ANY($?)", column), pq.Array(lowered))
   }
+  anyOf(facetBrand, filter.Brands)
+  anyOf("v.model", filter.Models)
+  anyOf(facetFuelType, filter.FuelTypes)
+  anyOf(facetTransmission, filter.Transmissions)
+  anyOf("v.color", filter.Colors)
+  
+  addRange(w, "v.year", filter.YearMin, filter.YearMax)
+  addRange(w, vehiclePriceExpr, filter.PriceMin, filter.PriceMax)
+  addRange(w, "COALESCE(v.mileage, 0)", filter.MileageMin, filter.MileageMax)
+  addRange(w, vehicleDaysInStockExpr, filter.DaysInStockMin, filter.DaysInStockMax)
+  
+  return w
+}
+
+func (r *vehicleRepository) List(ctx context.Context, params entity.ListParams, filter entity.VehicleFilter) ([]entity.Vehicle, *entity.PageInfo, error) {
+  where := vehicleWhere(filter, "")
+  whereClause, args := where.clause(), where.args
   
-  if status != "" {
-    whereClause += fmt.Sprintf(" AND v.status = $%d", argIndex)
-    args = append(args, status)
-    argIndex++
+  plan, err := vehicleSort.plan(params, where.nextArg())
+  if err != nil {
+    return nil, nil, err
   }
   
-  // Get total count
-  countQuery := fmt.Sprintf("SELECT COUNT(*) FROM vehicles v %s", whereClause)
-  var total int
-  err := r.db.Get(&total, countQuery, args...)
+  total, err := countTotal(params, func() (int, error) {
+    var total int
+    countQuery := fmt.Sprintf("SELECT COUNT(*) FROM vehicles v %s", whereClause)
+    if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+      return 0, fmt.Errorf("failed to get vehicle count: %w", err)
+    }
+    return total, nil
+  })
   if err != nil {
-    return nil, 0, fmt.Errorf("failed to get vehicle count: %w", err)
+    return nil, nil, err
   }
   
-  // Get vehicles with customer data
   query := fmt.Sprintf(`
     SELECT v.id, v.vehicle_code, v.chassis_number, v.license_plate, v.brand, v.model, v.variant,
            v.year, v.color, v.mileage, v.fuel_type, v.transmission, v.purchase_price,
            v.total_repair_cost, v.suggested_selling_price, v.approved_selling_price,
            v.final_selling_price, v.status, v.purchased_from_customer_id, v.sold_to_customer_id,
            v.purchased_by_cashier, v.sold_by_cashier, v.price_approved_by_admin,
-           v.purchased_at, v.sold_at, v.created_at, v.updated_at, v.purchase_notes, v.condition_notes
+           v.purchased_at, v.sold_at, v.created_at, v.updated_at, v.purchase_notes, v.condition_notes,
+           v.reserved_for_customer_id, v.reserved_at
     FROM vehicles v
+    %s%s
     %s
-    ORDER BY v.created_at DESC
-    LIMIT $%d OFFSET $%d
-  `, whereClause, argIndex, argIndex+1)
-  
-  args = append(args, limit, offset)
+    %s
+  `, whereClause, plan.keyset, plan.orderBy, plan.limit)
   
   var vehicles []entity.Vehicle
-  err = r.db.Select(&vehicles, query, args...)
+  err = r.db.SelectContext(ctx, &vehicles, query, append(args, plan.args...)...)
+  if err != nil {
+    return nil, nil, fmt.Errorf("failed to list vehicles: %w", err)
+  }
+  
+  vehicles, page, err := vehicleSort.page(vehicles, plan, total)
   if err != nil {
-    return nil, 0, fmt.Errorf("failed to list vehicles: %w", err)
+    return nil, nil, err
   }
   
-  // Load customer data for each vehicle
+  // Attach the customers each vehicle was bought from in one query
+  customerIDs := make([]int, 0, len(vehicles))
+  for _, v := range vehicles {
+    if v.PurchasedFromCustomerID != nil {
+      customerIDs = append(customerIDs, *v.PurchasedFromCustomerID)
+    }
+  }
+  customers, err := loadByID(ctx, r.db, `
+    SELECT id, customer_code, name, phone, email, address, id_card_number, type,
+           created_at, updated_at, created_by, is_active
+    FROM customers
+    WHERE id = ANY($1)
+  `, customerIDs, func(c *entity.Customer) int { return c.ID })
+  if err != nil {
+    return nil, nil, fmt.Errorf("failed to load customers: %w", err)
+  }
   for i := range vehicles {
     if vehicles[i].PurchasedFromCustomerID != nil {
-      customer := &entity.Customer{}
-      customerQuery := `
-        SELECT id, customer_code, name, phone, email, address, id_card_number, type,
-               created_at, updated_at, created_by, is_active
-        FROM customers
-        WHERE id = $1
-      `
-      err = r.db.Get(customer, customerQuery, *vehicles[i].PurchasedFromCustomerID)
-      if err == nil {
-        vehicles[i].PurchasedFromCustomer = customer
-      }
+      vehicles[i].PurchasedFromCustomer = customers[*vehicles[i].PurchasedFromCustomerID]
     }
   }
   
-  return vehicles, total, nil
+  return vehicles, page, nil
 }
 
-func (r *vehicleRepository) Update(vehicle *entity.Vehicle) error {
+
+// Facets counts the vehicles matching filter per brand, fuel type and
+// transmission, one query per facet.
+func (r *vehicleRepository) Facets(ctx context.Context, filter entity.VehicleFilter) (*entity.VehicleFacets, error) {
+  facets := &entity.VehicleFacets{}
+  for column, counts := range map[string]*[]entity.FacetCount{
+    facetBrand:        &facets.Brands,
+    facetFuelType:     &facets.FuelTypes,
+    facetTransmission: &facets.Transmissions,
+  } {
+    where := vehicleWhere(filter, column)
+    query := fmt.Sprintf(`
+      SELECT %s AS value, COUNT(*) AS count
+      FROM vehicles v
+      %s AND %s IS NOT NULL
+      GROUP BY %s
+      ORDER BY count DESC, value
+    `, column, where.clause(), column, column)
+    
+    *counts = []entity.FacetCount{}
+    if err := r.db.SelectContext(ctx, counts, query, where.args...); err != nil {
+      return nil, fmt.Errorf("failed to count vehicles by %s: %w", strings.TrimPrefix(column, "v."), err)
+    }
+  }
+  
+  return facets, nil
+}
+func (r *vehicleRepository) Update(ctx context.Context, vehicle *entity.Vehicle) error {
   query := `
     UPDATE vehicles
     SET license_plate = $1, brand = $2, model = $3, variant = $4, year = $5, color = $6,
@@ -225,21 +388,26 @@ func (r *vehicleRepository) Update(vehicle *entity.Vehicle) error {
     WHERE id = $13
   `
   
-  _, err := r.db.Exec(query, vehicle.LicensePlate, vehicle.Brand, vehicle.Model, vehicle.Variant,
+  _, err := r.db.ExecContext(ctx, query, vehicle.LicensePlate, vehicle.Brand, vehicle.Model, vehicle.Variant,
                      vehicle.Year, vehicle.Color, vehicle.Mileage, vehicle.FuelType,
                      vehicle.Transmission, vehicle.SuggestedSellingPrice, vehicle.PurchaseNotes,
                      vehicle.ConditionNotes, vehicle.ID)
   if err != nil {
-    return fmt.Errorf("failed to update vehicle: %w", err)
+    return wrapWriteError("update vehicle", err)
   }
   
   return nil
 }
 
-func (r *vehicleRepository) UpdateStatus(id int, status string) error {
-  query := `UPDATE vehicles SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+// UpdateStatus also releases any reservation; use Reserve to reserve.
+func (r *vehicleRepository) UpdateStatus(ctx context.Context, id int, status string) error {
+  query := `
+    UPDATE vehicles
+    SET status = $1, reserved_for_customer_id = NULL, reserved_at = NULL, updated_at = CURRENT_TIMESTAMP
+    WHERE id = $2
+  `
   
-  _, err := r.db.Exec(query, status, id)
+  _, err := r.db.ExecContext(ctx, query, status, id)
   if err != nil {
     return fmt.Errorf("failed to update vehicle status: %w", err)
   }
@@ -247,10 +415,80 @@ func (r *vehicleRepository) UpdateStatus(id int, status string) error {
   return nil
 }
 
-func (r *vehicleRepository) Delete(id int) error {
+// Reserve sets the vehicle reserved, held for customerID if given.
+func (r *vehicleRepository) Reserve(ctx context.Context, id int, customerID *int) error {
+  query := `
+    UPDATE vehicles
+    SET status = 'reserved', reserved_for_customer_id = $1, reserved_at = CURRENT_TIMESTAMP,
+        updated_at = CURRENT_TIMESTAMP
+    WHERE id = $2
+  `
+  
+  _, err := r.db.ExecContext(ctx, query, customerID, id)
+  if err != nil {
+    return wrapWriteError("reserve vehicle", err)
+  }
+  
+  return nil
+}
+
+// RecordPurchase stores who the vehicle was bought from, by whom and for
+// how much, along with its status.
+func (r *vehicleRepository) RecordPurchase(ctx context.Context, vehicle *entity.Vehicle) error {
+  query := `
+    UPDATE vehicles
+    SET purchase_price = $1, purchased_from_customer_id = $2, purchased_by_cashier = $3,
+        purchased_at = $4, status = $5, updated_at = CURRENT_TIMESTAMP
+    WHERE id = $6
+  `
+  
+  _, err := r.db.ExecContext(ctx, query, vehicle.PurchasePrice, vehicle.PurchasedFromCustomerID,
+                     vehicle.PurchasedByCashier, vehicle.PurchasedAt, vehicle.Status, vehicle.ID)
+  if err != nil {
+    return wrapWriteError("record vehicle purchase", err)
+  }
+  
+  return nil
+}
+
+// RecordSale stores who the vehicle was sold to, by whom and for how much,
+// along with its status. A reservation ends with the sale.
+func (r *vehicleRepository) RecordSale(ctx context.Context, vehicle *entity.Vehicle) error {
+  query := `
+    UPDATE vehicles
+    SET final_selling_price = $1, sold_to_customer_id = $2, sold_by_cashier = $3, sold_at = $4,
+        status = $5, reserved_for_customer_id = NULL, reserved_at = NULL, updated_at = CURRENT_TIMESTAMP
+    WHERE id = $6
+  `
+  
+  _, err := r.db.ExecContext(ctx, query, vehicle.FinalSellingPrice, vehicle.SoldToCustomerID,
+                     vehicle.SoldByCashier, vehicle.SoldAt, vehicle.Status, vehicle.ID)
+  if err != nil {
+    return wrapWriteError("record vehicle sale", err)
+  }
+  
+  return nil
+}
+
+func (r *vehicleRepository) ApprovePrice(ctx context.Context, id int, price float64, approvedBy int) error {
+  query := `
+    UPDATE vehicles
+    SET approved_selling_price = $1, price_approved_by_admin = $2, updated_at = CURRENT_TIMESTAMP
+    WHERE id = $3
+  `
+  
+  _, err := r.db.ExecContext(ctx, query, price, approvedBy, id)
+  if err != nil {
+    return fmt.Errorf("failed to approve vehicle price: %w", err)
+  }
+  
+  return nil
+}
+
+func (r *vehicleRepository) Delete(ctx context.Context, id int) error {
   query := `DELETE FROM vehicles WHERE id = $1`
   
-  _, err := r.db.Exec(query, id)
+  _, err := r.db.ExecContext(ctx, query, id)
   if err != nil {
     return fmt.Errorf("failed to delete vehicle: %w", err)
   }
@@ -258,17 +496,21 @@ func (r *vehicleRepository) Delete(id int) error {
   return nil
 }
 
-func (r *vehicleRepository) GenerateVehicleCode() (string, error) {
+func (r *vehicleRepository) GenerateVehicleCode(ctx context.Context) (string, error) {
+  return nextVehicleCode(ctx, r.db)
+}
+
+func nextVehicleCode(ctx context.Context, q sqlx.QueryerContext) (string, error) {
   var lastCode string
   query := `
     SELECT vehicle_code 
     FROM vehicles 
     WHERE vehicle_code LIKE 'VEH-%' 
-    ORDER BY vehicle_code DESC 
+    ORDER BY LENGTH(vehicle_code) DESC, vehicle_code DESC 
     LIMIT 1
   `
   
-  err := r.db.Get(&lastCode, query)
+  err := sqlx.GetContext(ctx, q, &lastCode, query)
   if err != nil && err != sql.ErrNoRows {
     return "", fmt.Errorf("failed to get last vehicle code: %w", err)
   }
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// whereBuilder collects the conditions of a filtered query. Each "$?" in a
+// condition is numbered in turn as its argument is added.
+type whereBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+func (w *whereBuilder) add(condition string, args ...interface{}) {
+	for _, arg := range args {
+		w.args = append(w.args, arg)
+		condition = strings.Replace(condition, "$?", fmt.Sprintf("$%d", len(w.args)), 1)
+	}
+	w.conditions = append(w.conditions, condition)
+}
+
+// clause is the WHERE clause, "WHERE 1=1" when nothing was added so more
+// conditions can follow with AND.
+func (w *whereBuilder) clause() string {
+	if len(w.conditions) == 0 {
+		return "WHERE 1=1"
+	}
+	return "WHERE " + strings.Join(w.conditions, " AND ")
+}
+
+// nextArg is the placeholder number after the filter arguments.
+func (w *whereBuilder) nextArg() int {
+	return len(w.args) + 1
+}
+
+// addRange adds the bounds of a range filter that are set.
+func addRange[T any](w *whereBuilder, expr string, min, max *T) {
+	if min != nil {
+		w.add(expr+" >= $?", *min)
+	}
+	if max != nil {
+		w.add(expr+" <= $?", *max)
+	}
+}
@@ -0,0 +1,91 @@
+// Package scheduler runs background jobs on a fixed interval inside the
+// server process. Jobs must be safe to run on several servers at once; the
+// scheduler does not coordinate between processes.
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Job is run once when the scheduler starts and then every Interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	jobs []Job
+	wg   sync.WaitGroup
+}
+
+func New(jobs ...Job) *Scheduler {
+	return &Scheduler{jobs: jobs}
+}
+
+// Start runs each job in its own goroutine until ctx is cancelled. A run
+// that fails or panics is logged and the job runs again at the next tick.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		if job.Interval <= 0 {
+			slog.WarnContext(ctx, "scheduler job disabled", slog.String("job", job.Name))
+			continue
+		}
+
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+}
+
+// Wait blocks until every job has returned after ctx was cancelled. A run
+// in progress sees the cancelled context and is expected to stop early.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.run(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "scheduler job panicked",
+				slog.String("job", job.Name),
+				slog.Any("panic", r),
+				slog.String("stack", string(debug.Stack())),
+			)
+		}
+	}()
+
+	if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+		slog.ErrorContext(ctx, "scheduler job failed",
+			slog.String("job", job.Name),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	slog.DebugContext(ctx, "scheduler job finished",
+		slog.String("job", job.Name),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+	)
+}
@@ -0,0 +1,324 @@
+// Package spreadsheet reads the first sheet of a CSV or XLSX file as rows
+// of text, for bulk imports, and streams single-sheet files for exports.
+// XLSX is handled with archive/zip and encoding/xml only; when reading,
+// formulas yield their cached values and styles are ignored.
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// MaxRows bounds the data rows of one file.
+const MaxRows = 5000
+
+// The largest sheet Excel can hold; references past it only come from
+// crafted files, which would otherwise pad rows and cells without limit.
+const (
+	xlsxMaxRows    = 1048576
+	xlsxMaxColumns = 16384
+)
+
+// Table is a sheet with its first row taken as the header. Header names are
+// lower-cased with spaces turned into underscores, so "Chassis Number"
+// reads as chassis_number.
+type Table struct {
+	Header []string
+	Rows   []Row
+}
+
+// Row is one data row. Number is its line in the file, counting the header
+// as 1, so errors can point at what the user sees in their editor.
+type Row struct {
+	Number int
+	Cells  []string
+}
+
+// Cell returns the value in column i, or "" past the end of a short row.
+func (r Row) Cell(i int) string {
+	if i < len(r.Cells) {
+		return r.Cells[i]
+	}
+	return ""
+}
+
+// ErrUnsupportedFormat is returned for files that are neither CSV nor XLSX.
+var ErrUnsupportedFormat = errors.New("unsupported file format; upload a .csv or .xlsx file")
+
+// Read parses data according to the extension of filename.
+func Read(filename string, data []byte) (*Table, error) {
+	var rows [][]string
+	var err error
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		rows, err = readCSV(data)
+	case ".xlsx":
+		rows, err = readXLSX(data)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newTable(rows)
+}
+
+func newTable(rows [][]string) (*Table, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("the file is empty")
+	}
+
+	table := &Table{Header: make([]string, len(rows[0]))}
+	for i, name := range rows[0] {
+		table.Header[i] = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+	}
+
+	for i, cells := range rows[1:] {
+		if blank(cells) {
+			continue
+		}
+		if len(table.Rows) == MaxRows {
+			return nil, fmt.Errorf("the file has more than %d rows; split it up", MaxRows)
+		}
+		for j := range cells {
+			cells[j] = strings.TrimSpace(cells[j])
+		}
+		table.Rows = append(table.Rows, Row{Number: i + 2, Cells: cells})
+	}
+	return table, nil
+}
+
+func blank(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// readCSV accepts comma- and semicolon-separated files, the latter being
+// what spreadsheet apps write in locales that use the decimal comma.
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("the file is not valid CSV: %w", err)
+	}
+	return rows, nil
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is rich or plain text: either a single <t> or <r><t> runs.
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSX(data []byte) ([][]string, error) {
+	invalid := errors.New("the file is not a valid XLSX workbook")
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, invalid
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := decodeXML(f, &sst); err != nil {
+			return nil, err
+		}
+		shared = make([]string, len(sst.Items))
+		for i, item := range sst.Items {
+			shared[i] = item.String()
+		}
+	}
+
+	var sheet xlsxSheet
+	if err := decodeXML(files[sheetPath], &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		// Rows with only formatting often run far past the data
+		if len(row.Cells) == 0 {
+			continue
+		}
+		if row.Number > xlsxMaxRows {
+			return nil, invalid
+		}
+		if row.Number > MaxRows+1 {
+			return nil, fmt.Errorf("the file has more than %d rows; split it up", MaxRows)
+		}
+
+		// Empty rows are left out of the sheet; keep the numbering
+		for len(rows)+1 < row.Number {
+			rows = append(rows, nil)
+		}
+
+		var cells []string
+		for i, cell := range row.Cells {
+			col := columnIndex(cell.Ref)
+			if col < 0 {
+				col = i
+			}
+			if col >= xlsxMaxColumns {
+				return nil, invalid
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				var index int
+				if _, err := fmt.Sscanf(cell.Value, "%d", &index); err != nil || index < 0 || index >= len(shared) {
+					return nil, fmt.Errorf("the workbook refers to a missing shared string in cell %s", cell.Ref)
+				}
+				cells[col] = shared[index]
+			case "inlineStr":
+				cells[col] = cell.Inline.String()
+			case "b":
+				cells[col] = "false"
+				if cell.Value == "1" {
+					cells[col] = "true"
+				}
+			default:
+				cells[col] = cell.Value
+			}
+		}
+		rows = append(rows, cells)
+	}
+	return rows, nil
+}
+
+// firstSheetPath resolves the first sheet of the workbook to its part name.
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	invalid := errors.New("the XLSX workbook has no worksheet")
+
+	var workbook xlsxWorkbook
+	var rels xlsxRelationships
+	if err := decodeXML(files["xl/workbook.xml"], &workbook); err != nil || len(workbook.Sheets) == 0 {
+		return "", invalid
+	}
+	if err := decodeXML(files["xl/_rels/workbook.xml.rels"], &rels); err != nil {
+		return "", invalid
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		if _, ok := files[target]; ok {
+			return target, nil
+		}
+	}
+	return "", invalid
+}
+
+func decodeXML(f *zip.File, v interface{}) error {
+	if f == nil {
+		return errors.New("the XLSX workbook is incomplete")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, 64<<20)).Decode(v); err != nil {
+		return fmt.Errorf("the XLSX part %s is not valid XML", f.Name)
+	}
+	return nil
+}
+
+// columnIndex turns the letters of a cell reference into a 0-based column,
+// e.g. "C7" is 2 and "AA1" is 26; -1 when there are none. Columns past
+// the sheet limit all come back as xlsxMaxColumns.
+func columnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		if col > xlsxMaxColumns {
+			return xlsxMaxColumns
+		}
+	}
+	return col - 1
+}
@@ -0,0 +1,85 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// workbook builds a minimal XLSX whose first sheet holds sheetData.
+func workbook(t *testing.T, sheetData string) []byte {
+	t.Helper()
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml":   `<worksheet><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+
+	var b bytes.Buffer
+	archive := zip.NewWriter(&b)
+	for name, content := range parts {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestReadXLSX(t *testing.T) {
+	data := workbook(t, `<row r="1"><c r="A1" t="inlineStr"><is><t>Chassis Number</t></is></c><c r="C1" t="inlineStr"><is><t>Price</t></is></c></row>`+
+		`<row r="2" s="1" customFormat="1"/>`+
+		`<row r="3"><c r="A3" t="inlineStr"><is><t>MH1</t></is></c><c r="C3"><v>1500</v></c></row>`+
+		`<row r="100000" s="1" customFormat="1"/>`)
+
+	table, err := Read("vehicles.xlsx", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(table.Header, ","); got != "chassis_number,,price" {
+		t.Errorf("header = %q", got)
+	}
+	if len(table.Rows) != 1 || table.Rows[0].Number != 3 || table.Rows[0].Cell(2) != "1500" {
+		t.Errorf("rows = %+v", table.Rows)
+	}
+}
+
+func TestReadXLSXRejectsOutOfRangeReferences(t *testing.T) {
+	tests := []struct {
+		name      string
+		sheetData string
+		want      string
+	}{
+		{"column past XFD", `<row r="1"><c r="ZZZZZZZ1"><v>1</v></c></row>`, "not a valid XLSX"},
+		{"column overflowing int", `<row r="1"><c r="` + strings.Repeat("Z", 40) + `1"><v>1</v></c></row>`, "not a valid XLSX"},
+		{"column XFE", `<row r="1"><c r="XFE1"><v>1</v></c></row>`, "not a valid XLSX"},
+		{"row past the sheet", `<row r="1048577"><c r="A1048577"><v>1</v></c></row>`, "not a valid XLSX"},
+		{"row past MaxRows", `<row r="1"><c r="A1"><v>h</v></c></row><row r="5002"><c r="A5002"><v>1</v></c></row>`, "more than 5000 rows"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read("import.xlsx", workbook(t, tt.sheetData))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadXLSXAcceptsTheLastColumn(t *testing.T) {
+	table, err := Read("import.xlsx", workbook(t, `<row r="1"><c r="XFD1"><v>last</v></c></row>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Header) != xlsxMaxColumns || table.Header[xlsxMaxColumns-1] != "last" {
+		t.Errorf("header has %d columns", len(table.Header))
+	}
+}
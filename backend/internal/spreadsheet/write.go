@@ -0,0 +1,353 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is a file format Writer can produce.
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// ContentType is the MIME type to serve a file of this format with.
+func (f Format) ContentType() string {
+	if f == XLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Kind decides how a column's values are written: XLSX stores numbers and
+// dates as such with a matching number format, so they sum and sort in the
+// spreadsheet; CSV writes them in a form spreadsheet apps parse back.
+type Kind int
+
+const (
+	Text     Kind = iota
+	Integer       // ids and years, without thousands separators
+	Quantity      // counts and mileage, with thousands separators
+	Money         // amounts in rupiah with two decimals
+	Date
+	DateTime
+)
+
+// Column is a column of a written sheet.
+type Column struct {
+	Title string
+	Kind  Kind
+}
+
+// Options tune the output for the reader's locale.
+type Options struct {
+	// DecimalComma writes CSV decimals as 1500000,50 and separates fields
+	// with semicolons, as spreadsheet apps in such locales expect. XLSX
+	// stores numbers, which the app formats in its own locale.
+	DecimalComma bool
+}
+
+// Writer writes a sheet row by row without holding earlier rows, so a file
+// of any length is streamed. Close must be called to complete the file.
+type Writer interface {
+	// Write adds a row with one value per column: nil, string, bool, int,
+	// int64, float64 or time.Time, or a pointer to one of them.
+	Write(values []interface{}) error
+	Close() error
+}
+
+// NewWriter starts a file of the given format on w and writes the header
+// row.
+func NewWriter(w io.Writer, format Format, columns []Column, opts Options) (Writer, error) {
+	switch format {
+	case CSV:
+		return newCSVWriter(w, columns, opts)
+	case XLSX:
+		return newXLSXWriter(w, columns)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+type csvWriter struct {
+	writer  *csv.Writer
+	columns []Column
+	opts    Options
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, columns []Column, opts Options) (*csvWriter, error) {
+	// The byte order mark makes Excel read the file as UTF-8
+	if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+		return nil, err
+	}
+
+	cw := &csvWriter{writer: csv.NewWriter(w), columns: columns, opts: opts, record: make([]string, len(columns))}
+	if opts.DecimalComma {
+		cw.writer.Comma = ';'
+	}
+	for i, column := range columns {
+		cw.record[i] = column.Title
+	}
+	return cw, cw.writer.Write(cw.record)
+}
+
+func (w *csvWriter) Write(values []interface{}) error {
+	for i, column := range w.columns {
+		w.record[i] = ""
+		if i < len(values) {
+			w.record[i] = w.format(column.Kind, deref(values[i]))
+		}
+	}
+	return w.writer.Write(w.record)
+}
+
+func (w *csvWriter) format(kind Kind, value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if kind == Date {
+			return v.Format("2006-01-02")
+		}
+		return v.Format("2006-01-02 15:04:05")
+	case float64:
+		decimals := -1
+		if kind == Money {
+			decimals = 2
+		}
+		s := strconv.FormatFloat(v, 'f', decimals, 64)
+		if w.opts.DecimalComma {
+			s = strings.Replace(s, ".", ",", 1)
+		}
+		return s
+	case string:
+		return neutralizeFormula(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formulaPrefixes start a formula, or a cell some spreadsheet apps evaluate
+// as one, when a CSV file is opened.
+const formulaPrefixes = "=+-@\t\r"
+
+// neutralizeFormula prefixes text that would be evaluated as a formula with
+// an apostrophe, so a customer name like =HYPERLINK(...) stays text. XLSX
+// cells are typed as strings and need no escaping.
+func neutralizeFormula(s string) string {
+	if s != "" && strings.ContainsRune(formulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// Cell styles, indexes into cellXfs of xlsxStyles
+const (
+	styleDefault = iota
+	styleHeader
+	styleInteger
+	styleQuantity
+	styleMoney
+	styleDate
+	styleDateTime
+)
+
+var kindStyles = map[Kind]int{
+	Integer:  styleInteger,
+	Quantity: styleQuantity,
+	Money:    styleMoney,
+	Date:     styleDate,
+	DateTime: styleDateTime,
+}
+
+var kindWidths = map[Kind]int{
+	Text:     24,
+	Integer:  10,
+	Quantity: 12,
+	Money:    18,
+	Date:     12,
+	DateTime: 18,
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+const xlsxWorkbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`
+
+// xlsxStyles defines the cell styles in the order of the style constants.
+// Built-in formats 1 and 3 are "0" and "#,##0".
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><numFmts count="3"><numFmt numFmtId="164" formatCode="&quot;Rp&quot;\ #,##0.00"/><numFmt numFmtId="165" formatCode="yyyy\-mm\-dd"/><numFmt numFmtId="166" formatCode="yyyy\-mm\-dd\ hh:mm"/></numFmts><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="7"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/><xf numFmtId="1" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="3" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="166" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs></styleSheet>`
+
+// xlsxWriter writes the fixed parts of the workbook first and the sheet
+// last, so the sheet can be streamed into the archive. Strings are written
+// inline rather than to a shared string table, which would need every row
+// in memory before the sheet could be written.
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+	columns []Column
+	row     int
+}
+
+func newXLSXWriter(w io.Writer, columns []Column) (*xlsxWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbookXML},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		f, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxWriter{archive: archive, sheet: bufio.NewWriter(f), columns: columns}
+
+	// Keep the header row in view while scrolling
+	xw.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews><cols>`)
+	for i, column := range columns {
+		fmt.Fprintf(xw.sheet, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, kindWidths[column.Kind])
+	}
+	xw.sheet.WriteString(`</cols><sheetData>`)
+
+	titles := make([]interface{}, len(columns))
+	for i, column := range columns {
+		titles[i] = column.Title
+	}
+	if err := xw.writeRow(titles, true); err != nil {
+		return nil, err
+	}
+	return xw, nil
+}
+
+func (w *xlsxWriter) Write(values []interface{}) error {
+	return w.writeRow(values, false)
+}
+
+func (w *xlsxWriter) writeRow(values []interface{}, header bool) error {
+	w.row++
+	fmt.Fprintf(w.sheet, `<row r="%d">`, w.row)
+	for i, column := range w.columns {
+		if i >= len(values) {
+			break
+		}
+		ref := columnName(i) + strconv.Itoa(w.row)
+		style := kindStyles[column.Kind]
+		if header {
+			style = styleHeader
+		}
+
+		switch v := deref(values[i]).(type) {
+		case nil:
+		case string:
+			fmt.Fprintf(w.sheet, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+			xml.EscapeText(w.sheet, []byte(v))
+			w.sheet.WriteString(`</t></is></c>`)
+		case bool:
+			b := 0
+			if v {
+				b = 1
+			}
+			fmt.Fprintf(w.sheet, `<c r="%s" t="b"><v>%d</v></c>`, ref, b)
+		case int:
+			fmt.Fprintf(w.sheet, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, v)
+		case int64:
+			fmt.Fprintf(w.sheet, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, v)
+		case float64:
+			fmt.Fprintf(w.sheet, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(v, 'f', -1, 64))
+		case time.Time:
+			fmt.Fprintf(w.sheet, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(serialDate(v), 'f', -1, 64))
+		default:
+			return fmt.Errorf("cannot write a %T to a spreadsheet", v)
+		}
+	}
+	_, err := w.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (w *xlsxWriter) Close() error {
+	w.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.archive.Close()
+}
+
+// serialDate is the spreadsheet date serial of t's wall-clock time: days
+// since 1899-12-30, with the time of day as the fraction.
+func serialDate(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	return wall.Sub(time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)).Hours() / 24
+}
+
+// columnName is the inverse of columnIndex: 0 is "A" and 26 is "AA".
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func deref(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *string:
+		if v != nil {
+			return *v
+		}
+	case *int:
+		if v != nil {
+			return *v
+		}
+	case *float64:
+		if v != nil {
+			return *v
+		}
+	case *bool:
+		if v != nil {
+			return *v
+		}
+	case *time.Time:
+		if v != nil {
+			return *v
+		}
+	default:
+		return value
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package spreadsheet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCSVNeutralizesFormulas(t *testing.T) {
+	var b strings.Builder
+	w, err := NewWriter(&b, CSV, []Column{{Title: "Name", Kind: Text}, {Title: "Amount", Kind: Money}}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := "@SUM(A1)"
+	rows := [][]interface{}{
+		{"=HYPERLINK(\"http://x\")", -1500.5},
+		{"+62812", 0.0},
+		{"-1", 1.0},
+		{&name, 2.0},
+		{"\tTab", 3.0},
+		{"Budi = Andi", 4.0},
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "\xef\xbb\xbfName,Amount\n" +
+		"\"'=HYPERLINK(\"\"http://x\"\")\",-1500.50\n" +
+		"'+62812,0.00\n" +
+		"'-1,1.00\n" +
+		"'@SUM(A1),2.00\n" +
+		"'\tTab,3.00\n" +
+		"Budi = Andi,4.00\n"
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+}
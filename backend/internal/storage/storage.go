@@ -0,0 +1,115 @@
+// Package storage keeps uploaded files, e.g. customer documents, under
+// slash-separated keys. The database stores the key, never a path, so the
+// backend can be swapped without touching the records.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"vehicle-showroom/internal/config"
+)
+
+// ErrNotFound is returned by Open for a key with no file.
+var ErrNotFound = errors.New("file not found")
+
+// Storage saves, reads and removes files. Implementations for object stores
+// (S3, GCS, ...) can be added without touching the usecases.
+type Storage interface {
+	// Put writes r under key, replacing any file there, and returns the
+	// number of bytes written.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the file under key; a missing file is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		if err := os.MkdirAll(cfg.LocalPath, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create storage directory: %w", err)
+		}
+		return &localStorage{root: cfg.LocalPath}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.Driver)
+	}
+}
+
+// localStorage keeps files in a directory on the server's disk.
+type localStorage struct {
+	root string
+}
+
+// path maps key into the root, refusing keys that would escape it.
+func (s *localStorage) path(key string) (string, error) {
+	if key == "" || path.IsAbs(key) || !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes to a temporary file first, so a failed upload never leaves a
+// partial file under key.
+func (s *localStorage) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		return 0, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if err == nil {
+		err = ctx.Err()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return 0, fmt.Errorf("failed to store file: %w", err)
+	}
+	return n, nil
+}
+
+func (s *localStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return f, nil
+}
+
+func (s *localStorage) Delete(ctx context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
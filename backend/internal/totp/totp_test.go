@@ -0,0 +1,90 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 6238 appendix B test vectors,
+// "12345678901234567890", in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeAtRFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes; 6-digit codes are their last six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := CodeAt(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("CodeAt(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("CodeAt(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeAtAcceptsLowercaseSecret(t *testing.T) {
+	got, err := CodeAt(" gezdgnbvgy3tqojqgezdgnbvgy3tqojq ", Step(time.Unix(59, 0)))
+	if err != nil || got != "287082" {
+		t.Errorf("CodeAt = %q, %v, want 287082", got, err)
+	}
+}
+
+func TestCodeAtInvalidSecret(t *testing.T) {
+	if _, err := CodeAt("not base32!", 1); err == nil {
+		t.Error("CodeAt with an invalid secret succeeded")
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	code := func(step int64) string {
+		c, err := CodeAt(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", code(current), 1, current, true},
+		{"previous step within skew", code(current - 1), 1, current - 1, true},
+		{"next step within skew", code(current + 1), 1, current + 1, true},
+		{"two steps behind outside skew", code(current - 2), 1, 0, false},
+		{"two steps ahead outside skew", code(current + 2), 1, 0, false},
+		{"two steps behind with skew 2", code(current - 2), 2, current - 2, true},
+		{"no skew rejects the previous step", code(current - 1), 0, 0, false},
+		{"spaces are ignored", "050 471", 0, current, true},
+		{"wrong code", "000000", 1, 0, false},
+		{"too short", "05047", 1, 0, false},
+		{"too long", "0504710", 1, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, tt.skew)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate(%q, skew %d) = %d, %v, want %d, %v", tt.code, tt.skew, step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package usecase
 
 import (
-  "errors"
+  "context"
+  "crypto/rand"
+  "crypto/sha256"
+  "encoding/base64"
+  "encoding/hex"
   "fmt"
   "time"
 
@@ -9,92 +13,260 @@ import (
   "golang.org/x/crypto/bcrypt"
   "vehicle-showroom/internal/config"
   "vehicle-showroom/internal/entity"
+  "vehicle-showroom/internal/metrics"
   "vehicle-showroom/internal/repository"
 )
 
 type AuthUsecase interface {
-  Login(req *entity.LoginRequest, ipAddress string) (*entity.LoginResponse, error)
-  Register(req *entity.RegisterRequest) (*entity.User, error)
-  Logout(token string) error
-  GetProfile(token string) (*entity.User, error)
-  ValidateToken(token string) (*entity.User, error)
+  Login(ctx context.Context, req *entity.LoginRequest, ipAddress, userAgent string) (*entity.LoginResponse, error)
+  VerifyTwoFactor(ctx context.Context, challengeToken, code, ipAddress, userAgent string) (*entity.LoginResponse, error)
+  Refresh(ctx context.Context, refreshToken, ipAddress, userAgent string) (*entity.LoginResponse, error)
+  Register(ctx context.Context, req *entity.RegisterRequest) (*entity.User, error)
+  Logout(ctx context.Context, sessionToken string) error
+  GetProfile(ctx context.Context, token string) (*entity.User, error)
+  ValidateToken(ctx context.Context, token string) (*entity.User, *entity.UserSession, error)
+  ListSessions(ctx context.Context, userID, currentSessionID int) ([]entity.UserSession, error)
+  RevokeSession(ctx context.Context, userID, sessionID int) error
+  ForceLogout(ctx context.Context, userID int) error
+  UnlockUser(ctx context.Context, userID int) error
+  TwoFactorSetupRequired(user *entity.User) bool
+}
+
+// Challenge tokens are JWTs with this purpose and no session id, so they can
+// never be used as access tokens.
+const twoFactorChallengePurpose = "2fa_challenge"
+
+// LoginThrottledError is returned when a login is refused before the password
+// is checked, either because of progressive delay or an account lock.
+type LoginThrottledError struct {
+  RetryAfter time.Duration
+  Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+  seconds := int(e.RetryAfter.Seconds()) + 1
+  if e.Locked {
+    return fmt.Sprintf("account is temporarily locked, try again in %d seconds", seconds)
+  }
+  return fmt.Sprintf("too many failed login attempts, try again in %d seconds", seconds)
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+  if e.Locked {
+    return entity.ErrLocked
+  }
+  return entity.ErrTooManyRequests
 }
 
 type authUsecase struct {
-  userRepo    repository.UserRepository
-  sessionRepo repository.SessionRepository
-  jwtConfig   config.JWTConfig
+  userRepo         repository.UserRepository
+  sessionRepo      repository.SessionRepository
+  roleRepo         repository.RoleRepository
+  loginAttemptRepo repository.LoginAttemptRepository
+  twoFactorRepo    repository.TwoFactorRepository
+  jwtConfig        config.JWTConfig
+  loginConfig      config.LoginConfig
+  passwordConfig   config.PasswordConfig
+  twoFactorConfig  config.TwoFactorConfig
 }
 
 func NewAuthUsecase(
   userRepo repository.UserRepository,
   sessionRepo repository.SessionRepository,
+  roleRepo repository.RoleRepository,
+  loginAttemptRepo repository.LoginAttemptRepository,
+  twoFactorRepo repository.TwoFactorRepository,
   jwtConfig config.JWTConfig,
+  loginConfig config.LoginConfig,
+  passwordConfig config.PasswordConfig,
+  twoFactorConfig config.TwoFactorConfig,
 ) AuthUsecase {
   return &authUsecase{
-    userRepo:    userRepo,
-    sessionRepo: sessionRepo,
-    jwtConfig:   jwtConfig,
+    userRepo:         userRepo,
+    sessionRepo:      sessionRepo,
+    roleRepo:         roleRepo,
+    loginAttemptRepo: loginAttemptRepo,
+    twoFactorRepo:    twoFactorRepo,
+    jwtConfig:        jwtConfig,
+    loginConfig:      loginConfig,
+    passwordConfig:   passwordConfig,
+    twoFactorConfig:  twoFactorConfig,
   }
 }
 
-func (u *authUsecase) Login(req *entity.LoginRequest, ipAddress string) (*entity.LoginResponse, error) {
+func (u *authUsecase) Login(ctx context.Context, req *entity.LoginRequest, ipAddress, userAgent string) (*entity.LoginResponse, error) {
+  now := time.Now()
+  
   // Get user by username
-  user, err := u.userRepo.GetByUsername(req.Username)
+  user, err := u.userRepo.GetByUsername(ctx, req.Username)
   if err != nil {
     return nil, fmt.Errorf("failed to get user: %w", err)
   }
   
+  if err := u.checkThrottle(ctx, req.Username, user, ipAddress, userAgent, now); err != nil {
+    return nil, err
+  }
+  
   if user == nil {
-    return nil, errors.New("invalid username or password")
+    return nil, u.rejectAttempt(ctx, req.Username, nil, ipAddress, userAgent, entity.LoginFailureUnknownUser,
+      entity.NewUnauthorizedError("invalid username or password"))
   }
   
   // Verify password
   if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-    return nil, errors.New("invalid username or password")
+    return nil, u.failLogin(ctx, user, ipAddress, userAgent, entity.LoginFailureInvalidPassword,
+      "invalid username or password", now)
   }
   
-  // Generate JWT token
-  token, err := u.generateJWT(user)
+  if user.TwoFactorEnabled {
+    return u.startTwoFactorChallenge(ctx, user, ipAddress, userAgent)
+  }
+  
+  return u.completeLogin(ctx, user, ipAddress, userAgent)
+}
+
+// VerifyTwoFactor finishes a login that was answered with a challenge token.
+// Wrong codes count towards the same lockout as wrong passwords.
+func (u *authUsecase) VerifyTwoFactor(ctx context.Context, challengeToken, code, ipAddress, userAgent string) (*entity.LoginResponse, error) {
+  claims, err := u.parseJWT(challengeToken)
   if err != nil {
-    return nil, fmt.Errorf("failed to generate token: %w", err)
+    return nil, entity.NewUnauthorizedError("invalid or expired two-factor challenge")
   }
   
-  // Create session
-  session := &entity.UserSession{
-    UserID:       user.ID,
-    SessionToken: token,
-    IPAddress:    &ipAddress,
-    IsActive:     true,
+  userID, ok := claims["user_id"].(float64)
+  if !ok || claims["purpose"] != twoFactorChallengePurpose {
+    return nil, entity.NewUnauthorizedError("invalid or expired two-factor challenge")
   }
   
-  if err := u.sessionRepo.Create(session); err != nil {
-    return nil, fmt.Errorf("failed to create session: %w", err)
+  user, err := u.userRepo.GetByID(ctx, int(userID))
+  if err != nil {
+    return nil, fmt.Errorf("failed to get user: %w", err)
+  }
+  if user == nil || !user.TwoFactorEnabled {
+    return nil, entity.NewUnauthorizedError("invalid or expired two-factor challenge")
+  }
+  
+  now := time.Now()
+  if err := u.checkThrottle(ctx, user.Username, user, ipAddress, userAgent, now); err != nil {
+    return nil, err
+  }
+  
+  ok, err = verifySecondFactor(ctx, u.twoFactorRepo, user, code)
+  if err != nil {
+    return nil, err
+  }
+  if !ok {
+    return nil, u.failLogin(ctx, user, ipAddress, userAgent, entity.LoginFailureInvalid2FACode,
+      "invalid verification code", now)
+  }
+  
+  return u.completeLogin(ctx, user, ipAddress, userAgent)
+}
+
+// Refresh exchanges a refresh token for a new access/refresh token pair. Each
+// refresh token is single-use: presenting one that was already rotated is
+// treated as theft and revokes the whole session.
+func (u *authUsecase) Refresh(ctx context.Context, refreshToken, ipAddress, userAgent string) (*entity.LoginResponse, error) {
+  hash := hashToken(refreshToken)
+  
+  session, err := u.sessionRepo.GetByRefreshTokenHash(ctx, hash)
+  if err != nil {
+    return nil, fmt.Errorf("failed to get session: %w", err)
+  }
+  
+  if session == nil {
+    // Reuse detection: a rotated token coming back means it was copied
+    sessionID, err := u.sessionRepo.GetSessionIDByRotatedHash(ctx, hash)
+    if err != nil {
+      return nil, fmt.Errorf("failed to check refresh token: %w", err)
+    }
+    if sessionID != 0 {
+      if err := u.sessionRepo.Revoke(ctx, sessionID, "refresh_token_reuse"); err != nil {
+        return nil, fmt.Errorf("failed to revoke session: %w", err)
+      }
+      return nil, entity.NewUnauthorizedError("refresh token reuse detected, session revoked")
+    }
+    return nil, entity.NewUnauthorizedError("invalid refresh token")
+  }
+  
+  if !session.IsActive {
+    return nil, entity.NewUnauthorizedError("session not found or inactive")
+  }
+  
+  if session.RefreshExpiresAt != nil && session.RefreshExpiresAt.Before(time.Now()) {
+    return nil, entity.NewUnauthorizedError("refresh token expired")
+  }
+  
+  user, err := u.userRepo.GetByID(ctx, session.UserID)
+  if err != nil {
+    return nil, fmt.Errorf("failed to get user: %w", err)
+  }
+  if user == nil {
+    return nil, entity.NewUnauthorizedError("user not found")
+  }
+  
+  newRefreshToken, err := generateRandomToken()
+  if err != nil {
+    return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+  }
+  
+  expiresAt := time.Now().Add(time.Hour * time.Duration(u.jwtConfig.RefreshExpireHours))
+  rotated, err := u.sessionRepo.RotateRefreshToken(ctx, session.ID, hash, hashToken(newRefreshToken), expiresAt, ipAddress, userAgent)
+  if err != nil {
+    return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+  }
+  if !rotated {
+    // Another request rotated this token first
+    if err := u.sessionRepo.Revoke(ctx, session.ID, "refresh_token_reuse"); err != nil {
+      return nil, fmt.Errorf("failed to revoke session: %w", err)
+    }
+    return nil, entity.NewUnauthorizedError("refresh token reuse detected, session revoked")
+  }
+  
+  token, err := u.generateJWT(user, session.SessionToken)
+  if err != nil {
+    return nil, fmt.Errorf("failed to generate token: %w", err)
   }
   
   return &entity.LoginResponse{
-    Token: token,
-    User:  *user,
+    Token:                  token,
+    RefreshToken:           newRefreshToken,
+    ExpiresIn:              u.jwtConfig.AccessExpireMinutes * 60,
+    User:                   user,
+    TwoFactorSetupRequired: u.TwoFactorSetupRequired(user),
   }, nil
 }
 
-func (u *authUsecase) Register(req *entity.RegisterRequest) (*entity.User, error) {
+func (u *authUsecase) Register(ctx context.Context, req *entity.RegisterRequest) (*entity.User, error) {
   // Check if username already exists
-  existingUser, err := u.userRepo.GetByUsername(req.Username)
+  existingUser, err := u.userRepo.GetByUsername(ctx, req.Username)
   if err != nil {
     return nil, fmt.Errorf("failed to check username: %w", err)
   }
   if existingUser != nil {
-    return nil, errors.New("username already exists")
+    return nil, entity.NewConflictError("username already exists")
   }
   
   // Check if email already exists
-  existingUser, err = u.userRepo.GetByEmail(req.Email)
+  existingUser, err = u.userRepo.GetByEmail(ctx, req.Email)
   if err != nil {
     return nil, fmt.Errorf("failed to check email: %w", err)
   }
   if existingUser != nil {
-    return nil, errors.New("email already exists")
+    return nil, entity.NewConflictError("email already exists")
+  }
+  
+  // Check that the role exists
+  role, err := u.roleRepo.GetByName(ctx, req.Role)
+  if err != nil {
+    return nil, fmt.Errorf("failed to check role: %w", err)
+  }
+  if role == nil {
+    return nil, entity.NewFieldError("role", "does not exist")
+  }
+  
+  if err := validatePassword(u.passwordConfig, req.Password, req.Username); err != nil {
+    return nil, err
   }
   
   // Hash password
@@ -114,81 +286,384 @@ func (u *authUsecase) Register(req *entity.RegisterRequest) (*entity.User, error
     IsActive:     true,
   }
   
-  if err := u.userRepo.Create(user); err != nil {
+  if err := u.userRepo.Create(ctx, user); err != nil {
     return nil, fmt.Errorf("failed to create user: %w", err)
   }
   
   return user, nil
 }
 
-func (u *authUsecase) Logout(token string) error {
-  return u.sessionRepo.UpdateLogout(token)
+func (u *authUsecase) Logout(ctx context.Context, sessionToken string) error {
+  return u.sessionRepo.UpdateLogout(ctx, sessionToken)
 }
 
-func (u *authUsecase) GetProfile(token string) (*entity.User, error) {
-  return u.ValidateToken(token)
+func (u *authUsecase) GetProfile(ctx context.Context, token string) (*entity.User, error) {
+  user, _, err := u.ValidateToken(ctx, token)
+  return user, err
 }
 
-func (u *authUsecase) ValidateToken(tokenString string) (*entity.User, error) {
-  // Parse JWT token
-  token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-    if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-      return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-    }
-    return []byte(u.jwtConfig.Secret), nil
-  })
-  
+func (u *authUsecase) ValidateToken(ctx context.Context, tokenString string) (*entity.User, *entity.UserSession, error) {
+  claims, err := u.parseJWT(tokenString)
   if err != nil {
-    return nil, fmt.Errorf("failed to parse token: %w", err)
-  }
-  
-  if !token.Valid {
-    return nil, errors.New("invalid token")
+    return nil, nil, err
   }
   
-  // Extract claims
-  claims, ok := token.Claims.(jwt.MapClaims)
+  userID, ok := claims["user_id"].(float64)
   if !ok {
-    return nil, errors.New("invalid token claims")
+    return nil, nil, entity.NewUnauthorizedError("invalid user id in token")
   }
   
-  userID, ok := claims["user_id"].(float64)
+  sessionToken, ok := claims["sid"].(string)
   if !ok {
-    return nil, errors.New("invalid user id in token")
+    return nil, nil, entity.NewUnauthorizedError("invalid session id in token")
   }
   
   // Check session
-  session, err := u.sessionRepo.GetByToken(tokenString)
+  session, err := u.sessionRepo.GetByToken(ctx, sessionToken)
   if err != nil {
-    return nil, fmt.Errorf("failed to get session: %w", err)
+    return nil, nil, fmt.Errorf("failed to get session: %w", err)
   }
   
-  if session == nil || !session.IsActive {
-    return nil, errors.New("session not found or inactive")
+  if session == nil || !session.IsActive || session.UserID != int(userID) {
+    return nil, nil, entity.NewUnauthorizedError("session not found or inactive")
   }
   
   // Get user
-  user, err := u.userRepo.GetByID(int(userID))
+  user, err := u.userRepo.GetByID(ctx, int(userID))
   if err != nil {
-    return nil, fmt.Errorf("failed to get user: %w", err)
+    return nil, nil, fmt.Errorf("failed to get user: %w", err)
   }
   
   if user == nil {
-    return nil, errors.New("user not found")
+    return nil, nil, entity.NewUnauthorizedError("user not found")
   }
   
-  return user, nil
+  if err := u.sessionRepo.Touch(ctx, session.ID); err != nil {
+    return nil, nil, fmt.Errorf("failed to update session: %w", err)
+  }
+  
+  return user, session, nil
+}
+
+func (u *authUsecase) ListSessions(ctx context.Context, userID, currentSessionID int) ([]entity.UserSession, error) {
+  sessions, err := u.sessionRepo.ListActiveByUserID(ctx, userID)
+  if err != nil {
+    return nil, fmt.Errorf("failed to list sessions: %w", err)
+  }
+  
+  for i := range sessions {
+    sessions[i].Current = sessions[i].ID == currentSessionID
+  }
+  
+  return sessions, nil
+}
+
+func (u *authUsecase) RevokeSession(ctx context.Context, userID, sessionID int) error {
+  session, err := u.sessionRepo.GetByID(ctx, sessionID)
+  if err != nil {
+    return fmt.Errorf("failed to get session: %w", err)
+  }
+  
+  // Sessions of other users are reported as missing rather than forbidden
+  if session == nil || session.UserID != userID || !session.IsActive {
+    return entity.NewNotFoundError("session")
+  }
+  
+  return u.sessionRepo.Revoke(ctx, sessionID, "revoked_by_user")
+}
+
+func (u *authUsecase) ForceLogout(ctx context.Context, userID int) error {
+  user, err := u.userRepo.GetByID(ctx, userID)
+  if err != nil {
+    return fmt.Errorf("failed to get user: %w", err)
+  }
+  if user == nil {
+    return entity.NewNotFoundError("user")
+  }
+  
+  return u.sessionRepo.RevokeByUserID(ctx, userID, "forced_logout")
+}
+
+func (u *authUsecase) UnlockUser(ctx context.Context, userID int) error {
+  user, err := u.userRepo.GetByID(ctx, userID)
+  if err != nil {
+    return fmt.Errorf("failed to get user: %w", err)
+  }
+  if user == nil {
+    return entity.NewNotFoundError("user")
+  }
+  
+  return u.userRepo.ResetFailedLogins(ctx, userID)
+}
+
+func (u *authUsecase) TwoFactorSetupRequired(user *entity.User) bool {
+  return twoFactorRequired(u.twoFactorConfig, user) && !user.TwoFactorEnabled
+}
+
+// checkThrottle refuses the attempt while the IP or the account is blocked,
+// locked or inside its progressive delay. user may be nil for unknown names.
+func (u *authUsecase) checkThrottle(ctx context.Context, username string, user *entity.User, ipAddress, userAgent string, now time.Time) error {
+  since := now.Add(-time.Minute * time.Duration(u.loginConfig.WindowMinutes))
+  
+  var userID *int
+  if user != nil {
+    userID = &user.ID
+  }
+  
+  ipStats, err := u.loginAttemptRepo.GetFailureStatsByIP(ctx, ipAddress, since)
+  if err != nil {
+    return fmt.Errorf("failed to check login attempts: %w", err)
+  }
+  
+  if u.loginConfig.MaxIPFailures > 0 && ipStats.Count >= u.loginConfig.MaxIPFailures {
+    retryAfter := ipStats.LastFailureAt.Add(time.Minute * time.Duration(u.loginConfig.WindowMinutes)).Sub(now)
+    return u.rejectAttempt(ctx, username, userID, ipAddress, userAgent, entity.LoginFailureThrottled,
+      &LoginThrottledError{RetryAfter: retryAfter})
+  }
+  
+  if user != nil && user.LockedUntil != nil && user.LockedUntil.After(now) {
+    return u.rejectAttempt(ctx, username, userID, ipAddress, userAgent, entity.LoginFailureLocked,
+      &LoginThrottledError{RetryAfter: user.LockedUntil.Sub(now), Locked: true})
+  }
+  
+  usernameStats, err := u.loginAttemptRepo.GetFailureStatsByUsername(ctx, username, since)
+  if err != nil {
+    return fmt.Errorf("failed to check login attempts: %w", err)
+  }
+  
+  // Known users count consecutive failures on their row so an admin unlock
+  // resets the delay as well
+  usernameFailures := usernameStats.Count
+  if user != nil {
+    usernameFailures = consecutiveFailures(user, now)
+  }
+  
+  wait := u.progressiveDelay(usernameFailures, usernameStats.LastFailureAt, now)
+  if ipWait := u.progressiveDelay(ipStats.Count-u.loginConfig.MaxIPFailures/2, ipStats.LastFailureAt, now); ipWait > wait {
+    wait = ipWait
+  }
+  if wait > 0 {
+    return u.rejectAttempt(ctx, username, userID, ipAddress, userAgent, entity.LoginFailureThrottled,
+      &LoginThrottledError{RetryAfter: wait})
+  }
+  
+  return nil
 }
 
-func (u *authUsecase) generateJWT(user *entity.User) (string, error) {
+// failLogin counts a wrong password or code against the account and locks it
+// once MaxFailedAttempts is reached.
+func (u *authUsecase) failLogin(ctx context.Context, user *entity.User, ipAddress, userAgent, reason, message string, now time.Time) error {
+  lockUntil := now.Add(time.Minute * time.Duration(u.loginConfig.LockoutMinutes))
+  updated, err := u.userRepo.RecordFailedLogin(ctx, user.ID, u.loginConfig.MaxFailedAttempts, lockUntil, now)
+  if err != nil {
+    return fmt.Errorf("failed to record failed login: %w", err)
+  }
+  
+  var loginErr error = entity.NewUnauthorizedError(message)
+  if updated.LockedUntil != nil && updated.LockedUntil.After(now) {
+    loginErr = &LoginThrottledError{RetryAfter: updated.LockedUntil.Sub(now), Locked: true}
+  }
+  return u.rejectAttempt(ctx, user.Username, &user.ID, ipAddress, userAgent, reason, loginErr)
+}
+
+// completeLogin records the successful attempt, clears the failure counter
+// and opens a session.
+func (u *authUsecase) completeLogin(ctx context.Context, user *entity.User, ipAddress, userAgent string) (*entity.LoginResponse, error) {
+  if err := u.recordAttempt(ctx, user.Username, &user.ID, ipAddress, userAgent, nil); err != nil {
+    return nil, err
+  }
+  
+  if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+    if err := u.userRepo.ResetFailedLogins(ctx, user.ID); err != nil {
+      return nil, fmt.Errorf("failed to reset failed logins: %w", err)
+    }
+  }
+  
+  response, err := u.startSession(ctx, user, ipAddress, userAgent)
+  if err != nil {
+    return nil, err
+  }
+  
+  response.TwoFactorSetupRequired = u.TwoFactorSetupRequired(user)
+  return response, nil
+}
+
+// startTwoFactorChallenge answers a correct password on a 2FA account with a
+// short-lived challenge token instead of a session.
+func (u *authUsecase) startTwoFactorChallenge(ctx context.Context, user *entity.User, ipAddress, userAgent string) (*entity.LoginResponse, error) {
+  reason := entity.LoginFailure2FAPending
+  if err := u.recordAttempt(ctx, user.Username, &user.ID, ipAddress, userAgent, &reason); err != nil {
+    return nil, err
+  }
+  
+  claims := jwt.MapClaims{
+    "user_id": user.ID,
+    "purpose": twoFactorChallengePurpose,
+    "exp":     time.Now().Add(time.Minute * time.Duration(u.twoFactorConfig.ChallengeMinutes)).Unix(),
+    "iat":     time.Now().Unix(),
+  }
+  
+  token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(u.jwtConfig.Secret))
+  if err != nil {
+    return nil, fmt.Errorf("failed to generate challenge token: %w", err)
+  }
+  
+  return &entity.LoginResponse{
+    TwoFactorRequired: true,
+    ChallengeToken:    token,
+    ExpiresIn:         u.twoFactorConfig.ChallengeMinutes * 60,
+  }, nil
+}
+
+// consecutiveFailures is the user's failure count, which an expired lock
+// has already served; RecordFailedLogin starts it over on the next failure.
+func consecutiveFailures(user *entity.User, now time.Time) int {
+  if user.LockedUntil != nil && !user.LockedUntil.After(now) {
+    return 0
+  }
+  return user.FailedLoginAttempts
+}
+
+// progressiveDelay doubles the required wait for every failure past
+// DelayAfter, measured from the most recent failure.
+func (u *authUsecase) progressiveDelay(failures int, lastFailureAt *time.Time, now time.Time) time.Duration {
+  if lastFailureAt == nil || failures < u.loginConfig.DelayAfter {
+    return 0
+  }
+  
+  maxDelay := time.Second * time.Duration(u.loginConfig.MaxDelaySeconds)
+  delay := maxDelay
+  if exp := failures - u.loginConfig.DelayAfter; exp < 16 {
+    delay = time.Second << uint(exp)
+  }
+  if delay > maxDelay {
+    delay = maxDelay
+  }
+  
+  return lastFailureAt.Add(delay).Sub(now)
+}
+
+// rejectAttempt records a failed attempt and returns loginErr, unless the
+// attempt could not be recorded.
+func (u *authUsecase) rejectAttempt(ctx context.Context, username string, userID *int, ipAddress, userAgent, reason string, loginErr error) error {
+  metrics.LoginFailures.Inc(reason)
+  if err := u.recordAttempt(ctx, username, userID, ipAddress, userAgent, &reason); err != nil {
+    return err
+  }
+  return loginErr
+}
+
+func (u *authUsecase) recordAttempt(ctx context.Context, username string, userID *int, ipAddress, userAgent string, failureReason *string) error {
+  attempt := &entity.LoginAttempt{
+    Username:      username,
+    UserID:        userID,
+    IPAddress:     &ipAddress,
+    UserAgent:     &userAgent,
+    Success:       failureReason == nil,
+    FailureReason: failureReason,
+    AttemptedAt:   time.Now(),
+  }
+  
+  if err := u.loginAttemptRepo.Create(ctx, attempt); err != nil {
+    return fmt.Errorf("failed to record login attempt: %w", err)
+  }
+  
+  return nil
+}
+
+func (u *authUsecase) startSession(ctx context.Context, user *entity.User, ipAddress, userAgent string) (*entity.LoginResponse, error) {
+  sessionToken, err := generateRandomToken()
+  if err != nil {
+    return nil, fmt.Errorf("failed to generate session id: %w", err)
+  }
+  
+  refreshToken, err := generateRandomToken()
+  if err != nil {
+    return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+  }
+  
+  // Generate JWT token
+  token, err := u.generateJWT(user, sessionToken)
+  if err != nil {
+    return nil, fmt.Errorf("failed to generate token: %w", err)
+  }
+  
+  refreshHash := hashToken(refreshToken)
+  refreshExpiresAt := time.Now().Add(time.Hour * time.Duration(u.jwtConfig.RefreshExpireHours))
+  
+  // Create session
+  session := &entity.UserSession{
+    UserID:           user.ID,
+    SessionToken:     sessionToken,
+    RefreshTokenHash: &refreshHash,
+    RefreshExpiresAt: &refreshExpiresAt,
+    IPAddress:        &ipAddress,
+    UserAgent:        &userAgent,
+    IsActive:         true,
+  }
+  
+  if err := u.sessionRepo.Create(ctx, session); err != nil {
+    return nil, fmt.Errorf("failed to create session: %w", err)
+  }
+  
+  return &entity.LoginResponse{
+    Token:        token,
+    RefreshToken: refreshToken,
+    ExpiresIn:    u.jwtConfig.AccessExpireMinutes * 60,
+    User:         user,
+  }, nil
+}
+
+func (u *authUsecase) parseJWT(tokenString string) (jwt.MapClaims, error) {
+  token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+    if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+      return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+    }
+    return []byte(u.jwtConfig.Secret), nil
+  })
+  
+  if err != nil {
+    return nil, entity.NewUnauthorizedError("invalid or expired token")
+  }
+  
+  if !token.Valid {
+    return nil, entity.NewUnauthorizedError("invalid token")
+  }
+  
+  claims, ok := token.Claims.(jwt.MapClaims)
+  if !ok {
+    return nil, entity.NewUnauthorizedError("invalid token claims")
+  }
+  
+  return claims, nil
+}
+
+func (u *authUsecase) generateJWT(user *entity.User, sessionToken string) (string, error) {
   claims := jwt.MapClaims{
     "user_id":  user.ID,
     "username": user.Username,
     "role":     user.Role,
-    "exp":      time.Now().Add(time.Hour * time.Duration(u.jwtConfig.ExpireHours)).Unix(),
+    "sid":      sessionToken,
+    "exp":      time.Now().Add(time.Minute * time.Duration(u.jwtConfig.AccessExpireMinutes)).Unix(),
     "iat":      time.Now().Unix(),
   }
   
   token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
   return token.SignedString([]byte(u.jwtConfig.Secret))
 }
+
+// generateRandomToken returns 32 random bytes, URL-safe encoded.
+func generateRandomToken() (string, error) {
+  b := make([]byte, 32)
+  if _, err := rand.Read(b); err != nil {
+    return "", err
+  }
+  return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is used for opaque tokens that are stored server-side; only the
+// hash is persisted so a database leak does not hand out live tokens.
+func hashToken(token string) string {
+  sum := sha256.Sum256([]byte(token))
+  return hex.EncodeToString(sum[:])
+}
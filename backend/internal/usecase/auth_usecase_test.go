@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"vehicle-showroom/internal/config"
+	"vehicle-showroom/internal/entity"
+)
+
+func TestProgressiveDelay(t *testing.T) {
+	u := &authUsecase{loginConfig: config.LoginConfig{DelayAfter: 3, MaxDelaySeconds: 30}}
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	at := func(ago time.Duration) *time.Time {
+		t := now.Add(-ago)
+		return &t
+	}
+
+	tests := []struct {
+		name          string
+		failures      int
+		lastFailureAt *time.Time
+		want          time.Duration
+	}{
+		{"no failures recorded", 5, nil, 0},
+		{"below threshold", 2, at(0), 0},
+		{"at threshold", 3, at(0), time.Second},
+		{"doubles per failure", 5, at(0), 4 * time.Second},
+		{"capped at max", 8, at(0), 30 * time.Second},
+		{"huge count does not overflow", 1000, at(0), 30 * time.Second},
+		{"measured from last failure", 5, at(3 * time.Second), time.Second},
+		{"elapsed wait is negative", 5, at(10 * time.Second), -6 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := u.progressiveDelay(tt.failures, tt.lastFailureAt, now); got != tt.want {
+				t.Errorf("progressiveDelay(%d) = %v, want %v", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsecutiveFailures(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	at := func(offset time.Duration) *time.Time {
+		t := now.Add(offset)
+		return &t
+	}
+
+	tests := []struct {
+		name        string
+		failures    int
+		lockedUntil *time.Time
+		want        int
+	}{
+		{"never locked", 3, nil, 3},
+		{"still locked", 5, at(time.Minute), 5},
+		{"lock expired", 5, at(-time.Minute), 0},
+		{"lock expires now", 5, at(0), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &entity.User{FailedLoginAttempts: tt.failures, LockedUntil: tt.lockedUntil}
+			if got := consecutiveFailures(user, now); got != tt.want {
+				t.Errorf("consecutiveFailures = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
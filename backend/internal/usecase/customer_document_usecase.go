@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/repository"
+	"vehicle-showroom/internal/storage"
+)
+
+// documentTypes are the file formats accepted for customer documents, by
+// sniffed content type, with the extension they are stored under.
+var documentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+// DocumentFile is an uploaded file as the handler received it.
+type DocumentFile struct {
+	Name    string
+	Content io.Reader
+}
+
+type CustomerDocumentUsecase interface {
+	Upload(ctx context.Context, customerID int, req *entity.UploadCustomerDocumentRequest, file DocumentFile, uploadedBy int) (*entity.CustomerDocument, error)
+	List(ctx context.Context, customerID int) ([]entity.CustomerDocument, error)
+	Open(ctx context.Context, customerID, id int) (*entity.CustomerDocument, io.ReadCloser, error)
+	Verify(ctx context.Context, customerID, id, verifiedBy int) (*entity.CustomerDocument, error)
+	Delete(ctx context.Context, customerID, id int) error
+	KYCStatus(ctx context.Context, customerID int) (*entity.KYCStatus, error)
+}
+
+type customerDocumentUsecase struct {
+	documentRepo repository.CustomerDocumentRepository
+	customerRepo repository.CustomerRepository
+	store        storage.Storage
+}
+
+func NewCustomerDocumentUsecase(documentRepo repository.CustomerDocumentRepository, customerRepo repository.CustomerRepository, store storage.Storage) CustomerDocumentUsecase {
+	return &customerDocumentUsecase{
+		documentRepo: documentRepo,
+		customerRepo: customerRepo,
+		store:        store,
+	}
+}
+
+// Upload checks the form, stores the file and records it. ID cards must
+// carry a well-formed NIK and tax documents an NPWP; both are stored
+// without separators.
+func (u *customerDocumentUsecase) Upload(ctx context.Context, customerID int, req *entity.UploadCustomerDocumentRequest, file DocumentFile, uploadedBy int) (*entity.CustomerDocument, error) {
+	if _, err := u.customer(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	document := &entity.CustomerDocument{
+		CustomerID: customerID,
+		Type:       req.Type,
+		FileName:   path.Base(strings.ReplaceAll(file.Name, `\`, "/")),
+		UploadedBy: &uploadedBy,
+	}
+
+	number := ""
+	if req.DocumentNumber != nil {
+		number = strings.TrimSpace(*req.DocumentNumber)
+	}
+	switch req.Type {
+	case entity.DocumentIDCard:
+		nik, ok := entity.NormalizeNIK(number)
+		if !ok {
+			return nil, entity.NewFieldError("document_number", "must be a 16-digit NIK")
+		}
+		number = nik
+	case entity.DocumentTaxNumber:
+		npwp, ok := entity.NormalizeNPWP(number)
+		if !ok {
+			return nil, entity.NewFieldError("document_number", "must be an NPWP of 15 or 16 digits")
+		}
+		number = npwp
+	}
+	if number != "" {
+		document.DocumentNumber = &number
+	}
+
+	if req.ExpiresAt != nil && *req.ExpiresAt != "" {
+		expiresAt, err := time.Parse("2006-01-02", *req.ExpiresAt)
+		if err != nil {
+			return nil, entity.NewFieldError("expires_at", "must be a date (YYYY-MM-DD)")
+		}
+		document.ExpiresAt = &expiresAt
+	}
+
+	// The declared content type is the client's word; trust the bytes
+	content := bufio.NewReader(file.Content)
+	head, _ := content.Peek(512)
+	document.ContentType = strings.SplitN(http.DetectContentType(head), ";", 2)[0]
+	ext, ok := documentTypes[document.ContentType]
+	if !ok {
+		return nil, entity.NewFieldError("file", "must be a PDF, JPEG or PNG file")
+	}
+
+	key, err := documentKey(customerID, ext)
+	if err != nil {
+		return nil, err
+	}
+	if document.SizeBytes, err = u.store.Put(ctx, key, content); err != nil {
+		return nil, fmt.Errorf("failed to store customer document: %w", err)
+	}
+	document.StorageKey = key
+
+	if err := u.documentRepo.Create(ctx, document); err != nil {
+		u.removeFile(ctx, key)
+		return nil, fmt.Errorf("failed to create customer document: %w", err)
+	}
+
+	return document, nil
+}
+
+func (u *customerDocumentUsecase) List(ctx context.Context, customerID int) ([]entity.CustomerDocument, error) {
+	if _, err := u.customer(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	return u.documentRepo.ListByCustomer(ctx, customerID)
+}
+
+// Open returns the document with its file, which the caller must close.
+func (u *customerDocumentUsecase) Open(ctx context.Context, customerID, id int) (*entity.CustomerDocument, io.ReadCloser, error) {
+	document, err := u.document(ctx, customerID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := u.store.Open(ctx, document.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, entity.NewNotFoundError("document file")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open customer document: %w", err)
+	}
+
+	return document, file, nil
+}
+
+// Verify records that the document was checked against the original.
+// Verifying it again keeps the first verification.
+func (u *customerDocumentUsecase) Verify(ctx context.Context, customerID, id, verifiedBy int) (*entity.CustomerDocument, error) {
+	document, err := u.document(ctx, customerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if document.VerifiedAt != nil {
+		return document, nil
+	}
+	if document.Expired(time.Now()) {
+		return nil, entity.NewInvalidTransitionError("document expired on %s", document.ExpiresAt.Format("2006-01-02"))
+	}
+
+	if err := u.documentRepo.Verify(ctx, id, verifiedBy); err != nil {
+		return nil, fmt.Errorf("failed to verify customer document: %w", err)
+	}
+
+	return u.documentRepo.GetByID(ctx, customerID, id)
+}
+
+func (u *customerDocumentUsecase) Delete(ctx context.Context, customerID, id int) error {
+	document, err := u.document(ctx, customerID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := u.documentRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete customer document: %w", err)
+	}
+	u.removeFile(ctx, document.StorageKey)
+
+	return nil
+}
+
+// KYCStatus lists the documents the customer still needs verified before
+// a sale; see entity.RequiredDocuments.
+func (u *customerDocumentUsecase) KYCStatus(ctx context.Context, customerID int) (*entity.KYCStatus, error) {
+	customer, err := u.customer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := u.documentRepo.ListByCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := entity.CheckKYC(customer.Type, documents, time.Now())
+	return &status, nil
+}
+
+func (u *customerDocumentUsecase) customer(ctx context.Context, id int) (*entity.Customer, error) {
+	customer, err := u.customerRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	if customer == nil {
+		return nil, entity.NewNotFoundError("customer")
+	}
+	return customer, nil
+}
+
+func (u *customerDocumentUsecase) document(ctx context.Context, customerID, id int) (*entity.CustomerDocument, error) {
+	document, err := u.documentRepo.GetByID(ctx, customerID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer document: %w", err)
+	}
+	if document == nil {
+		return nil, entity.NewNotFoundError("customer document")
+	}
+	return document, nil
+}
+
+// removeFile deletes a file whose record is gone or was never written. A
+// failure only leaves an orphaned file behind, so it is logged, not returned.
+func (u *customerDocumentUsecase) removeFile(ctx context.Context, key string) {
+	if err := u.store.Delete(context.WithoutCancel(ctx), key); err != nil {
+		slog.ErrorContext(ctx, "failed to remove customer document file",
+			slog.String("key", key),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// documentKey is a fresh, unguessable key under the customer's prefix.
+func documentKey(customerID int, ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate document key: %w", err)
+	}
+	return fmt.Sprintf("customers/%d/%s%s", customerID, hex.EncodeToString(b), ext), nil
+}
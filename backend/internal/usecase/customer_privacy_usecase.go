@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/repository"
+	"vehicle-showroom/internal/storage"
+)
+
+type CustomerPrivacyUsecase interface {
+	Consents(ctx context.Context, customerID int) (*entity.CustomerConsents, error)
+	RecordConsent(ctx context.Context, customerID int, req *entity.RecordConsentRequest, recordedBy int) (*entity.CustomerConsents, error)
+	Export(ctx context.Context, customerID int) (*entity.CustomerDataExport, error)
+	Anonymize(ctx context.Context, customerID, anonymizedBy int) (*entity.CustomerAnonymization, error)
+}
+
+type customerPrivacyUsecase struct {
+	customerRepo repository.CustomerRepository
+	documentRepo repository.CustomerDocumentRepository
+	leadRepo     repository.LeadRepository
+	store        storage.Storage
+}
+
+func NewCustomerPrivacyUsecase(
+	customerRepo repository.CustomerRepository,
+	documentRepo repository.CustomerDocumentRepository,
+	leadRepo repository.LeadRepository,
+	store storage.Storage,
+) CustomerPrivacyUsecase {
+	return &customerPrivacyUsecase{
+		customerRepo: customerRepo,
+		documentRepo: documentRepo,
+		leadRepo:     leadRepo,
+		store:        store,
+	}
+}
+
+func (u *customerPrivacyUsecase) Consents(ctx context.Context, customerID int) (*entity.CustomerConsents, error) {
+	if _, err := u.customer(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	history, err := u.customerRepo.ListConsents(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return entity.NewCustomerConsents(history), nil
+}
+
+// RecordConsent appends to the consent history rather than overwriting it,
+// and returns the consents now in force.
+func (u *customerPrivacyUsecase) RecordConsent(ctx context.Context, customerID int, req *entity.RecordConsentRequest, recordedBy int) (*entity.CustomerConsents, error) {
+	if _, err := u.customer(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	consent := &entity.CustomerConsent{
+		CustomerID: customerID,
+		Channel:    req.Channel,
+		Granted:    *req.Granted,
+		Source:     req.Source,
+		RecordedBy: &recordedBy,
+	}
+	if err := u.customerRepo.RecordConsent(ctx, consent); err != nil {
+		return nil, fmt.Errorf("failed to record customer consent: %w", err)
+	}
+
+	return u.Consents(ctx, customerID)
+}
+
+// Export gathers everything stored about the customer, deleted or not.
+func (u *customerPrivacyUsecase) Export(ctx context.Context, customerID int) (*entity.CustomerDataExport, error) {
+	customer, err := u.customerRepo.GetForPrivacy(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	if customer == nil {
+		return nil, entity.NewNotFoundError("customer")
+	}
+	if customer.AnonymizedAt != nil {
+		return nil, entity.NewConflictError("customer has already been anonymized")
+	}
+
+	export := &entity.CustomerDataExport{ExportedAt: time.Now(), Customer: customer}
+	if export.Consents, err = u.customerRepo.ListConsents(ctx, customerID); err != nil {
+		return nil, err
+	}
+	if export.Notes, err = u.customerRepo.ListNotes(ctx, customerID); err != nil {
+		return nil, err
+	}
+	if export.Documents, err = u.documentRepo.ListByCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	if export.VehiclesSoldToUs, err = u.customerRepo.VehiclesSoldToUs(ctx, customerID); err != nil {
+		return nil, err
+	}
+	if export.Purchases, err = u.customerRepo.PurchasesByCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	if export.Sales, err = u.customerRepo.SalesByCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	if export.Reservations, err = u.customerRepo.ReservedVehicles(ctx, customerID); err != nil {
+		return nil, err
+	}
+	if export.Leads, err = u.leadRepo.ListByCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	if export.Merges, err = u.customerRepo.ListMerges(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// Anonymize scrubs the customer's personal data; see
+// CustomerRepository.Anonymize. Document files are removed once the records
+// are gone.
+func (u *customerPrivacyUsecase) Anonymize(ctx context.Context, customerID, anonymizedBy int) (*entity.CustomerAnonymization, error) {
+	result, err := u.customerRepo.Anonymize(ctx, customerID, anonymizedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range result.StorageKeys {
+		if err := u.store.Delete(context.WithoutCancel(ctx), key); err != nil {
+			slog.ErrorContext(ctx, "failed to remove anonymized customer document file",
+				slog.String("key", key),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	slog.InfoContext(ctx, "customer anonymized",
+		slog.Int("customer_id", customerID),
+		slog.Any("customer_ids", result.CustomerIDs),
+		slog.Int("anonymized_by", anonymizedBy),
+	)
+	return result, nil
+}
+
+func (u *customerPrivacyUsecase) customer(ctx context.Context, id int) (*entity.Customer, error) {
+	customer, err := u.customerRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	if customer == nil {
+		return nil, entity.NewNotFoundError("customer")
+	}
+	return customer, nil
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/repository"
+)
+
+// privacyCustomerRepo serves one customer row as GetForPrivacy would, active
+// or not, and nothing related to it. Other methods are not expected.
+type privacyCustomerRepo struct {
+	repository.CustomerRepository
+	customer *entity.Customer
+}
+
+func (r *privacyCustomerRepo) GetForPrivacy(ctx context.Context, id int) (*entity.Customer, error) {
+	if r.customer == nil || r.customer.ID != id {
+		return nil, nil
+	}
+	return r.customer, nil
+}
+
+func (r *privacyCustomerRepo) ListConsents(context.Context, int) ([]entity.CustomerConsent, error) {
+	return []entity.CustomerConsent{}, nil
+}
+
+func (r *privacyCustomerRepo) ListNotes(context.Context, int) ([]entity.CustomerNote, error) {
+	return []entity.CustomerNote{}, nil
+}
+
+func (r *privacyCustomerRepo) VehiclesSoldToUs(context.Context, int) ([]entity.Vehicle, error) {
+	return []entity.Vehicle{}, nil
+}
+
+func (r *privacyCustomerRepo) PurchasesByCustomer(context.Context, int) ([]entity.PurchaseTransaction, error) {
+	return []entity.PurchaseTransaction{}, nil
+}
+
+func (r *privacyCustomerRepo) SalesByCustomer(context.Context, int) ([]entity.SalesTransaction, error) {
+	return []entity.SalesTransaction{}, nil
+}
+
+func (r *privacyCustomerRepo) ReservedVehicles(context.Context, int) ([]entity.Vehicle, error) {
+	return []entity.Vehicle{}, nil
+}
+
+func (r *privacyCustomerRepo) ListMerges(context.Context, int) ([]entity.CustomerMerge, error) {
+	return []entity.CustomerMerge{}, nil
+}
+
+type privacyDocumentRepo struct {
+	repository.CustomerDocumentRepository
+}
+
+func (privacyDocumentRepo) ListByCustomer(context.Context, int) ([]entity.CustomerDocument, error) {
+	return []entity.CustomerDocument{}, nil
+}
+
+type privacyLeadRepo struct {
+	repository.LeadRepository
+}
+
+func (privacyLeadRepo) ListByCustomer(context.Context, int) ([]entity.Lead, error) {
+	return []entity.Lead{}, nil
+}
+
+func TestExportDeletedCustomer(t *testing.T) {
+	anonymizedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		customer *entity.Customer
+		wantErr  error
+	}{
+		{"active", &entity.Customer{ID: 7, Name: "Budi", IsActive: true}, nil},
+		{"deleted", &entity.Customer{ID: 7, Name: "Budi", IsActive: false}, nil},
+		{"anonymized", &entity.Customer{ID: 7, Name: entity.AnonymizedCustomerName, AnonymizedAt: &anonymizedAt}, entity.ErrConflict},
+		{"missing", nil, entity.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := NewCustomerPrivacyUsecase(&privacyCustomerRepo{customer: tt.customer}, privacyDocumentRepo{}, privacyLeadRepo{}, nil)
+
+			export, err := u.Export(context.Background(), 7)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if export.Customer.Name != "Budi" {
+				t.Errorf("exported customer = %+v", export.Customer)
+			}
+		})
+	}
+}
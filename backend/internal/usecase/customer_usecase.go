@@ -1,6 +1,7 @@
 package usecase
 
 import (
+  "context"
   "fmt"
 
   "vehicle-showroom/internal/entity"
@@ -8,11 +9,16 @@ import (
 )
 
 type CustomerUsecase interface {
-  Create(req *entity.CreateCustomerRequest, createdBy int) (*entity.Customer, error)
-  GetByID(id int) (*entity.Customer, error)
-  List(page, limit int, search string) (*entity.CustomerListResponse, error)
-  Update(id int, req *entity.UpdateCustomerRequest) (*entity.Customer, error)
-  Delete(id int) error
+  Create(ctx context.Context, req *entity.CreateCustomerRequest, createdBy int) (*entity.Customer, []entity.CustomerDuplicate, error)
+  GetByID(ctx context.Context, id int) (*entity.Customer, error)
+  List(ctx context.Context, params entity.ListParams, search string) (*entity.CustomerListResponse, error)
+  Update(ctx context.Context, id int, req *entity.UpdateCustomerRequest) (*entity.Customer, error)
+  Delete(ctx context.Context, id int) error
+  GetProfile(ctx context.Context, id int) (*entity.CustomerProfile, error)
+  AddNote(ctx context.Context, id int, req *entity.CreateCustomerNoteRequest, createdBy int) (*entity.CustomerNote, error)
+  FindDuplicates(ctx context.Context, id int) ([]entity.CustomerDuplicate, error)
+  Merge(ctx context.Context, survivorID, mergedID, mergedBy int) (*entity.CustomerMerge, error)
+  ListMerges(ctx context.Context, id int) ([]entity.CustomerMerge, error)
 }
 
 type customerUsecase struct {
@@ -25,11 +31,14 @@ func NewCustomerUsecase(customerRepo repository.CustomerRepository) CustomerUsec
   }
 }
 
-func (u *customerUsecase) Create(req *entity.CreateCustomerRequest, createdBy int) (*entity.Customer, error) {
+// Create adds the customer and returns, as a warning, any existing customers
+// that look like the same person. They do not stop the create: an admin can
+// merge them later.
+func (u *customerUsecase) Create(ctx context.Context, req *entity.CreateCustomerRequest, createdBy int) (*entity.Customer, []entity.CustomerDuplicate, error) {
   // Generate customer code
-  customerCode, err := u.customerRepo.GenerateCustomerCode()
+  customerCode, err := u.customerRepo.GenerateCustomerCode(ctx)
   if err != nil {
-    return nil, fmt.Errorf("failed to generate customer code: %w", err)
+    return nil, nil, fmt.Errorf("failed to generate customer code: %w", err)
   }
   
   customer := &entity.Customer{
@@ -44,55 +53,57 @@ func (u *customerUsecase) Create(req *entity.CreateCustomerRequest, createdBy in
     IsActive:     true,
   }
   
-  if err := u.customerRepo.Create(customer); err != nil {
-    return nil, fmt.Errorf("failed to create customer: %w", err)
+  duplicates, err := u.customerRepo.FindDuplicates(ctx, customer)
+  if err != nil {
+    return nil, nil, err
   }
   
-  return customer, nil
+  if err := u.customerRepo.Create(ctx, customer); err != nil {
+    return nil, nil, fmt.Errorf("failed to create customer: %w", err)
+  }
+  
+  return customer, duplicates, nil
 }
 
-func (u *customerUsecase) GetByID(id int) (*entity.Customer, error) {
-  customer, err := u.customerRepo.GetByID(id)
+func (u *customerUsecase) GetByID(ctx context.Context, id int) (*entity.Customer, error) {
+  customer, err := u.customerRepo.GetByID(ctx, id)
   if err != nil {
     return nil, fmt.Errorf("failed to get customer: %w", err)
   }
   
   if customer == nil {
-    return nil, fmt.Errorf("customer not found")
+    return nil, entity.NewNotFoundError("customer")
   }
   
   return customer, nil
 }
 
-func (u *customerUsecase) List(page, limit int, search string) (*entity.CustomerListResponse, error) {
-  if page <= 0 {
-    page = 1
-  }
-  if limit <= 0 || limit > 100 {
-    limit = 10
-  }
+func (u *customerUsecase) List(ctx context.Context, params entity.ListParams, search string) (*entity.CustomerListResponse, error) {
+  params.Normalize(10, 100)
   
-  customers, total, err := u.customerRepo.List(page, limit, search)
+  customers, page, err := u.customerRepo.List(ctx, params, search)
   if err != nil {
     return nil, fmt.Errorf("failed to list customers: %w", err)
   }
   
   return &entity.CustomerListResponse{
-    Customers: customers,
-    Total:     total,
-    Page:      page,
-    Limit:     limit,
+    Customers:  customers,
+    Total:      page.Total,
+    HasMore:    page.HasMore,
+    NextCursor: page.NextCursor,
+    Page:       params.ResponsePage(),
+    Limit:      params.Limit,
   }, nil
 }
 
-func (u *customerUsecase) Update(id int, req *entity.UpdateCustomerRequest) (*entity.Customer, error) {
-  customer, err := u.customerRepo.GetByID(id)
+func (u *customerUsecase) Update(ctx context.Context, id int, req *entity.UpdateCustomerRequest) (*entity.Customer, error) {
+  customer, err := u.customerRepo.GetByID(ctx, id)
   if err != nil {
     return nil, fmt.Errorf("failed to get customer: %w", err)
   }
   
   if customer == nil {
-    return nil, fmt.Errorf("customer not found")
+    return nil, entity.NewNotFoundError("customer")
   }
   
   customer.Name = req.Name
@@ -102,26 +113,116 @@ func (u *customerUsecase) Update(id int, req *entity.UpdateCustomerRequest) (*en
   customer.IDCardNumber = req.IDCardNumber
   customer.Type = req.Type
   
-  if err := u.customerRepo.Update(customer); err != nil {
+  if err := u.customerRepo.Update(ctx, customer); err != nil {
     return nil, fmt.Errorf("failed to update customer: %w", err)
   }
   
   return customer, nil
 }
 
-func (u *customerUsecase) Delete(id int) error {
-  customer, err := u.customerRepo.GetByID(id)
+func (u *customerUsecase) Delete(ctx context.Context, id int) error {
+  customer, err := u.customerRepo.GetByID(ctx, id)
   if err != nil {
     return fmt.Errorf("failed to get customer: %w", err)
   }
   
   if customer == nil {
-    return fmt.Errorf("customer not found")
+    return entity.NewNotFoundError("customer")
   }
   
-  if err := u.customerRepo.Delete(id); err != nil {
+  if err := u.customerRepo.Delete(ctx, id); err != nil {
     return fmt.Errorf("failed to delete customer: %w", err)
   }
   
   return nil
 }
+
+// GetProfile gathers the customer's vehicles, transactions, reservations
+// and notes with their totals.
+func (u *customerUsecase) GetProfile(ctx context.Context, id int) (*entity.CustomerProfile, error) {
+  customer, err := u.GetByID(ctx, id)
+  if err != nil {
+    return nil, err
+  }
+  
+  profile := &entity.CustomerProfile{Customer: customer}
+  
+  stats, err := u.customerRepo.Stats(ctx, id)
+  if err != nil {
+    return nil, err
+  }
+  profile.Stats = *stats
+  
+  if profile.VehiclesSoldToUs, err = u.customerRepo.VehiclesSoldToUs(ctx, id); err != nil {
+    return nil, err
+  }
+  if profile.VehiclesBought, err = u.customerRepo.SalesByCustomer(ctx, id); err != nil {
+    return nil, err
+  }
+  if profile.OpenReservations, err = u.customerRepo.ReservedVehicles(ctx, id); err != nil {
+    return nil, err
+  }
+  if profile.Notes, err = u.customerRepo.ListNotes(ctx, id); err != nil {
+    return nil, err
+  }
+  
+  return profile, nil
+}
+
+func (u *customerUsecase) AddNote(ctx context.Context, id int, req *entity.CreateCustomerNoteRequest, createdBy int) (*entity.CustomerNote, error) {
+  if _, err := u.GetByID(ctx, id); err != nil {
+    return nil, err
+  }
+  
+  note := &entity.CustomerNote{
+    CustomerID: id,
+    Note:       req.Note,
+    CreatedBy:  &createdBy,
+  }
+  
+  if err := u.customerRepo.AddNote(ctx, note); err != nil {
+    return nil, fmt.Errorf("failed to add customer note: %w", err)
+  }
+  
+  return note, nil
+}
+
+// FindDuplicates lists the other customers that look like the same person
+// as customer id.
+func (u *customerUsecase) FindDuplicates(ctx context.Context, id int) ([]entity.CustomerDuplicate, error) {
+  customer, err := u.GetByID(ctx, id)
+  if err != nil {
+    return nil, err
+  }
+  
+  return u.customerRepo.FindDuplicates(ctx, customer)
+}
+
+// Merge folds customer mergedID into survivorID, which keeps its code and
+// its own contact details; see CustomerRepository.Merge.
+func (u *customerUsecase) Merge(ctx context.Context, survivorID, mergedID, mergedBy int) (*entity.CustomerMerge, error) {
+  if survivorID == mergedID {
+    return nil, entity.NewFieldError("merge_customer_id", "must be another customer")
+  }
+  
+  for _, id := range []int{survivorID, mergedID} {
+    if _, err := u.GetByID(ctx, id); err != nil {
+      return nil, err
+    }
+  }
+  
+  merge, err := u.customerRepo.Merge(ctx, survivorID, mergedID, mergedBy)
+  if err != nil {
+    return nil, fmt.Errorf("failed to merge customers: %w", err)
+  }
+  
+  return merge, nil
+}
+
+func (u *customerUsecase) ListMerges(ctx context.Context, id int) ([]entity.CustomerMerge, error) {
+  if _, err := u.GetByID(ctx, id); err != nil {
+    return nil, err
+  }
+  
+  return u.customerRepo.ListMerges(ctx, id)
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"vehicle-showroom/internal/config"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/repository"
+)
+
+type HealthUsecase interface {
+	Ready(ctx context.Context) *entity.ReadinessStatus
+	// StartDraining makes Ready fail from now on, so the reverse proxy stops
+	// routing new requests while in-flight ones finish.
+	StartDraining()
+}
+
+type healthUsecase struct {
+	healthRepo repository.HealthRepository
+	// Readiness probes run often; a database that takes longer than this to
+	// answer is treated as unavailable
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+func NewHealthUsecase(healthRepo repository.HealthRepository, timeoutConfig config.TimeoutConfig) HealthUsecase {
+	return &healthUsecase{
+		healthRepo: healthRepo,
+		timeout:    time.Duration(timeoutConfig.ReadinessSeconds) * time.Second,
+	}
+}
+
+func (u *healthUsecase) Ready(ctx context.Context) *entity.ReadinessStatus {
+	status := &entity.ReadinessStatus{
+		Status: entity.HealthStatusOK,
+		Checks: make(map[string]entity.HealthCheck),
+	}
+	fail := func(check string, err error) {
+		status.Status = entity.HealthStatusUnavailable
+		status.Checks[check] = entity.HealthCheck{Status: entity.HealthStatusUnavailable, Error: err.Error()}
+	}
+
+	if u.draining.Load() {
+		fail("server", fmt.Errorf("shutting down"))
+	} else {
+		status.Checks["server"] = entity.HealthCheck{Status: entity.HealthStatusOK}
+	}
+
+	if u.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, u.timeout)
+		defer cancel()
+	}
+
+	if err := u.healthRepo.Ping(ctx); err != nil {
+		fail("database", err)
+		// Without a database the schema cannot be checked either
+		return status
+	}
+	status.Checks["database"] = entity.HealthCheck{Status: entity.HealthStatusOK}
+
+	current, latest, err := u.healthRepo.SchemaVersions(ctx)
+	if err != nil {
+		fail("migrations", err)
+		return status
+	}
+	status.SchemaVersion, status.LatestSchemaVersion = current, latest
+
+	// A newer schema is fine during a rollback; an older one means this
+	// binary expects tables or columns that do not exist yet.
+	if current < latest {
+		fail("migrations", fmt.Errorf("schema version %d is behind expected version %d", current, latest))
+	} else {
+		status.Checks["migrations"] = entity.HealthCheck{Status: entity.HealthStatusOK}
+	}
+
+	return status
+}
+
+func (u *healthUsecase) StartDraining() {
+	u.draining.Store(true)
+}
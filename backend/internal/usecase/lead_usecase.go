@@ -0,0 +1,435 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/repository"
+)
+
+type LeadUsecase interface {
+	Create(ctx context.Context, req *entity.CreateLeadRequest, createdBy int) (*entity.Lead, error)
+	GetByID(ctx context.Context, id int) (*entity.Lead, error)
+	List(ctx context.Context, params entity.ListParams, filter entity.LeadFilter) (*entity.LeadListResponse, error)
+	Update(ctx context.Context, id int, req *entity.UpdateLeadRequest) (*entity.Lead, error)
+	UpdateStatus(ctx context.Context, id int, req *entity.UpdateLeadStatusRequest) (*entity.Lead, error)
+	Convert(ctx context.Context, id int, req *entity.ConvertLeadRequest, convertedBy int) (*entity.LeadConversion, error)
+	ScheduleTestDrive(ctx context.Context, leadID int, req *entity.ScheduleTestDriveRequest, createdBy int) (*entity.TestDrive, error)
+	UpdateTestDriveStatus(ctx context.Context, leadID, id int, req *entity.UpdateTestDriveStatusRequest) (*entity.TestDrive, error)
+	VehicleTestDrives(ctx context.Context, vehicleID int, from time.Time, to *time.Time) ([]entity.TestDrive, error)
+}
+
+type leadUsecase struct {
+	leadRepo     repository.LeadRepository
+	vehicleRepo  repository.VehicleRepository
+	customerRepo repository.CustomerRepository
+}
+
+func NewLeadUsecase(
+	leadRepo repository.LeadRepository,
+	vehicleRepo repository.VehicleRepository,
+	customerRepo repository.CustomerRepository,
+) LeadUsecase {
+	return &leadUsecase{
+		leadRepo:     leadRepo,
+		vehicleRepo:  vehicleRepo,
+		customerRepo: customerRepo,
+	}
+}
+
+func (u *leadUsecase) Create(ctx context.Context, req *entity.CreateLeadRequest, createdBy int) (*entity.Lead, error) {
+	vehicleIDs, err := u.vehicleIDs(ctx, req.VehicleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	leadNumber, err := u.leadRepo.GenerateLeadNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate lead number: %w", err)
+	}
+
+	lead := &entity.Lead{
+		LeadNumber: leadNumber,
+		Name:       strings.TrimSpace(req.Name),
+		Phone:      req.Phone,
+		Email:      req.Email,
+		Source:     req.Source,
+		Status:     entity.LeadNew,
+		AssignedTo: req.AssignedTo,
+		Notes:      req.Notes,
+		CreatedBy:  &createdBy,
+	}
+	if lead.AssignedTo == nil {
+		lead.AssignedTo = &createdBy
+	}
+
+	if err := u.leadRepo.Create(ctx, lead, vehicleIDs); err != nil {
+		return nil, fmt.Errorf("failed to create lead: %w", err)
+	}
+
+	return u.leadRepo.GetByID(ctx, lead.ID)
+}
+
+func (u *leadUsecase) GetByID(ctx context.Context, id int) (*entity.Lead, error) {
+	lead, err := u.leadRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lead: %w", err)
+	}
+	if lead == nil {
+		return nil, entity.NewNotFoundError("lead")
+	}
+	return lead, nil
+}
+
+func (u *leadUsecase) List(ctx context.Context, params entity.ListParams, filter entity.LeadFilter) (*entity.LeadListResponse, error) {
+	params.Normalize(10, 100)
+
+	leads, page, err := u.leadRepo.List(ctx, params, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list leads: %w", err)
+	}
+
+	return &entity.LeadListResponse{
+		Leads:      leads,
+		Total:      page.Total,
+		HasMore:    page.HasMore,
+		NextCursor: page.NextCursor,
+		Page:       params.ResponsePage(),
+		Limit:      params.Limit,
+	}, nil
+}
+
+func (u *leadUsecase) Update(ctx context.Context, id int, req *entity.UpdateLeadRequest) (*entity.Lead, error) {
+	lead, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicleIDs, err := u.vehicleIDs(ctx, req.VehicleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	lead.Name = strings.TrimSpace(req.Name)
+	lead.Phone = req.Phone
+	lead.Email = req.Email
+	lead.Source = req.Source
+	lead.AssignedTo = req.AssignedTo
+	lead.Notes = req.Notes
+
+	if err := u.leadRepo.Update(ctx, lead, vehicleIDs); err != nil {
+		return nil, fmt.Errorf("failed to update lead: %w", err)
+	}
+
+	return u.leadRepo.GetByID(ctx, id)
+}
+
+// UpdateStatus moves an open lead to any stage, backwards included: a
+// negotiation can go back to a second test drive. Won and lost leads stay
+// closed.
+func (u *leadUsecase) UpdateStatus(ctx context.Context, id int, req *entity.UpdateLeadStatusRequest) (*entity.Lead, error) {
+	lead, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if lead.Status == req.Status {
+		return lead, nil
+	}
+	if entity.LeadClosed(lead.Status) {
+		return nil, entity.NewInvalidTransitionError("lead is already %s", lead.Status)
+	}
+	if req.Status == entity.LeadLost && (req.LostReason == nil || strings.TrimSpace(*req.LostReason) == "") {
+		return nil, entity.NewFieldError("lost_reason", "is required when the lead is lost")
+	}
+
+	if err := u.leadRepo.UpdateStatus(ctx, id, req.Status, req.LostReason); err != nil {
+		return nil, fmt.Errorf("failed to update lead status: %w", err)
+	}
+
+	return u.leadRepo.GetByID(ctx, id)
+}
+
+// Convert makes a won lead a customer, or links it to an existing one, and
+// prefills the sale of the vehicle the lead wants. The sale itself is left
+// to the cashier, who still picks the payment method.
+func (u *leadUsecase) Convert(ctx context.Context, id int, req *entity.ConvertLeadRequest, convertedBy int) (*entity.LeadConversion, error) {
+	lead, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if lead.Status != entity.LeadWon {
+		return nil, entity.NewInvalidTransitionError("only won leads can be converted (status: %s)", lead.Status)
+	}
+	if lead.CustomerID != nil {
+		return nil, entity.NewConflictError("lead is already converted")
+	}
+
+	vehicle, err := u.saleVehicle(ctx, lead, req.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	conversion := &entity.LeadConversion{}
+	if req.CustomerID != nil {
+		customer, err := u.customerRepo.GetByID(ctx, *req.CustomerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get customer: %w", err)
+		}
+		if customer == nil {
+			return nil, entity.NewNotFoundError("customer")
+		}
+		if err := u.leadRepo.MarkConverted(ctx, id, customer.ID); err != nil {
+			return nil, err
+		}
+		conversion.Customer = customer
+	} else {
+		customer, duplicates, err := u.newCustomer(ctx, lead, req, convertedBy)
+		if err != nil {
+			return nil, err
+		}
+		// The customer is only created if the lead can still be claimed
+		if err := u.leadRepo.ConvertToNewCustomer(ctx, id, customer); err != nil {
+			return nil, err
+		}
+		conversion.Customer = customer
+		conversion.PossibleDuplicates = duplicates
+	}
+
+	if conversion.Lead, err = u.leadRepo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get lead: %w", err)
+	}
+
+	if vehicle != nil && forSaleTo(vehicle, conversion.Customer.ID) {
+		notes := "Lead " + lead.LeadNumber
+		conversion.SalesRequest = &entity.CreateSalesTransactionRequest{
+			VehicleID:  vehicle.ID,
+			CustomerID: conversion.Customer.ID,
+			Notes:      &notes,
+		}
+		if vehicle.ApprovedSellingPrice != nil {
+			conversion.SalesRequest.VehiclePrice = *vehicle.ApprovedSellingPrice
+		} else if vehicle.SuggestedSellingPrice != nil {
+			conversion.SalesRequest.VehiclePrice = *vehicle.SuggestedSellingPrice
+		}
+	}
+
+	return conversion, nil
+}
+
+// ScheduleTestDrive books the vehicle for the lead, adding it to the lead's
+// vehicles of interest, and moves a new or contacted lead to test_drive.
+func (u *leadUsecase) ScheduleTestDrive(ctx context.Context, leadID int, req *entity.ScheduleTestDriveRequest, createdBy int) (*entity.TestDrive, error) {
+	lead, err := u.GetByID(ctx, leadID)
+	if err != nil {
+		return nil, err
+	}
+	if entity.LeadClosed(lead.Status) {
+		return nil, entity.NewInvalidTransitionError("lead is already %s", lead.Status)
+	}
+
+	startsAt, endsAt := localTime(req.StartsAt), localTime(req.EndsAt)
+	if !startsAt.After(time.Now()) {
+		return nil, entity.NewFieldError("starts_at", "must be in the future")
+	}
+	if endsAt.Sub(startsAt) > entity.MaxTestDriveDuration {
+		return nil, entity.NewFieldError("ends_at", fmt.Sprintf("must be at most %s after starts_at", entity.MaxTestDriveDuration))
+	}
+
+	vehicle, err := u.vehicleRepo.GetByID(ctx, req.VehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vehicle: %w", err)
+	}
+	if vehicle == nil {
+		return nil, entity.NewNotFoundError("vehicle")
+	}
+	if vehicle.Status == "sold" {
+		return nil, entity.NewInvalidTransitionError("vehicle is already sold")
+	}
+
+	drive := &entity.TestDrive{
+		LeadID:    leadID,
+		VehicleID: req.VehicleID,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		Status:    entity.TestDriveScheduled,
+		Notes:     req.Notes,
+		CreatedBy: &createdBy,
+	}
+	conflict, err := u.leadRepo.ScheduleTestDrive(ctx, drive)
+	if err != nil {
+		return nil, err
+	}
+	if conflict != nil {
+		return nil, entity.NewTestDriveConflictError(conflict)
+	}
+
+	if !hasVehicle(lead, req.VehicleID) {
+		vehicleIDs := []int{req.VehicleID}
+		for _, v := range lead.Vehicles {
+			vehicleIDs = append(vehicleIDs, v.ID)
+		}
+		if err := u.leadRepo.Update(ctx, lead, vehicleIDs); err != nil {
+			return nil, fmt.Errorf("failed to link test drive vehicle: %w", err)
+		}
+	}
+	if lead.Status == entity.LeadNew || lead.Status == entity.LeadContacted {
+		if err := u.leadRepo.UpdateStatus(ctx, leadID, entity.LeadTestDrive, nil); err != nil {
+			return nil, fmt.Errorf("failed to update lead status: %w", err)
+		}
+	}
+
+	return drive, nil
+}
+
+// UpdateTestDriveStatus records how a scheduled test drive ended.
+func (u *leadUsecase) UpdateTestDriveStatus(ctx context.Context, leadID, id int, req *entity.UpdateTestDriveStatusRequest) (*entity.TestDrive, error) {
+	drive, err := u.leadRepo.GetTestDrive(ctx, leadID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test drive: %w", err)
+	}
+	if drive == nil {
+		return nil, entity.NewNotFoundError("test drive")
+	}
+
+	if drive.Status == req.Status {
+		return drive, nil
+	}
+	if drive.Status != entity.TestDriveScheduled {
+		return nil, entity.NewInvalidTransitionError("test drive is already %s", drive.Status)
+	}
+
+	if err := u.leadRepo.UpdateTestDriveStatus(ctx, id, req.Status, req.Notes); err != nil {
+		return nil, fmt.Errorf("failed to update test drive status: %w", err)
+	}
+
+	return u.leadRepo.GetTestDrive(ctx, leadID, id)
+}
+
+// VehicleTestDrives is the vehicle's test drive calendar.
+func (u *leadUsecase) VehicleTestDrives(ctx context.Context, vehicleID int, from time.Time, to *time.Time) ([]entity.TestDrive, error) {
+	vehicle, err := u.vehicleRepo.GetByID(ctx, vehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vehicle: %w", err)
+	}
+	if vehicle == nil {
+		return nil, entity.NewNotFoundError("vehicle")
+	}
+
+	return u.leadRepo.ListTestDrivesByVehicle(ctx, vehicleID, from, to)
+}
+
+// vehicleIDs checks that the vehicles of interest exist and drops repeats.
+func (u *leadUsecase) vehicleIDs(ctx context.Context, ids []int) ([]int, error) {
+	distinct := make([]int, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		vehicle, err := u.vehicleRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get vehicle: %w", err)
+		}
+		if vehicle == nil {
+			return nil, entity.NewFieldError("vehicle_ids", fmt.Sprintf("vehicle %d does not exist", id))
+		}
+		distinct = append(distinct, id)
+	}
+	return distinct, nil
+}
+
+// newCustomer builds the customer a lead converts into, with the existing
+// customers that may be the same person. It is not stored yet.
+func (u *leadUsecase) newCustomer(ctx context.Context, lead *entity.Lead, req *entity.ConvertLeadRequest, createdBy int) (*entity.Customer, []entity.CustomerDuplicate, error) {
+	customerCode, err := u.customerRepo.GenerateCustomerCode(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate customer code: %w", err)
+	}
+
+	customer := &entity.Customer{
+		CustomerCode: customerCode,
+		Name:         lead.Name,
+		Phone:        lead.Phone,
+		Email:        lead.Email,
+		Address:      req.Address,
+		IDCardNumber: req.IDCardNumber,
+		Type:         req.Type,
+		CreatedBy:    &createdBy,
+		IsActive:     true,
+	}
+	if customer.Type == "" {
+		customer.Type = "individual"
+	}
+
+	duplicates, err := u.customerRepo.FindDuplicates(ctx, customer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return customer, duplicates, nil
+}
+
+// saleVehicle is the vehicle to prefill the sale with: the requested one,
+// or the lead's only vehicle of interest. It is nil when the lead has
+// several and none was picked.
+func (u *leadUsecase) saleVehicle(ctx context.Context, lead *entity.Lead, vehicleID *int) (*entity.Vehicle, error) {
+	id := 0
+	switch {
+	case vehicleID != nil:
+		id = *vehicleID
+	case len(lead.Vehicles) == 1:
+		id = lead.Vehicles[0].ID
+	default:
+		return nil, nil
+	}
+
+	vehicle, err := u.vehicleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vehicle: %w", err)
+	}
+	if vehicle == nil {
+		return nil, entity.NewNotFoundError("vehicle")
+	}
+	return vehicle, nil
+}
+
+// forSaleTo reports whether the vehicle can be sold to the customer: ready
+// to sell, or reserved for them or for nobody in particular.
+func forSaleTo(vehicle *entity.Vehicle, customerID int) bool {
+	switch vehicle.Status {
+	case "ready_to_sell":
+		return true
+	case "reserved":
+		return vehicle.ReservedForCustomerID == nil || *vehicle.ReservedForCustomerID == customerID
+	}
+	return false
+}
+
+// localTime moves a client-supplied time into the server's zone. TIMESTAMP
+// columns keep only the wall clock, dropping any offset, so everything
+// stored has to be local like the time.Now() values written next to it.
+func localTime(t time.Time) time.Time {
+	return t.In(time.Local)
+}
+
+func localTimePtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	local := localTime(*t)
+	return &local
+}
+
+func hasVehicle(lead *entity.Lead, vehicleID int) bool {
+	for _, v := range lead.Vehicles {
+		if v.ID == vehicleID {
+			return true
+		}
+	}
+	return false
+}
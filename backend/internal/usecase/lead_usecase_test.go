@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+)
+
+// The same instant sent with different offsets must be stored as the same
+// wall clock, or the overlap check in TIMESTAMP columns misses it.
+func TestLocalTime(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	fromJakarta := localTime(time.Date(2024, 5, 1, 10, 0, 0, 0, jakarta))
+	fromUTC := localTime(time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC))
+
+	const wallClock = "2006-01-02 15:04:05"
+	if fromJakarta.Format(wallClock) != fromUTC.Format(wallClock) {
+		t.Errorf("wall clocks differ: %s and %s", fromJakarta.Format(wallClock), fromUTC.Format(wallClock))
+	}
+	if fromJakarta.Location() != time.Local {
+		t.Errorf("location = %s, want Local", fromJakarta.Location())
+	}
+}
+
+func TestLocalTimePtr(t *testing.T) {
+	if localTimePtr(nil) != nil {
+		t.Error("nil time should stay nil")
+	}
+	remindAt := time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC)
+	got := localTimePtr(&remindAt)
+	if !got.Equal(remindAt) || got.Location() != time.Local {
+		t.Errorf("localTimePtr = %s, want %s in Local", got, remindAt)
+	}
+}
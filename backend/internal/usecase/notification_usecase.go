@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/events"
+	"vehicle-showroom/internal/repository"
+)
+
+// NotificationUsecase stores in-app notifications and pushes them, along
+// with domain events, to connected clients.
+//
+// Notify, NotifyPermission and Broadcast are called after the action they
+// report has been committed, so they log failures instead of returning
+// them: a lost notification must not fail a sale.
+type NotificationUsecase interface {
+	Notify(ctx context.Context, userIDs []int, n entity.NewNotification)
+	NotifyPermission(ctx context.Context, permission string, n entity.NewNotification)
+	Broadcast(ctx context.Context, eventType, permission string, data interface{})
+	List(ctx context.Context, userID int, params entity.ListParams, unreadOnly bool) (*entity.NotificationListResponse, error)
+	MarkRead(ctx context.Context, userID, id int) (*entity.Notification, error)
+	MarkAllRead(ctx context.Context, userID int) (*entity.MarkNotificationsReadResponse, error)
+	Subscribe(user *entity.User) *events.Subscription
+}
+
+type notificationUsecase struct {
+	notificationRepo repository.NotificationRepository
+	broker           events.Broker
+}
+
+func NewNotificationUsecase(notificationRepo repository.NotificationRepository, broker events.Broker) NotificationUsecase {
+	return &notificationUsecase{
+		notificationRepo: notificationRepo,
+		broker:           broker,
+	}
+}
+
+func (u *notificationUsecase) Notify(ctx context.Context, userIDs []int, n entity.NewNotification) {
+	notifications, err := u.notificationRepo.CreateForUsers(ctx, userIDs, n)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create notifications",
+			slog.String("type", n.Type),
+			slog.Any("user_ids", userIDs),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	for _, notification := range notifications {
+		u.publish(ctx, entity.EventNotification, notification.UserID, "", notification)
+	}
+}
+
+// NotifyPermission notifies every active user whose role holds permission.
+func (u *notificationUsecase) NotifyPermission(ctx context.Context, permission string, n entity.NewNotification) {
+	userIDs, err := u.notificationRepo.UserIDsWithPermission(ctx, permission)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find users to notify",
+			slog.String("type", n.Type),
+			slog.String("permission", permission),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	u.Notify(ctx, userIDs, n)
+}
+
+// Broadcast pushes a domain event to the connected users whose role holds
+// permission. It is not stored.
+func (u *notificationUsecase) Broadcast(ctx context.Context, eventType, permission string, data interface{}) {
+	u.publish(ctx, eventType, 0, permission, data)
+}
+
+func (u *notificationUsecase) publish(ctx context.Context, eventType string, userID int, permission string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err == nil {
+		err = u.broker.Publish(ctx, entity.Event{Type: eventType, UserID: userID, Permission: permission, Data: payload})
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to publish event",
+			slog.String("event", eventType),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func (u *notificationUsecase) List(ctx context.Context, userID int, params entity.ListParams, unreadOnly bool) (*entity.NotificationListResponse, error) {
+	params.Normalize(20, 100)
+
+	notifications, page, err := u.notificationRepo.List(ctx, userID, params, unreadOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	unread, err := u.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.NotificationListResponse{
+		Notifications: notifications,
+		UnreadCount:   unread,
+		Total:         page.Total,
+		HasMore:       page.HasMore,
+		NextCursor:    page.NextCursor,
+		Page:          params.ResponsePage(),
+		Limit:         params.Limit,
+	}, nil
+}
+
+func (u *notificationUsecase) MarkRead(ctx context.Context, userID, id int) (*entity.Notification, error) {
+	notification, err := u.notificationRepo.MarkRead(ctx, userID, id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if notification == nil {
+		return nil, entity.NewNotFoundError("notification")
+	}
+	return notification, nil
+}
+
+func (u *notificationUsecase) MarkAllRead(ctx context.Context, userID int) (*entity.MarkNotificationsReadResponse, error) {
+	marked, err := u.notificationRepo.MarkAllRead(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &entity.MarkNotificationsReadResponse{Marked: marked}, nil
+}
+
+func (u *notificationUsecase) Subscribe(user *entity.User) *events.Subscription {
+	return u.broker.Subscribe(user)
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"vehicle-showroom/internal/config"
+	"vehicle-showroom/internal/entity"
+)
+
+// validatePassword checks a candidate password against the configured policy
+// and returns every violated rule in one error.
+func validatePassword(policy config.PasswordConfig, password, username string) error {
+	var problems []string
+
+	if len([]rune(password)) < policy.MinLength {
+		problems = append(problems, fmt.Sprintf("be at least %d characters long", policy.MinLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if policy.RequireUppercase && !hasUpper {
+		problems = append(problems, "contain an uppercase letter")
+	}
+	if policy.RequireLowercase && !hasLower {
+		problems = append(problems, "contain a lowercase letter")
+	}
+	if policy.RequireDigit && !hasDigit {
+		problems = append(problems, "contain a digit")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		problems = append(problems, "contain a symbol")
+	}
+	if username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		problems = append(problems, "not contain the username")
+	}
+
+	if len(problems) > 0 {
+		message := "must " + strings.Join(problems, ", ")
+		return entity.NewValidationError("password "+message, entity.FieldError{Field: "password", Message: message})
+	}
+
+	return nil
+}
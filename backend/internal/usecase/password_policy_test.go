@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"vehicle-showroom/internal/config"
+	"vehicle-showroom/internal/entity"
+)
+
+func TestValidatePassword(t *testing.T) {
+	policy := config.PasswordConfig{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		username string
+		want     string // error message; empty when the password is accepted
+	}{
+		{"meets every rule", "Sh0wroom!", "budi", ""},
+		{"too short", "Sh0w!", "budi", "password must be at least 8 characters long"},
+		{"length counts runes", "Ünïcödé1!", "budi", ""},
+		{"no uppercase", "sh0wroom!", "budi", "password must contain an uppercase letter"},
+		{"no lowercase", "SH0WROOM!", "budi", "password must contain a lowercase letter"},
+		{"no digit", "Showroom!", "budi", "password must contain a digit"},
+		{"no symbol", "Sh0wroom1", "budi", "password must contain a symbol"},
+		{"contains username in another case", "xBUDIx1!a", "budi", "password must not contain the username"},
+		{"no username to compare", "Sh0wroom!", "", ""},
+		{"lists every problem", "abc", "budi", "password must be at least 8 characters long, contain an uppercase letter, contain a digit, contain a symbol"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePassword(policy, tt.password, tt.username)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("validatePassword(%q) = %v, want nil", tt.password, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("validatePassword(%q) = nil, want %q", tt.password, tt.want)
+			}
+			if !errors.Is(err, entity.ErrValidation) {
+				t.Errorf("validatePassword(%q) = %v, want a validation error", tt.password, err)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("validatePassword(%q) = %q, want %q", tt.password, err.Error(), tt.want)
+			}
+		})
+	}
+
+	t.Run("rules can be turned off", func(t *testing.T) {
+		if err := validatePassword(config.PasswordConfig{MinLength: 4}, "abcd", "budi"); err != nil {
+			t.Errorf("validatePassword = %v, want nil", err)
+		}
+	})
+}
@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"vehicle-showroom/internal/config"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/notifier"
+	"vehicle-showroom/internal/repository"
+)
+
+type PasswordUsecase interface {
+	ChangePassword(ctx context.Context, user *entity.User, currentSessionID int, req *entity.ChangePasswordRequest) error
+	RequestReset(ctx context.Context, email, ipAddress string) error
+	ResetPassword(ctx context.Context, req *entity.ResetPasswordRequest) error
+	SetPassword(ctx context.Context, username, newPassword string) error
+}
+
+type passwordUsecase struct {
+	userRepo          repository.UserRepository
+	sessionRepo       repository.SessionRepository
+	passwordResetRepo repository.PasswordResetRepository
+	notifier          notifier.Notifier
+	passwordConfig    config.PasswordConfig
+}
+
+func NewPasswordUsecase(
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	passwordResetRepo repository.PasswordResetRepository,
+	notifier notifier.Notifier,
+	passwordConfig config.PasswordConfig,
+) PasswordUsecase {
+	return &passwordUsecase{
+		userRepo:          userRepo,
+		sessionRepo:       sessionRepo,
+		passwordResetRepo: passwordResetRepo,
+		notifier:          notifier,
+		passwordConfig:    passwordConfig,
+	}
+}
+
+// ChangePassword sets a new password and signs the user out everywhere except
+// the session that made the change.
+func (u *passwordUsecase) ChangePassword(ctx context.Context, user *entity.User, currentSessionID int, req *entity.ChangePasswordRequest) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)); err != nil {
+		return entity.NewFieldError("current_password", "is incorrect")
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		return entity.NewFieldError("new_password", "must be different from the current password")
+	}
+
+	if err := u.setPassword(ctx, user, req.NewPassword); err != nil {
+		return err
+	}
+
+	if err := u.sessionRepo.RevokeOthersByUserID(ctx, user.ID, currentSessionID, "password_changed"); err != nil {
+		return fmt.Errorf("failed to revoke other sessions: %w", err)
+	}
+
+	return nil
+}
+
+// ResetThrottledError is returned when a forgot-password request goes over
+// the per-email or per-IP limit.
+type ResetThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ResetThrottledError) Error() string {
+	return fmt.Sprintf("too many password reset requests, try again in %d seconds", int(e.RetryAfter.Seconds())+1)
+}
+
+func (e *ResetThrottledError) Unwrap() error {
+	return entity.ErrTooManyRequests
+}
+
+// RequestReset records the request and, within the per-IP and per-email
+// limits, issues a single-use reset token. The account lookup and the message
+// happen in the background, so the answer is the same, and as fast, for
+// unknown emails and the endpoint cannot be used to discover accounts.
+func (u *passwordUsecase) RequestReset(ctx context.Context, email, ipAddress string) error {
+	now := time.Now()
+	if err := u.checkResetThrottle(ctx, email, ipAddress, now); err != nil {
+		return err
+	}
+
+	if err := u.passwordResetRepo.RecordRequest(ctx, email, ipAddress, now); err != nil {
+		return err
+	}
+
+	go u.sendReset(context.WithoutCancel(ctx), email, ipAddress)
+	return nil
+}
+
+// checkResetThrottle counts requests whether or not the email has an
+// account. Refused requests are not recorded, so retrying does not extend
+// the wait.
+func (u *passwordUsecase) checkResetThrottle(ctx context.Context, email, ipAddress string, now time.Time) error {
+	window := time.Minute * time.Duration(u.passwordConfig.ResetWindowMinutes)
+	since := now.Add(-window)
+
+	ipStats, err := u.passwordResetRepo.GetRequestStatsByIP(ctx, ipAddress, since)
+	if err != nil {
+		return err
+	}
+	if u.passwordConfig.ResetMaxPerIP > 0 && ipStats.Count >= u.passwordConfig.ResetMaxPerIP {
+		return &ResetThrottledError{RetryAfter: ipStats.LastRequestAt.Add(window).Sub(now)}
+	}
+
+	emailStats, err := u.passwordResetRepo.GetRequestStatsByEmail(ctx, email, since)
+	if err != nil {
+		return err
+	}
+	if u.passwordConfig.ResetMaxPerEmail > 0 && emailStats.Count >= u.passwordConfig.ResetMaxPerEmail {
+		return &ResetThrottledError{RetryAfter: emailStats.LastRequestAt.Add(window).Sub(now)}
+	}
+
+	return nil
+}
+
+// sendReset issues the token and sends the link when email belongs to an
+// account. It runs after the response, so failures are only logged.
+func (u *passwordUsecase) sendReset(ctx context.Context, email, ipAddress string) {
+	if err := u.issueReset(ctx, email, ipAddress); err != nil {
+		slog.ErrorContext(ctx, "failed to issue password reset",
+			slog.String("ip", ipAddress),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func (u *passwordUsecase) issueReset(ctx context.Context, email, ipAddress string) error {
+	user, err := u.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil
+	}
+
+	// Only the newest link should work
+	if err := u.passwordResetRepo.InvalidateByUserID(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to invalidate previous reset tokens: %w", err)
+	}
+
+	token, err := generateRandomToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+
+	expiresIn := time.Minute * time.Duration(u.passwordConfig.ResetTokenMinutes)
+	resetToken := &entity.PasswordResetToken{
+		UserID:      user.ID,
+		TokenHash:   hashToken(token),
+		ExpiresAt:   time.Now().Add(expiresIn),
+		RequestedIP: &ipAddress,
+	}
+
+	if err := u.passwordResetRepo.Create(ctx, resetToken); err != nil {
+		return fmt.Errorf("failed to create reset token: %w", err)
+	}
+
+	msg := notifier.Message{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to reset your password. It expires in %d minutes and can only be used once.\n\n%s?token=%s\n\nIf you did not request this, you can ignore this message.",
+			user.FullName, u.passwordConfig.ResetTokenMinutes, u.passwordConfig.ResetURL, token,
+		),
+	}
+
+	if err := u.notifier.Send(msg); err != nil {
+		return fmt.Errorf("failed to send reset message: %w", err)
+	}
+
+	return nil
+}
+
+func (u *passwordUsecase) ResetPassword(ctx context.Context, req *entity.ResetPasswordRequest) error {
+	resetToken, err := u.passwordResetRepo.GetByHash(ctx, hashToken(req.Token))
+	if err != nil {
+		return fmt.Errorf("failed to get reset token: %w", err)
+	}
+
+	if resetToken == nil || resetToken.UsedAt != nil || resetToken.ExpiresAt.Before(time.Now()) {
+		return entity.NewValidationError("reset token is invalid or expired")
+	}
+
+	user, err := u.userRepo.GetByID(ctx, resetToken.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return entity.NewValidationError("reset token is invalid or expired")
+	}
+
+	// Validate before consuming so a weak password does not burn the token
+	hashedPassword, err := u.hashPassword(user, req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	consumed, err := u.passwordResetRepo.Redeem(ctx, resetToken.ID, user.ID, hashedPassword)
+	if err != nil {
+		return fmt.Errorf("failed to consume reset token: %w", err)
+	}
+	if !consumed {
+		return entity.NewValidationError("reset token is invalid or expired")
+	}
+
+	if err := u.sessionRepo.RevokeByUserID(ctx, user.ID, "password_reset"); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+// SetPassword is the operator path for a user who cannot use the reset link.
+// Like a reset, it clears the lockout and signs the user out everywhere.
+func (u *passwordUsecase) SetPassword(ctx context.Context, username, newPassword string) error {
+	user, err := u.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return entity.NewNotFoundError("user")
+	}
+
+	if err := u.setPassword(ctx, user, newPassword); err != nil {
+		return err
+	}
+
+	if err := u.passwordResetRepo.InvalidateByUserID(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to invalidate reset tokens: %w", err)
+	}
+
+	if err := u.userRepo.ResetFailedLogins(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to reset failed logins: %w", err)
+	}
+
+	if err := u.sessionRepo.RevokeByUserID(ctx, user.ID, "password_set_by_operator"); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+func (u *passwordUsecase) setPassword(ctx context.Context, user *entity.User, password string) error {
+	hashedPassword, err := u.hashPassword(user, password)
+	if err != nil {
+		return err
+	}
+
+	if err := u.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	return nil
+}
+
+// hashPassword checks the password against the policy and hashes it.
+func (u *passwordUsecase) hashPassword(user *entity.User, password string) (string, error) {
+	if err := validatePassword(u.passwordConfig, password, user.Username); err != nil {
+		return "", err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return string(hashedPassword), nil
+}
@@ -1,52 +1,57 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 
 	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/metrics"
 	"vehicle-showroom/internal/repository"
 )
 
 type RepairUsecase interface {
-	Create(req *entity.CreateRepairRequest, createdBy int) (*entity.Repair, error)
-	GetByID(id int) (*entity.Repair, error)
-	List(page, limit int, search, status string) (*entity.RepairListResponse, error)
-	Update(id int, req *entity.UpdateRepairRequest) (*entity.Repair, error)
-	UpdateStatus(id int, status string) (*entity.Repair, error)
-	AddPart(repairId int, req *entity.AddPartToRepairRequest, processedBy int) (*entity.RepairPart, error)
-	RemovePart(repairId, partId int) error
+	Create(ctx context.Context, req *entity.CreateRepairRequest, createdBy int) (*entity.Repair, error)
+	GetByID(ctx context.Context, id int) (*entity.Repair, error)
+	List(ctx context.Context, params entity.ListParams, search, status string) (*entity.RepairListResponse, error)
+	Update(ctx context.Context, id int, req *entity.UpdateRepairRequest) (*entity.Repair, error)
+	UpdateStatus(ctx context.Context, id int, status string) (*entity.Repair, error)
+	AddPart(ctx context.Context, repairId int, req *entity.AddPartToRepairRequest, processedBy int) (*entity.RepairPart, error)
+	RemovePart(ctx context.Context, repairId, partId int) error
 }
 
 type repairUsecase struct {
 	repairRepo    repository.RepairRepository
 	vehicleRepo   repository.VehicleRepository
 	sparePartRepo repository.SparePartRepository
+	notifications NotificationUsecase
 }
 
 func NewRepairUsecase(
 	repairRepo repository.RepairRepository,
 	vehicleRepo repository.VehicleRepository,
 	sparePartRepo repository.SparePartRepository,
+	notifications NotificationUsecase,
 ) RepairUsecase {
 	return &repairUsecase{
 		repairRepo:    repairRepo,
 		vehicleRepo:   vehicleRepo,
 		sparePartRepo: sparePartRepo,
+		notifications: notifications,
 	}
 }
 
-func (u *repairUsecase) Create(req *entity.CreateRepairRequest, createdBy int) (*entity.Repair, error) {
+func (u *repairUsecase) Create(ctx context.Context, req *entity.CreateRepairRequest, createdBy int) (*entity.Repair, error) {
 	// Validate vehicle exists
-	vehicle, err := u.vehicleRepo.GetByID(req.VehicleID)
+	vehicle, err := u.vehicleRepo.GetByID(ctx, req.VehicleID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get vehicle: %w", err)
 	}
 	if vehicle == nil {
-		return nil, fmt.Errorf("vehicle not found")
+		return nil, entity.NewNotFoundError("vehicle")
 	}
 
 	// Generate repair number
-	repairNumber, err := u.repairRepo.GenerateRepairNumber()
+	repairNumber, err := u.repairRepo.GenerateRepairNumber(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate repair number: %w", err)
 	}
@@ -63,63 +68,66 @@ func (u *repairUsecase) Create(req *entity.CreateRepairRequest, createdBy int) (
 		MechanicID:     req.MechanicID,
 	}
 
-	if err := u.repairRepo.Create(repair); err != nil {
+	if err := u.repairRepo.Create(ctx, repair); err != nil {
 		return nil, fmt.Errorf("failed to create repair: %w", err)
 	}
 
 	// Update vehicle status to in_repair
-	if err := u.vehicleRepo.UpdateStatus(req.VehicleID, "in_repair"); err != nil {
+	if err := u.vehicleRepo.UpdateStatus(ctx, req.VehicleID, "in_repair"); err != nil {
 		return nil, fmt.Errorf("failed to update vehicle status: %w", err)
 	}
 
+	if repair.MechanicID != nil && *repair.MechanicID != createdBy {
+		u.notifyAssigned(ctx, repair, vehicle)
+	}
+
 	// Get the created repair with related data
-	return u.repairRepo.GetByID(repair.ID)
+	return u.repairRepo.GetByID(ctx, repair.ID)
 }
 
-func (u *repairUsecase) GetByID(id int) (*entity.Repair, error) {
-	repair, err := u.repairRepo.GetByID(id)
+func (u *repairUsecase) GetByID(ctx context.Context, id int) (*entity.Repair, error) {
+	repair, err := u.repairRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get repair: %w", err)
 	}
 
 	if repair == nil {
-		return nil, fmt.Errorf("repair not found")
+		return nil, entity.NewNotFoundError("repair")
 	}
 
 	return repair, nil
 }
 
-func (u *repairUsecase) List(page, limit int, search, status string) (*entity.RepairListResponse, error) {
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 || limit > 100 {
-		limit = 10
-	}
+func (u *repairUsecase) List(ctx context.Context, params entity.ListParams, search, status string) (*entity.RepairListResponse, error) {
+	params.Normalize(10, 100)
 
-	repairs, total, err := u.repairRepo.List(page, limit, search, status)
+	repairs, page, err := u.repairRepo.List(ctx, params, search, status)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list repairs: %w", err)
 	}
 
 	return &entity.RepairListResponse{
-		Repairs: repairs,
-		Total:   total,
-		Page:    page,
-		Limit:   limit,
+		Repairs:    repairs,
+		Total:      page.Total,
+		HasMore:    page.HasMore,
+		NextCursor: page.NextCursor,
+		Page:       params.ResponsePage(),
+		Limit:      params.Limit,
 	}, nil
 }
 
-func (u *repairUsecase) Update(id int, req *entity.UpdateRepairRequest) (*entity.Repair, error) {
-	repair, err := u.repairRepo.GetByID(id)
+func (u *repairUsecase) Update(ctx context.Context, id int, req *entity.UpdateRepairRequest) (*entity.Repair, error) {
+	repair, err := u.repairRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get repair: %w", err)
 	}
 
 	if repair == nil {
-		return nil, fmt.Errorf("repair not found")
+		return nil, entity.NewNotFoundError("repair")
 	}
 
+	reassigned := req.MechanicID != nil && (repair.MechanicID == nil || *repair.MechanicID != *req.MechanicID)
+
 	repair.Title = req.Title
 	repair.Description = req.Description
 	if req.LaborCost != nil {
@@ -128,73 +136,102 @@ func (u *repairUsecase) Update(id int, req *entity.UpdateRepairRequest) (*entity
 	repair.MechanicID = req.MechanicID
 	repair.WorkNotes = req.WorkNotes
 
-	if err := u.repairRepo.Update(repair); err != nil {
+	if err := u.repairRepo.Update(ctx, repair); err != nil {
 		return nil, fmt.Errorf("failed to update repair: %w", err)
 	}
 
 	// Update repair costs
-	if err := u.repairRepo.UpdateRepairCosts(id); err != nil {
+	if err := u.repairRepo.UpdateRepairCosts(ctx, id); err != nil {
 		return nil, fmt.Errorf("failed to update repair costs: %w", err)
 	}
 
-	return u.repairRepo.GetByID(id)
+	if reassigned {
+		u.notifyAssigned(ctx, repair, repair.Vehicle)
+	}
+
+	return u.repairRepo.GetByID(ctx, id)
 }
 
-func (u *repairUsecase) UpdateStatus(id int, status string) (*entity.Repair, error) {
-	repair, err := u.repairRepo.GetByID(id)
+func (u *repairUsecase) UpdateStatus(ctx context.Context, id int, status string) (*entity.Repair, error) {
+	repair, err := u.repairRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get repair: %w", err)
 	}
 
 	if repair == nil {
-		return nil, fmt.Errorf("repair not found")
+		return nil, entity.NewNotFoundError("repair")
+	}
+
+	if repair.Status == status {
+		return repair, nil
 	}
 
-	if err := u.repairRepo.UpdateStatus(id, status); err != nil {
+	// Completing a repair adds its cost to the vehicle, so finished repairs
+	// cannot be reopened
+	if repair.Status == "completed" || repair.Status == "cancelled" {
+		return nil, entity.NewInvalidTransitionError("repair is already %s", repair.Status)
+	}
+
+	if err := u.repairRepo.UpdateStatus(ctx, id, status); err != nil {
 		return nil, fmt.Errorf("failed to update repair status: %w", err)
 	}
 
 	// If repair is completed, update vehicle status and total repair cost
 	if status == "completed" {
+		metrics.RepairsCompleted.Inc()
+
 		// Update vehicle's total repair cost
-		vehicle, err := u.vehicleRepo.GetByID(repair.VehicleID)
+		vehicle, err := u.vehicleRepo.GetByID(ctx, repair.VehicleID)
 		if err == nil && vehicle != nil {
 			// Get updated repair with costs
-			updatedRepair, err := u.repairRepo.GetByID(id)
+			updatedRepair, err := u.repairRepo.GetByID(ctx, id)
 			if err == nil {
 				vehicle.TotalRepairCost += updatedRepair.TotalCost
-				u.vehicleRepo.Update(vehicle)
+				u.vehicleRepo.Update(ctx, vehicle)
 			}
 		}
 
 		// Update vehicle status to ready_to_sell
-		u.vehicleRepo.UpdateStatus(repair.VehicleID, "ready_to_sell")
+		u.vehicleRepo.UpdateStatus(ctx, repair.VehicleID, "ready_to_sell")
 	}
 
-	return u.repairRepo.GetByID(id)
+	updated, err := u.repairRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if status == "completed" && updated != nil {
+		u.notifications.Broadcast(ctx, entity.EventRepairCompleted, entity.PermRepairView, entity.RepairCompletedEvent{
+			RepairID:     updated.ID,
+			RepairNumber: updated.RepairNumber,
+			VehicleID:    updated.VehicleID,
+			MechanicID:   updated.MechanicID,
+			TotalCost:    updated.TotalCost,
+		})
+	}
+	return updated, nil
 }
 
-func (u *repairUsecase) AddPart(repairId int, req *entity.AddPartToRepairRequest, processedBy int) (*entity.RepairPart, error) {
+func (u *repairUsecase) AddPart(ctx context.Context, repairId int, req *entity.AddPartToRepairRequest, processedBy int) (*entity.RepairPart, error) {
 	// Validate repair exists
-	repair, err := u.repairRepo.GetByID(repairId)
+	repair, err := u.repairRepo.GetByID(ctx, repairId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get repair: %w", err)
 	}
 	if repair == nil {
-		return nil, fmt.Errorf("repair not found")
+		return nil, entity.NewNotFoundError("repair")
 	}
 
 	// Validate spare part exists and has sufficient stock
-	sparePart, err := u.sparePartRepo.GetByID(req.SparePartID)
+	sparePart, err := u.sparePartRepo.GetByID(ctx, req.SparePartID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get spare part: %w", err)
 	}
 	if sparePart == nil {
-		return nil, fmt.Errorf("spare part not found")
+		return nil, entity.NewNotFoundError("spare part")
 	}
 
 	if sparePart.StockQuantity < req.Quantity {
-		return nil, fmt.Errorf("insufficient stock: available %d, requested %d", sparePart.StockQuantity, req.Quantity)
+		return nil, entity.NewInsufficientStockError(sparePart.ID, sparePart.StockQuantity, req.Quantity)
 	}
 
 	// Calculate costs
@@ -209,18 +246,31 @@ func (u *repairUsecase) AddPart(repairId int, req *entity.AddPartToRepairRequest
 		TotalCost:    totalCost,
 	}
 
-	if err := u.repairRepo.AddPart(repairPart); err != nil {
+	if err := u.repairRepo.AddPart(ctx, repairPart); err != nil {
 		return nil, fmt.Errorf("failed to add part to repair: %w", err)
 	}
 
 	// Update spare part stock
 	newStock := sparePart.StockQuantity - req.Quantity
-	if err := u.sparePartRepo.UpdateStock(req.SparePartID, newStock); err != nil {
+	if err := u.sparePartRepo.UpdateStock(ctx, req.SparePartID, newStock); err != nil {
 		return nil, fmt.Errorf("failed to update spare part stock: %w", err)
 	}
+	if newStock <= 0 {
+		metrics.StockOuts.Inc()
+	}
+	// Only the use that crosses the minimum raises the event
+	if sparePart.StockQuantity > sparePart.MinStockLevel && newStock <= sparePart.MinStockLevel {
+		u.notifications.Broadcast(ctx, entity.EventStockLow, entity.PermSparePartView, entity.StockLowEvent{
+			SparePartID:   sparePart.ID,
+			PartCode:      sparePart.PartCode,
+			Name:          sparePart.Name,
+			StockQuantity: newStock,
+			MinStockLevel: sparePart.MinStockLevel,
+		})
+	}
 
 	// Update repair costs
-	if err := u.repairRepo.UpdateRepairCosts(repairId); err != nil {
+	if err := u.repairRepo.UpdateRepairCosts(ctx, repairId); err != nil {
 		return nil, fmt.Errorf("failed to update repair costs: %w", err)
 	}
 
@@ -229,18 +279,18 @@ func (u *repairUsecase) AddPart(repairId int, req *entity.AddPartToRepairRequest
 	return repairPart, nil
 }
 
-func (u *repairUsecase) RemovePart(repairId, partId int) error {
+func (u *repairUsecase) RemovePart(ctx context.Context, repairId, partId int) error {
 	// Validate repair exists
-	repair, err := u.repairRepo.GetByID(repairId)
+	repair, err := u.repairRepo.GetByID(ctx, repairId)
 	if err != nil {
 		return fmt.Errorf("failed to get repair: %w", err)
 	}
 	if repair == nil {
-		return fmt.Errorf("repair not found")
+		return entity.NewNotFoundError("repair")
 	}
 
 	// Get repair part details before removing
-	parts, err := u.repairRepo.GetRepairParts(repairId)
+	parts, err := u.repairRepo.GetRepairParts(ctx, repairId)
 	if err != nil {
 		return fmt.Errorf("failed to get repair parts: %w", err)
 	}
@@ -254,25 +304,40 @@ func (u *repairUsecase) RemovePart(repairId, partId int) error {
 	}
 
 	if partToRemove == nil {
-		return fmt.Errorf("repair part not found")
+		return entity.NewNotFoundError("repair part")
 	}
 
 	// Remove part from repair
-	if err := u.repairRepo.RemovePart(repairId, partId); err != nil {
+	if err := u.repairRepo.RemovePart(ctx, repairId, partId); err != nil {
 		return fmt.Errorf("failed to remove part from repair: %w", err)
 	}
 
 	// Restore spare part stock
-	sparePart, err := u.sparePartRepo.GetByID(partToRemove.SparePartID)
+	sparePart, err := u.sparePartRepo.GetByID(ctx, partToRemove.SparePartID)
 	if err == nil && sparePart != nil {
 		newStock := sparePart.StockQuantity + partToRemove.QuantityUsed
-		u.sparePartRepo.UpdateStock(partToRemove.SparePartID, newStock)
+		u.sparePartRepo.UpdateStock(ctx, partToRemove.SparePartID, newStock)
 	}
 
 	// Update repair costs
-	if err := u.repairRepo.UpdateRepairCosts(repairId); err != nil {
+	if err := u.repairRepo.UpdateRepairCosts(ctx, repairId); err != nil {
 		return fmt.Errorf("failed to update repair costs: %w", err)
 	}
 
 	return nil
 }
+
+// notifyAssigned tells the repair's mechanic that the job is theirs.
+func (u *repairUsecase) notifyAssigned(ctx context.Context, repair *entity.Repair, vehicle *entity.Vehicle) {
+	body := repair.Title
+	if vehicle != nil {
+		body = fmt.Sprintf("%s on %s %s %s", repair.Title, vehicle.VehicleCode, vehicle.Brand, vehicle.Model)
+	}
+	u.notifications.Notify(ctx, []int{*repair.MechanicID}, entity.NewNotification{
+		Type:       entity.NotificationRepairAssigned,
+		Title:      "Repair " + repair.RepairNumber + " assigned to you",
+		Body:       body,
+		EntityType: entity.TaskEntityRepair,
+		EntityID:   repair.ID,
+	})
+}
@@ -1,7 +1,7 @@
 package usecase
 
 import (
-	"fmt"
+	"context"
 	"time"
 
 	"vehicle-showroom/internal/entity"
@@ -9,9 +9,12 @@ import (
 )
 
 type ReportUsecase interface {
-	GetVehicleProfitabilityReport(startDate, endDate string) ([]entity.VehicleProfitability, error)
-	GetSalesReport(startDate, endDate string) ([]entity.SalesTransaction, error)
-	GetPurchaseReport(startDate, endDate string) ([]entity.PurchaseTransaction, error)
+	GetVehicleProfitabilityReport(ctx context.Context, startDate, endDate string) ([]entity.VehicleProfitability, error)
+	GetSalesReport(ctx context.Context, startDate, endDate string) ([]entity.SalesTransaction, error)
+	GetPurchaseReport(ctx context.Context, startDate, endDate string) ([]entity.PurchaseTransaction, error)
+	StreamVehicleProfitabilityReport(ctx context.Context, startDate, endDate string, fn func([]entity.VehicleProfitability) error) error
+	StreamSalesReport(ctx context.Context, startDate, endDate string, fn func([]entity.SalesTransaction) error) error
+	StreamPurchaseReport(ctx context.Context, startDate, endDate string, fn func([]entity.PurchaseTransaction) error) error
 }
 
 type reportUsecase struct {
@@ -24,45 +27,64 @@ func NewReportUsecase(reportRepo repository.ReportRepository) ReportUsecase {
 	}
 }
 
-func (u *reportUsecase) GetVehicleProfitabilityReport(startDateStr, endDateStr string) ([]entity.VehicleProfitability, error) {
-	start, err := time.Parse("2006-01-02", startDateStr)
+func (u *reportUsecase) GetVehicleProfitabilityReport(ctx context.Context, startDateStr, endDateStr string) ([]entity.VehicleProfitability, error) {
+	start, end, err := parseDateRange(startDateStr, endDateStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid start date format: %w", err)
+		return nil, err
 	}
-	end, err := time.Parse("2006-01-02", endDateStr)
+	return u.reportRepo.GetVehicleProfitabilityReport(ctx, start, end)
+}
+
+func (u *reportUsecase) GetSalesReport(ctx context.Context, startDateStr, endDateStr string) ([]entity.SalesTransaction, error) {
+	start, end, err := parseDateRange(startDateStr, endDateStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid end date format: %w", err)
+		return nil, err
 	}
-	// To include the whole end day
-	end = end.Add(24*time.Hour - 1*time.Nanosecond)
+	return u.reportRepo.GetSalesReport(ctx, start, end)
+}
 
-	return u.reportRepo.GetVehicleProfitabilityReport(start, end)
+func (u *reportUsecase) GetPurchaseReport(ctx context.Context, startDateStr, endDateStr string) ([]entity.PurchaseTransaction, error) {
+	start, end, err := parseDateRange(startDateStr, endDateStr)
+	if err != nil {
+		return nil, err
+	}
+	return u.reportRepo.GetPurchaseReport(ctx, start, end)
 }
 
-func (u *reportUsecase) GetSalesReport(startDateStr, endDateStr string) ([]entity.SalesTransaction, error) {
-	start, err := time.Parse("2006-01-02", startDateStr)
+func (u *reportUsecase) StreamVehicleProfitabilityReport(ctx context.Context, startDateStr, endDateStr string, fn func([]entity.VehicleProfitability) error) error {
+	start, end, err := parseDateRange(startDateStr, endDateStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid start date format: %w", err)
+		return err
 	}
-	end, err := time.Parse("2006-01-02", endDateStr)
+	return u.reportRepo.StreamVehicleProfitabilityReport(ctx, start, end, fn)
+}
+
+func (u *reportUsecase) StreamSalesReport(ctx context.Context, startDateStr, endDateStr string, fn func([]entity.SalesTransaction) error) error {
+	start, end, err := parseDateRange(startDateStr, endDateStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid end date format: %w", err)
+		return err
 	}
-	end = end.Add(24*time.Hour - 1*time.Nanosecond)
+	return u.reportRepo.StreamSalesReport(ctx, start, end, fn)
+}
 
-	return u.reportRepo.GetSalesReport(start, end)
+func (u *reportUsecase) StreamPurchaseReport(ctx context.Context, startDateStr, endDateStr string, fn func([]entity.PurchaseTransaction) error) error {
+	start, end, err := parseDateRange(startDateStr, endDateStr)
+	if err != nil {
+		return err
+	}
+	return u.reportRepo.StreamPurchaseReport(ctx, start, end, fn)
 }
 
-func (u *reportUsecase) GetPurchaseReport(startDateStr, endDateStr string) ([]entity.PurchaseTransaction, error) {
+// parseDateRange parses YYYY-MM-DD dates; the range includes the whole end
+// day.
+func parseDateRange(startDateStr, endDateStr string) (time.Time, time.Time, error) {
 	start, err := time.Parse("2006-01-02", startDateStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid start date format: %w", err)
+		return time.Time{}, time.Time{}, entity.NewFieldError("start_date", "must be a date in YYYY-MM-DD format")
 	}
 	end, err := time.Parse("2006-01-02", endDateStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid end date format: %w", err)
+		return time.Time{}, time.Time{}, entity.NewFieldError("end_date", "must be a date in YYYY-MM-DD format")
 	}
-	end = end.Add(24*time.Hour - 1*time.Nanosecond)
-
-	return u.reportRepo.GetPurchaseReport(start, end)
+	return start, end.Add(24*time.Hour - 1*time.Nanosecond), nil
 }
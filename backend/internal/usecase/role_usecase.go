@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/repository"
+)
+
+type RoleUsecase interface {
+	List(ctx context.Context) ([]entity.Role, error)
+	GetByID(ctx context.Context, id int) (*entity.Role, error)
+	Create(ctx context.Context, req *entity.CreateRoleRequest) (*entity.Role, error)
+	Update(ctx context.Context, id int, req *entity.UpdateRoleRequest) (*entity.Role, error)
+	Delete(ctx context.Context, id int) error
+	ListPermissions(ctx context.Context) ([]entity.Permission, error)
+	HasPermission(ctx context.Context, roleName, permission string) (bool, error)
+}
+
+type roleUsecase struct {
+	roleRepo repository.RoleRepository
+
+	// Permission sets are read on every request, so they are cached per role
+	// and dropped whenever a role is changed through this usecase.
+	mu    sync.RWMutex
+	cache map[string]map[string]bool
+}
+
+func NewRoleUsecase(roleRepo repository.RoleRepository) RoleUsecase {
+	return &roleUsecase{
+		roleRepo: roleRepo,
+		cache:    make(map[string]map[string]bool),
+	}
+}
+
+func (u *roleUsecase) List(ctx context.Context) ([]entity.Role, error) {
+	roles, err := u.roleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+func (u *roleUsecase) GetByID(ctx context.Context, id int) (*entity.Role, error) {
+	role, err := u.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	if role == nil {
+		return nil, entity.NewNotFoundError("role")
+	}
+
+	return role, nil
+}
+
+func (u *roleUsecase) Create(ctx context.Context, req *entity.CreateRoleRequest) (*entity.Role, error) {
+	existing, err := u.roleRepo.GetByName(ctx, req.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check role name: %w", err)
+	}
+	if existing != nil {
+		return nil, entity.NewConflictError("role name already exists")
+	}
+
+	if err := validatePermissionCodes(req.Permissions); err != nil {
+		return nil, err
+	}
+
+	role := &entity.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}
+
+	if err := u.roleRepo.Create(ctx, role); err != nil {
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+
+	u.invalidate()
+
+	return u.roleRepo.GetByID(ctx, role.ID)
+}
+
+func (u *roleUsecase) Update(ctx context.Context, id int, req *entity.UpdateRoleRequest) (*entity.Role, error) {
+	role, err := u.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	if role == nil {
+		return nil, entity.NewNotFoundError("role")
+	}
+
+	if role.IsSystem && req.Name != role.Name {
+		return nil, entity.NewForbiddenError("system roles cannot be renamed")
+	}
+
+	if req.Name != role.Name {
+		existing, err := u.roleRepo.GetByName(ctx, req.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check role name: %w", err)
+		}
+		if existing != nil {
+			return nil, entity.NewConflictError("role name already exists")
+		}
+	}
+
+	if err := validatePermissionCodes(req.Permissions); err != nil {
+		return nil, err
+	}
+
+	// Never let the admin role lock itself out of role management
+	if role.Name == entity.RoleAdmin && !containsString(req.Permissions, entity.PermRoleManage) {
+		return nil, entity.NewForbiddenError(fmt.Sprintf("the admin role must keep the %s permission", entity.PermRoleManage))
+	}
+
+	role.Name = req.Name
+	role.Description = req.Description
+	role.Permissions = req.Permissions
+
+	if err := u.roleRepo.Update(ctx, role); err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+
+	u.invalidate()
+
+	return u.roleRepo.GetByID(ctx, id)
+}
+
+func (u *roleUsecase) Delete(ctx context.Context, id int) error {
+	role, err := u.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+
+	if role == nil {
+		return entity.NewNotFoundError("role")
+	}
+
+	if role.IsSystem {
+		return entity.NewForbiddenError("system roles cannot be deleted")
+	}
+
+	users, err := u.roleRepo.CountUsers(ctx, role.Name)
+	if err != nil {
+		return fmt.Errorf("failed to check role usage: %w", err)
+	}
+	if users > 0 {
+		return entity.NewConflictError("role is assigned to %d user(s)", users)
+	}
+
+	if err := u.roleRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	u.invalidate()
+
+	return nil
+}
+
+func (u *roleUsecase) ListPermissions(ctx context.Context) ([]entity.Permission, error) {
+	permissions, err := u.roleRepo.ListPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+
+	return permissions, nil
+}
+
+func (u *roleUsecase) HasPermission(ctx context.Context, roleName, permission string) (bool, error) {
+	u.mu.RLock()
+	perms, ok := u.cache[roleName]
+	u.mu.RUnlock()
+
+	if !ok {
+		codes, err := u.roleRepo.GetPermissionCodes(ctx, roleName)
+		if err != nil {
+			return false, fmt.Errorf("failed to load role permissions: %w", err)
+		}
+
+		perms = make(map[string]bool, len(codes))
+		for _, code := range codes {
+			perms[code] = true
+		}
+
+		u.mu.Lock()
+		u.cache[roleName] = perms
+		u.mu.Unlock()
+	}
+
+	return perms[permission], nil
+}
+
+func (u *roleUsecase) invalidate() {
+	u.mu.Lock()
+	u.cache = make(map[string]map[string]bool)
+	u.mu.Unlock()
+}
+
+func validatePermissionCodes(codes []string) error {
+	known := make(map[string]bool, len(entity.PermissionCatalog))
+	for _, def := range entity.PermissionCatalog {
+		known[def.Code] = true
+	}
+
+	for _, code := range codes {
+		if !known[code] {
+			return entity.NewFieldError("permissions", "contains unknown permission "+code)
+		}
+	}
+
+	return nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
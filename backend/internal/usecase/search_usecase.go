@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/repository"
+)
+
+type SearchUsecase interface {
+	Search(ctx context.Context, role, query string, types []string, limit int) (*entity.SearchResponse, error)
+}
+
+type searchUsecase struct {
+	searchRepo  repository.SearchRepository
+	roleUsecase RoleUsecase
+}
+
+// NewSearchUsecase takes the role usecase rather than the repository to
+// share its cached permission sets.
+func NewSearchUsecase(searchRepo repository.SearchRepository, roleUsecase RoleUsecase) SearchUsecase {
+	return &searchUsecase{
+		searchRepo:  searchRepo,
+		roleUsecase: roleUsecase,
+	}
+}
+
+// Search finds customers, vehicles, invoices and repairs matching query,
+// best first. types narrows the kinds searched (all when empty); kinds the
+// role may not view are left out rather than refused.
+func (u *searchUsecase) Search(ctx context.Context, role, query string, types []string, limit int) (*entity.SearchResponse, error) {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < 2 {
+		return nil, entity.NewFieldError("q", "must be at least 2 characters")
+	}
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+
+	known := make(map[string]bool, len(entity.SearchTypes))
+	for _, st := range entity.SearchTypes {
+		known[st.Type] = true
+	}
+	requested := make(map[string]bool, len(types))
+	for _, t := range types {
+		if !known[t] {
+			return nil, entity.NewFieldError("types", fmt.Sprintf("has unknown type %q", t))
+		}
+		requested[t] = true
+	}
+
+	var searchable []string
+	for _, st := range entity.SearchTypes {
+		if len(types) > 0 && !requested[st.Type] {
+			continue
+		}
+		allowed, err := u.roleUsecase.HasPermission(ctx, role, st.Permission)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			searchable = append(searchable, st.Type)
+		}
+	}
+
+	hits, err := u.searchRepo.Search(ctx, query, searchable, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.SearchResponse{Query: query, Hits: hits}, nil
+}
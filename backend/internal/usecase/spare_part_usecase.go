@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 
 	"vehicle-showroom/internal/entity"
@@ -8,11 +9,12 @@ import (
 )
 
 type SparePartUsecase interface {
-	Create(req *entity.CreateSparePartRequest) (*entity.SparePart, error)
-	GetByID(id int) (*entity.SparePart, error)
-	List(page, limit int, search string) (*entity.SparePartListResponse, error)
-	Update(id int, req *entity.UpdateSparePartRequest) (*entity.SparePart, error)
-	Delete(id int) error
+	Create(ctx context.Context, req *entity.CreateSparePartRequest) (*entity.SparePart, error)
+	Import(ctx context.Context, rows []entity.ImportRow[entity.CreateSparePartRequest], dryRun bool) (*entity.ImportResult, error)
+	GetByID(ctx context.Context, id int) (*entity.SparePart, error)
+	List(ctx context.Context, params entity.ListParams, search string) (*entity.SparePartListResponse, error)
+	Update(ctx context.Context, id int, req *entity.UpdateSparePartRequest) (*entity.SparePart, error)
+	Delete(ctx context.Context, id int) error
 }
 
 type sparePartUsecase struct {
@@ -25,75 +27,88 @@ func NewSparePartUsecase(sparePartRepo repository.SparePartRepository) SparePart
 	}
 }
 
-func (u *sparePartUsecase) Create(req *entity.CreateSparePartRequest) (*entity.SparePart, error) {
+func (u *sparePartUsecase) Create(ctx context.Context, req *entity.CreateSparePartRequest) (*entity.SparePart, error) {
 	// Generate part code
-	partCode, err := u.sparePartRepo.GeneratePartCode()
+	partCode, err := u.sparePartRepo.GeneratePartCode(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate part code: %w", err)
 	}
 
-	sparePart := &entity.SparePart{
-		PartCode:      partCode,
-		Name:          req.Name,
-		Description:   req.Description,
-		Brand:         req.Brand,
-		CostPrice:     req.CostPrice,
-		SellingPrice:  req.SellingPrice,
-		StockQuantity: req.StockQuantity,
-		MinStockLevel: req.MinStockLevel,
-		UnitMeasure:   req.UnitMeasure,
-		IsActive:      true,
-	}
+	sparePart := newSparePart(req)
+	sparePart.PartCode = partCode
 
-	if err := u.sparePartRepo.Create(sparePart); err != nil {
+	if err := u.sparePartRepo.Create(ctx, sparePart); err != nil {
 		return nil, fmt.Errorf("failed to create spare part: %w", err)
 	}
 
 	return sparePart, nil
 }
 
-func (u *sparePartUsecase) GetByID(id int) (*entity.SparePart, error) {
-	sparePart, err := u.sparePartRepo.GetByID(id)
+// Import creates a spare part per row as Create does, all in one
+// transaction.
+func (u *sparePartUsecase) Import(ctx context.Context, rows []entity.ImportRow[entity.CreateSparePartRequest], dryRun bool) (*entity.ImportResult, error) {
+	result := entity.NewImportResult(rows, dryRun)
+	if dryRun {
+		return result, nil
+	}
+	if len(result.Errors) > 0 {
+		return nil, result.Rejected()
+	}
+
+	spareParts := make([]*entity.SparePart, len(rows))
+	for i := range rows {
+		spareParts[i] = newSparePart(&rows[i].Data)
+	}
+	if err := u.sparePartRepo.CreateBatch(ctx, spareParts); err != nil {
+		return nil, fmt.Errorf("failed to import spare parts: %w", err)
+	}
+
+	result.Created = len(spareParts)
+	for _, sparePart := range spareParts {
+		result.Codes = append(result.Codes, sparePart.PartCode)
+	}
+	return result, nil
+}
+
+func (u *sparePartUsecase) GetByID(ctx context.Context, id int) (*entity.SparePart, error) {
+	sparePart, err := u.sparePartRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get spare part: %w", err)
 	}
 
 	if sparePart == nil {
-		return nil, fmt.Errorf("spare part not found")
+		return nil, entity.NewNotFoundError("spare part")
 	}
 
 	return sparePart, nil
 }
 
-func (u *sparePartUsecase) List(page, limit int, search string) (*entity.SparePartListResponse, error) {
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 || limit > 100 {
-		limit = 10
-	}
+func (u *sparePartUsecase) List(ctx context.Context, params entity.ListParams, search string) (*entity.SparePartListResponse, error) {
+	params.Normalize(10, 100)
 
-	spareParts, total, err := u.sparePartRepo.List(page, limit, search)
+	spareParts, page, err := u.sparePartRepo.List(ctx, params, search)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list spare parts: %w", err)
 	}
 
 	return &entity.SparePartListResponse{
 		SpareParts: spareParts,
-		Total:      total,
-		Page:       page,
-		Limit:      limit,
+		Total:      page.Total,
+		HasMore:    page.HasMore,
+		NextCursor: page.NextCursor,
+		Page:       params.ResponsePage(),
+		Limit:      params.Limit,
 	}, nil
 }
 
-func (u *sparePartUsecase) Update(id int, req *entity.UpdateSparePartRequest) (*entity.SparePart, error) {
-	sparePart, err := u.sparePartRepo.GetByID(id)
+func (u *sparePartUsecase) Update(ctx context.Context, id int, req *entity.UpdateSparePartRequest) (*entity.SparePart, error) {
+	sparePart, err := u.sparePartRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get spare part: %w", err)
 	}
 
 	if sparePart == nil {
-		return nil, fmt.Errorf("spare part not found")
+		return nil, entity.NewNotFoundError("spare part")
 	}
 
 	sparePart.Name = req.Name
@@ -107,26 +122,41 @@ func (u *sparePartUsecase) Update(id int, req *entity.UpdateSparePartRequest) (*
 		sparePart.IsActive = *req.IsActive
 	}
 
-	if err := u.sparePartRepo.Update(sparePart); err != nil {
+	if err := u.sparePartRepo.Update(ctx, sparePart); err != nil {
 		return nil, fmt.Errorf("failed to update spare part: %w", err)
 	}
 
 	return sparePart, nil
 }
 
-func (u *sparePartUsecase) Delete(id int) error {
-	sparePart, err := u.sparePartRepo.GetByID(id)
+func (u *sparePartUsecase) Delete(ctx context.Context, id int) error {
+	sparePart, err := u.sparePartRepo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get spare part: %w", err)
 	}
 
 	if sparePart == nil {
-		return fmt.Errorf("spare part not found")
+		return entity.NewNotFoundError("spare part")
 	}
 
-	if err := u.sparePartRepo.Delete(id); err != nil {
+	if err := u.sparePartRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete spare part: %w", err)
 	}
 
 	return nil
 }
+
+// newSparePart is the part a create request describes, still without a code.
+func newSparePart(req *entity.CreateSparePartRequest) *entity.SparePart {
+	return &entity.SparePart{
+		Name:          req.Name,
+		Description:   req.Description,
+		Brand:         req.Brand,
+		CostPrice:     req.CostPrice,
+		SellingPrice:  req.SellingPrice,
+		StockQuantity: req.StockQuantity,
+		MinStockLevel: req.MinStockLevel,
+		UnitMeasure:   req.UnitMeasure,
+		IsActive:      true,
+	}
+}
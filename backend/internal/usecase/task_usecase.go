@@ -0,0 +1,307 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/metrics"
+	"vehicle-showroom/internal/notifier"
+	"vehicle-showroom/internal/repository"
+)
+
+// taskBatchSize bounds how many tasks one scheduler query claims.
+const taskBatchSize = 100
+
+type TaskUsecase interface {
+	Create(ctx context.Context, req *entity.CreateTaskRequest, createdBy int) (*entity.Task, error)
+	GetByID(ctx context.Context, id int) (*entity.Task, error)
+	List(ctx context.Context, params entity.ListParams, filter entity.TaskFilter) (*entity.TaskListResponse, error)
+	ListMine(ctx context.Context, userID int, params entity.ListParams, filter entity.TaskFilter) (*entity.TaskListResponse, error)
+	Update(ctx context.Context, id int, req *entity.UpdateTaskRequest) (*entity.Task, error)
+	UpdateStatus(ctx context.Context, id int, req *entity.UpdateTaskStatusRequest, user *entity.User) (*entity.Task, error)
+	ProcessDue(ctx context.Context) error
+}
+
+type taskUsecase struct {
+	taskRepo      repository.TaskRepository
+	userRepo      repository.UserRepository
+	roleUsecase   RoleUsecase
+	notifications NotificationUsecase
+	notifier      notifier.Notifier
+}
+
+func NewTaskUsecase(
+	taskRepo repository.TaskRepository,
+	userRepo repository.UserRepository,
+	roleUsecase RoleUsecase,
+	notifications NotificationUsecase,
+	notifier notifier.Notifier,
+) TaskUsecase {
+	return &taskUsecase{
+		taskRepo:      taskRepo,
+		userRepo:      userRepo,
+		roleUsecase:   roleUsecase,
+		notifications: notifications,
+		notifier:      notifier,
+	}
+}
+
+func (u *taskUsecase) Create(ctx context.Context, req *entity.CreateTaskRequest, createdBy int) (*entity.Task, error) {
+	task := &entity.Task{
+		Title:       strings.TrimSpace(req.Title),
+		Description: req.Description,
+		AssignedTo:  createdBy,
+		DueAt:       localTime(req.DueAt),
+		RemindAt:    localTimePtr(req.RemindAt),
+		Status:      entity.TaskOpen,
+		EntityType:  req.EntityType,
+		EntityID:    req.EntityID,
+		CreatedBy:   &createdBy,
+	}
+	if req.AssignedTo != nil {
+		task.AssignedTo = *req.AssignedTo
+	}
+
+	if err := u.validate(ctx, task); err != nil {
+		return nil, err
+	}
+
+	if err := u.taskRepo.Create(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+	if task.AssignedTo != createdBy {
+		u.notifyAssigned(ctx, task)
+	}
+
+	return u.taskRepo.GetByID(ctx, task.ID)
+}
+
+func (u *taskUsecase) GetByID(ctx context.Context, id int) (*entity.Task, error) {
+	task, err := u.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if task == nil {
+		return nil, entity.NewNotFoundError("task")
+	}
+	return task, nil
+}
+
+func (u *taskUsecase) List(ctx context.Context, params entity.ListParams, filter entity.TaskFilter) (*entity.TaskListResponse, error) {
+	params.Normalize(10, 100)
+
+	tasks, page, err := u.taskRepo.List(ctx, params, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	return &entity.TaskListResponse{
+		Tasks:      tasks,
+		Total:      page.Total,
+		HasMore:    page.HasMore,
+		NextCursor: page.NextCursor,
+		Page:       params.ResponsePage(),
+		Limit:      params.Limit,
+	}, nil
+}
+
+// ListMine lists the tasks assigned to userID; any assignee in filter is
+// replaced.
+func (u *taskUsecase) ListMine(ctx context.Context, userID int, params entity.ListParams, filter entity.TaskFilter) (*entity.TaskListResponse, error) {
+	filter.AssignedTo = &userID
+	return u.List(ctx, params, filter)
+}
+
+func (u *taskUsecase) Update(ctx context.Context, id int, req *entity.UpdateTaskRequest) (*entity.Task, error) {
+	task, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if entity.TaskClosed(task.Status) {
+		return nil, entity.NewInvalidTransitionError("task is already %s", task.Status)
+	}
+
+	reassigned := task.AssignedTo != req.AssignedTo
+
+	task.Title = strings.TrimSpace(req.Title)
+	task.Description = req.Description
+	task.AssignedTo = req.AssignedTo
+	task.DueAt = localTime(req.DueAt)
+	task.RemindAt = localTimePtr(req.RemindAt)
+	task.EntityType = req.EntityType
+	task.EntityID = req.EntityID
+
+	if err := u.validate(ctx, task); err != nil {
+		return nil, err
+	}
+
+	if err := u.taskRepo.Update(ctx, task, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to update task: %w", err)
+	}
+	if reassigned {
+		u.notifyAssigned(ctx, task)
+	}
+
+	return u.taskRepo.GetByID(ctx, id)
+}
+
+// UpdateStatus lets the assignee work through their own task; changing
+// someone else's needs PermTaskUpdate. Done and cancelled tasks can be
+// reopened.
+func (u *taskUsecase) UpdateStatus(ctx context.Context, id int, req *entity.UpdateTaskStatusRequest, user *entity.User) (*entity.Task, error) {
+	task, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.AssignedTo != user.ID {
+		allowed, err := u.roleUsecase.HasPermission(ctx, user.Role, entity.PermTaskUpdate)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, entity.NewForbiddenError("task is assigned to someone else")
+		}
+	}
+
+	if task.Status == req.Status {
+		return task, nil
+	}
+
+	if err := u.taskRepo.UpdateStatus(ctx, id, req.Status, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to update task status: %w", err)
+	}
+
+	return u.taskRepo.GetByID(ctx, id)
+}
+
+// ProcessDue is the scheduler job: it sends the reminders that have come
+// due, then marks past-due tasks overdue and tells their assignees, by
+// message and in the app. Each task is claimed before it is announced, so a
+// failed send is logged and not retried.
+func (u *taskUsecase) ProcessDue(ctx context.Context) error {
+	now := time.Now()
+
+	for {
+		tasks, err := u.taskRepo.ClaimReminders(ctx, now, taskBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			u.notify(ctx, task, entity.NotificationTaskReminder, notifier.Message{
+				To:      task.AssigneeEmail,
+				Subject: "Reminder: " + task.Title,
+				Body: fmt.Sprintf(
+					"Hi %s,\n\nThis is a reminder for your task \"%s\", due %s.%s",
+					task.AssigneeName, task.Title, task.DueAt.Format(taskTimeLayout), taskDetails(task.Task),
+				),
+			})
+			metrics.TaskReminders.Inc()
+		}
+		if len(tasks) < taskBatchSize {
+			break
+		}
+	}
+
+	for {
+		tasks, err := u.taskRepo.MarkOverdue(ctx, now, taskBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			u.notify(ctx, task, entity.NotificationTaskOverdue, notifier.Message{
+				To:      task.AssigneeEmail,
+				Subject: "Overdue: " + task.Title,
+				Body: fmt.Sprintf(
+					"Hi %s,\n\nYour task \"%s\" was due %s and is now overdue.%s",
+					task.AssigneeName, task.Title, task.DueAt.Format(taskTimeLayout), taskDetails(task.Task),
+				),
+			})
+			metrics.TasksOverdue.Inc()
+		}
+		if len(tasks) < taskBatchSize {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (u *taskUsecase) notify(ctx context.Context, task entity.TaskDue, notificationType string, msg notifier.Message) {
+	if err := u.notifier.Send(msg); err != nil {
+		slog.ErrorContext(ctx, "failed to send task notification",
+			slog.Int("task_id", task.ID),
+			slog.String("subject", msg.Subject),
+			slog.String("error", err.Error()),
+		)
+	}
+	u.notifications.Notify(ctx, []int{task.AssignedTo}, entity.NewNotification{
+		Type:       notificationType,
+		Title:      msg.Subject,
+		Body:       "Due " + task.DueAt.Format(taskTimeLayout),
+		EntityType: entity.NotificationEntityTask,
+		EntityID:   task.ID,
+	})
+}
+
+// notifyAssigned tells the assignee in the app that someone gave them the
+// task.
+func (u *taskUsecase) notifyAssigned(ctx context.Context, task *entity.Task) {
+	u.notifications.Notify(ctx, []int{task.AssignedTo}, entity.NewNotification{
+		Type:       entity.NotificationTaskAssigned,
+		Title:      "New task: " + task.Title,
+		Body:       "Due " + task.DueAt.Format(taskTimeLayout),
+		EntityType: entity.NotificationEntityTask,
+		EntityID:   task.ID,
+	})
+}
+
+// validate checks the assignee, the reminder time and the linked record.
+func (u *taskUsecase) validate(ctx context.Context, task *entity.Task) error {
+	assignee, err := u.userRepo.GetByID(ctx, task.AssignedTo)
+	if err != nil {
+		return fmt.Errorf("failed to get assignee: %w", err)
+	}
+	if assignee == nil || !assignee.IsActive {
+		return entity.NewFieldError("assigned_to", "must be an active user")
+	}
+
+	if task.RemindAt != nil && !task.RemindAt.Before(task.DueAt) {
+		return entity.NewFieldError("remind_at", "must be before due_at")
+	}
+
+	if (task.EntityType == nil) != (task.EntityID == nil) {
+		return entity.NewFieldError("entity_id", "entity_type and entity_id must be given together")
+	}
+	if task.EntityType != nil {
+		exists, err := u.taskRepo.EntityExists(ctx, *task.EntityType, *task.EntityID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return entity.NewFieldError("entity_id", fmt.Sprintf("%s %d does not exist", *task.EntityType, *task.EntityID))
+		}
+	}
+
+	return nil
+}
+
+const taskTimeLayout = "Mon 2 Jan 2006 15:04"
+
+// taskDetails renders the optional description and linked record for a
+// notification body.
+func taskDetails(task entity.Task) string {
+	var b strings.Builder
+	if task.Description != nil && *task.Description != "" {
+		b.WriteString("\n\n")
+		b.WriteString(*task.Description)
+	}
+	if task.EntityType != nil && task.EntityID != nil {
+		fmt.Fprintf(&b, "\n\nRelated %s: #%d", *task.EntityType, *task.EntityID)
+	}
+	return b.String()
+}
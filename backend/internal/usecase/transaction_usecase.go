@@ -1,72 +1,84 @@
 package usecase
 
 import (
+  "context"
   "fmt"
   "time"
 
+  "vehicle-showroom/internal/config"
   "vehicle-showroom/internal/entity"
+  "vehicle-showroom/internal/metrics"
   "vehicle-showroom/internal/repository"
 )
 
 type TransactionUsecase interface {
   // Purchase Transactions
-  CreatePurchase(req *entity.CreatePurchaseTransactionRequest, cashierID int) (*entity.PurchaseTransaction, error)
-  GetPurchaseByID(id int) (*entity.PurchaseTransaction, error)
-  ListPurchases(page, limit int, search string) (*entity.TransactionListResponse, error)
+  CreatePurchase(ctx context.Context, req *entity.CreatePurchaseTransactionRequest, cashierID int) (*entity.PurchaseTransaction, error)
+  GetPurchaseByID(ctx context.Context, id int) (*entity.PurchaseTransaction, error)
+  ListPurchases(ctx context.Context, params entity.ListParams, search string) (*entity.TransactionListResponse, error)
   
   // Sales Transactions
-  CreateSales(req *entity.CreateSalesTransactionRequest, cashierID int) (*entity.SalesTransaction, error)
-  GetSalesByID(id int) (*entity.SalesTransaction, error)
-  ListSales(page, limit int, search string) (*entity.TransactionListResponse, error)
+  CreateSales(ctx context.Context, req *entity.CreateSalesTransactionRequest, cashierID int) (*entity.SalesTransaction, error)
+  GetSalesByID(ctx context.Context, id int) (*entity.SalesTransaction, error)
+  ListSales(ctx context.Context, params entity.ListParams, search string) (*entity.TransactionListResponse, error)
   
   // Dashboard
-  GetDashboardStats() (*entity.DashboardStats, error)
+  GetDashboardStats(ctx context.Context) (*entity.DashboardStats, error)
 }
 
 type transactionUsecase struct {
   transactionRepo repository.TransactionRepository
   vehicleRepo     repository.VehicleRepository
   customerRepo    repository.CustomerRepository
+  documentRepo    repository.CustomerDocumentRepository
+  notifications   NotificationUsecase
+  kyc             config.KYCConfig
 }
 
 func NewTransactionUsecase(
   transactionRepo repository.TransactionRepository,
   vehicleRepo repository.VehicleRepository,
   customerRepo repository.CustomerRepository,
+  documentRepo repository.CustomerDocumentRepository,
+  notifications NotificationUsecase,
+  kyc config.KYCConfig,
 ) TransactionUsecase {
   return &transactionUsecase{
     transactionRepo: transactionRepo,
     vehicleRepo:     vehicleRepo,
     customerRepo:    customerRepo,
+    documentRepo:    documentRepo,
+    notifications:   notifications,
+    kyc:             kyc,
   }
 }
 
-func (u *transactionUsecase) CreatePurchase(req *entity.CreatePurchaseTransactionRequest, cashierID int) (*entity.PurchaseTransaction, error) {
+func (u *transactionUsecase) CreatePurchase(ctx context.Context, req *entity.CreatePurchaseTransactionRequest, cashierID int) (*entity.PurchaseTransaction, error) {
   // Validate vehicle exists
-  vehicle, err := u.vehicleRepo.GetByID(req.VehicleID)
+  vehicle, err := u.vehicleRepo.GetByID(ctx, req.VehicleID)
   if err != nil {
     return nil, fmt.Errorf("failed to get vehicle: %w", err)
   }
   if vehicle == nil {
-    return nil, fmt.Errorf("vehicle not found")
+    return nil, entity.NewNotFoundError("vehicle")
   }
   
   // Validate customer exists
-  customer, err := u.customerRepo.GetByID(req.CustomerID)
+  customer, err := u.customerRepo.GetByID(ctx, req.CustomerID)
   if err != nil {
     return nil, fmt.Errorf("failed to get customer: %w", err)
   }
   if customer == nil {
-    return nil, fmt.Errorf("customer not found")
+    return nil, entity.NewNotFoundError("customer")
   }
   
   // Generate transaction and invoice numbers
-  transactionNumber, err := u.transactionRepo.GeneratePurchaseTransactionNumber()
+  transactionNumber, err := u.transactionRepo.GeneratePurchaseTransactionNumber(ctx)
   if err != nil {
     return nil, fmt.Errorf("failed to generate transaction number: %w", err)
   }
   
-  invoiceNumber, err := u.transactionRepo.GeneratePurchaseInvoiceNumber()
+  invoiceNumber, err := u.transactionRepo.GeneratePurchaseInvoiceNumber(ctx)
   if err != nil {
     return nil, fmt.Errorf("failed to generate invoice number: %w", err)
   }
@@ -91,7 +103,7 @@ func (u *transactionUsecase) CreatePurchase(req *entity.CreatePurchaseTransactio
     Notes:             req.Notes,
   }
   
-  if err := u.transactionRepo.CreatePurchase(transaction); err != nil {
+  if err := u.transactionRepo.CreatePurchase(ctx, transaction); err != nil {
     return nil, fmt.Errorf("failed to create purchase transaction: %w", err)
   }
   
@@ -103,77 +115,86 @@ func (u *transactionUsecase) CreatePurchase(req *entity.CreatePurchaseTransactio
   vehicle.PurchasedAt = &now
   vehicle.Status = "purchased"
   
-  if err := u.vehicleRepo.Update(vehicle); err != nil {
+  if err := u.vehicleRepo.RecordPurchase(ctx, vehicle); err != nil {
     return nil, fmt.Errorf("failed to update vehicle: %w", err)
   }
+  metrics.VehiclesPurchased.Inc()
   
   // Get the created transaction with related data
-  return u.transactionRepo.GetPurchaseByID(transaction.ID)
+  return u.transactionRepo.GetPurchaseByID(ctx, transaction.ID)
 }
 
-func (u *transactionUsecase) GetPurchaseByID(id int) (*entity.PurchaseTransaction, error) {
-  transaction, err := u.transactionRepo.GetPurchaseByID(id)
+func (u *transactionUsecase) GetPurchaseByID(ctx context.Context, id int) (*entity.PurchaseTransaction, error) {
+  transaction, err := u.transactionRepo.GetPurchaseByID(ctx, id)
   if err != nil {
     return nil, fmt.Errorf("failed to get purchase transaction: %w", err)
   }
   
   if transaction == nil {
-    return nil, fmt.Errorf("purchase transaction not found")
+    return nil, entity.NewNotFoundError("purchase transaction")
   }
   
   return transaction, nil
 }
 
-func (u *transactionUsecase) ListPurchases(page, limit int, search string) (*entity.TransactionListResponse, error) {
-  if page <= 0 {
-    page = 1
-  }
-  if limit <= 0 || limit > 100 {
-    limit = 10
-  }
+func (u *transactionUsecase) ListPurchases(ctx context.Context, params entity.ListParams, search string) (*entity.TransactionListResponse, error) {
+  params.Normalize(10, 100)
   
-  transactions, total, err := u.transactionRepo.ListPurchases(page, limit, search)
+  transactions, page, err := u.transactionRepo.ListPurchases(ctx, params, search)
   if err != nil {
     return nil, fmt.Errorf("failed to list purchase transactions: %w", err)
   }
   
   return &entity.TransactionListResponse{
     Transactions: transactions,
-    Total:        total,
-    Page:         page,
-    Limit:        limit,
+    Total:        page.Total,
+    HasMore:      page.HasMore,
+    NextCursor:   page.NextCursor,
+    Page:         params.ResponsePage(),
+    Limit:        params.Limit,
   }, nil
 }
 
-func (u *transactionUsecase) CreateSales(req *entity.CreateSalesTransactionRequest, cashierID int) (*entity.SalesTransaction, error) {
+func (u *transactionUsecase) CreateSales(ctx context.Context, req *entity.CreateSalesTransactionRequest, cashierID int) (*entity.SalesTransaction, error) {
   // Validate vehicle exists and is available for sale
-  vehicle, err := u.vehicleRepo.GetByID(req.VehicleID)
+  vehicle, err := u.vehicleRepo.GetByID(ctx, req.VehicleID)
   if err != nil {
     return nil, fmt.Errorf("failed to get vehicle: %w", err)
   }
   if vehicle == nil {
-    return nil, fmt.Errorf("vehicle not found")
+    return nil, entity.NewNotFoundError("vehicle")
   }
   if vehicle.Status != "ready_to_sell" && vehicle.Status != "reserved" {
-    return nil, fmt.Errorf("vehicle is not available for sale")
+    return nil, entity.NewInvalidTransitionError("vehicle is not available for sale (status: %s)", vehicle.Status)
   }
   
   // Validate customer exists
-  customer, err := u.customerRepo.GetByID(req.CustomerID)
+  customer, err := u.customerRepo.GetByID(ctx, req.CustomerID)
   if err != nil {
     return nil, fmt.Errorf("failed to get customer: %w", err)
   }
   if customer == nil {
-    return nil, fmt.Errorf("customer not found")
+    return nil, entity.NewNotFoundError("customer")
+  }
+  
+  // Buyers must be identified when the KYC policy is on
+  if u.kyc.RequireForSales {
+    documents, err := u.documentRepo.ListByCustomer(ctx, customer.ID)
+    if err != nil {
+      return nil, fmt.Errorf("failed to check customer documents: %w", err)
+    }
+    if status := entity.CheckKYC(customer.Type, documents, time.Now()); !status.Verified {
+      return nil, entity.NewKYCRequiredError(customer.ID, status.Missing)
+    }
   }
   
   // Generate transaction and invoice numbers
-  transactionNumber, err := u.transactionRepo.GenerateSalesTransactionNumber()
+  transactionNumber, err := u.transactionRepo.GenerateSalesTransactionNumber(ctx)
   if err != nil {
     return nil, fmt.Errorf("failed to generate transaction number: %w", err)
   }
   
-  invoiceNumber, err := u.transactionRepo.GenerateSalesInvoiceNumber()
+  invoiceNumber, err := u.transactionRepo.GenerateSalesInvoiceNumber(ctx)
   if err != nil {
     return nil, fmt.Errorf("failed to generate invoice number: %w", err)
   }
@@ -199,7 +220,7 @@ func (u *transactionUsecase) CreateSales(req *entity.CreateSalesTransactionReque
     Notes:             req.Notes,
   }
   
-  if err := u.transactionRepo.CreateSales(transaction); err != nil {
+  if err := u.transactionRepo.CreateSales(ctx, transaction); err != nil {
     return nil, fmt.Errorf("failed to create sales transaction: %w", err)
   }
   
@@ -211,50 +232,57 @@ func (u *transactionUsecase) CreateSales(req *entity.CreateSalesTransactionReque
   vehicle.SoldAt = &now
   vehicle.Status = "sold"
   
-  if err := u.vehicleRepo.Update(vehicle); err != nil {
+  if err := u.vehicleRepo.RecordSale(ctx, vehicle); err != nil {
     return nil, fmt.Errorf("failed to update vehicle: %w", err)
   }
+  metrics.VehiclesSold.Inc()
+  u.notifications.Broadcast(ctx, entity.EventVehicleSold, entity.PermSalesView, entity.VehicleSoldEvent{
+    VehicleID:         vehicle.ID,
+    VehicleCode:       vehicle.VehicleCode,
+    Brand:             vehicle.Brand,
+    Model:             vehicle.Model,
+    TransactionID:     transaction.ID,
+    TransactionNumber: transaction.TransactionNumber,
+    TotalAmount:       transaction.TotalAmount,
+  })
   
   // Get the created transaction with related data
-  return u.transactionRepo.GetSalesByID(transaction.ID)
+  return u.transactionRepo.GetSalesByID(ctx, transaction.ID)
 }
 
-func (u *transactionUsecase) GetSalesByID(id int) (*entity.SalesTransaction, error) {
-  transaction, err := u.transactionRepo.GetSalesByID(id)
+func (u *transactionUsecase) GetSalesByID(ctx context.Context, id int) (*entity.SalesTransaction, error) {
+  transaction, err := u.transactionRepo.GetSalesByID(ctx, id)
   if err != nil {
     return nil, fmt.Errorf("failed to get sales transaction: %w", err)
   }
   
   if transaction == nil {
-    return nil, fmt.Errorf("sales transaction not found")
+    return nil, entity.NewNotFoundError("sales transaction")
   }
   
   return transaction, nil
 }
 
-func (u *transactionUsecase) ListSales(page, limit int, search string) (*entity.TransactionListResponse, error) {
-  if page <= 0 {
-    page = 1
-  }
-  if limit <= 0 || limit > 100 {
-    limit = 10
-  }
+func (u *transactionUsecase) ListSales(ctx context.Context, params entity.ListParams, search string) (*entity.TransactionListResponse, error) {
+  params.Normalize(10, 100)
   
-  transactions, total, err := u.transactionRepo.ListSales(page, limit, search)
+  transactions, page, err := u.transactionRepo.ListSales(ctx, params, search)
   if err != nil {
     return nil, fmt.Errorf("failed to list sales transactions: %w", err)
   }
   
   return &entity.TransactionListResponse{
     Transactions: transactions,
-    Total:        total,
-    Page:         page,
-    Limit:        limit,
+    Total:        page.Total,
+    HasMore:      page.HasMore,
+    NextCursor:   page.NextCursor,
+    Page:         params.ResponsePage(),
+    Limit:        params.Limit,
   }, nil
 }
 
-func (u *transactionUsecase) GetDashboardStats() (*entity.DashboardStats, error) {
-  stats, err := u.transactionRepo.GetDashboardStats()
+func (u *transactionUsecase) GetDashboardStats(ctx context.Context) (*entity.DashboardStats, error) {
+  stats, err := u.transactionRepo.GetDashboardStats(ctx)
   if err != nil {
     return nil, fmt.Errorf("failed to get dashboard stats: %w", err)
   }
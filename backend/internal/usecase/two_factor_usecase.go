@@ -0,0 +1,327 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"vehicle-showroom/internal/config"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/repository"
+	"vehicle-showroom/internal/totp"
+)
+
+const (
+	recoveryCodeCount = 10
+	// Accept the previous and next code as well to tolerate clock drift
+	totpSkew = 1
+)
+
+type TwoFactorUsecase interface {
+	Status(ctx context.Context, user *entity.User) (*entity.TwoFactorStatus, error)
+	Setup(ctx context.Context, user *entity.User) (*entity.TwoFactorSetupResponse, error)
+	Enable(ctx context.Context, user *entity.User, currentSessionID int, code string) ([]string, error)
+	Disable(ctx context.Context, user *entity.User, req *entity.DisableTwoFactorRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, user *entity.User, code string) ([]string, error)
+	Reset(ctx context.Context, userID int) error
+}
+
+type twoFactorUsecase struct {
+	userRepo      repository.UserRepository
+	sessionRepo   repository.SessionRepository
+	twoFactorRepo repository.TwoFactorRepository
+	config        config.TwoFactorConfig
+	loginConfig   config.LoginConfig
+}
+
+func NewTwoFactorUsecase(
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+	twoFactorConfig config.TwoFactorConfig,
+	loginConfig config.LoginConfig,
+) TwoFactorUsecase {
+	return &twoFactorUsecase{
+		userRepo:      userRepo,
+		sessionRepo:   sessionRepo,
+		twoFactorRepo: twoFactorRepo,
+		config:        twoFactorConfig,
+		loginConfig:   loginConfig,
+	}
+}
+
+func (u *twoFactorUsecase) Status(ctx context.Context, user *entity.User) (*entity.TwoFactorStatus, error) {
+	status := &entity.TwoFactorStatus{
+		Enabled:  user.TwoFactorEnabled,
+		Required: twoFactorRequired(u.config, user),
+	}
+
+	if user.TwoFactorEnabled {
+		remaining, err := u.twoFactorRepo.CountRecoveryCodes(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+		}
+		status.RecoveryCodesRemaining = remaining
+	}
+
+	return status, nil
+}
+
+// Setup generates a new secret for the user. It only becomes active once
+// Enable confirms that the authenticator app produces matching codes.
+func (u *twoFactorUsecase) Setup(ctx context.Context, user *entity.User) (*entity.TwoFactorSetupResponse, error) {
+	if user.TwoFactorEnabled {
+		return nil, entity.NewConflictError("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	if err := u.twoFactorRepo.SetSecret(ctx, user.ID, secret); err != nil {
+		return nil, fmt.Errorf("failed to store secret: %w", err)
+	}
+
+	return &entity.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(u.config.Issuer, user.Username, secret),
+	}, nil
+}
+
+// Enable turns 2FA on and signs the user out everywhere except the session
+// that enabled it, since those sessions never proved a second factor.
+func (u *twoFactorUsecase) Enable(ctx context.Context, user *entity.User, currentSessionID int, code string) ([]string, error) {
+	if user.TwoFactorEnabled {
+		return nil, entity.NewConflictError("two-factor authentication is already enabled")
+	}
+
+	if user.TOTPSecret == nil {
+		return nil, entity.NewInvalidTransitionError("start two-factor setup first")
+	}
+
+	now := time.Now()
+	if err := u.checkLocked(user, now); err != nil {
+		return nil, err
+	}
+
+	// Recovery codes do not exist yet, so only an authenticator code proves
+	// the app was set up correctly
+	ok, err := verifyTOTP(ctx, u.twoFactorRepo, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, u.failAttempt(ctx, user, "code", "is invalid", now)
+	}
+	if err := u.resetAttempts(ctx, user); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	if err := u.twoFactorRepo.Enable(ctx, user.ID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	if err := u.sessionRepo.RevokeOthersByUserID(ctx, user.ID, currentSessionID, "two_factor_enabled"); err != nil {
+		return nil, fmt.Errorf("failed to revoke other sessions: %w", err)
+	}
+
+	return codes, nil
+}
+
+func (u *twoFactorUsecase) Disable(ctx context.Context, user *entity.User, req *entity.DisableTwoFactorRequest) error {
+	if !user.TwoFactorEnabled {
+		return entity.NewInvalidTransitionError("two-factor authentication is not enabled")
+	}
+
+	if twoFactorRequired(u.config, user) {
+		return entity.NewForbiddenError("two-factor authentication is mandatory for your role")
+	}
+
+	now := time.Now()
+	if err := u.checkLocked(user, now); err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return u.failAttempt(ctx, user, "password", "is incorrect", now)
+	}
+
+	ok, err := verifySecondFactor(ctx, u.twoFactorRepo, user, req.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return u.failAttempt(ctx, user, "code", "is invalid", now)
+	}
+	if err := u.resetAttempts(ctx, user); err != nil {
+		return err
+	}
+
+	return u.twoFactorRepo.Disable(ctx, user.ID)
+}
+
+func (u *twoFactorUsecase) RegenerateRecoveryCodes(ctx context.Context, user *entity.User, code string) ([]string, error) {
+	if !user.TwoFactorEnabled {
+		return nil, entity.NewInvalidTransitionError("two-factor authentication is not enabled")
+	}
+
+	now := time.Now()
+	if err := u.checkLocked(user, now); err != nil {
+		return nil, err
+	}
+
+	ok, err := verifyTOTP(ctx, u.twoFactorRepo, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, u.failAttempt(ctx, user, "code", "is invalid", now)
+	}
+	if err := u.resetAttempts(ctx, user); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	if err := u.twoFactorRepo.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// Reset removes 2FA from another user's account, e.g. after a lost phone,
+// and signs them out everywhere in case the phone was stolen. If the policy
+// requires 2FA for them they will be asked to enroll again.
+func (u *twoFactorUsecase) Reset(ctx context.Context, userID int) error {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return entity.NewNotFoundError("user")
+	}
+
+	if err := u.twoFactorRepo.Disable(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := u.sessionRepo.RevokeByUserID(ctx, userID, "two_factor_reset"); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+// checkLocked refuses a code while the account is locked. Wrong codes here
+// and wrong passwords at login count against the same lock.
+func (u *twoFactorUsecase) checkLocked(user *entity.User, now time.Time) error {
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		return &LoginThrottledError{RetryAfter: user.LockedUntil.Sub(now), Locked: true}
+	}
+	return nil
+}
+
+// failAttempt counts a wrong password or code like a failed login, so a
+// stolen access token cannot be used to guess codes.
+func (u *twoFactorUsecase) failAttempt(ctx context.Context, user *entity.User, field, message string, now time.Time) error {
+	lockUntil := now.Add(time.Minute * time.Duration(u.loginConfig.LockoutMinutes))
+	updated, err := u.userRepo.RecordFailedLogin(ctx, user.ID, u.loginConfig.MaxFailedAttempts, lockUntil, now)
+	if err != nil {
+		return fmt.Errorf("failed to record failed attempt: %w", err)
+	}
+
+	if updated.LockedUntil != nil && updated.LockedUntil.After(now) {
+		return &LoginThrottledError{RetryAfter: updated.LockedUntil.Sub(now), Locked: true}
+	}
+	return entity.NewFieldError(field, message)
+}
+
+func (u *twoFactorUsecase) resetAttempts(ctx context.Context, user *entity.User) error {
+	if user.FailedLoginAttempts == 0 && user.LockedUntil == nil {
+		return nil
+	}
+	if err := u.userRepo.ResetFailedLogins(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to reset failed attempts: %w", err)
+	}
+	return nil
+}
+
+// twoFactorRequired reports whether the org policy forces 2FA on the user.
+func twoFactorRequired(cfg config.TwoFactorConfig, user *entity.User) bool {
+	return cfg.RequireForAdmin && user.Role == entity.RoleAdmin
+}
+
+// verifySecondFactor accepts either a current authenticator code or an unused
+// recovery code.
+func verifySecondFactor(ctx context.Context, repo repository.TwoFactorRepository, user *entity.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return verifyTOTP(ctx, repo, user, code)
+	}
+
+	used, err := repo.UseRecoveryCode(ctx, user.ID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return false, fmt.Errorf("failed to check recovery code: %w", err)
+	}
+
+	return used, nil
+}
+
+func verifyTOTP(ctx context.Context, repo repository.TwoFactorRepository, user *entity.User, code string) (bool, error) {
+	if user.TOTPSecret == nil {
+		return false, nil
+	}
+
+	step, ok := totp.Validate(*user.TOTPSecret, code, time.Now(), totpSkew)
+	if !ok {
+		return false, nil
+	}
+
+	fresh, err := repo.ConsumeStep(ctx, user.ID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to check verification code: %w", err)
+	}
+
+	return fresh, nil
+}
+
+// generateRecoveryCodes returns the codes to show the user once, and their
+// hashes to store.
+func generateRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(encoding.EncodeToString(b))
+		codes[i] = raw[:4] + "-" + raw[4:]
+		hashes[i] = hashToken(raw)
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
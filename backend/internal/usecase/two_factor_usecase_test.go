@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"vehicle-showroom/internal/config"
+	"vehicle-showroom/internal/entity"
+	"vehicle-showroom/internal/repository"
+	"vehicle-showroom/internal/totp"
+)
+
+// failureCountingUserRepo keeps the failure counter and lock in memory the
+// way RecordFailedLogin does in SQL. Other methods are not expected.
+type failureCountingUserRepo struct {
+	repository.UserRepository
+	user *entity.User
+}
+
+func (r *failureCountingUserRepo) RecordFailedLogin(ctx context.Context, id int, maxAttempts int, lockUntil, now time.Time) (*entity.User, error) {
+	r.user.FailedLoginAttempts = consecutiveFailures(r.user, now) + 1
+	if r.user.FailedLoginAttempts >= maxAttempts {
+		r.user.LockedUntil = &lockUntil
+	}
+	updated := *r.user
+	return &updated, nil
+}
+
+func (r *failureCountingUserRepo) ResetFailedLogins(ctx context.Context, id int) error {
+	r.user.FailedLoginAttempts, r.user.LockedUntil = 0, nil
+	return nil
+}
+
+type enablingTwoFactorRepo struct {
+	repository.TwoFactorRepository
+}
+
+func (enablingTwoFactorRepo) ConsumeStep(context.Context, int, int64) (bool, error) { return true, nil }
+
+func (enablingTwoFactorRepo) Enable(context.Context, int, []string) error { return nil }
+
+type revokingSessionRepo struct {
+	repository.SessionRepository
+	kept   int
+	reason string
+}
+
+func (r *revokingSessionRepo) RevokeOthersByUserID(ctx context.Context, userID, keepSessionID int, reason string) error {
+	r.kept, r.reason = keepSessionID, reason
+	return nil
+}
+
+func TestEnableCountsWrongCodes(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &entity.User{ID: 4, TOTPSecret: &secret}
+	users := &failureCountingUserRepo{user: user}
+	sessions := &revokingSessionRepo{}
+	u := NewTwoFactorUsecase(users, sessions, enablingTwoFactorRepo{}, config.TwoFactorConfig{},
+		config.LoginConfig{MaxFailedAttempts: 3, LockoutMinutes: 15})
+
+	code := func() string {
+		c, err := totp.CodeAt(secret, totp.Step(time.Now()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	wrong := "000000"
+	if _, ok := totp.Validate(secret, wrong, time.Now(), totpSkew); ok {
+		wrong = "111111"
+	}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		_, err := u.Enable(context.Background(), user, 9, wrong)
+		want := entity.ErrValidation
+		if attempt == 3 {
+			want = entity.ErrLocked
+		}
+		if !errors.Is(err, want) {
+			t.Fatalf("attempt %d: err = %v, want %v", attempt, err, want)
+		}
+	}
+
+	// Locked: even the right code is refused until the lock expires
+	if _, err := u.Enable(context.Background(), user, 9, code()); !errors.Is(err, entity.ErrLocked) {
+		t.Fatalf("right code while locked: err = %v, want locked", err)
+	}
+	if sessions.reason != "" {
+		t.Errorf("sessions revoked while locked")
+	}
+
+	expired := time.Now().Add(-time.Minute)
+	user.LockedUntil = &expired
+	if _, err := u.Enable(context.Background(), user, 9, code()); err != nil {
+		t.Fatalf("right code after the lock: %v", err)
+	}
+	if user.FailedLoginAttempts != 0 || user.LockedUntil != nil {
+		t.Errorf("failures not reset: %d, %v", user.FailedLoginAttempts, user.LockedUntil)
+	}
+	if sessions.kept != 9 || sessions.reason != "two_factor_enabled" {
+		t.Errorf("RevokeOthersByUserID kept %d with reason %q", sessions.kept, sessions.reason)
+	}
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+  "context"
   "fmt"
   "time"
 
@@ -9,117 +10,185 @@ import (
 )
 
 type VehicleUsecase interface {
-  Create(req *entity.CreateVehicleRequest, purchasedBy int) (*entity.Vehicle, error)
-  GetByID(id int) (*entity.Vehicle, error)
-  List(page, limit int, search, status string) (*entity.VehicleListResponse, error)
-  Update(id int, req *entity.UpdateVehicleRequest) (*entity.Vehicle, error)
-  UpdateStatus(id int, status string) (*entity.Vehicle, error)
-  Delete(id int) error
+  Create(ctx context.Context, req *entity.CreateVehicleRequest, purchasedBy int) (*entity.Vehicle, error)
+  Import(ctx context.Context, rows []entity.ImportRow[entity.CreateVehicleRequest], purchasedBy int, dryRun bool) (*entity.ImportResult, error)
+  GetByID(ctx context.Context, id int) (*entity.Vehicle, error)
+  List(ctx context.Context, params entity.ListParams, filter entity.VehicleFilter, withFacets bool) (*entity.VehicleListResponse, error)
+  Update(ctx context.Context, id int, req *entity.UpdateVehicleRequest) (*entity.Vehicle, error)
+  UpdateStatus(ctx context.Context, id int, status string, customerID *int) (*entity.Vehicle, error)
+  ApprovePrice(ctx context.Context, id int, price float64, approvedBy int) (*entity.Vehicle, error)
+  Delete(ctx context.Context, id int) error
 }
 
 type vehicleUsecase struct {
-  vehicleRepo  repository.VehicleRepository
-  customerRepo repository.CustomerRepository
+  vehicleRepo   repository.VehicleRepository
+  customerRepo  repository.CustomerRepository
+  notifications NotificationUsecase
 }
 
-func NewVehicleUsecase(vehicleRepo repository.VehicleRepository, customerRepo repository.CustomerRepository) VehicleUsecase {
+func NewVehicleUsecase(vehicleRepo repository.VehicleRepository, customerRepo repository.CustomerRepository, notifications NotificationUsecase) VehicleUsecase {
   return &vehicleUsecase{
-    vehicleRepo:  vehicleRepo,
-    customerRepo: customerRepo,
+    vehicleRepo:   vehicleRepo,
+    customerRepo:  customerRepo,
+    notifications: notifications,
   }
 }
 
-func (u *vehicleUsecase) Create(req *entity.CreateVehicleRequest, purchasedBy int) (*entity.Vehicle, error) {
+func (u *vehicleUsecase) Create(ctx context.Context, req *entity.CreateVehicleRequest, purchasedBy int) (*entity.Vehicle, error) {
   // Validate customer if provided
   if req.PurchasedFromCustomerID != nil {
-    customer, err := u.customerRepo.GetByID(*req.PurchasedFromCustomerID)
+    customer, err := u.customerRepo.GetByID(ctx, *req.PurchasedFromCustomerID)
     if err != nil {
       return nil, fmt.Errorf("failed to validate customer: %w", err)
     }
     if customer == nil {
-      return nil, fmt.Errorf("customer not found")
+      return nil, entity.NewNotFoundError("customer")
     }
   }
   
   // Generate vehicle code
-  vehicleCode, err := u.vehicleRepo.GenerateVehicleCode()
+  vehicleCode, err := u.vehicleRepo.GenerateVehicleCode(ctx)
   if err != nil {
     return nil, fmt.Errorf("failed to generate vehicle code: %w", err)
   }
   
-  now := time.Now()
-  vehicle := &entity.Vehicle{
-    VehicleCode:             vehicleCode,
-    ChassisNumber:           req.ChassisNumber,
-    LicensePlate:            req.LicensePlate,
-    Brand:                   req.Brand,
-    Model:                   req.Model,
-    Variant:                 req.Variant,
-    Year:                    req.Year,
-    Color:                   req.Color,
-    Mileage:                 req.Mileage,
-    FuelType:                req.FuelType,
-    Transmission:            req.Transmission,
-    PurchasePrice:           req.PurchasePrice,
-    TotalRepairCost:         0,
-    Status:                  "purchased",
-    PurchasedFromCustomerID: req.PurchasedFromCustomerID,
-    PurchasedByCashier:      &purchasedBy,
-    PurchasedAt:             &now,
-    PurchaseNotes:           req.PurchaseNotes,
-    ConditionNotes:          req.ConditionNotes,
-  }
+  vehicle := newVehicle(req, purchasedBy, time.Now())
+  vehicle.VehicleCode = vehicleCode
   
-  if err := u.vehicleRepo.Create(vehicle); err != nil {
+  if err := u.vehicleRepo.Create(ctx, vehicle); err != nil {
     return nil, fmt.Errorf("failed to create vehicle: %w", err)
   }
   
   // Get the created vehicle with related data
-  return u.vehicleRepo.GetByID(vehicle.ID)
+  return u.vehicleRepo.GetByID(ctx, vehicle.ID)
+}
+
+// Import creates a vehicle per row as Create does, all in one transaction.
+// Besides the per-row checks it rejects chassis numbers that already exist
+// or repeat within the file, and customers that do not exist.
+func (u *vehicleUsecase) Import(ctx context.Context, rows []entity.ImportRow[entity.CreateVehicleRequest], purchasedBy int, dryRun bool) (*entity.ImportResult, error) {
+  firstRow := make(map[string]int, len(rows))
+  chassisNumbers := make([]string, 0, len(rows))
+  for i := range rows {
+    number := rows[i].Data.ChassisNumber
+    if number == "" {
+      continue
+    }
+    if first, ok := firstRow[number]; ok {
+      rows[i].Errors = append(rows[i].Errors, entity.FieldError{Field: "chassis_number", Message: fmt.Sprintf("repeats row %d", first)})
+      continue
+    }
+    firstRow[number] = rows[i].Row
+    chassisNumbers = append(chassisNumbers, number)
+  }
+  
+  existing, err := u.vehicleRepo.ExistingChassisNumbers(ctx, chassisNumbers)
+  if err != nil {
+    return nil, err
+  }
+  
+  customerExists := map[int]bool{}
+  for i := range rows {
+    row := &rows[i]
+    if existing[row.Data.ChassisNumber] {
+      row.Errors = append(row.Errors, entity.FieldError{Field: "chassis_number", Message: "already exists"})
+    }
+    
+    customerID := row.Data.PurchasedFromCustomerID
+    if customerID == nil {
+      continue
+    }
+    exists, checked := customerExists[*customerID]
+    if !checked {
+      customer, err := u.customerRepo.GetByID(ctx, *customerID)
+      if err != nil {
+        return nil, fmt.Errorf("failed to validate customer: %w", err)
+      }
+      exists = customer != nil
+      customerExists[*customerID] = exists
+    }
+    if !exists {
+      row.Errors = append(row.Errors, entity.FieldError{Field: "purchased_from_customer_id", Message: "refers to a customer that does not exist"})
+    }
+  }
+  
+  result := entity.NewImportResult(rows, dryRun)
+  if dryRun {
+    return result, nil
+  }
+  if len(result.Errors) > 0 {
+    return nil, result.Rejected()
+  }
+  
+  now := time.Now()
+  vehicles := make([]*entity.Vehicle, len(rows))
+  for i := range rows {
+    vehicles[i] = newVehicle(&rows[i].Data, purchasedBy, now)
+  }
+  if err := u.vehicleRepo.CreateBatch(ctx, vehicles); err != nil {
+    return nil, fmt.Errorf("failed to import vehicles: %w", err)
+  }
+  
+  result.Created = len(vehicles)
+  for _, vehicle := range vehicles {
+    result.Codes = append(result.Codes, vehicle.VehicleCode)
+  }
+  return result, nil
 }
 
-func (u *vehicleUsecase) GetByID(id int) (*entity.Vehicle, error) {
-  vehicle, err := u.vehicleRepo.GetByID(id)
+func (u *vehicleUsecase) GetByID(ctx context.Context, id int) (*entity.Vehicle, error) {
+  vehicle, err := u.vehicleRepo.GetByID(ctx, id)
   if err != nil {
     return nil, fmt.Errorf("failed to get vehicle: %w", err)
   }
   
   if vehicle == nil {
-    return nil, fmt.Errorf("vehicle not found")
+    return nil, entity.NewNotFoundError("vehicle")
   }
   
   return vehicle, nil
 }
 
-func (u *vehicleUsecase) List(page, limit int, search, status string) (*entity.VehicleListResponse, error) {
-  if page <= 0 {
-    page = 1
-  }
-  if limit <= 0 || limit > 100 {
-    limit = 10
+// List returns a page of the vehicles matching filter and, with withFacets,
+// their counts per brand, fuel type and transmission.
+func (u *vehicleUsecase) List(ctx context.Context, params entity.ListParams, filter entity.VehicleFilter, withFacets bool) (*entity.VehicleListResponse, error) {
+  params.Normalize(10, 100)
+  if err := filter.Validate(); err != nil {
+    return nil, err
   }
   
-  vehicles, total, err := u.vehicleRepo.List(page, limit, search, status)
+  vehicles, page, err := u.vehicleRepo.List(ctx, params, filter)
   if err != nil {
     return nil, fmt.Errorf("failed to list vehicles: %w", err)
   }
   
+  var facets *entity.VehicleFacets
+  if withFacets {
+    facets, err = u.vehicleRepo.Facets(ctx, filter)
+    if err != nil {
+      return nil, fmt.Errorf("failed to count vehicle facets: %w", err)
+    }
+  }
+  
   return &entity.VehicleListResponse{
-    Vehicles: vehicles,
-    Total:    total,
-    Page:     page,
-    Limit:    limit,
+    Vehicles:   vehicles,
+    Total:      page.Total,
+    HasMore:    page.HasMore,
+    NextCursor: page.NextCursor,
+    Page:       params.ResponsePage(),
+    Limit:      params.Limit,
+    Facets:     facets,
   }, nil
 }
 
-func (u *vehicleUsecase) Update(id int, req *entity.UpdateVehicleRequest) (*entity.Vehicle, error) {
-  vehicle, err := u.vehicleRepo.GetByID(id)
+func (u *vehicleUsecase) Update(ctx context.Context, id int, req *entity.UpdateVehicleRequest) (*entity.Vehicle, error) {
+  vehicle, err := u.vehicleRepo.GetByID(ctx, id)
   if err != nil {
     return nil, fmt.Errorf("failed to get vehicle: %w", err)
   }
   
   if vehicle == nil {
-    return nil, fmt.Errorf("vehicle not found")
+    return nil, entity.NewNotFoundError("vehicle")
   }
   
   vehicle.LicensePlate = req.LicensePlate
@@ -131,47 +200,150 @@ func (u *vehicleUsecase) Update(id int, req *entity.UpdateVehicleRequest) (*enti
   vehicle.Mileage = req.Mileage
   vehicle.FuelType = req.FuelType
   vehicle.Transmission = req.Transmission
+  priceChanged := !samePrice(vehicle.SuggestedSellingPrice, req.SuggestedSellingPrice)
   vehicle.SuggestedSellingPrice = req.SuggestedSellingPrice
   vehicle.PurchaseNotes = req.PurchaseNotes
   vehicle.ConditionNotes = req.ConditionNotes
   
-  if err := u.vehicleRepo.Update(vehicle); err != nil {
+  if err := u.vehicleRepo.Update(ctx, vehicle); err != nil {
     return nil, fmt.Errorf("failed to update vehicle: %w", err)
   }
+  if priceChanged && vehicle.SuggestedSellingPrice != nil && vehicle.Status != "sold" {
+    u.requestPriceApproval(ctx, vehicle)
+  }
   
-  return u.vehicleRepo.GetByID(id)
+  return u.vehicleRepo.GetByID(ctx, id)
 }
 
-func (u *vehicleUsecase) UpdateStatus(id int, status string) (*entity.Vehicle, error) {
-  vehicle, err := u.vehicleRepo.GetByID(id)
+// UpdateStatus moves the vehicle to status. A reservation may name the
+// customer it is held for; reserving again changes who that is.
+func (u *vehicleUsecase) UpdateStatus(ctx context.Context, id int, status string, customerID *int) (*entity.Vehicle, error) {
+  if customerID != nil && status != "reserved" {
+    return nil, entity.NewFieldError("customer_id", "can only be given when reserving")
+  }
+  
+  vehicle, err := u.vehicleRepo.GetByID(ctx, id)
   if err != nil {
     return nil, fmt.Errorf("failed to get vehicle: %w", err)
   }
   
   if vehicle == nil {
-    return nil, fmt.Errorf("vehicle not found")
+    return nil, entity.NewNotFoundError("vehicle")
+  }
+  
+  if vehicle.Status == status && status != "reserved" {
+    return vehicle, nil
   }
   
-  if err := u.vehicleRepo.UpdateStatus(id, status); err != nil {
+  // Sales go through a transaction so the price and buyer are recorded, and
+  // a sold vehicle stays sold
+  if vehicle.Status == "sold" {
+    return nil, entity.NewInvalidTransitionError("vehicle is already sold")
+  }
+  if status == "sold" {
+    return nil, entity.NewInvalidTransitionError("record a sales transaction to mark a vehicle as sold")
+  }
+  
+  if status == "reserved" {
+    if customerID != nil {
+      customer, err := u.customerRepo.GetByID(ctx, *customerID)
+      if err != nil {
+        return nil, fmt.Errorf("failed to validate customer: %w", err)
+      }
+      if customer == nil {
+        return nil, entity.NewNotFoundError("customer")
+      }
+    }
+    if err := u.vehicleRepo.Reserve(ctx, id, customerID); err != nil {
+      return nil, fmt.Errorf("failed to reserve vehicle: %w", err)
+    }
+    return u.vehicleRepo.GetByID(ctx, id)
+  }
+  
+  if err := u.vehicleRepo.UpdateStatus(ctx, id, status); err != nil {
     return nil, fmt.Errorf("failed to update vehicle status: %w", err)
   }
   
-  return u.vehicleRepo.GetByID(id)
+  return u.vehicleRepo.GetByID(ctx, id)
 }
 
-func (u *vehicleUsecase) Delete(id int) error {
-  vehicle, err := u.vehicleRepo.GetByID(id)
+func (u *vehicleUsecase) ApprovePrice(ctx context.Context, id int, price float64, approvedBy int) (*entity.Vehicle, error) {
+  vehicle, err := u.vehicleRepo.GetByID(ctx, id)
+  if err != nil {
+    return nil, fmt.Errorf("failed to get vehicle: %w", err)
+  }
+  
+  if vehicle == nil {
+    return nil, entity.NewNotFoundError("vehicle")
+  }
+  
+  if vehicle.Status == "sold" {
+    return nil, entity.NewInvalidTransitionError("vehicle is already sold")
+  }
+  
+  if err := u.vehicleRepo.ApprovePrice(ctx, id, price, approvedBy); err != nil {
+    return nil, fmt.Errorf("failed to approve vehicle price: %w", err)
+  }
+  
+  return u.vehicleRepo.GetByID(ctx, id)
+}
+
+func (u *vehicleUsecase) Delete(ctx context.Context, id int) error {
+  vehicle, err := u.vehicleRepo.GetByID(ctx, id)
   if err != nil {
     return fmt.Errorf("failed to get vehicle: %w", err)
   }
   
   if vehicle == nil {
-    return fmt.Errorf("vehicle not found")
+    return entity.NewNotFoundError("vehicle")
   }
   
-  if err := u.vehicleRepo.Delete(id); err != nil {
+  if err := u.vehicleRepo.Delete(ctx, id); err != nil {
     return fmt.Errorf("failed to delete vehicle: %w", err)
   }
   
   return nil
 }
+
+// requestPriceApproval tells the users who approve prices that the
+// vehicle's suggested selling price changed.
+func (u *vehicleUsecase) requestPriceApproval(ctx context.Context, vehicle *entity.Vehicle) {
+  u.notifications.NotifyPermission(ctx, entity.PermVehiclePriceApprove, entity.NewNotification{
+    Type:       entity.NotificationPriceApproval,
+    Title:      fmt.Sprintf("Price approval needed for %s %s %s", vehicle.VehicleCode, vehicle.Brand, vehicle.Model),
+    Body:       fmt.Sprintf("Suggested selling price: %.0f", *vehicle.SuggestedSellingPrice),
+    EntityType: entity.TaskEntityVehicle,
+    EntityID:   vehicle.ID,
+  })
+}
+
+func samePrice(a, b *float64) bool {
+  if a == nil || b == nil {
+    return a == b
+  }
+  return *a == *b
+}
+
+// newVehicle is the vehicle a create request describes, still without a code.
+func newVehicle(req *entity.CreateVehicleRequest, purchasedBy int, now time.Time) *entity.Vehicle {
+  return &entity.Vehicle{
+    ChassisNumber:           req.ChassisNumber,
+    LicensePlate:            req.LicensePlate,
+    Brand:                   req.Brand,
+    Model:                   req.Model,
+    Variant:                 req.Variant,
+    Year:                    req.Year,
+    Color:                   req.Color,
+    Mileage:                 req.Mileage,
+    FuelType:                req.FuelType,
+    Transmission:            req.Transmission,
+    PurchasePrice:           req.PurchasePrice,
+    TotalRepairCost:         0,
+    Status:                  "purchased",
+    PurchasedFromCustomerID: req.PurchasedFromCustomerID,
+    PurchasedByCashier:      &purchasedBy,
+    PurchasedAt:             &now,
+    PurchaseNotes:           req.PurchaseNotes,
+    ConditionNotes:          req.ConditionNotes,
+  }
+}
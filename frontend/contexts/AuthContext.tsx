@@ -13,10 +13,16 @@ interface User {
   updated_at: string;
 }
 
+interface LoginResult {
+  twoFactorRequired: boolean;
+  challengeToken?: string;
+}
+
 interface AuthContextType {
   user: User | null;
   loading: boolean;
-  login: (username: string, password: string) => Promise<void>;
+  login: (username: string, password: string) => Promise<LoginResult>;
+  verifyTwoFactor: (challengeToken: string, code: string) => Promise<void>;
   logout: () => Promise<void>;
 }
 
@@ -44,10 +50,24 @@ export function AuthProvider({ children }: { children: React.ReactNode }) {
     }
   };
 
-  const login = async (username: string, password: string) => {
+  const storeSession = (response: Awaited<ReturnType<typeof authService.login>>) => {
+    localStorage.setItem('auth_token', response.token!);
+    localStorage.setItem('refresh_token', response.refresh_token!);
+    setUser(response.user!);
+  };
+
+  const login = async (username: string, password: string): Promise<LoginResult> => {
     const response = await authService.login(username, password);
-    localStorage.setItem('auth_token', response.token);
-    setUser(response.user);
+    if (response.two_factor_required) {
+      return { twoFactorRequired: true, challengeToken: response.challenge_token };
+    }
+    storeSession(response);
+    return { twoFactorRequired: false };
+  };
+
+  const verifyTwoFactor = async (challengeToken: string, code: string) => {
+    const response = await authService.verifyTwoFactor(challengeToken, code);
+    storeSession(response);
   };
 
   const logout = async () => {
@@ -57,12 +77,13 @@ export function AuthProvider({ children }: { children: React.ReactNode }) {
       console.error('Logout error:', error);
     } finally {
       localStorage.removeItem('auth_token');
+      localStorage.removeItem('refresh_token');
       setUser(null);
     }
   };
 
   return (
-    <AuthContext.Provider value={{ user, loading, login, logout }}>
+    <AuthContext.Provider value={{ user, loading, login, verifyTwoFactor, logout }}>
       {children}
     </AuthContext.Provider>
   );
@@ -2,14 +2,14 @@ import React, { useState } from 'react';
 import { Button } from '@/components/ui/button';
 import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '@/components/ui/card';
 import { Tabs, TabsContent, TabsList, TabsTrigger } from '@/components/ui/tabs';
-import { ArrowLeft, BarChart3, Calendar as CalendarIcon } from 'lucide-react';
+import { ArrowLeft, BarChart3, Calendar as CalendarIcon, Download } from 'lucide-react';
 import { useNavigate } from 'react-router-dom';
 import { DateRange } from 'react-day-picker';
 import { addDays, format } from 'date-fns';
 import { Popover, PopoverContent, PopoverTrigger } from '@/components/ui/popover';
 import { Calendar } from '@/components/ui/calendar';
 import { cn } from '@/lib/utils';
-import { reportService, VehicleProfitability } from '../services/reportService';
+import { ExportFormat, ReportName, reportService, VehicleProfitability } from '../services/reportService';
 import { useToast } from '@/components/ui/use-toast';
 import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from '@/components/ui/table';
 import { SalesTransaction, PurchaseTransaction } from '../services/transactionService';
@@ -30,6 +30,39 @@ const formatDate = (dateString: string) => {
 	});
 };
 
+function ExportButtons({ report, date }: { report: ReportName; date: DateRange | undefined }) {
+	const [exporting, setExporting] = useState<ExportFormat | null>(null);
+	const { toast } = useToast();
+
+	const handleExport = async (exportFormat: ExportFormat) => {
+		if (!date?.from || !date?.to) {
+			toast({ title: 'Error', description: 'Please select a date range.', variant: 'destructive' });
+			return;
+		}
+		setExporting(exportFormat);
+		try {
+			await reportService.exportReport(report, format(date.from, 'yyyy-MM-dd'), format(date.to, 'yyyy-MM-dd'), exportFormat);
+		} catch (error) {
+			toast({ title: 'Error', description: 'Failed to export report.', variant: 'destructive' });
+		} finally {
+			setExporting(null);
+		}
+	};
+
+	return (
+		<>
+			<Button variant="outline" onClick={() => handleExport('xlsx')} disabled={exporting !== null}>
+				<Download className="h-4 w-4 mr-2" />
+				{exporting === 'xlsx' ? 'Exporting...' : 'Excel'}
+			</Button>
+			<Button variant="outline" onClick={() => handleExport('csv')} disabled={exporting !== null}>
+				<Download className="h-4 w-4 mr-2" />
+				{exporting === 'csv' ? 'Exporting...' : 'CSV'}
+			</Button>
+		</>
+	);
+}
+
 function ProfitabilityReportTab() {
 	const [date, setDate] = useState<DateRange | undefined>({
 		from: addDays(new Date(), -30),
@@ -70,6 +103,7 @@ function ProfitabilityReportTab() {
 					<Button onClick={handleGenerate} disabled={loading}>
 						{loading ? 'Generating...' : 'Generate Report'}
 					</Button>
+					<ExportButtons report="profitability" date={date} />
 				</div>
 				<div className="border rounded-md">
 					<Table>
@@ -118,13 +152,14 @@ function ProfitabilityReportTab() {
 // Similar components for SalesReportTab and PurchaseReportTab can be created
 // For brevity, I'll create a generic report tab component
 interface ReportTabProps<T> {
+	report: ReportName;
 	title: string;
 	description: string;
 	fetchData: (startDate: string, endDate: string) => Promise<T[]>;
 	columns: { header: string; accessor: (item: T) => React.ReactNode; className?: string }[];
 }
 
-function GenericReportTab<T>({ title, description, fetchData, columns }: ReportTabProps<T>) {
+function GenericReportTab<T>({ report, title, description, fetchData, columns }: ReportTabProps<T>) {
 	const [date, setDate] = useState<DateRange | undefined>({
 		from: addDays(new Date(), -30),
 		to: new Date(),
@@ -161,6 +196,7 @@ function GenericReportTab<T>({ title, description, fetchData, columns }: ReportT
 					<Button onClick={handleGenerate} disabled={loading}>
 						{loading ? 'Generating...' : 'Generate Report'}
 					</Button>
+					<ExportButtons report={report} date={date} />
 				</div>
 				<div className="border rounded-md">
 					<Table>
@@ -240,6 +276,7 @@ export default function ReportsPage() {
 					</TabsContent>
 					<TabsContent value="sales">
 						<GenericReportTab
+							report="sales"
 							title="Sales Report"
 							description="View all sales transactions within a specific date range."
 							fetchData={reportService.getSalesReport}
@@ -248,6 +285,7 @@ export default function ReportsPage() {
 					</TabsContent>
 					<TabsContent value="purchases">
 						<GenericReportTab
+							report="purchases"
 							title="Purchase Report"
 							description="View all purchase transactions within a specific date range."
 							fetchData={reportService.getPurchaseReport}
@@ -11,6 +11,7 @@ import { useNavigate } from 'react-router-dom';
 import { transactionService, CreateSalesTransactionRequest } from '../services/transactionService';
 import { customerService, Customer } from '../services/customerService';
 import { vehicleService, Vehicle } from '../services/vehicleService';
+import { getApiError } from '../services/apiClient';
 
 export default function SalesFormPage() {
   const [formData, setFormData] = useState<CreateSalesTransactionRequest>({
@@ -100,9 +101,12 @@ export default function SalesFormPage() {
       navigate('/transactions');
     } catch (error) {
       console.error('Failed to create sales transaction:', error);
+      const apiError = getApiError(error);
       toast({
         title: "Error",
-        description: "Failed to create sales transaction",
+        description: apiError?.code === 'INVALID_TRANSITION' || apiError?.code === 'VALIDATION_FAILED'
+          ? apiError.message
+          : "Failed to create sales transaction",
         variant: "destructive",
       });
     } finally {
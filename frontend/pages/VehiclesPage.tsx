@@ -7,7 +7,7 @@ import { Badge } from '@/components/ui/badge';
 import { useToast } from '@/components/ui/use-toast';
 import { Search, Plus, Edit, Trash2, ArrowLeft, Car, Calendar, Gauge, Fuel, Settings } from 'lucide-react';
 import { useNavigate } from 'react-router-dom';
-import { vehicleService, Vehicle } from '../services/vehicleService';
+import { vehicleService, Vehicle, VehicleFacets, VehicleFilters } from '../services/vehicleService';
 import { useAuth } from '../contexts/AuthContext';
 
 const statusColors = {
@@ -26,6 +26,18 @@ const statusLabels = {
   sold: 'Sold',
 };
 
+type FacetKey = 'brand' | 'fuel_type' | 'transmission';
+type RangeKey = 'year_min' | 'year_max' | 'price_min' | 'price_max' | 'mileage_max' | 'days_in_stock_min';
+
+const rangeInputs: { key: RangeKey; placeholder: string }[] = [
+  { key: 'year_min', placeholder: 'Year from' },
+  { key: 'year_max', placeholder: 'Year to' },
+  { key: 'price_min', placeholder: 'Min price' },
+  { key: 'price_max', placeholder: 'Max price' },
+  { key: 'mileage_max', placeholder: 'Max mileage (km)' },
+  { key: 'days_in_stock_min', placeholder: 'Min days in stock' },
+];
+
 export default function VehiclesPage() {
   const [vehicles, setVehicles] = useState<Vehicle[]>([]);
   const [loading, setLoading] = useState(true);
@@ -33,6 +45,8 @@ export default function VehiclesPage() {
   const [statusFilter, setStatusFilter] = useState('');
   const [page, setPage] = useState(1);
   const [total, setTotal] = useState(0);
+  const [filters, setFilters] = useState<VehicleFilters>({});
+  const [facets, setFacets] = useState<VehicleFacets | null>(null);
   const navigate = useNavigate();
   const { toast } = useToast();
   const { user } = useAuth();
@@ -46,14 +60,15 @@ export default function VehiclesPage() {
 
   useEffect(() => {
     loadVehicles();
-  }, [page, search, statusFilter]);
+  }, [page, search, statusFilter, filters]);
 
   const loadVehicles = async () => {
     try {
       setLoading(true);
-      const response = await vehicleService.list(page, limit, search, statusFilter);
+      const response = await vehicleService.list(page, limit, search, statusFilter, filters, true);
       setVehicles(response.vehicles);
-      setTotal(response.total);
+      setTotal(response.total ?? 0);
+      setFacets(response.facets ?? null);
     } catch (error) {
       console.error('Failed to load vehicles:', error);
       toast({
@@ -76,6 +91,40 @@ export default function VehiclesPage() {
     setPage(1);
   };
 
+  const toggleFacet = (key: FacetKey, value: string) => {
+    setFilters((current) => {
+      const selected = current[key] ?? [];
+      const next = selected.includes(value)
+        ? selected.filter((v) => v !== value)
+        : [...selected, value];
+      return { ...current, [key]: next };
+    });
+    setPage(1);
+  };
+
+  const handleRange = (key: RangeKey, value: string) => {
+    setFilters((current) => ({ ...current, [key]: value === '' ? undefined : Number(value) }));
+    setPage(1);
+  };
+
+  const clearFilters = () => {
+    setFilters({});
+    setPage(1);
+  };
+
+  const facetGroups: { key: FacetKey; label: string }[] = [
+    { key: 'brand', label: 'Brand' },
+    { key: 'fuel_type', label: 'Fuel' },
+    { key: 'transmission', label: 'Transmission' },
+  ];
+  const facetCounts = (key: FacetKey) => {
+    if (!facets) return [];
+    return key === 'brand' ? facets.brands : key === 'fuel_type' ? facets.fuel_types : facets.transmissions;
+  };
+  const hasFilters = Object.values(filters).some((value) =>
+    Array.isArray(value) ? value.length > 0 : value !== undefined
+  );
+
   const handleStatusChange = async (vehicleId: number, newStatus: string) => {
     try {
       await vehicleService.updateStatus(vehicleId, newStatus);
@@ -188,6 +237,42 @@ export default function VehiclesPage() {
                 </SelectContent>
               </Select>
             </div>
+            <div className="grid grid-cols-2 md:grid-cols-6 gap-4 mt-4">
+              {rangeInputs.map(({ key, placeholder }) => (
+                <Input
+                  key={key}
+                  type="number"
+                  min={0}
+                  placeholder={placeholder}
+                  value={filters[key] ?? ''}
+                  onChange={(e) => handleRange(key, e.target.value)}
+                />
+              ))}
+            </div>
+            {facets && (
+              <div className="mt-4 space-y-2">
+                {facetGroups.map(({ key, label }) => facetCounts(key).length > 0 && (
+                  <div key={key} className="flex flex-wrap items-center gap-2">
+                    <span className="text-sm text-gray-500 w-24">{label}</span>
+                    {facetCounts(key).map((facet) => (
+                      <Badge
+                        key={facet.value}
+                        variant={filters[key]?.includes(facet.value) ? 'default' : 'outline'}
+                        className="cursor-pointer capitalize"
+                        onClick={() => toggleFacet(key, facet.value)}
+                      >
+                        {facet.value} ({facet.count})
+                      </Badge>
+                    ))}
+                  </div>
+                ))}
+                {hasFilters && (
+                  <Button variant="ghost" size="sm" onClick={clearFilters}>
+                    Clear filters
+                  </Button>
+                )}
+              </div>
+            )}
           </CardContent>
         </Card>
 
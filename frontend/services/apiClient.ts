@@ -23,14 +23,61 @@ apiClient.interceptors.request.use(
   }
 );
 
+// Shared between concurrent 401s so the refresh token is only rotated once
+let refreshPromise: Promise<string> | null = null;
+
+const refreshAccessToken = async (): Promise<string> => {
+  const refreshToken = localStorage.getItem('refresh_token');
+  if (!refreshToken) {
+    throw new Error('No refresh token');
+  }
+  const response = await axios.post(`${API_BASE_URL}/auth/refresh`, { refresh_token: refreshToken });
+  const { token, refresh_token } = response.data.data;
+  localStorage.setItem('auth_token', token);
+  localStorage.setItem('refresh_token', refresh_token);
+  return token;
+};
+
 // Response interceptor to handle auth errors
 apiClient.interceptors.response.use(
   (response) => response,
-  (error) => {
+  async (error) => {
+    const original = error.config;
+    if (error.response?.status === 401 && original && !original._retry && !original.url?.startsWith('/auth/')) {
+      original._retry = true;
+      try {
+        refreshPromise = refreshPromise ?? refreshAccessToken();
+        const token = await refreshPromise;
+        original.headers.Authorization = `Bearer ${token}`;
+        return apiClient(original);
+      } catch {
+        // fall through to logout
+      } finally {
+        refreshPromise = null;
+      }
+    }
     if (error.response?.status === 401) {
       localStorage.removeItem('auth_token');
+      localStorage.removeItem('refresh_token');
       window.location.href = '/login';
     }
     return Promise.reject(error);
   }
 );
+
+// Every error response from the API has this shape; switch on `code`, not on
+// the HTTP status or the message text.
+export interface ApiError {
+  code: string;
+  message: string;
+  fields?: { field: string; message: string }[];
+  details?: Record<string, unknown>;
+  request_id?: string;
+}
+
+export const getApiError = (error: unknown): ApiError | null => {
+  if (axios.isAxiosError(error) && error.response?.data?.error?.code) {
+    return error.response.data.error as ApiError;
+  }
+  return null;
+};
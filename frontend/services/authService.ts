@@ -6,8 +6,13 @@ interface LoginRequest {
 }
 
 interface LoginResponse {
-  token: string;
-  user: {
+  token?: string;
+  refresh_token?: string;
+  expires_in: number;
+  two_factor_required?: boolean;
+  challenge_token?: string;
+  two_factor_setup_required?: boolean;
+  user?: {
     id: number;
     username: string;
     email: string;
@@ -38,6 +43,11 @@ export const authService = {
     return response.data.data;
   },
 
+  async verifyTwoFactor(challengeToken: string, code: string): Promise<LoginResponse> {
+    const response = await apiClient.post('/auth/login/2fa', { challenge_token: challengeToken, code });
+    return response.data.data;
+  },
+
   async logout(): Promise<void> {
     await apiClient.post('/auth/logout');
   },
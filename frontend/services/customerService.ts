@@ -36,6 +36,8 @@ export interface UpdateCustomerRequest {
 export interface CustomerListResponse {
   customers: Customer[];
   total: number;
+  has_more: boolean;
+  next_cursor?: string;
   page: number;
   limit: number;
 }
@@ -64,6 +64,8 @@ export interface AddPartToRepairRequest {
 export interface RepairListResponse {
   repairs: Repair[];
   total: number;
+  has_more: boolean;
+  next_cursor?: string;
   page: number;
   limit: number;
 }
@@ -14,6 +14,9 @@ export interface VehicleProfitability {
 	sold_at: string;
 }
 
+export type ReportName = 'profitability' | 'sales' | 'purchases';
+export type ExportFormat = 'csv' | 'xlsx';
+
 export const reportService = {
 	async getVehicleProfitability(startDate: string, endDate: string): Promise<VehicleProfitability[]> {
 		const response = await apiClient.get('/reports/profitability', {
@@ -35,4 +38,18 @@ export const reportService = {
 		});
 		return response.data.data;
 	},
+
+	// Downloads the report as a file; column titles follow the browser's language
+	async exportReport(report: ReportName, startDate: string, endDate: string, format: ExportFormat): Promise<void> {
+		const response = await apiClient.get(`/reports/${report}`, {
+			params: { start_date: startDate, end_date: endDate, format },
+			responseType: 'blob',
+		});
+		const url = URL.createObjectURL(response.data);
+		const link = document.createElement('a');
+		link.href = url;
+		link.download = `${report}-report-${startDate}-${endDate}.${format}`;
+		link.click();
+		URL.revokeObjectURL(url);
+	},
 };
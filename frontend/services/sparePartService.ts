@@ -41,6 +41,8 @@ export interface UpdateSparePartRequest {
 export interface SparePartListResponse {
   spare_parts: SparePart[];
   total: number;
+  has_more: boolean;
+  next_cursor?: string;
   page: number;
   limit: number;
 }
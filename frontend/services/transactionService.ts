@@ -76,6 +76,8 @@ export interface CreateSalesTransactionRequest {
 export interface TransactionListResponse {
   transactions: PurchaseTransaction[] | SalesTransaction[];
   total: number;
+  has_more: boolean;
+  next_cursor?: string;
   page: number;
   limit: number;
 }
@@ -70,14 +70,52 @@ export interface UpdateVehicleRequest {
 export interface VehicleListResponse {
   vehicles: Vehicle[];
   total: number;
+  has_more: boolean;
+  next_cursor?: string;
   page: number;
   limit: number;
+  facets?: VehicleFacets;
 }
 
+export interface FacetCount {
+  value: string;
+  count: number;
+}
+
+export interface VehicleFacets {
+  brands: FacetCount[];
+  fuel_types: FacetCount[];
+  transmissions: FacetCount[];
+}
+
+export interface VehicleFilters {
+  brand?: string[];
+  model?: string[];
+  fuel_type?: string[];
+  transmission?: string[];
+  color?: string[];
+  year_min?: number;
+  year_max?: number;
+  price_min?: number;
+  price_max?: number;
+  mileage_min?: number;
+  mileage_max?: number;
+  days_in_stock_min?: number;
+  days_in_stock_max?: number;
+}
+
+// List parameters are sent comma-separated; empty ones are left out
+const filterParams = (filters: VehicleFilters) =>
+  Object.fromEntries(
+    Object.entries(filters)
+      .map(([key, value]) => [key, Array.isArray(value) ? value.join(',') : value])
+      .filter(([, value]) => value !== undefined && value !== '')
+  );
+
 export const vehicleService = {
-  async list(page = 1, limit = 10, search = '', status = ''): Promise<VehicleListResponse> {
+  async list(page = 1, limit = 10, search = '', status = '', filters?: VehicleFilters, facets = false): Promise<VehicleListResponse> {
     const response = await apiClient.get('/vehicles', {
-      params: { page, limit, search, status }
+      params: { page, limit, search, status, ...(filters ? filterParams(filters) : {}), ...(facets ? { facets } : {}) }
     });
     return response.data.data;
   },